@@ -0,0 +1,343 @@
+// Command server starts the orchestrator's HTTP API.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/api"
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/broker"
+	"github.com/fengmingli/orchestrator/internal/db"
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/fieldcrypt"
+	"github.com/fengmingli/orchestrator/internal/grafana"
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/notify"
+	"github.com/fengmingli/orchestrator/internal/service"
+	"github.com/fengmingli/orchestrator/internal/shellpolicy"
+	"github.com/fengmingli/orchestrator/internal/stepsign"
+)
+
+// DefaultWorkerPoolConcurrency bounds how many executions run at once
+// when ORCHESTRATOR_WORKER_CONCURRENCY is unset.
+const DefaultWorkerPoolConcurrency = 8
+
+// DefaultArtifactDir is where artifacts are stored when
+// ORCHESTRATOR_ARTIFACT_DIR is unset.
+const DefaultArtifactDir = "artifacts"
+
+// DefaultStepBulkheadCapacity bounds how many steps the orchestrator
+// runs at once across every execution when ORCHESTRATOR_STEP_BULKHEAD
+// is unset. Zero means unbounded, matching the orchestrator's
+// behavior before this bulkhead existed.
+const DefaultStepBulkheadCapacity = 0
+
+func main() {
+	dsn := os.Getenv("ORCHESTRATOR_DSN")
+	if dsn == "" {
+		dsn = "orchestrator.db"
+	}
+
+	gormDB, err := db.Open(dsn)
+	if err != nil {
+		log.Fatalf("open db: %v", err)
+	}
+
+	shellPolicy, err := shellPolicy()
+	if err != nil {
+		log.Fatalf("shell policy: %v", err)
+	}
+
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{Policy: shellPolicy, AllowedRunAsUsers: os.Getenv("ORCHESTRATOR_SHELL_RUN_AS_ALLOWLIST")})
+	registry.Register("http", executor.NewRetryableExecutor(executor.HTTPTask{}))
+	registry.Register("graphql", executor.NewRetryableExecutor(executor.GraphQLTask{}))
+	registry.Register("jenkins_job", executor.JenkinsJobTask{})
+	registry.Register("gitlab_pipeline", executor.GitLabPipelineTask{})
+	registry.Register("wasm", executor.WASMTask{})
+	registry.Register("script", executor.ScriptTask{})
+	registry.Register("file_transfer", executor.NewRetryableExecutor(executor.FileTransferTask{}))
+	registry.Register("aws_action", executor.AWSActionTask{})
+	registry.Register("gcp_action", executor.GCPActionTask{})
+	registry.Register("ansible_playbook", executor.AnsiblePlaybookTask{})
+	registry.Register("service_control", executor.ServiceControlTask{})
+	registry.Register("db_backup", executor.DatabaseBackupTask{})
+	registry.Register("db_restore", executor.DatabaseRestoreTask{})
+	registry.Register("consume_gate", executor.ConsumeGateTask{Consumers: map[string]broker.Consumer{"memory": broker.NewMemoryConsumer()}})
+	registry.Register("fan_out", executor.FanOutTask{Registry: registry})
+	registry.Register("poll", executor.PollTask{Registry: registry})
+	registry.Register("verify", executor.HealthCheckTask{Registry: registry})
+	registry.Register("external", executor.ExternalTask{})
+	if err := loadPlugins(registry); err != nil {
+		log.Fatalf("load plugins: %v", err)
+	}
+	for _, stepType := range disabledStepTypes() {
+		registry.Disable(stepType)
+	}
+
+	configKeys, err := configEncryptionKeys()
+	if err != nil {
+		log.Fatalf("config encryption keys: %v", err)
+	}
+
+	signer, err := stepsign.SignerFromEnvVar("ORCHESTRATOR_STEP_SIGNING_KEY")
+	if err != nil {
+		log.Fatalf("step signing key: %v", err)
+	}
+
+	artifactStore := artifact.NewLocalStore(artifactDir())
+	opts := []executor.Option{executor.WithArtifactStore(artifactStore), executor.WithBulkhead(stepBulkheadCapacity())}
+	if annotator := grafanaAnnotator(); annotator != nil {
+		opts = append(opts, executor.WithHook(annotator))
+	}
+	if configKeys != nil {
+		opts = append(opts, executor.WithFieldCrypt(configKeys))
+	}
+	redactions := service.NewRedactionService(gormDB)
+	opts = append(opts, executor.WithRedactor(redactions))
+	if signer != nil {
+		opts = append(opts, executor.WithStepSigner(signer))
+	}
+	if journalDir := statusJournalDir(); journalDir != "" {
+		if err := os.MkdirAll(journalDir, 0o755); err != nil {
+			log.Fatalf("status journal dir: %v", err)
+		}
+		opts = append(opts, executor.WithStatusJournal(journalDir))
+	}
+	router, err := teamRouter()
+	if err != nil {
+		log.Fatalf("team webhooks: %v", err)
+	}
+	if router != nil {
+		opts = append(opts, executor.WithTeamRouter(router))
+	}
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry, opts...)
+	blackouts := service.NewBlackoutService(gormDB)
+	pool := executor.NewWorkerPool(orchestrator, workerPoolConcurrency(), executor.WithBlackoutChecker(blackouts))
+	templates := service.NewTemplateService(gormDB, configKeys, shellPolicy, signer)
+	quotas := service.NewQuotaService(gormDB)
+	executions := service.NewExecutionService(gormDB, orchestrator, quotas, pool, blackouts)
+	search := service.NewSearchService(gormDB)
+	labels := service.NewLabelService(gormDB)
+	layout := service.NewLayoutService(gormDB)
+	artifacts := service.NewArtifactService(gormDB, artifactStore)
+	locks := service.NewLockService(gormDB, map[string]lock.Provider{"memory": lock.NewMemoryLockProvider()})
+	shares := service.NewShareService(gormDB, shareSecret())
+	agents := service.NewAgentService(gormDB)
+	alerts := service.NewAlertService(gormDB, executions, labels)
+	if threshold := watchdogThreshold(); threshold > 0 {
+		service.NewWatchdog(executions, threshold, watchdogForceFail())
+	}
+
+	stepTypes := service.NewRegistryService(registry)
+	dashboard := service.NewDashboardService(gormDB, locks)
+	fixtures := service.NewFixtureService(gormDB, executions)
+	costs := service.NewCostService(gormDB)
+
+	srv := api.NewServer(templates, executions, search, labels, layout, quotas, artifacts, locks, shares, agents, alerts, redactions, stepTypes, dashboard, fixtures, blackouts, costs, adminToken())
+
+	addr := os.Getenv("ORCHESTRATOR_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	log.Printf("orchestrator listening on %s", addr)
+	if err := http.ListenAndServe(addr, srv.Router()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadPlugins registers any custom step types declared in
+// ORCHESTRATOR_PLUGINS, a JSON array of executor.PluginConfig, letting
+// operators add step types without recompiling the orchestrator.
+func loadPlugins(registry *executor.Registry) error {
+	raw := os.Getenv("ORCHESTRATOR_PLUGINS")
+	if raw == "" {
+		return nil
+	}
+	var configs []executor.PluginConfig
+	if err := json.Unmarshal([]byte(raw), &configs); err != nil {
+		return err
+	}
+	executor.RegisterPlugins(registry, configs)
+	return nil
+}
+
+// disabledStepTypes reads ORCHESTRATOR_DISABLED_STEP_TYPES, a
+// comma-separated list of step type names (e.g. "shell,aws_action") to
+// forbid in this deployment, returning none if it's unset. A disabled
+// type stays registered (it still shows up in GET /step-types) but
+// every lookup against it fails as if it had never been registered.
+func disabledStepTypes() []string {
+	raw := os.Getenv("ORCHESTRATOR_DISABLED_STEP_TYPES")
+	if raw == "" {
+		return nil
+	}
+	var types []string
+	for _, stepType := range strings.Split(raw, ",") {
+		if stepType = strings.TrimSpace(stepType); stepType != "" {
+			types = append(types, stepType)
+		}
+	}
+	return types
+}
+
+// workerPoolConcurrency reads ORCHESTRATOR_WORKER_CONCURRENCY, falling
+// back to DefaultWorkerPoolConcurrency.
+func workerPoolConcurrency() int {
+	raw := os.Getenv("ORCHESTRATOR_WORKER_CONCURRENCY")
+	if raw == "" {
+		return DefaultWorkerPoolConcurrency
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return DefaultWorkerPoolConcurrency
+	}
+	return n
+}
+
+// artifactDir reads ORCHESTRATOR_ARTIFACT_DIR, falling back to
+// DefaultArtifactDir.
+func artifactDir() string {
+	if dir := os.Getenv("ORCHESTRATOR_ARTIFACT_DIR"); dir != "" {
+		return dir
+	}
+	return DefaultArtifactDir
+}
+
+// statusJournalDir reads ORCHESTRATOR_STATUS_JOURNAL_DIR, the directory
+// StatusWriter journals buffered step status writes to while the
+// database is unreachable. Empty (the default) disables the journal,
+// same as leaving out WithStatusJournal entirely.
+func statusJournalDir() string {
+	return os.Getenv("ORCHESTRATOR_STATUS_JOURNAL_DIR")
+}
+
+// stepBulkheadCapacity reads ORCHESTRATOR_STEP_BULKHEAD, falling back to
+// DefaultStepBulkheadCapacity.
+func stepBulkheadCapacity() int {
+	raw := os.Getenv("ORCHESTRATOR_STEP_BULKHEAD")
+	if raw == "" {
+		return DefaultStepBulkheadCapacity
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return DefaultStepBulkheadCapacity
+	}
+	return n
+}
+
+// grafanaAnnotator builds a grafana.Annotator from ORCHESTRATOR_GRAFANA_URL
+// (and optionally ORCHESTRATOR_GRAFANA_TOKEN), or returns nil if
+// ORCHESTRATOR_GRAFANA_URL is unset, since the integration is opt-in.
+func grafanaAnnotator() *grafana.Annotator {
+	url := os.Getenv("ORCHESTRATOR_GRAFANA_URL")
+	if url == "" {
+		return nil
+	}
+	return &grafana.Annotator{BaseURL: url, APIToken: os.Getenv("ORCHESTRATOR_GRAFANA_TOKEN")}
+}
+
+// teamRouter builds a notify.TeamRouter from ORCHESTRATOR_TEAM_WEBHOOKS,
+// a JSON object mapping a WorkflowTemplate.OnCallRoutingKey to the
+// webhook URL its failures should post to, or returns nil if it's unset,
+// since per-team routing is opt-in and every template defaults to the
+// orchestrator's single Notifier. Its Default always falls back to
+// LogNotifier, the same default the single-Notifier path uses.
+func teamRouter() (*notify.TeamRouter, error) {
+	raw := os.Getenv("ORCHESTRATOR_TEAM_WEBHOOKS")
+	if raw == "" {
+		return nil, nil
+	}
+	var urls map[string]string
+	if err := json.Unmarshal([]byte(raw), &urls); err != nil {
+		return nil, err
+	}
+	routes := make(map[string]notify.Notifier, len(urls))
+	for key, url := range urls {
+		routes[key] = notify.WebhookNotifier{URL: url}
+	}
+	return notify.NewTeamRouter(notify.LogNotifier{}, routes), nil
+}
+
+// configEncryptionKeys builds a fieldcrypt.KeyRing from
+// ORCHESTRATOR_CONFIG_ENCRYPTION_KEYS, a comma-separated "id:base64key"
+// list (first entry current), or returns nil if it's unset, since
+// encrypting step Config at rest is opt-in.
+func configEncryptionKeys() (*fieldcrypt.KeyRing, error) {
+	return fieldcrypt.KeyRingFromEnvVar("ORCHESTRATOR_CONFIG_ENCRYPTION_KEYS")
+}
+
+// shellPolicy builds a shellpolicy.Policy from shellpolicy.DefaultDenylist
+// plus any extra comma-separated regexes in
+// ORCHESTRATOR_SHELL_POLICY_DENYLIST, and restricts sudo to the
+// comma-separated regexes in ORCHESTRATOR_SHELL_POLICY_SUDO_ALLOWLIST
+// (unrestricted if that's unset). Shell step validation is always on;
+// operators who want it disabled should register their own ShellTask
+// with a nil Policy instead.
+func shellPolicy() (*shellpolicy.Policy, error) {
+	denylist := shellpolicy.DefaultDenylist
+	if extra := os.Getenv("ORCHESTRATOR_SHELL_POLICY_DENYLIST"); extra != "" {
+		denylist = append(denylist, strings.Split(extra, ",")...)
+	}
+	var sudoAllow []string
+	if raw := os.Getenv("ORCHESTRATOR_SHELL_POLICY_SUDO_ALLOWLIST"); raw != "" {
+		sudoAllow = strings.Split(raw, ",")
+	}
+	return shellpolicy.New(denylist, sudoAllow)
+}
+
+// adminToken reads ORCHESTRATOR_ADMIN_TOKEN, the shared secret that
+// gates /debug/pprof/* and /debug/diagnostics. An empty value (the
+// default) disables both routes, since diagnosing production via
+// goroutine dumps and scheduler internals is opt-in.
+func adminToken() string {
+	return os.Getenv("ORCHESTRATOR_ADMIN_TOKEN")
+}
+
+// watchdogThreshold reads ORCHESTRATOR_WATCHDOG_THRESHOLD_SECONDS,
+// returning 0 (the default) if it's unset or invalid. A zero threshold
+// disables the deadlock watchdog entirely, since deciding how long a
+// step may run before it's "stuck" depends on the workloads this
+// orchestrator runs and has no sane universal default.
+func watchdogThreshold() time.Duration {
+	raw := os.Getenv("ORCHESTRATOR_WATCHDOG_THRESHOLD_SECONDS")
+	if raw == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second
+}
+
+// watchdogForceFail reads ORCHESTRATOR_WATCHDOG_FORCE_FAIL, defaulting
+// to false so a newly enabled watchdog only logs and alerts on a stuck
+// step until an operator opts into having it fail the step outright.
+func watchdogForceFail() bool {
+	return os.Getenv("ORCHESTRATOR_WATCHDOG_FORCE_FAIL") == "true"
+}
+
+// shareSecret reads ORCHESTRATOR_SHARE_SECRET, the key execution share
+// links are signed with. If it's unset, a random secret is generated
+// for this process's lifetime, logged as a warning since every link
+// minted before a restart stops verifying once a new one is generated.
+func shareSecret() []byte {
+	if raw := os.Getenv("ORCHESTRATOR_SHARE_SECRET"); raw != "" {
+		return []byte(raw)
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("generate share secret: %v", err)
+	}
+	log.Printf("warning: ORCHESTRATOR_SHARE_SECRET not set, generated a random secret; share links won't survive a restart")
+	return []byte(base64.RawURLEncoding.EncodeToString(secret))
+}
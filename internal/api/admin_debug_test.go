@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+func TestRequireAdminTokenGatesOnTheConfiguredToken(t *testing.T) {
+	cases := []struct {
+		name       string
+		adminToken string
+		header     string
+		wantStatus int
+	}{
+		{"disabled when no token is configured", "", "", http.StatusServiceUnavailable},
+		{"rejects a missing header", "secret", "", http.StatusUnauthorized},
+		{"rejects the wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"accepts the right token", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Server{adminToken: tc.adminToken}
+			handler := s.requireAdminToken(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/diagnostics", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tc.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestShareTokenRoutesMap401And410Correctly(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusSucceeded}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	shares := service.NewShareService(gormDB, []byte("secret"))
+	s := &Server{shares: shares, executions: service.NewExecutionService(gormDB, nil, nil, nil, nil)}
+
+	validToken, _, err := shares.CreateLink(execution.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	expiredToken, _, err := shares.CreateLink(execution.ID, -time.Hour)
+	if err != nil {
+		t.Fatalf("CreateLink (expired): %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"valid token is accepted", validToken, http.StatusOK},
+		{"malformed token is unauthorized", "not-a-real-token", http.StatusUnauthorized},
+		{"expired token is gone", expiredToken, http.StatusGone},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/share/"+tc.token, nil)
+			req = withChiURLParams(req, map[string]string{"token": tc.token})
+			rec := httptest.NewRecorder()
+			handler := s.requireShareToken(s.getSharedExecution)
+			handler(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d (%s)", tc.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestForceReleaseLockClearsALockRegardlessOfOwner(t *testing.T) {
+	gormDB := newTestDB(t)
+	provider := lock.NewMemoryLockProvider()
+	if _, err := provider.TryLock(context.Background(), "db-migration", "someone-else", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	locks := service.NewLockService(gormDB, map[string]lock.Provider{"memory": provider})
+	s := &Server{locks: locks}
+
+	req := httptest.NewRequest(http.MethodPost, "/locks/memory/db-migration/release", strings.NewReader(`{"requested_by":"alice","reason":"stuck"}`))
+	req = withChiURLParams(req, map[string]string{"provider": "memory", "key": "db-migration"})
+	rec := httptest.NewRecorder()
+	s.forceReleaseLock(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+
+	held, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(held) != 0 {
+		t.Fatalf("expected the lock to be released, still held: %v", held)
+	}
+}
+
+// TestForceReleaseLockRouteRequiresAdminToken drives the real router
+// rather than calling forceReleaseLock directly, so it catches a missing
+// requireAdminToken wrapper in router.go's route registration, not just
+// a bug inside the handler itself.
+func TestForceReleaseLockRouteRequiresAdminToken(t *testing.T) {
+	gormDB := newTestDB(t)
+	provider := lock.NewMemoryLockProvider()
+	if _, err := provider.TryLock(context.Background(), "db-migration", "someone-else", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	locks := service.NewLockService(gormDB, map[string]lock.Provider{"memory": provider})
+	s := &Server{locks: locks, adminToken: "secret"}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/locks/memory/db-migration/release", strings.NewReader(`{"requested_by":"alice","reason":"stuck"}`))
+	rec := httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without an admin token, got %d: %s", rec.Code, rec.Body)
+	}
+
+	held, err := provider.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(held) != 1 {
+		t.Fatalf("expected the lock to remain held without a valid admin token, held: %v", held)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/locks/memory/db-migration/release", strings.NewReader(`{"requested_by":"alice","reason":"stuck"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.Router().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204 with a valid admin token, got %d: %s", rec.Code, rec.Body)
+	}
+}
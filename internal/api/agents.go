@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// heartbeatAgent serves POST /agents/heartbeat, recording the calling
+// agent's platform, version, advertised capabilities and current load.
+func (s *Server) heartbeatAgent(w http.ResponseWriter, r *http.Request) {
+	var agent model.Agent
+	if err := decodeJSON(r, &agent); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if agent.Name == "" {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("name is required"))
+		return
+	}
+	saved, err := s.agents.Heartbeat(agent)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, saved)
+}
+
+// listAgents serves GET /agents, reporting the fleet inventory for
+// operators and a multi-agent dispatcher.
+func (s *Server) listAgents(w http.ResponseWriter, r *http.Request) {
+	agents, err := s.agents.List()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, agents)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// alertmanagerWebhook serves POST /integrations/alertmanager/webhook,
+// the receiver URL configured in a webhook_config, routing each alert in
+// the delivery to a template via the configured AlertRoutes and
+// reporting what was done with it (started, deduped, annotated,
+// unrouted or ignored).
+func (s *Server) alertmanagerWebhook(w http.ResponseWriter, r *http.Request) {
+	var webhook service.AlertmanagerWebhook
+	if err := decodeJSON(r, &webhook); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	outcomes := s.alerts.HandleWebhook(r.Context(), webhook)
+	writeJSON(w, http.StatusOK, outcomes)
+}
+
+// listAlertRoutes serves GET /integrations/alertmanager/routes.
+func (s *Server) listAlertRoutes(w http.ResponseWriter, r *http.Request) {
+	routes, err := s.alerts.Routes()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, routes)
+}
+
+// createAlertRoute serves POST /integrations/alertmanager/routes.
+func (s *Server) createAlertRoute(w http.ResponseWriter, r *http.Request) {
+	var route model.AlertRoute
+	if err := decodeJSON(r, &route); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if route.TemplateID == 0 {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("template_id is required"))
+		return
+	}
+	if err := s.alerts.CreateRoute(&route); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, route)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// listExecutionArtifacts serves GET /executions/:id/artifacts, listing the
+// metadata of every artifact a step of the execution produced.
+func (s *Server) listExecutionArtifacts(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	artifacts, err := s.artifacts.ListByExecution(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, artifacts)
+}
+
+// getExecutionArtifact serves GET /executions/:id/artifacts/:name,
+// streaming the named artifact's bytes back to the caller.
+func (s *Server) getExecutionArtifact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	name := chi.URLParam(r, "name")
+
+	art, body, err := s.artifacts.Open(r.Context(), id, name)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	defer body.Close()
+
+	if art.ContentType != "" {
+		w.Header().Set("Content-Type", art.ContentType)
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+	io.Copy(w, body)
+}
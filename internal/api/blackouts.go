@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// listBlackoutWindows serves GET /blackout-windows?project=..., returning
+// every window that could apply to project (project-specific or global).
+// An empty project lists every global window.
+func (s *Server) listBlackoutWindows(w http.ResponseWriter, r *http.Request) {
+	windows, err := s.blackouts.List(r.URL.Query().Get("project"))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, windows)
+}
+
+func (s *Server) createBlackoutWindow(w http.ResponseWriter, r *http.Request) {
+	var window model.BlackoutWindow
+	if err := decodeJSON(r, &window); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.blackouts.Create(&window); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, window)
+}
+
+func (s *Server) deleteBlackoutWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.blackouts.Delete(id); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// getExecutionCost serves GET /executions/:id/cost, reporting one
+// execution's agent runtime and cost, broken down per step.
+func (s *Server) getExecutionCost(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	report, err := s.costs.Execution(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// getTemplateCost serves GET /templates/:id/cost, rolling up agent
+// runtime and cost across every execution of that template.
+func (s *Server) getTemplateCost(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	report, err := s.costs.Template(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// getProjectCostReport serves GET /cost-reports/projects/:project, rolling
+// up agent runtime and cost across every execution belonging to project.
+func (s *Server) getProjectCostReport(w http.ResponseWriter, r *http.Request) {
+	project := chi.URLParam(r, "project")
+	report, err := s.costs.Project(project)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,14 @@
+package api
+
+import "net/http"
+
+// getDashboard serves GET /dashboard, aggregating the counters and
+// recent activity an operations home page needs into one call.
+func (s *Server) getDashboard(w http.ResponseWriter, r *http.Request) {
+	dashboard, err := s.dashboard.Build()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, dashboard)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// mountDebugRoutes registers /debug/pprof/* (the standard net/http/pprof
+// profiles) and /debug/diagnostics on r, both gated by requireAdminToken,
+// so a hung DAG or a deadlock stuck in waitPreds can be diagnosed in
+// production without exposing goroutine stacks and scheduler internals
+// to the open API.
+func (s *Server) mountDebugRoutes(r chi.Router) {
+	r.Route("/debug", func(r chi.Router) {
+		r.HandleFunc("/pprof/cmdline", s.requireAdminToken(pprof.Cmdline))
+		r.HandleFunc("/pprof/profile", s.requireAdminToken(pprof.Profile))
+		r.HandleFunc("/pprof/symbol", s.requireAdminToken(pprof.Symbol))
+		r.HandleFunc("/pprof/trace", s.requireAdminToken(pprof.Trace))
+		r.HandleFunc("/pprof/*", s.requireAdminToken(pprof.Index))
+		r.Get("/diagnostics", s.requireAdminToken(s.getDiagnostics))
+	})
+}
+
+// requireAdminToken gates next behind the ORCHESTRATOR_ADMIN_TOKEN
+// shared secret, checked by verifyAdminToken. If the Server was built
+// with an empty adminToken, the route is disabled outright (503)
+// rather than accepting every request.
+func (s *Server) requireAdminToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.verifyAdminToken(r); err != nil {
+			writeError(w, r, s.adminTokenErrorStatus(), err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// verifyAdminToken checks r's "Authorization: Bearer <token>" header
+// against ORCHESTRATOR_ADMIN_TOKEN with a constant-time comparison, for
+// any handler that needs to gate part of a request behind the admin
+// token rather than an entire route (see requireAdminToken for that
+// case, and createExecution's Override check for this one).
+func (s *Server) verifyAdminToken(r *http.Request) error {
+	if s.adminToken == "" {
+		return errors.New("admin actions are disabled: ORCHESTRATOR_ADMIN_TOKEN is not set")
+	}
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, prefix) {
+		return errors.New("missing admin token")
+	}
+	given := strings.TrimPrefix(header, prefix)
+	if subtle.ConstantTimeCompare([]byte(given), []byte(s.adminToken)) != 1 {
+		return errors.New("invalid admin token")
+	}
+	return nil
+}
+
+// adminTokenErrorStatus is the status a verifyAdminToken failure maps
+// to: 503 if admin actions are disabled outright (no token configured),
+// 401 for a missing or wrong one.
+func (s *Server) adminTokenErrorStatus() int {
+	if s.adminToken == "" {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusUnauthorized
+}
+
+// diagnosticsResponse is the body of GET /debug/diagnostics.
+type diagnosticsResponse struct {
+	// Goroutines is runtime.NumGoroutine(); a full goroutine dump with
+	// every stack trace is available at /debug/pprof/goroutine?debug=2.
+	Goroutines int `json:"goroutines"`
+	GOMAXPROCS int `json:"gomaxprocs"`
+	// Concurrency is the scheduler's current state: WorkerPool
+	// utilization plus, per running execution, which DAG nodes are
+	// running right now and how many are blocked on dependencies. A
+	// DAG stuck in waitPreds shows up here as an execution whose
+	// layers never stop reporting nodes blocked.
+	Concurrency *service.ConcurrencySnapshot `json:"concurrency"`
+}
+
+// getDiagnostics serves GET /debug/diagnostics, an admin-only snapshot
+// of runtime and scheduler state for diagnosing hung DAGs and
+// deadlocks without attaching a debugger.
+func (s *Server) getDiagnostics(w http.ResponseWriter, r *http.Request) {
+	concurrency, err := s.executions.Concurrency()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diagnosticsResponse{
+		Goroutines:  runtime.NumGoroutine(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		Concurrency: concurrency,
+	})
+}
@@ -0,0 +1,583 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/report"
+	"github.com/fengmingli/orchestrator/internal/schema"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+type createExecutionRequest struct {
+	TemplateID uint `json:"template_id"`
+	// Priority lets this execution jump ahead of lower-priority
+	// executions still waiting for a free worker (e.g. incident
+	// remediation ahead of routine batch work). Defaults to 0.
+	Priority int `json:"priority"`
+	// MaxParallel overrides the template's own MaxParallel for this
+	// execution if greater than zero. Leave at 0 to use the template's
+	// value.
+	MaxParallel int `json:"max_parallel,omitempty"`
+	// Params is validated against the template's ParamsSchema, if it
+	// declares one, before the execution is created.
+	Params json.RawMessage `json:"params,omitempty"`
+	// Override lets an emergency run start even inside a
+	// model.BlackoutWindow that would otherwise reject it. Requires the
+	// same admin token as /debug/*, checked by createExecution before
+	// this is ever passed to ExecutionService.Start.
+	Override bool `json:"override,omitempty"`
+}
+
+type simulateExecutionRequest struct {
+	TemplateID uint                      `json:"template_id"`
+	Faults     map[string]executor.Fault `json:"faults"`
+}
+
+// createChaosExecutionRequest is the payload for POST
+// /executions/chaos: a normal execution request plus the ChaosConfig to
+// subject every step to.
+type createChaosExecutionRequest struct {
+	TemplateID  uint                 `json:"template_id"`
+	Priority    int                  `json:"priority"`
+	MaxParallel int                  `json:"max_parallel,omitempty"`
+	Params      json.RawMessage      `json:"params,omitempty"`
+	Chaos       executor.ChaosConfig `json:"chaos"`
+	// Override lets an emergency chaos run start even inside a
+	// model.BlackoutWindow, subject to the same admin token check as
+	// createExecutionRequest.Override.
+	Override bool `json:"override,omitempty"`
+}
+
+func (s *Server) listExecutions(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	executions, next, err := s.executions.ListPage(q)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: executions, NextCursor: next})
+}
+
+func (s *Server) createExecution(w http.ResponseWriter, r *http.Request) {
+	var req createExecutionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if req.Override {
+		if err := s.verifyAdminToken(r); err != nil {
+			writeError(w, r, s.adminTokenErrorStatus(), fmt.Errorf("override: %w", err))
+			return
+		}
+	}
+	execution, err := s.executions.Start(r.Context(), req.TemplateID, req.Priority, req.MaxParallel, string(req.Params), req.Override)
+	if err != nil {
+		writeExecutionStartError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, execution)
+}
+
+// writeExecutionStartError maps a failure to start an execution to an HTTP
+// status: quota and blackout rejections get a 429 and output contract
+// violations get a 422 instead of a generic 500, so callers can
+// distinguish those from a real server error.
+func writeExecutionStartError(w http.ResponseWriter, r *http.Request, err error) {
+	var quotaErr *service.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		writeError(w, r, http.StatusTooManyRequests, err)
+		return
+	}
+	var blackoutErr *service.BlackoutError
+	if errors.As(err, &blackoutErr) {
+		writeError(w, r, http.StatusTooManyRequests, err)
+		return
+	}
+	var outputErr *service.OutputSchemaViolationError
+	if errors.As(err, &outputErr) {
+		writeError(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+	var validationErr *schema.ValidationError
+	if errors.As(err, &validationErr) {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeError(w, r, http.StatusInternalServerError, err)
+}
+
+// simulateExecution serves POST /executions/simulate, running a template
+// with specified steps forced to fail/succeed/delay so template authors
+// can validate failure policies without touching real systems.
+func (s *Server) simulateExecution(w http.ResponseWriter, r *http.Request) {
+	var req simulateExecutionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	execution, err := s.executions.StartSimulated(r.Context(), req.TemplateID, req.Faults)
+	if err != nil {
+		writeExecutionStartError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, execution)
+}
+
+// createChaosExecution serves POST /executions/chaos, starting an
+// execution with its steps subject to randomized fault injection, for
+// reliability testing against unpredictable failures.
+func (s *Server) createChaosExecution(w http.ResponseWriter, r *http.Request) {
+	var req createChaosExecutionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if req.Override {
+		if err := s.verifyAdminToken(r); err != nil {
+			writeError(w, r, s.adminTokenErrorStatus(), fmt.Errorf("override: %w", err))
+			return
+		}
+	}
+	execution, err := s.executions.StartWithChaos(r.Context(), req.TemplateID, req.Priority, req.MaxParallel, string(req.Params), req.Chaos, req.Override)
+	if err != nil {
+		writeExecutionStartError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, execution)
+}
+
+// resumeExecution serves POST /executions/:id/resume, re-running a failed
+// execution's steps against its template's current DAG. A 409 means the
+// template changed since the execution started and resume was refused
+// rather than risk running it against a different DAG.
+func (s *Server) resumeExecution(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	execution, err := s.executions.Resume(r.Context(), id)
+	if err != nil {
+		var dagErr *service.DAGChangedError
+		if errors.As(err, &dagErr) {
+			writeError(w, r, http.StatusConflict, err)
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+// getExecutionStep serves GET /executions/:id/steps/:stepId, returning
+// one step execution with its full output and error text. Listing
+// endpoints (GET /executions, GET /executions/:id) omit that text to
+// keep big executions' payloads small; this is how a client fetches it
+// for the one step it's actually looking at.
+func (s *Server) getExecutionStep(w http.ResponseWriter, r *http.Request) {
+	stepID, err := strconv.ParseUint(chi.URLParam(r, "stepId"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	step, err := s.executions.Step(uint(stepID))
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, step)
+}
+
+// getExecutionStepAttempts serves GET /executions/:id/steps/:stepName/attempts,
+// returning every attempt recorded for that step name, oldest first,
+// with full output and error text, so a client can see what earlier
+// attempts failed with instead of only the latest one.
+func (s *Server) getExecutionStepAttempts(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stepName := chi.URLParam(r, "stepName")
+	attempts, err := s.executions.StepAttempts(id, stepName)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: attempts})
+}
+
+func (s *Server) getExecution(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	execution, err := s.executions.Get(id)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+// getExecutionLiveDAG serves GET /executions/:id/dag/live, returning the
+// execution's template DAG structure with each node's current status, so
+// the UI can render real-time node colors.
+func (s *Server) getExecutionLiveDAG(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	live, err := s.executions.LiveDAG(id)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, live)
+}
+
+// getExecutionsConcurrency serves GET /executions/concurrency, reporting
+// the WorkerPool's current utilization plus, for every execution with a
+// step running right now, which nodes are running and how many are
+// blocked on dependencies per DAG layer, to help an operator tune
+// WorkerPool concurrency and MaxParallel.
+func (s *Server) getExecutionsConcurrency(w http.ResponseWriter, r *http.Request) {
+	snapshot, err := s.executions.Concurrency()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// compareExecutions serves GET /executions/compare?a=:id&b=:id, diffing
+// two executions step by step to help a postmortem find where a
+// succeeding run and a failing one diverged.
+func (s *Server) compareExecutions(w http.ResponseWriter, r *http.Request) {
+	idA, err := strconv.ParseUint(r.URL.Query().Get("a"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid a: %w", err))
+		return
+	}
+	idB, err := strconv.ParseUint(r.URL.Query().Get("b"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("invalid b: %w", err))
+		return
+	}
+	diff, err := s.executions.Compare(uint(idA), uint(idB))
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+// getExecutionEvents serves GET /executions/:id/events, returning the
+// ordered event stream used to reconstruct and audit an execution's
+// timeline.
+func (s *Server) getExecutionEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	events, err := s.executions.Events(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// getExecutionExternalInputs serves GET /executions/:id/external-inputs,
+// returning every external input a step of the execution recorded (e.g.
+// an HTTPTask's response body), for diagnosing a flaky remediation step
+// by replaying it against the exact same external data it saw the first
+// time, e.g. via executor.ReplayHTTPClient.
+func (s *Server) getExecutionExternalInputs(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	inputs, err := s.executions.ExternalInputs(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inputs)
+}
+
+// addExecutionNoteRequest is the body of POST /executions/:id/notes.
+type addExecutionNoteRequest struct {
+	StepName  string `json:"step_name,omitempty"`
+	Message   string `json:"message"`
+	CreatedBy string `json:"created_by,omitempty"`
+}
+
+// addExecutionNote serves POST /executions/:id/notes, attaching a
+// timestamped operator annotation to the execution, optionally scoped to
+// one of its steps.
+func (s *Server) addExecutionNote(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req addExecutionNoteRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	note, err := s.executions.AddNote(id, req.StepName, req.Message, req.CreatedBy)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// getExecutionNotes serves GET /executions/:id/notes, listing every note
+// attached to the execution in the order they were added.
+func (s *Server) getExecutionNotes(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	notes, err := s.executions.Notes(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: notes})
+}
+
+// addExecutionLinkRequest is the body of POST /executions/:id/links.
+type addExecutionLinkRequest struct {
+	Kind  model.ExecutionLinkKind `json:"kind"`
+	URL   string                  `json:"url"`
+	Label string                  `json:"label,omitempty"`
+}
+
+// addExecutionLink serves POST /executions/:id/links, attaching an
+// external reference (a ticket, an incident, a dashboard) to the
+// execution.
+func (s *Server) addExecutionLink(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req addExecutionLinkRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	link, err := s.executions.AddLink(id, req.Kind, req.URL, req.Label)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, link)
+}
+
+// getExecutionLinks serves GET /executions/:id/links, listing every
+// external reference attached to the execution in the order they were
+// added.
+func (s *Server) getExecutionLinks(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	links, err := s.executions.Links(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: links})
+}
+
+// getExecutionReport serves GET /executions/:id/report?format=json|csv|html,
+// rendering a self-contained summary of the execution suitable for
+// attaching to an incident postmortem. Defaults to json.
+func (s *Server) getExecutionReport(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	rep, err := s.executions.Report(id)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	switch format {
+	case "json":
+		body, err := report.JSON(rep)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	case "csv":
+		body, err := report.CSV(rep)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte(body))
+	case "html":
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(report.HTML(rep)))
+	default:
+		writeError(w, r, http.StatusBadRequest, errors.New("unsupported format, expected json, csv or html"))
+	}
+}
+
+// getExecutionStages serves GET /executions/:id/stages, returning the
+// execution's steps grouped by template stage with a roll-up status per
+// stage, so a UI can render the DAG view organized by stage instead of
+// by individual step.
+func (s *Server) getExecutionStages(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stages, err := s.executions.StageSummaries(r.Context(), id)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stages)
+}
+
+// skipExecutionStage serves POST /executions/:id/stages/:stage/skip,
+// marking every not-yet-terminal step in the stage as Skipped.
+func (s *Server) skipExecutionStage(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stage := chi.URLParam(r, "stage")
+	if err := s.executions.SkipStage(r.Context(), id, stage); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rerunExecutionStage serves POST /executions/:id/stages/:stage/rerun,
+// re-running just the named stage's steps against a failed execution.
+func (s *Server) rerunExecutionStage(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stage := chi.URLParam(r, "stage")
+	execution, err := s.executions.RerunStage(r.Context(), id, stage)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+type completeExecutionStepRequest struct {
+	// Token must match the one recorded against the step's
+	// node_awaiting_external event; it's the only credential this
+	// endpoint checks, since the repo has no broader auth framework yet.
+	Token  string `json:"token"`
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// completeExecutionStep serves POST /executions/:id/steps/:stepId/complete,
+// delivering a result to an "external" step left pending-external by
+// executor.ExternalTask. A non-empty Error in the request fails the step
+// instead of succeeding it.
+func (s *Server) completeExecutionStep(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stepID, err := strconv.ParseUint(chi.URLParam(r, "stepId"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req completeExecutionStepRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	result := executor.ExternalResult{Output: req.Output, Error: req.Error}
+	if err := s.executions.CompleteExternalStep(id, uint(stepID), req.Token, result); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rerunExecutionStep serves
+// POST /executions/:id/steps/:stepId/rerun?include_downstream=true,
+// re-executing a single step of a failed execution (and optionally its
+// downstream subtree), for cases where an operator fixed an external
+// issue by hand.
+func (s *Server) rerunExecutionStep(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	stepID, err := strconv.ParseUint(chi.URLParam(r, "stepId"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	includeDownstream := r.URL.Query().Get("include_downstream") == "true"
+	execution, err := s.executions.RerunStep(r.Context(), id, uint(stepID), includeDownstream)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+func parseID(r *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	defer r.Body.Close()
+	return json.NewDecoder(r.Body).Decode(v)
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+)
+
+// createFixtureRequest is the body of POST /templates/:id/fixtures: a
+// name for the fixture plus the faults its golden execution (and every
+// later test run) is simulated with.
+type createFixtureRequest struct {
+	Name   string                    `json:"name"`
+	Faults map[string]executor.Fault `json:"faults"`
+}
+
+// createFixture serves POST /templates/:id/fixtures, running the
+// template in simulation mode and recording the result as a new
+// TemplateFixture's golden execution.
+func (s *Server) createFixture(w http.ResponseWriter, r *http.Request) {
+	templateID, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req createFixtureRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	fixture, err := s.fixtures.CreateFixture(r.Context(), templateID, req.Name, req.Faults)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, fixture)
+}
+
+// listFixtures serves GET /templates/:id/fixtures.
+func (s *Server) listFixtures(w http.ResponseWriter, r *http.Request) {
+	templateID, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	fixtures, err := s.fixtures.List(templateID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: fixtures})
+}
+
+// testRunFixture serves POST /fixtures/:id/test-run, replaying a
+// fixture's faults against its template's current DAG and diffing the
+// result against the fixture's golden execution.
+func (s *Server) testRunFixture(w http.ResponseWriter, r *http.Request) {
+	fixtureID, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	result, err := s.fixtures.TestRun(r.Context(), fixtureID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
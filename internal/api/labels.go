@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func (s *Server) getTemplateLabels(w http.ResponseWriter, r *http.Request) {
+	s.getLabels(w, r, model.LabelOwnerTemplate)
+}
+
+func (s *Server) setTemplateLabels(w http.ResponseWriter, r *http.Request) {
+	s.setLabels(w, r, model.LabelOwnerTemplate)
+}
+
+func (s *Server) getExecutionLabels(w http.ResponseWriter, r *http.Request) {
+	s.getLabels(w, r, model.LabelOwnerExecution)
+}
+
+func (s *Server) setExecutionLabels(w http.ResponseWriter, r *http.Request) {
+	s.setLabels(w, r, model.LabelOwnerExecution)
+}
+
+func (s *Server) getLabels(w http.ResponseWriter, r *http.Request, ownerType model.LabelOwnerType) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	labels, err := s.labels.Get(ownerType, id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func (s *Server) setLabels(w http.ResponseWriter, r *http.Request, ownerType model.LabelOwnerType) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var labels map[string]string
+	if err := decodeJSON(r, &labels); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.labels.Set(ownerType, id, labels); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+type saveLayoutRequest struct {
+	Positions map[string]service.NodePosition `json:"positions"`
+	Edges     []model.EdgeLayout              `json:"edges"`
+}
+
+// saveLayout serves PUT /templates/:id/layout, persisting node positions
+// and edge routing metadata drawn by the visual DAG editor.
+func (s *Server) saveLayout(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req saveLayoutRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.layout.SavePositions(id, req.Positions); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.layout.SaveEdgeLayout(id, req.Edges); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// autoLayout serves POST /templates/:id/layout/auto, computing and
+// persisting default layered coordinates for every step.
+func (s *Server) autoLayout(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	positions, err := s.layout.AutoLayout(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, positions)
+}
+
+// exportTemplate serves GET /templates/:id/export?format=svg|png|dot,
+// rendering the template's DAG for embedding in reports and
+// notifications without a browser-side renderer. Defaults to svg.
+func (s *Server) exportTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "svg"
+	}
+	switch format {
+	case "svg":
+		svg, err := s.layout.ExportSVG(id)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(svg))
+	case "png":
+		img, err := s.layout.ExportPNG(id)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(img)
+	case "dot":
+		dot, err := s.layout.ExportGraphviz(id)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		w.Write([]byte(dot))
+	default:
+		writeError(w, r, http.StatusBadRequest, errors.New("unsupported format, expected svg, png or dot"))
+	}
+}
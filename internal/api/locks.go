@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// listLocks serves GET /locks, listing every lock currently held across
+// all registered lock providers.
+func (s *Server) listLocks(w http.ResponseWriter, r *http.Request) {
+	locks, err := s.locks.List(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, locks)
+}
+
+// lockMetrics serves GET /locks/metrics, reporting contention and wait
+// time per lock provider.
+func (s *Server) lockMetrics(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.locks.Metrics())
+}
+
+// forceReleaseLockRequest is the body of POST /locks/:provider/:key/release.
+type forceReleaseLockRequest struct {
+	RequestedBy string `json:"requested_by"`
+	Reason      string `json:"reason"`
+}
+
+// forceReleaseLock serves POST /locks/:provider/:key/release, clearing a
+// stuck lock regardless of its current owner and recording why in the
+// audit trail. The router gates it behind requireAdminToken: releasing
+// an arbitrary ConcurrencyKey or leader-election lock out from under
+// whatever holds it is exactly as sensitive as the /debug routes.
+func (s *Server) forceReleaseLock(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	key := chi.URLParam(r, "key")
+	var req forceReleaseLockRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.locks.ForceRelease(r.Context(), provider, key, req.RequestedBy, req.Reason); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHeader is the header a caller can set to propagate its own
+// request ID, and the header every response carries its (possibly
+// generated) one back on, so a client and the API agree on a single ID
+// to correlate against server-side logs. It's chi middleware's own
+// header name, since requestID below is a thin wrapper around chi's
+// RequestID middleware rather than a reimplementation of it.
+var requestIDHeader = middleware.RequestIDHeader
+
+// requestID assigns a request ID to every request that doesn't already
+// carry one in X-Request-Id, and echoes whichever ID won back on the
+// response, so writeError and accessLog (and anything downstream that
+// reads middleware.RequestIDKey from the context) see the same value a
+// caller can find in its own copy of the response.
+func requestID(next http.Handler) http.Handler {
+	withID := middleware.RequestID(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		withID.ServeHTTP(w, r)
+	})
+}
+
+// withRequestIDHeader sets the response's X-Request-Id header from ctx
+// before status is written, so it reaches the client whether the
+// handler succeeds or calls writeError. It must run before anything
+// writes the status code.
+func withRequestIDHeader(w http.ResponseWriter, r *http.Request) {
+	if id := middleware.GetReqID(r.Context()); id != "" {
+		w.Header().Set(requestIDHeader, id)
+	}
+}
+
+// accessLog writes one structured "service" log line per request, with
+// its method, path, status, latency, and request ID, so an operator
+// can trace an API call through to the engine actions it caused by
+// request ID alone.
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		withRequestIDHeader(w, r)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		next.ServeHTTP(ww, r)
+		log := apiLog.With("request_id", middleware.GetReqID(r.Context())).
+			With("method", r.Method).
+			With("path", r.URL.Path).
+			With("status", ww.Status()).
+			With("latency_ms", time.Since(start).Milliseconds())
+		log.Infof("%s %s -> %d", r.Method, r.URL.Path, ww.Status())
+	})
+}
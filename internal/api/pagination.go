@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// listResponse wraps a page of list results along with the cursor to
+// request the next page.
+type listResponse struct {
+	Items      any    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// parseListQuery builds a service.ListQuery from the cursor, limit, sort,
+// desc, created_after and created_before query-string parameters of r.
+func parseListQuery(r *http.Request) (service.ListQuery, error) {
+	q := r.URL.Query()
+	out := service.ListQuery{
+		Cursor:     q.Get("cursor"),
+		Sort:       q.Get("sort"),
+		Maintainer: q.Get("maintainer"),
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return out, err
+		}
+		out.Limit = limit
+	}
+	if v := q.Get("desc"); v != "" {
+		desc, err := strconv.ParseBool(v)
+		if err != nil {
+			return out, err
+		}
+		out.Desc = desc
+	}
+	if v := q.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return out, err
+		}
+		out.CreatedAfter = &t
+	}
+	if v := q.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return out, err
+		}
+		out.CreatedBefore = &t
+	}
+	if labels := q["label"]; len(labels) > 0 {
+		out.Labels = make(map[string]string, len(labels))
+		for _, l := range labels {
+			k, v, ok := strings.Cut(l, "=")
+			if !ok {
+				return out, fmt.Errorf("invalid label selector %q, expected key=value", l)
+			}
+			out.Labels[k] = v
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func (s *Server) getQuota(w http.ResponseWriter, r *http.Request) {
+	project := chi.URLParam(r, "project")
+	quota, err := s.quotas.Get(project)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, quota)
+}
+
+func (s *Server) setQuota(w http.ResponseWriter, r *http.Request) {
+	project := chi.URLParam(r, "project")
+	var quota model.Quota
+	if err := decodeJSON(r, &quota); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.quotas.Set(project, quota); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, quota)
+}
+
+// getQuotaUsage serves GET /quotas/:project/usage, reporting a project's
+// current consumption against each quota dimension.
+func (s *Server) getQuotaUsage(w http.ResponseWriter, r *http.Request) {
+	project := chi.URLParam(r, "project")
+	usage, err := s.quotas.Usage(project)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
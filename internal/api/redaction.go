@@ -0,0 +1,38 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// listRedactionRules serves GET /redaction-rules?project=foo, returning
+// every rule scoped to project plus every global (empty Project) rule.
+// An empty or absent project returns just the global rules.
+func (s *Server) listRedactionRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.redactions.Rules(r.URL.Query().Get("project"))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// createRedactionRule serves POST /redaction-rules.
+func (s *Server) createRedactionRule(w http.ResponseWriter, r *http.Request) {
+	var rule model.RedactionRule
+	if err := decodeJSON(r, &rule); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if rule.Pattern == "" {
+		writeError(w, r, http.StatusBadRequest, fmt.Errorf("pattern is required"))
+		return
+	}
+	if err := s.redactions.CreateRule(&rule); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, rule)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// searchHandler serves GET /api/v1/search?q=..., returning typed matches
+// across template names/descriptions, step names/scripts, and execution
+// error messages.
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		writeError(w, r, http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+	results, err := s.search.Search(q, 0)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: results})
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// withChiURLParams attaches chi route params to r's context, so a
+// handler that reads them via chi.URLParam can be called directly in a
+// test without routing a real request through the full router.
+func withChiURLParams(r *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(&model.WorkflowTemplate{}, &model.TemplateStep{}, &model.WorkflowExecution{}, &model.StepExecution{}, &model.LockAuditEvent{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestCreateExecutionRejectsOverrideWithoutAValidAdminToken(t *testing.T) {
+	cases := []struct {
+		name       string
+		adminToken string
+		header     string
+		wantStatus int
+	}{
+		{"no admin token configured at all", "", "", http.StatusServiceUnavailable},
+		{"token configured, header missing", "secret", "", http.StatusUnauthorized},
+		{"token configured, wrong token given", "secret", "Bearer wrong", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Server{adminToken: tc.adminToken}
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/executions", strings.NewReader(`{"template_id":1,"override":true}`))
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+
+			// s.executions is nil: if the override check didn't reject
+			// this request before reaching ExecutionService.Start, this
+			// would panic on a nil pointer instead of failing cleanly,
+			// which is itself evidence the gate ran first.
+			s.createExecution(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.wantStatus, rec.Code, rec.Body)
+			}
+		})
+	}
+}
+
+func TestCreateExecutionAllowsANonOverrideRequestWithoutAnAdminToken(t *testing.T) {
+	gormDB := newTestDB(t)
+	executions := service.NewExecutionService(gormDB, nil, nil, nil, nil)
+	s := &Server{adminToken: "secret", executions: executions}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/executions", strings.NewReader(`{"template_id":1}`))
+	rec := httptest.NewRecorder()
+	s.createExecution(rec, req)
+
+	// No admin token was presented, but override was never requested,
+	// so the request should fail for an ordinary reason (no such
+	// template) rather than an authorization error.
+	if rec.Code == http.StatusUnauthorized {
+		t.Fatalf("expected a non-override request to skip the admin token check, got 401: %s", rec.Body)
+	}
+}
+
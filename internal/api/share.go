@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// DefaultShareLinkTTL is how long a share link stays valid when the
+// caller doesn't specify ExpiresInSeconds.
+const DefaultShareLinkTTL = 24 * time.Hour
+
+type createShareLinkRequest struct {
+	// ExpiresInSeconds overrides DefaultShareLinkTTL if positive.
+	ExpiresInSeconds int64 `json:"expires_in_seconds,omitempty"`
+}
+
+type createShareLinkResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// createShareLink serves POST /executions/:id/share, minting a signed,
+// expiring token an on-call engineer can hand to a stakeholder with no
+// account for read-only access to this one execution.
+func (s *Server) createShareLink(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req createShareLinkRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(r, &req); err != nil {
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+	}
+	ttl := DefaultShareLinkTTL
+	if req.ExpiresInSeconds > 0 {
+		ttl = time.Duration(req.ExpiresInSeconds) * time.Second
+	}
+
+	token, expiresAt, err := s.shares.CreateLink(id, ttl)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, createShareLinkResponse{Token: token, ExpiresAt: expiresAt})
+}
+
+type shareExecutionIDKey struct{}
+
+// requireShareToken resolves the {token} route param to the execution
+// it scopes access to and stores it in the request context, rejecting
+// invalid tokens with 401 and expired ones with 410 before next runs.
+// This is the only access control point in the API: every other
+// endpoint is open, but a share link is meant to grant exactly one
+// execution's read-only view and nothing else.
+func (s *Server) requireShareToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := chi.URLParam(r, "token")
+		executionID, err := s.shares.ExecutionIDForToken(token)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, service.ErrShareTokenExpired) {
+				status = http.StatusGone
+			}
+			writeError(w, r, status, err)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), shareExecutionIDKey{}, executionID)))
+	}
+}
+
+func shareExecutionIDFromContext(ctx context.Context) uint {
+	id, _ := ctx.Value(shareExecutionIDKey{}).(uint)
+	return id
+}
+
+// getSharedExecution serves GET /share/:token, returning the scoped
+// execution's current status and steps for a read-only viewer with no
+// account.
+func (s *Server) getSharedExecution(w http.ResponseWriter, r *http.Request) {
+	execution, err := s.executions.Get(shareExecutionIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, execution)
+}
+
+// getSharedExecutionEvents serves GET /share/:token/events, the
+// scoped-access equivalent of GET /executions/:id/events, so a shared
+// viewer can follow an execution's log stream.
+func (s *Server) getSharedExecutionEvents(w http.ResponseWriter, r *http.Request) {
+	events, err := s.executions.Events(shareExecutionIDFromContext(r.Context()))
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
@@ -0,0 +1,10 @@
+package api
+
+import "net/http"
+
+// listStepTypes serves GET /step-types, reporting every step type the
+// Registry knows how to run, whether it's currently enabled for this
+// deployment, and its config schema, if it has one.
+func (s *Server) listStepTypes(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.stepTypes.Types())
+}
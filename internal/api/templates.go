@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/service"
+)
+
+// templateIncludeRequest names another template to import steps from
+// and the prefix to import them under.
+type templateIncludeRequest struct {
+	SourceTemplateID uint   `json:"source_template_id"`
+	Prefix           string `json:"prefix"`
+}
+
+// createTemplateRequest is the payload for POST /templates: a
+// WorkflowTemplate plus any other templates' steps to compose into it.
+type createTemplateRequest struct {
+	model.WorkflowTemplate
+	Includes []templateIncludeRequest `json:"includes,omitempty"`
+}
+
+func (s *Server) listTemplates(w http.ResponseWriter, r *http.Request) {
+	q, err := parseListQuery(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	tmpls, next, err := s.templates.ListPage(q)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: tmpls, NextCursor: next})
+}
+
+func (s *Server) createTemplate(w http.ResponseWriter, r *http.Request) {
+	var req createTemplateRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	includes := make([]service.TemplateInclude, 0, len(req.Includes))
+	for _, include := range req.Includes {
+		includes = append(includes, service.TemplateInclude{SourceTemplateID: include.SourceTemplateID, Prefix: include.Prefix})
+	}
+	tmpl := req.WorkflowTemplate
+	if err := s.templates.Create(&tmpl, includes); err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tmpl)
+}
+
+// validateDAGRequest is the payload for POST /templates/validate-dag:
+// just the steps, since the rest of a WorkflowTemplate has no bearing
+// on whether its DAG is well-formed.
+type validateDAGRequest struct {
+	Steps []model.TemplateStep `json:"steps"`
+}
+
+type validateDAGResponse struct {
+	Valid     bool     `json:"valid"`
+	CyclePath []string `json:"cycle_path,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// validateDAG serves POST /templates/validate-dag, checking a candidate
+// step list for cycles or unknown dependencies without persisting
+// anything, so the template editor can highlight the offending steps
+// as the author builds the DAG.
+func (s *Server) validateDAG(w http.ResponseWriter, r *http.Request) {
+	var req validateDAGRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	cyclePath, err := s.templates.ValidateDAG(req.Steps)
+	if err != nil {
+		writeJSON(w, http.StatusOK, validateDAGResponse{Valid: false, CyclePath: cyclePath, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, validateDAGResponse{Valid: true})
+}
+
+// simulateTemplateDuration serves GET /templates/:id/simulate-duration,
+// returning the template's theoretical best-case and worst-case
+// wall-clock duration, for an author sizing an SLA or choosing a
+// MaxParallel before it has enough real runs to judge by.
+func (s *Server) simulateTemplateDuration(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	estimate, err := s.templates.SimulateDuration(id)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, estimate)
+}
+
+func (s *Server) cloneTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	clone, err := s.templates.Clone(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, clone)
+}
+
+func (s *Server) templateCatalog(w http.ResponseWriter, r *http.Request) {
+	tmpls, err := s.templates.Catalog()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: tmpls})
+}
+
+// transferOwnershipRequest is the body of
+// POST /templates/:id/transfer-ownership.
+type transferOwnershipRequest struct {
+	Maintainers string `json:"maintainers"`
+	RequestedBy string `json:"requested_by"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// transferOwnership serves POST /templates/:id/transfer-ownership,
+// replacing the template's maintainer list and recording who requested
+// the change and why.
+func (s *Server) transferOwnership(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	var req transferOwnershipRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	tmpl, err := s.templates.TransferOwnership(id, req.Maintainers, req.RequestedBy, req.Reason)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tmpl)
+}
+
+// ownershipHistory serves GET /templates/:id/ownership-history, listing
+// every recorded TransferOwnership call for the template, most recent
+// first.
+func (s *Server) ownershipHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	events, err := s.templates.OwnershipHistory(id)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: events})
+}
+
+func (s *Server) getTemplate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	tmpl, err := s.templates.Get(uint(id))
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tmpl)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+)
+
+// API version strings, as used in URL paths and by withAPIVersion.
+const (
+	APIVersionV1 = "v1"
+	APIVersionV2 = "v2"
+)
+
+type ctxKeyAPIVersion struct{}
+
+// withAPIVersion tags every request reaching next with version, so
+// handlers deep in the call stack (writeError, in particular) can tell
+// which version's response shape to use without version-specific
+// route registration. Router mounts the identical handler tree at both
+// /api/v1 and /api/v2; this is the only thing that actually
+// distinguishes the two at request time.
+func withAPIVersion(version string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ctxKeyAPIVersion{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiVersion returns the version withAPIVersion tagged r's context
+// with, defaulting to APIVersionV1 for any request that bypassed it
+// (there shouldn't be any reachable through Router, but defaulting to
+// the conservative, already-public response shape is safer than a
+// panic if a future route is mounted without it).
+func apiVersion(r *http.Request) string {
+	if v, ok := r.Context().Value(ctxKeyAPIVersion{}).(string); ok {
+		return v
+	}
+	return APIVersionV1
+}
+
+// deprecationHeaders marks every v1 response as deprecated in favor of
+// v2, per the conventions of RFC 8594 (Deprecation) and the Sunset
+// header draft it references. Sunset is only set once an actual
+// retirement date has been decided (ORCHESTRATOR_API_V1_SUNSET_AT, an
+// RFC3339 timestamp) rather than inventing one; until then, clients
+// still get Deprecation and Link so they have time to move to v2.
+func deprecationHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", `</api/v2>; rel="successor-version"`)
+		if sunset := os.Getenv("ORCHESTRATOR_API_V1_SUNSET_AT"); sunset != "" {
+			w.Header().Set("Sunset", sunset)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// errorCodeForStatus maps an HTTP status to the stable machine-readable
+// code v2's typed error envelope reports alongside its human-readable
+// message, so a v2 client can switch on code without parsing message
+// text or the status line.
+func errorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusUnprocessableEntity:
+		return "unprocessable_entity"
+	case http.StatusTooManyRequests:
+		return "too_many_requests"
+	case http.StatusServiceUnavailable:
+		return "unavailable"
+	default:
+		if status >= 500 {
+			return "internal_error"
+		}
+		return "request_error"
+	}
+}
+
+// v2Error is the body of a v2 error response, replacing v1's untyped
+// {"error": "message"} with a stable code a client can branch on.
+type v2Error struct {
+	Error v2ErrorDetail `json:"error"`
+}
+
+type v2ErrorDetail struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
@@ -0,0 +1,38 @@
+package artifact
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore persists artifacts as files under Dir, mirroring each key as
+// a relative path so callers can namespace keys by execution and step.
+type LocalStore struct {
+	Dir string
+}
+
+// NewLocalStore builds a LocalStore rooted at dir.
+func NewLocalStore(dir string) LocalStore {
+	return LocalStore{Dir: dir}
+}
+
+// Put writes r to Dir/key, creating any intermediate directories.
+func (s LocalStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	path := filepath.Join(s.Dir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return io.Copy(file, r)
+}
+
+// Get opens Dir/key for reading.
+func (s LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.Dir, key))
+}
@@ -0,0 +1,34 @@
+package artifact
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreRoundTrips(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	n, err := store.Put(context.Background(), "exec/step/name", strings.NewReader("contents"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if n != int64(len("contents")) {
+		t.Fatalf("expected 8 bytes written, got %d", n)
+	}
+
+	reader, err := store.Get(context.Background(), "exec/step/name")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer reader.Close()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(got) != "contents" {
+		t.Fatalf("expected %q, got %q", "contents", got)
+	}
+}
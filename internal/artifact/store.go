@@ -0,0 +1,18 @@
+// Package artifact persists files steps produce during an execution so
+// downstream steps, or a human after the fact, can retrieve them.
+package artifact
+
+import (
+	"context"
+	"io"
+)
+
+// Store persists artifact bytes under an opaque key and serves them back.
+// LocalStore is the only implementation today; a Store backed by S3 or
+// MinIO can satisfy the same interface without any caller changes.
+type Store interface {
+	// Put writes all of r under key, returning the number of bytes written.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get opens key for reading. Callers must Close the returned ReadCloser.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
@@ -0,0 +1,31 @@
+// Package broker provides named message consumption, used by the
+// executor's consume-and-wait gate step to block a workflow until a
+// matching message arrives on a topic/queue from an external system
+// (e.g. a Kafka topic or an AMQP queue confirming an asynchronous
+// operation completed).
+package broker
+
+import "context"
+
+// Message is one message delivered by a Consumer.
+type Message struct {
+	Key     string
+	Value   string
+	Headers map[string]string
+}
+
+// Consumer subscribes to named topics/queues and delivers messages as
+// they arrive. Implementations must be safe for concurrent use, since a
+// single Consumer is shared across every step that names it.
+//
+// This package ships only MemoryConsumer. Real Kafka/AMQP backends are
+// expected to be supplied by the operator as their own Consumer
+// implementation, the same way lock.Provider ships MemoryLockProvider
+// and a MySQL-backed one but leaves other backends to the operator.
+type Consumer interface {
+	// Subscribe starts delivering messages published to topic onto the
+	// returned channel. The returned cancel func must be called exactly
+	// once, whenever the caller is done reading, to stop delivery and
+	// release any resources; it's safe to call even after ctx is done.
+	Subscribe(ctx context.Context, topic string) (messages <-chan Message, cancel func(), err error)
+}
@@ -0,0 +1,60 @@
+package broker
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryConsumer is an in-process Consumer backed by fan-out channels,
+// suitable for single-process deployments, tests, and as the default
+// "memory" provider operators can publish to from their own code before
+// wiring a real broker.
+type MemoryConsumer struct {
+	mu   sync.Mutex
+	subs map[string][]chan Message
+}
+
+// NewMemoryConsumer builds an empty MemoryConsumer.
+func NewMemoryConsumer() *MemoryConsumer {
+	return &MemoryConsumer{subs: make(map[string][]chan Message)}
+}
+
+// Subscribe implements Consumer.
+func (c *MemoryConsumer) Subscribe(ctx context.Context, topic string) (<-chan Message, func(), error) {
+	ch := make(chan Message, 16)
+
+	c.mu.Lock()
+	c.subs[topic] = append(c.subs[topic], ch)
+	c.mu.Unlock()
+
+	cancel := func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		subs := c.subs[topic]
+		for i, existing := range subs {
+			if existing == ch {
+				c.subs[topic] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+	return ch, cancel, nil
+}
+
+// Publish delivers msg to every current subscriber of topic. Messages
+// published before a subscriber calls Subscribe are not buffered for
+// it, matching how a real broker's at-most-once replay window works
+// without a consumer group already registered. A subscriber whose
+// channel is full drops the message rather than blocking the publisher.
+func (c *MemoryConsumer) Publish(ctx context.Context, topic string, msg Message) {
+	c.mu.Lock()
+	subs := append([]chan Message(nil), c.subs[topic]...)
+	c.mu.Unlock()
+
+	for _, ch := range subs {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package broker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryConsumerDeliversPublishedMessages(t *testing.T) {
+	c := NewMemoryConsumer()
+	messages, cancel, err := c.Subscribe(context.Background(), "orders")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer cancel()
+
+	c.Publish(context.Background(), "orders", Message{Key: "1", Value: "paid"})
+
+	select {
+	case msg := <-messages:
+		if msg.Value != "paid" {
+			t.Fatalf("unexpected message: %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestMemoryConsumerDoesNotDeliverToOtherTopics(t *testing.T) {
+	c := NewMemoryConsumer()
+	messages, cancel, err := c.Subscribe(context.Background(), "orders")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	defer cancel()
+
+	c.Publish(context.Background(), "shipments", Message{Value: "dispatched"})
+
+	select {
+	case msg := <-messages:
+		t.Fatalf("unexpected message on unrelated topic: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryConsumerStopsDeliveringAfterCancel(t *testing.T) {
+	c := NewMemoryConsumer()
+	messages, cancel, err := c.Subscribe(context.Background(), "orders")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	cancel()
+
+	c.Publish(context.Background(), "orders", Message{Value: "paid"})
+
+	select {
+	case msg, ok := <-messages:
+		if ok {
+			t.Fatalf("unexpected message after cancel: %+v", msg)
+		}
+	case <-time.After(50 * time.Millisecond):
+	}
+}
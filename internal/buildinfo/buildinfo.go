@@ -0,0 +1,12 @@
+// Package buildinfo holds version metadata set at build time, so running
+// processes can report which build they are without a separate manifest
+// file.
+package buildinfo
+
+// Version is the orchestrator's build version, e.g. a git tag or commit
+// SHA. Overridden at build time with:
+//
+//	go build -ldflags "-X github.com/fengmingli/orchestrator/internal/buildinfo.Version=1.2.3"
+//
+// Left as "dev" for local builds that don't set it.
+var Version = "dev"
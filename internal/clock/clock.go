@@ -0,0 +1,20 @@
+// Package clock abstracts time so retry backoff, TTL expiry and timeouts
+// can be tested deterministically with a fake clock instead of sleeping
+// in real time.
+package clock
+
+import "time"
+
+// Clock is the subset of the time package the orchestrator depends on.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Real is a Clock backed by the actual system clock.
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (Real) Sleep(d time.Duration)                  { time.Sleep(d) }
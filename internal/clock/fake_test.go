@@ -0,0 +1,31 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeAfterFiresOnAdvance(t *testing.T) {
+	f := NewFake(time.Unix(0, 0))
+	ch := f.After(5 * time.Second)
+
+	select {
+	case <-ch:
+		t.Fatal("expected After not to fire before Advance")
+	default:
+	}
+
+	f.Advance(3 * time.Second)
+	select {
+	case <-ch:
+		t.Fatal("expected After not to fire before the full duration elapses")
+	default:
+	}
+
+	f.Advance(2 * time.Second)
+	select {
+	case <-ch:
+	default:
+		t.Fatal("expected After to fire once the duration elapses")
+	}
+}
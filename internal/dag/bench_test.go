@@ -0,0 +1,132 @@
+package dag
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// These sizes mirror production-scale templates generated by fan-out step
+// types (see executor's dynamic steps); the table-driven DAG tests only
+// exercise graphs of a handful of nodes, which says nothing about how
+// Layers behaves once a template has thousands of them.
+const benchGraphSize = 10000
+
+// wideSteps returns benchGraphSize independent steps with no
+// dependencies on each other, all depending on a single root: the
+// broadest possible layer shape, one layer deep past the root.
+func wideSteps(n int) []model.TemplateStep {
+	steps := make([]model.TemplateStep, 0, n+1)
+	steps = append(steps, model.TemplateStep{Name: "root", Type: "shell"})
+	for i := 0; i < n; i++ {
+		steps = append(steps, model.TemplateStep{Name: fmt.Sprintf("leaf-%d", i), Type: "shell", DependsOn: "root"})
+	}
+	return steps
+}
+
+// deepSteps returns a single chain of n steps, each depending on the
+// one before it: the narrowest possible layer shape, n layers deep.
+func deepSteps(n int) []model.TemplateStep {
+	steps := make([]model.TemplateStep, n)
+	steps[0] = model.TemplateStep{Name: "step-0", Type: "shell"}
+	for i := 1; i < n; i++ {
+		steps[i] = model.TemplateStep{Name: fmt.Sprintf("step-%d", i), Type: "shell", DependsOn: fmt.Sprintf("step-%d", i-1)}
+	}
+	return steps
+}
+
+// diamondSteps returns n/4 repeated diamonds (one node fanning out to
+// two, which join back into one) chained end to end, exercising a graph
+// with both width and depth instead of only one or the other.
+func diamondSteps(n int) []model.TemplateStep {
+	diamonds := n / 4
+	steps := make([]model.TemplateStep, 0, diamonds*4)
+	prev := ""
+	for i := 0; i < diamonds; i++ {
+		top := fmt.Sprintf("top-%d", i)
+		left := fmt.Sprintf("left-%d", i)
+		right := fmt.Sprintf("right-%d", i)
+		bottom := fmt.Sprintf("bottom-%d", i)
+		steps = append(steps, model.TemplateStep{Name: top, Type: "shell", DependsOn: prev})
+		steps = append(steps, model.TemplateStep{Name: left, Type: "shell", DependsOn: top})
+		steps = append(steps, model.TemplateStep{Name: right, Type: "shell", DependsOn: top})
+		steps = append(steps, model.TemplateStep{Name: bottom, Type: "shell", DependsOn: left + "," + right})
+		prev = bottom
+	}
+	return steps
+}
+
+func BenchmarkLayersWide(b *testing.B) {
+	steps := wideSteps(benchGraphSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g, err := Build(steps)
+		if err != nil {
+			b.Fatalf("Build: %v", err)
+		}
+		if _, err := g.Layers(); err != nil {
+			b.Fatalf("Layers: %v", err)
+		}
+	}
+}
+
+func BenchmarkLayersDeep(b *testing.B) {
+	steps := deepSteps(benchGraphSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g, err := Build(steps)
+		if err != nil {
+			b.Fatalf("Build: %v", err)
+		}
+		if _, err := g.Layers(); err != nil {
+			b.Fatalf("Layers: %v", err)
+		}
+	}
+}
+
+func BenchmarkLayersDiamond(b *testing.B) {
+	steps := diamondSteps(benchGraphSize)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		g, err := Build(steps)
+		if err != nil {
+			b.Fatalf("Build: %v", err)
+		}
+		if _, err := g.Layers(); err != nil {
+			b.Fatalf("Layers: %v", err)
+		}
+	}
+}
+
+// TestLayersStaysWithinPerformanceBudgetOnLargeGraphs is a regression
+// guard, not a correctness test: it fails if Build+Layers on a
+// benchGraphSize-node graph regresses into something asymptotically
+// worse than today's near-linear indegree pass, long before it'd show up
+// as a slow production run. The deadline is generous on purpose so it
+// doesn't flake under normal CI load.
+func TestLayersStaysWithinPerformanceBudgetOnLargeGraphs(t *testing.T) {
+	const budget = 2 * time.Second
+	cases := map[string][]model.TemplateStep{
+		"wide":    wideSteps(benchGraphSize),
+		"deep":    deepSteps(benchGraphSize),
+		"diamond": diamondSteps(benchGraphSize),
+	}
+	for name, steps := range cases {
+		steps := steps
+		t.Run(name, func(t *testing.T) {
+			g, err := Build(steps)
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			start := time.Now()
+			if _, err := g.Layers(); err != nil {
+				t.Fatalf("Layers: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > budget {
+				t.Fatalf("Layers on a %d-node %s graph took %v, exceeding the %v budget", benchGraphSize, name, elapsed, budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CycleError reports that a graph could not be topologically sorted
+// because it contains a cycle. Path lists the cycle itself, in
+// dependency order, with the first name repeated at the end to close
+// the loop, so a caller like the templates API can highlight exactly
+// which steps to break instead of just dumping every step still stuck
+// with a nonzero indegree.
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dag: cycle detected: %s", strings.Join(e.Path, " -> "))
+}
+
+// findCycle locates one cycle among the nodes named in remaining (the
+// step names Layers still hasn't been able to place into a layer) by
+// walking DependsOn edges depth-first until a node already on the
+// current path is revisited. remaining is iterated in sorted order so
+// the result is deterministic.
+func findCycle(g *Graph, remaining map[string]int) []string {
+	names := make([]string, 0, len(remaining))
+	for name := range remaining {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	visited := make(map[string]bool, len(names))
+	onPath := make(map[string]bool, len(names))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		visited[name] = true
+		onPath[name] = true
+		path = append(path, name)
+		for _, dep := range g.Nodes[name].DependsOn {
+			if _, stuck := remaining[dep]; !stuck {
+				continue
+			}
+			if onPath[dep] {
+				start := 0
+				for i, n := range path {
+					if n == dep {
+						start = i
+						break
+					}
+				}
+				return append(append([]string(nil), path[start:]...), dep)
+			}
+			if !visited[dep] {
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		onPath[name] = false
+		path = path[:len(path)-1]
+		return nil
+	}
+
+	for _, name := range names {
+		if !visited[name] {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,438 @@
+// Package dag builds an executable graph out of a WorkflowTemplate's steps
+// and exposes topological layers for the scheduler to run.
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// Node is one step in the graph, along with its resolved dependency names.
+type Node struct {
+	Name      string
+	Step      model.TemplateStep
+	DependsOn []string
+}
+
+// NodeSnapshot is an immutable copy of one Node's shape at the moment
+// Snapshot was taken: safe to read from any goroutine without touching
+// the live Graph (or its lock) again.
+type NodeSnapshot struct {
+	Name      string
+	Type      string
+	Config    string
+	DependsOn []string
+}
+
+// Graph is a directed acyclic graph of Nodes keyed by step name.
+//
+// mu guards the layering cache below and serializes AddNode/AddEdge
+// against it and against each other; it does not protect direct access
+// to Nodes itself, which callers throughout this codebase have always
+// read and, via AddNode/AddEdge, mutated without going through a lock.
+// Snapshot exists precisely so a caller that wants to safely read the
+// graph's shape from another goroutine (e.g. to export or hash it) can
+// do so without touching Nodes directly.
+type Graph struct {
+	Nodes map[string]*Node
+
+	mu sync.RWMutex
+
+	// layers, layerOf and dependents memoize the last Layers() result.
+	// AddNode and AddEdge keep them up to date incrementally rather than
+	// discarding them outright, since dynamic step fan-out can call
+	// either repeatedly against the same graph while an execution is in
+	// flight: a new dependency-free node just joins layer 0, and a new
+	// edge only has to push the nodes downstream of it that the edge
+	// actually displaced, not recompute the whole layering. layers is
+	// nil whenever there's nothing cached yet, which forces the next
+	// Layers() call to compute it from scratch and populate all three.
+	layers     [][]*Node
+	layerOf    map[string]int
+	dependents map[string][]string
+}
+
+// Build constructs a Graph from a template's steps, splitting each step's
+// DependsOn field (a comma-separated list of step names) into edges.
+func Build(steps []model.TemplateStep) (*Graph, error) {
+	g := &Graph{Nodes: make(map[string]*Node, len(steps))}
+	for _, s := range steps {
+		if _, exists := g.Nodes[s.Name]; exists {
+			return nil, fmt.Errorf("dag: duplicate step name %q", s.Name)
+		}
+		g.Nodes[s.Name] = &Node{
+			Name:      s.Name,
+			Step:      s,
+			DependsOn: splitDeps(s.DependsOn),
+		}
+	}
+	for _, n := range g.Nodes {
+		for _, dep := range n.DependsOn {
+			if _, ok := g.Nodes[dep]; !ok {
+				return nil, fmt.Errorf("dag: step %q depends on unknown step %q", n.Name, dep)
+			}
+		}
+	}
+	return g, nil
+}
+
+// AddNode adds step as a new node with no dependencies, returning an
+// error if a node with the same name is already in the graph. Use
+// AddEdge afterward to give it dependencies.
+//
+// A node with no dependencies can only ever belong in layer 0, so if
+// Layers has already been called on g, the new node is slotted into the
+// cached layer 0 directly instead of invalidating the cache.
+func (g *Graph) AddNode(step model.TemplateStep) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, exists := g.Nodes[step.Name]; exists {
+		return fmt.Errorf("dag: duplicate step name %q", step.Name)
+	}
+	node := &Node{
+		Name:      step.Name,
+		Step:      step,
+		DependsOn: splitDeps(step.DependsOn),
+	}
+	g.Nodes[step.Name] = node
+
+	if g.layers == nil {
+		return nil
+	}
+	if len(node.DependsOn) > 0 {
+		// DependsOn was set directly on the step rather than through
+		// AddEdge, so the usual incremental bookkeeping below never ran
+		// for it; recomputing from scratch on the next Layers() call is
+		// the only way to place it correctly.
+		g.invalidate()
+		return nil
+	}
+	g.placeInLayer(node, 0)
+	return nil
+}
+
+// AddEdge records that to depends on from, returning an error if either
+// name isn't already a node in the graph, or if doing so would make from
+// (transitively, through its existing DependsOn edges) depend on to,
+// which would close a cycle. promote's incremental re-layering below
+// assumes the graph stays acyclic; it has no cycle detection of its own
+// and recurses forever if that assumption is violated, so this check
+// must run before it.
+//
+// It is a no-op if the dependency is already recorded.
+//
+// If Layers has already been called on g, adding the edge only
+// recomputes the part of the cached layering the edge actually
+// disturbs: if to was already placed after from, nothing downstream
+// needs to move; otherwise to, and whatever transitively depends on it,
+// is pushed forward just far enough to restore the invariant that every
+// node sits in a later layer than everything it depends on.
+func (g *Graph) AddEdge(from, to string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	toNode, ok := g.Nodes[to]
+	if !ok {
+		return fmt.Errorf("dag: unknown step %q", to)
+	}
+	if _, ok := g.Nodes[from]; !ok {
+		return fmt.Errorf("dag: unknown step %q", from)
+	}
+	for _, dep := range toNode.DependsOn {
+		if dep == from {
+			return nil
+		}
+	}
+	if from == to || g.dependsOnTransitively(from, to) {
+		return fmt.Errorf("dag: adding edge %q -> %q would introduce a cycle", from, to)
+	}
+	toNode.DependsOn = append(toNode.DependsOn, from)
+
+	if g.layers == nil {
+		return nil
+	}
+	g.dependents[from] = append(g.dependents[from], to)
+	if g.layerOf[from] < g.layerOf[to] {
+		return nil
+	}
+	touched := map[int]bool{}
+	g.promote(to, g.layerOf[from]+1, touched)
+	g.collapseEmptyLayers(touched)
+	return nil
+}
+
+// dependsOnTransitively reports whether name depends, directly or
+// transitively through its existing DependsOn edges, on target. visited
+// is bounded by the number of nodes in the graph, so a name that can't
+// reach target terminates instead of looping, even if the graph were
+// somehow already cyclic.
+func (g *Graph) dependsOnTransitively(name, target string) bool {
+	visited := make(map[string]bool, len(g.Nodes))
+	stack := []string{name}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[n] {
+			continue
+		}
+		visited[n] = true
+		node, ok := g.Nodes[n]
+		if !ok {
+			continue
+		}
+		for _, dep := range node.DependsOn {
+			if dep == target {
+				return true
+			}
+			stack = append(stack, dep)
+		}
+	}
+	return false
+}
+
+func splitDeps(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	deps := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			deps = append(deps, p)
+		}
+	}
+	return deps
+}
+
+// invalidate discards the cached layering, forcing the next Layers()
+// call to recompute it from scratch.
+func (g *Graph) invalidate() {
+	g.layers = nil
+	g.layerOf = nil
+	g.dependents = nil
+}
+
+// placeInLayer inserts node into the cached layering at layerIdx,
+// creating the layer if it doesn't exist yet, and keeps that layer
+// sorted by name so cached results stay consistent with a fresh
+// computation. It must only be called once the cache is known to exist.
+func (g *Graph) placeInLayer(node *Node, layerIdx int) {
+	for len(g.layers) <= layerIdx {
+		g.layers = append(g.layers, nil)
+	}
+	g.layers[layerIdx] = append(g.layers[layerIdx], node)
+	sort.Slice(g.layers[layerIdx], func(i, j int) bool { return g.layers[layerIdx][i].Name < g.layers[layerIdx][j].Name })
+	g.layerOf[node.Name] = layerIdx
+	if _, ok := g.dependents[node.Name]; !ok {
+		g.dependents[node.Name] = nil
+	}
+}
+
+// removeFromLayer deletes name from the cached layer it's currently in,
+// recording that layer's index in touched so collapseEmptyLayers can
+// clean it up afterward if it ended up empty.
+func (g *Graph) removeFromLayer(name string, touched map[int]bool) {
+	idx := g.layerOf[name]
+	touched[idx] = true
+	layer := g.layers[idx]
+	for i, n := range layer {
+		if n.Name == name {
+			g.layers[idx] = append(layer[:i], layer[i+1:]...)
+			return
+		}
+	}
+}
+
+// promote moves name into layerIdx (or later, if something already
+// downstream of it requires that), then recurses into whatever
+// transitively depends on name that the move leaves inconsistent. This
+// only visits the subgraph AddEdge's new edge actually displaced, not
+// the whole graph.
+func (g *Graph) promote(name string, layerIdx int, touched map[int]bool) {
+	if g.layerOf[name] >= layerIdx {
+		return
+	}
+	g.removeFromLayer(name, touched)
+	g.placeInLayer(g.Nodes[name], layerIdx)
+	touched[layerIdx] = true
+	for _, dependent := range g.dependents[name] {
+		g.promote(dependent, layerIdx+1, touched)
+	}
+}
+
+// collapseEmptyLayers removes any layer left empty by promote and
+// shifts every later node's layerOf index down to match, restoring the
+// invariant that Layers() never returns a gap. touched is the small set
+// of layer indices promote actually touched, so this only rescans
+// layers in that range rather than the whole cache.
+func (g *Graph) collapseEmptyLayers(touched map[int]bool) {
+	if len(touched) == 0 {
+		return
+	}
+	kept := g.layers[:0]
+	removed := 0
+	for i, layer := range g.layers {
+		if len(layer) == 0 && touched[i] {
+			removed++
+			continue
+		}
+		if removed > 0 {
+			for _, n := range layer {
+				g.layerOf[n.Name] = i - removed
+			}
+		}
+		kept = append(kept, layer)
+	}
+	g.layers = kept
+}
+
+// Layers returns the graph's nodes grouped into topologically-ordered
+// layers: every node in layer i depends only on nodes in layers < i, and
+// nodes within a layer can run in parallel. Within a layer, nodes are
+// sorted by name, which Build already requires to be unique, so the same
+// graph always produces the same layers regardless of Go's randomized
+// map iteration order — callers that want reproducible runs (see
+// executor.WithDeterministic) can rely on this without needing a
+// separate tie-breaking seed. An error is returned if the graph contains
+// a cycle.
+//
+// If AddNode/AddEdge have kept the cache up to date since the last call
+// (see their docs), this returns it directly. Otherwise it recomputes
+// from scratch: the bookkeeping below interns each node name to a small
+// integer index once up front and does its indegree/adjacency/frontier
+// tracking as plain int slices rather than string-keyed maps, and only
+// re-sorts each layer's own nodes rather than rescanning every
+// still-unplaced node on every layer, which keeps both the working set
+// and the running time close to linear in the number of nodes and
+// edges — that starts to matter once a generated template has tens of
+// thousands of steps.
+func (g *Graph) Layers() ([][]*Node, error) {
+	g.mu.RLock()
+	cached := g.layers
+	g.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.layers != nil {
+		// Another call populated the cache while we were waiting for the
+		// write lock above.
+		return g.layers, nil
+	}
+
+	n := len(g.Nodes)
+	if n == 0 {
+		return nil, nil
+	}
+
+	id := make(map[string]int, n)
+	names := make([]string, n)
+	nodes := make([]*Node, n)
+	i := 0
+	for name, node := range g.Nodes {
+		id[name] = i
+		names[i] = name
+		nodes[i] = node
+		i++
+	}
+
+	indegree := make([]int, n)
+	adjacency := make([][]int, n)
+	for idx, node := range nodes {
+		indegree[idx] = len(node.DependsOn)
+		for _, dep := range node.DependsOn {
+			d := id[dep]
+			adjacency[d] = append(adjacency[d], idx)
+		}
+	}
+
+	frontier := make([]int, 0, n)
+	for idx := range nodes {
+		if indegree[idx] == 0 {
+			frontier = append(frontier, idx)
+		}
+	}
+
+	layerOf := make(map[string]int, n)
+	var layers [][]*Node
+	placed := 0
+	layerIdx := 0
+	for len(frontier) > 0 {
+		sort.Slice(frontier, func(a, b int) bool { return names[frontier[a]] < names[frontier[b]] })
+		layer := make([]*Node, len(frontier))
+		var next []int
+		for j, idx := range frontier {
+			layer[j] = nodes[idx]
+			layerOf[names[idx]] = layerIdx
+			for _, dependent := range adjacency[idx] {
+				indegree[dependent]--
+				if indegree[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		placed += len(frontier)
+		layers = append(layers, layer)
+		frontier = next
+		layerIdx++
+	}
+	if placed < n {
+		remaining := make(map[string]int, n-placed)
+		for idx := range nodes {
+			if indegree[idx] > 0 {
+				remaining[names[idx]] = indegree[idx]
+			}
+		}
+		return nil, &CycleError{Path: findCycle(g, remaining)}
+	}
+
+	dependents := make(map[string][]string, n)
+	for idx, adj := range adjacency {
+		if len(adj) == 0 {
+			continue
+		}
+		deps := make([]string, len(adj))
+		for j, d := range adj {
+			deps[j] = names[d]
+		}
+		dependents[names[idx]] = deps
+	}
+	g.layers = layers
+	g.layerOf = layerOf
+	g.dependents = dependents
+	return layers, nil
+}
+
+// Snapshot copies out every node's name, type, config and dependency
+// list under a brief read lock, then releases it before returning:
+// callers that only need to serialize or hash the graph's current shape
+// (see ToGraphviz, StructuralHash) use this so that building a
+// potentially large string doesn't hold the lock — and so block
+// AddNode/AddEdge — for the whole time it takes to do that. The
+// returned slice is sorted by name and is the caller's own copy; later
+// mutations to g never affect it.
+func (g *Graph) Snapshot() []NodeSnapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	snapshot := make([]NodeSnapshot, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		snapshot = append(snapshot, NodeSnapshot{
+			Name:      n.Name,
+			Type:      n.Step.Type,
+			Config:    n.Step.Config,
+			DependsOn: append([]string(nil), n.DependsOn...),
+		})
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+	return snapshot
+}
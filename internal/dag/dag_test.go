@@ -0,0 +1,352 @@
+package dag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestLayersOrdersByDependency(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+		{Name: "c", Type: "shell", DependsOn: "a"},
+		{Name: "d", Type: "shell", DependsOn: "b,c"},
+	}
+	g, err := Build(steps)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	layers, err := g.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+	if len(layers) != 3 {
+		t.Fatalf("expected 3 layers, got %d", len(layers))
+	}
+	if len(layers[0]) != 1 || layers[0][0].Name != "a" {
+		t.Fatalf("expected layer 0 = [a], got %v", layers[0])
+	}
+	if len(layers[1]) != 2 {
+		t.Fatalf("expected layer 1 to have 2 nodes, got %d", len(layers[1]))
+	}
+	if len(layers[2]) != 1 || layers[2][0].Name != "d" {
+		t.Fatalf("expected layer 2 = [d], got %v", layers[2])
+	}
+}
+
+func TestLayersSortsEachLayerByName(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "z", Type: "shell"},
+		{Name: "m", Type: "shell"},
+		{Name: "a", Type: "shell"},
+	}
+	g, err := Build(steps)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	layers, err := g.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+	if len(layers) != 1 || len(layers[0]) != 3 {
+		t.Fatalf("expected a single layer of 3 nodes, got %v", layers)
+	}
+	got := []string{layers[0][0].Name, layers[0][1].Name, layers[0][2].Name}
+	want := []string{"a", "m", "z"}
+	if got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("expected layer sorted by name %v, got %v", want, got)
+	}
+}
+
+func TestLayersDetectsCycle(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", DependsOn: "b"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	}
+	g, err := Build(steps)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	_, err = g.Layers()
+	if err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected a *CycleError, got %T: %v", err, err)
+	}
+	if len(cycleErr.Path) != 3 || cycleErr.Path[0] != cycleErr.Path[2] {
+		t.Fatalf("expected a closed 2-node cycle path, got %v", cycleErr.Path)
+	}
+}
+
+func TestLayersCyclePathExcludesUnrelatedNodes(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "standalone", Type: "shell"},
+		{Name: "a", Type: "shell", DependsOn: "c"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+		{Name: "c", Type: "shell", DependsOn: "b"},
+	}
+	g, err := Build(steps)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	_, err = g.Layers()
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected a *CycleError, got %T: %v", err, err)
+	}
+	for _, name := range cycleErr.Path {
+		if name == "standalone" {
+			t.Fatalf("expected cycle path to exclude the unrelated node, got %v", cycleErr.Path)
+		}
+	}
+	if len(cycleErr.Path) != 4 {
+		t.Fatalf("expected the 3-node cycle plus the repeated start, got %v", cycleErr.Path)
+	}
+}
+
+func TestBuildRejectsUnknownDependency(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", DependsOn: "missing"},
+	}
+	if _, err := Build(steps); err == nil {
+		t.Fatal("expected error for unknown dependency, got nil")
+	}
+}
+
+func TestAddNodeAndAddEdgeGrowTheGraph(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := g.AddNode(model.TemplateStep{Name: "b", Type: "shell"}); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+	if err := g.AddEdge("a", "b"); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+
+	layers, err := g.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+	if len(layers) != 2 || layers[0][0].Name != "a" || layers[1][0].Name != "b" {
+		t.Fatalf("expected [[a] [b]], got %v", layers)
+	}
+}
+
+func TestAddNodeRejectsDuplicateName(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := g.AddNode(model.TemplateStep{Name: "a", Type: "shell"}); err == nil {
+		t.Fatal("expected an error adding a duplicate node name")
+	}
+}
+
+func TestAddEdgeRejectsUnknownNodes(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := g.AddEdge("a", "missing"); err == nil {
+		t.Fatal("expected an error for an unknown target node")
+	}
+	if err := g.AddEdge("missing", "a"); err == nil {
+		t.Fatal("expected an error for an unknown source node")
+	}
+}
+
+// TestAddEdgeRejectsACycleAfterLayersHasCached reproduces the scenario
+// that used to hang forever: once Layers() has populated the cache,
+// adding an edge that closes a cycle between two nodes that keep
+// leapfrogging each other's layer must return an error from promote's
+// incremental path instead of recursing without ever terminating. The
+// call is run on its own goroutine with a hard deadline so a regression
+// fails the test instead of hanging the whole suite.
+func TestAddEdgeRejectsACycleAfterLayersHasCached(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}, {Name: "b", Type: "shell", DependsOn: "a"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := g.Layers(); err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- g.AddEdge("b", "a") }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected AddEdge to reject the cycle-closing edge, got nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("AddEdge did not return within 5s; promote is likely recursing forever on the cycle")
+	}
+}
+
+// TestAddEdgeRejectsACycleBeforeLayersHasBeenCalled covers the same
+// rejection on the uncached path, where AddEdge returns before touching
+// promote at all.
+func TestAddEdgeRejectsACycleBeforeLayersHasBeenCalled(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}, {Name: "b", Type: "shell", DependsOn: "a"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := g.AddEdge("b", "a"); err == nil {
+		t.Fatal("expected AddEdge to reject the cycle-closing edge, got nil")
+	}
+}
+
+// TestAddEdgeRejectsASelfLoop covers from == to, which
+// dependsOnTransitively alone wouldn't catch since a node never already
+// depends on itself.
+func TestAddEdgeRejectsASelfLoop(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "a", Type: "shell"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := g.AddEdge("a", "a"); err == nil {
+		t.Fatal("expected AddEdge to reject a self-loop, got nil")
+	}
+}
+
+// TestSnapshotIsSafeAlongsideConcurrentMutation exercises Snapshot (and
+// the ToGraphviz/StructuralHash callers built on top of it) running
+// concurrently with AddNode/AddEdge mutating the same graph, the
+// scenario a dynamic step fan-out exporting a huge in-flight graph
+// would hit. It only needs to not race under `go test -race`; it makes
+// no assertion about which snapshot a given call happens to observe.
+func TestSnapshotIsSafeAlongsideConcurrentMutation(t *testing.T) {
+	g, err := Build([]model.TemplateStep{{Name: "seed", Type: "shell"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			name := fmt.Sprintf("n%d", i)
+			if err := g.AddNode(model.TemplateStep{Name: name, Type: "shell"}); err != nil {
+				t.Errorf("AddNode: %v", err)
+				return
+			}
+			if err := g.AddEdge("seed", name); err != nil {
+				t.Errorf("AddEdge: %v", err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		_ = g.ToGraphviz()
+		_ = g.StructuralHash()
+	}
+	<-done
+}
+
+func layerNames(layers [][]*Node) [][]string {
+	out := make([][]string, len(layers))
+	for i, layer := range layers {
+		names := make([]string, len(layer))
+		for j, n := range layer {
+			names[j] = n.Name
+		}
+		out[i] = names
+	}
+	return out
+}
+
+// TestAddNodeAfterLayersJoinsCachedLayerZero exercises the incremental
+// path in AddNode: since a dependency-free node can only ever belong in
+// layer 0, adding one after Layers has already been called must update
+// the cached layering in place rather than force a full recompute.
+func TestAddNodeAfterLayersJoinsCachedLayerZero(t *testing.T) {
+	g, err := Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := g.Layers(); err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	if err := g.AddNode(model.TemplateStep{Name: "c", Type: "shell"}); err != nil {
+		t.Fatalf("AddNode: %v", err)
+	}
+
+	layers, err := g.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+	got := layerNames(layers)
+	want := [][]string{{"a", "c"}, {"b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+// TestAddEdgeAfterLayersPromotesOnlyTheAffectedSubgraph covers AddEdge's
+// incremental path: pushing a node into a later layer because of a new
+// dependency must also push anything that transitively depends on it,
+// but must leave every other node's layer untouched. The result is
+// cross-checked against building the same final graph from scratch.
+func TestAddEdgeAfterLayersPromotesOnlyTheAffectedSubgraph(t *testing.T) {
+	g, err := Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+		{Name: "c", Type: "shell", DependsOn: "b"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := g.Layers(); err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	if err := g.AddNode(model.TemplateStep{Name: "d", Type: "shell"}); err != nil {
+		t.Fatalf("AddNode d: %v", err)
+	}
+	if err := g.AddNode(model.TemplateStep{Name: "e", Type: "shell", DependsOn: "d"}); err != nil {
+		t.Fatalf("AddNode e: %v", err)
+	}
+	// d starts in layer 0 alongside a; this pushes it behind c (layer 2),
+	// which must also push e, the only node depending on d, behind it.
+	if err := g.AddEdge("c", "d"); err != nil {
+		t.Fatalf("AddEdge: %v", err)
+	}
+
+	got, err := g.Layers()
+	if err != nil {
+		t.Fatalf("Layers: %v", err)
+	}
+
+	fresh, err := Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+		{Name: "c", Type: "shell", DependsOn: "b"},
+		{Name: "d", Type: "shell", DependsOn: "c"},
+		{Name: "e", Type: "shell", DependsOn: "d"},
+	})
+	if err != nil {
+		t.Fatalf("Build fresh: %v", err)
+	}
+	want, err := fresh.Layers()
+	if err != nil {
+		t.Fatalf("Layers fresh: %v", err)
+	}
+
+	if !reflect.DeepEqual(layerNames(got), layerNames(want)) {
+		t.Fatalf("incremental layering %v diverged from a from-scratch recompute %v", layerNames(got), layerNames(want))
+	}
+}
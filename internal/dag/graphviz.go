@@ -0,0 +1,28 @@
+package dag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToGraphviz renders the graph as a DOT document, suitable for feeding
+// to graphviz or any other DOT-consuming renderer. It builds the
+// document from a Snapshot rather than Nodes directly, so rendering a
+// huge graph doesn't hold Graph's lock for the whole time it takes to
+// build the string.
+func (g *Graph) ToGraphviz() string {
+	snapshot := g.Snapshot()
+
+	var b strings.Builder
+	b.WriteString("digraph workflow {\n")
+	for _, n := range snapshot {
+		fmt.Fprintf(&b, "  %q [label=%q];\n", n.Name, fmt.Sprintf("%s (%s)", n.Name, n.Type))
+	}
+	for _, n := range snapshot {
+		for _, dep := range n.DependsOn {
+			fmt.Fprintf(&b, "  %q -> %q;\n", dep, n.Name)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package dag
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"sort"
+	"strings"
+)
+
+// StructuralHash returns an MD5 hex digest of g's structure: every node's
+// name, type, config, and sorted dependency list. Two graphs built from
+// the same steps hash identically no matter when or how many times the
+// hash is computed, so it is safe to persist and compare later to detect
+// whether a template's DAG changed. Like ToGraphviz, it hashes a
+// Snapshot rather than Nodes directly, so hashing a huge graph doesn't
+// hold Graph's lock for the whole time it takes to build the digest.
+func (g *Graph) StructuralHash() string {
+	snapshot := g.Snapshot()
+
+	var b strings.Builder
+	for _, n := range snapshot {
+		deps := append([]string(nil), n.DependsOn...)
+		sort.Strings(deps)
+
+		b.WriteString(n.Name)
+		b.WriteByte('\n')
+		b.WriteString(n.Type)
+		b.WriteByte('\n')
+		b.WriteString(n.Config)
+		b.WriteByte('\n')
+		b.WriteString(strings.Join(deps, ","))
+		b.WriteByte('\n')
+	}
+
+	sum := md5.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,64 @@
+package dag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestStructuralHashIsStableAcrossCalls(t *testing.T) {
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "echo hi"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	}
+	g, err := Build(steps)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	first := g.StructuralHash()
+	time.Sleep(2 * time.Millisecond)
+	second := g.StructuralHash()
+	if first != second {
+		t.Fatalf("expected the same hash on repeated calls, got %q and %q", first, second)
+	}
+}
+
+func TestStructuralHashIgnoresDependencyOrder(t *testing.T) {
+	a, err := Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell"},
+		{Name: "c", Type: "shell", DependsOn: "a,b"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	b, err := Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell"},
+		{Name: "c", Type: "shell", DependsOn: "b,a"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if a.StructuralHash() != b.StructuralHash() {
+		t.Fatal("expected dependency order to not affect the structural hash")
+	}
+}
+
+func TestStructuralHashChangesWithStepConfig(t *testing.T) {
+	a, err := Build([]model.TemplateStep{{Name: "a", Type: "shell", Config: "echo one"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	b, err := Build([]model.TemplateStep{{Name: "a", Type: "shell", Config: "echo two"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if a.StructuralHash() == b.StructuralHash() {
+		t.Fatal("expected changing a step's config to change the structural hash")
+	}
+}
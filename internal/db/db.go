@@ -0,0 +1,50 @@
+// Package db wires up the GORM connection and schema migration shared by
+// the services and executor.
+package db
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// Open opens a GORM connection for the given DSN and runs auto-migration
+// for all known models. dsn is passed straight to the sqlite driver, so
+// ":memory:" and file paths both work.
+func Open(dsn string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate runs auto-migration for all models known to the orchestrator.
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&model.WorkflowTemplate{},
+		&model.TemplateStep{},
+		&model.WorkflowExecution{},
+		&model.StepExecution{},
+		&model.ExecutionEvent{},
+		&model.Label{},
+		&model.EdgeLayout{},
+		&model.Quota{},
+		&model.Artifact{},
+		&model.LockAuditEvent{},
+		&model.Agent{},
+		&model.ExternalInput{},
+		&model.AlertRoute{},
+		&model.AlertExecution{},
+		&model.RedactionRule{},
+		&model.TemplateOwnershipEvent{},
+		&model.ExecutionNote{},
+		&model.ExecutionLink{},
+		&model.TemplateFixture{},
+		&model.BlackoutWindow{},
+	)
+}
@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/expr-lang/expr"
+)
+
+// AnsiblePlaybookTaskConfig is the JSON shape expected in a step's
+// Config for AnsiblePlaybookTask.
+type AnsiblePlaybookTaskConfig struct {
+	Playbook  string `json:"playbook"`
+	Inventory string `json:"inventory,omitempty"`
+	// ExtraVars maps a playbook variable name to an expr-lang expression
+	// evaluated against outputs (predecessor step outputs, via
+	// StepOutputsFromContext) and vars, the same convention ScriptTask
+	// and GraphQLTask use, so a value can reference an earlier step's
+	// output.
+	ExtraVars map[string]string `json:"extra_vars,omitempty"`
+	Vars      map[string]string `json:"vars,omitempty"`
+	// VaultPasswordEnvVar names the environment variable holding the
+	// playbook's vault password. AnsiblePlaybookTask never stores the
+	// password itself: it's written to a short-lived temp file for
+	// --vault-password-file and removed once the playbook finishes.
+	VaultPasswordEnvVar string `json:"vault_password_env_var,omitempty"`
+}
+
+// AnsibleHostResult summarizes one host's entry in the play recap.
+type AnsibleHostResult struct {
+	OK          int `json:"ok"`
+	Changed     int `json:"changed"`
+	Unreachable int `json:"unreachable"`
+	Failed      int `json:"failed"`
+	Skipped     int `json:"skipped"`
+}
+
+// AnsiblePlaybookResult is the JSON-encoded output AnsiblePlaybookTask
+// leaves in a step's Output: the play recap's per-host stats, with the
+// hosts that failed or were unreachable surfaced separately so a
+// downstream step doesn't have to scan every host's stats to find them.
+type AnsiblePlaybookResult struct {
+	Hosts            map[string]AnsibleHostResult `json:"hosts"`
+	FailedHosts      []string                     `json:"failed_hosts,omitempty"`
+	UnreachableHosts []string                     `json:"unreachable_hosts,omitempty"`
+}
+
+// ansibleJSONCallbackOutput is the subset of ansible-playbook's
+// ANSIBLE_STDOUT_CALLBACK=json output AnsiblePlaybookTask reads.
+type ansibleJSONCallbackOutput struct {
+	Stats map[string]struct {
+		Ok          int `json:"ok"`
+		Changed     int `json:"changed"`
+		Unreachable int `json:"unreachable"`
+		Failures    int `json:"failures"`
+		Skipped     int `json:"skipped"`
+	} `json:"stats"`
+}
+
+// AnsiblePlaybookTask runs an Ansible playbook via ansible-playbook and
+// parses its play recap into structured output.
+type AnsiblePlaybookTask struct {
+	// CLI is the executable to invoke, defaulting to "ansible-playbook".
+	// Tests override it with a stub binary.
+	CLI string
+}
+
+// Run executes the playbook described by config (a JSON-encoded
+// AnsiblePlaybookTaskConfig) and returns a JSON-encoded
+// AnsiblePlaybookResult parsed from its play recap.
+func (t AnsiblePlaybookTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg AnsiblePlaybookTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("ansible playbook task: invalid config: %w", err)
+	}
+	if cfg.Playbook == "" {
+		return "", fmt.Errorf("ansible playbook task: playbook is required")
+	}
+
+	extraVars, err := resolveAnsibleExtraVars(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{cfg.Playbook}
+	if cfg.Inventory != "" {
+		args = append(args, "-i", cfg.Inventory)
+	}
+	if len(extraVars) > 0 {
+		encoded, err := json.Marshal(extraVars)
+		if err != nil {
+			return "", fmt.Errorf("ansible playbook task: encode extra vars: %w", err)
+		}
+		args = append(args, "--extra-vars", string(encoded))
+	}
+
+	env := append(os.Environ(), "ANSIBLE_STDOUT_CALLBACK=json")
+	if cfg.VaultPasswordEnvVar != "" {
+		password := os.Getenv(cfg.VaultPasswordEnvVar)
+		if password == "" {
+			return "", fmt.Errorf("ansible playbook task: %s is not set", cfg.VaultPasswordEnvVar)
+		}
+		vaultFile, err := os.CreateTemp("", "orchestrator-ansible-vault-*")
+		if err != nil {
+			return "", fmt.Errorf("ansible playbook task: write vault password file: %w", err)
+		}
+		defer os.Remove(vaultFile.Name())
+		if _, writeErr := vaultFile.WriteString(password); writeErr != nil {
+			vaultFile.Close()
+			return "", fmt.Errorf("ansible playbook task: write vault password file: %w", writeErr)
+		}
+		vaultFile.Close()
+		args = append(args, "--vault-password-file", vaultFile.Name())
+	}
+
+	cli := t.CLI
+	if cli == "" {
+		cli = "ansible-playbook"
+	}
+	cmd := exec.CommandContext(ctx, cli, args...)
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	result, parseErr := parseAnsibleRecap(stdout.Bytes())
+	if parseErr != nil {
+		if runErr != nil {
+			return stderr.String(), fmt.Errorf("ansible playbook task: %w", runErr)
+		}
+		return stdout.String(), fmt.Errorf("ansible playbook task: parse play recap: %w", parseErr)
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	output := string(encoded)
+	if runErr != nil {
+		return output, fmt.Errorf("ansible playbook task: %w", runErr)
+	}
+	if len(result.FailedHosts) > 0 || len(result.UnreachableHosts) > 0 {
+		return output, fmt.Errorf("ansible playbook task: %d host(s) failed, %d unreachable", len(result.FailedHosts), len(result.UnreachableHosts))
+	}
+	return output, nil
+}
+
+// resolveAnsibleExtraVars evaluates each of cfg.ExtraVars' expr-lang
+// expressions against ctx's predecessor outputs and cfg.Vars.
+func resolveAnsibleExtraVars(ctx context.Context, cfg AnsiblePlaybookTaskConfig) (map[string]interface{}, error) {
+	if len(cfg.ExtraVars) == 0 {
+		return nil, nil
+	}
+	env := map[string]interface{}{
+		"outputs": StepOutputsFromContext(ctx),
+		"vars":    cfg.Vars,
+	}
+	resolved := make(map[string]interface{}, len(cfg.ExtraVars))
+	for name, expression := range cfg.ExtraVars {
+		program, err := expr.Compile(expression, expr.Env(env))
+		if err != nil {
+			return nil, fmt.Errorf("ansible playbook task: compile extra var %q: %w", name, err)
+		}
+		value, err := expr.Run(program, env)
+		if err != nil {
+			return nil, fmt.Errorf("ansible playbook task: evaluate extra var %q: %w", name, err)
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
+
+// parseAnsibleRecap decodes ansible-playbook's ANSIBLE_STDOUT_CALLBACK=json
+// output into an AnsiblePlaybookResult, sorting FailedHosts and
+// UnreachableHosts for deterministic output across runs.
+func parseAnsibleRecap(raw []byte) (*AnsiblePlaybookResult, error) {
+	var parsed ansibleJSONCallbackOutput
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, err
+	}
+
+	result := &AnsiblePlaybookResult{Hosts: make(map[string]AnsibleHostResult, len(parsed.Stats))}
+	for host, stats := range parsed.Stats {
+		result.Hosts[host] = AnsibleHostResult{
+			OK:          stats.Ok,
+			Changed:     stats.Changed,
+			Unreachable: stats.Unreachable,
+			Failed:      stats.Failures,
+			Skipped:     stats.Skipped,
+		}
+		if stats.Failures > 0 {
+			result.FailedHosts = append(result.FailedHosts, host)
+		}
+		if stats.Unreachable > 0 {
+			result.UnreachableHosts = append(result.UnreachableHosts, host)
+		}
+	}
+	sort.Strings(result.FailedHosts)
+	sort.Strings(result.UnreachableHosts)
+	return result, nil
+}
@@ -0,0 +1,145 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fakeAnsiblePlaybook writes an executable script to dir that prints a
+// canned ANSIBLE_STDOUT_CALLBACK=json recap to stdout (and exits
+// nonzero if exitCode is nonzero), standing in for the real
+// ansible-playbook binary.
+func fakeAnsiblePlaybook(t *testing.T, dir, recapJSON string, exitCode int) string {
+	t.Helper()
+	path := filepath.Join(dir, "fake-ansible-playbook")
+	script := "#!/bin/sh\ncat <<'EOF'\n" + recapJSON + "\nEOF\nexit " + strconv.Itoa(exitCode) + "\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ansible-playbook: %v", err)
+	}
+	return path
+}
+
+const successRecap = `{"stats":{"web-1":{"ok":3,"changed":1,"unreachable":0,"failures":0,"skipped":0}}}`
+const failureRecap = `{"stats":{"web-1":{"ok":2,"changed":1,"unreachable":0,"failures":1,"skipped":0},"web-2":{"ok":0,"changed":0,"unreachable":1,"failures":0,"skipped":0}}}`
+
+func TestAnsiblePlaybookTaskReturnsTheParsedRecapOnSuccess(t *testing.T) {
+	cli := fakeAnsiblePlaybook(t, t.TempDir(), successRecap, 0)
+	task := AnsiblePlaybookTask{CLI: cli}
+
+	output, err := task.Run(context.Background(), `{"playbook":"site.yml"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result AnsiblePlaybookResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.Hosts["web-1"].OK != 3 || result.Hosts["web-1"].Changed != 1 {
+		t.Fatalf("unexpected host stats: %+v", result.Hosts["web-1"])
+	}
+	if len(result.FailedHosts) != 0 || len(result.UnreachableHosts) != 0 {
+		t.Fatalf("expected no failures, got %+v", result)
+	}
+}
+
+func TestAnsiblePlaybookTaskSurfacesPerHostFailures(t *testing.T) {
+	cli := fakeAnsiblePlaybook(t, t.TempDir(), failureRecap, 0)
+	task := AnsiblePlaybookTask{CLI: cli}
+
+	output, err := task.Run(context.Background(), `{"playbook":"site.yml"}`)
+	if err == nil {
+		t.Fatal("expected Run to fail when a host failed")
+	}
+	var result AnsiblePlaybookResult
+	if jsonErr := json.Unmarshal([]byte(output), &result); jsonErr != nil {
+		t.Fatalf("unmarshal output: %v", jsonErr)
+	}
+	if len(result.FailedHosts) != 1 || result.FailedHosts[0] != "web-1" {
+		t.Fatalf("expected web-1 to be reported failed, got %+v", result.FailedHosts)
+	}
+	if len(result.UnreachableHosts) != 1 || result.UnreachableHosts[0] != "web-2" {
+		t.Fatalf("expected web-2 to be reported unreachable, got %+v", result.UnreachableHosts)
+	}
+}
+
+func TestAnsiblePlaybookTaskRequiresAPlaybook(t *testing.T) {
+	task := AnsiblePlaybookTask{}
+	if _, err := task.Run(context.Background(), `{}`); err == nil {
+		t.Fatal("expected Run to reject a config with no playbook")
+	}
+}
+
+func TestAnsiblePlaybookTaskResolvesExtraVarsFromPredecessorOutputs(t *testing.T) {
+	dir := t.TempDir()
+	recordPath := filepath.Join(dir, "args.txt")
+	cliPath := filepath.Join(dir, "fake-ansible-playbook")
+	script := "#!/bin/sh\necho \"$@\" > " + recordPath + "\ncat <<'EOF'\n" + successRecap + "\nEOF\n"
+	if err := os.WriteFile(cliPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ansible-playbook: %v", err)
+	}
+
+	task := AnsiblePlaybookTask{CLI: cliPath}
+	config := `{"playbook":"site.yml","extra_vars":{"target":"outputs.lookup"}}`
+	ctx := withStepOutputs(context.Background(), map[string]string{"lookup": "web-1"})
+
+	if _, err := task.Run(ctx, config); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	recorded, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("read recorded args: %v", err)
+	}
+	if !strings.Contains(string(recorded), `"target":"web-1"`) {
+		t.Fatalf("expected resolved extra var in args, got %q", recorded)
+	}
+}
+
+func TestAnsiblePlaybookTaskRequiresTheVaultPasswordEnvVarToBeSet(t *testing.T) {
+	cli := fakeAnsiblePlaybook(t, t.TempDir(), successRecap, 0)
+	task := AnsiblePlaybookTask{CLI: cli}
+
+	config := `{"playbook":"site.yml","vault_password_env_var":"ORCHESTRATOR_TEST_VAULT_PASSWORD_UNSET"}`
+	if _, err := task.Run(context.Background(), config); err == nil {
+		t.Fatal("expected Run to reject a vault_password_env_var that isn't set")
+	}
+}
+
+func TestAnsiblePlaybookTaskWritesTheVaultPasswordToAFile(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_TEST_VAULT_PASSWORD", "s3cret")
+	dir := t.TempDir()
+	recordPath := filepath.Join(dir, "vault-password-seen.txt")
+	cliPath := filepath.Join(dir, "fake-ansible-playbook")
+	script := `#!/bin/sh
+while [ "$#" -gt 0 ]; do
+  if [ "$1" = "--vault-password-file" ]; then
+    cat "$2" > ` + recordPath + `
+  fi
+  shift
+done
+cat <<'EOF'
+` + successRecap + `
+EOF
+`
+	if err := os.WriteFile(cliPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ansible-playbook: %v", err)
+	}
+
+	task := AnsiblePlaybookTask{CLI: cliPath}
+	config := `{"playbook":"site.yml","vault_password_env_var":"ORCHESTRATOR_TEST_VAULT_PASSWORD"}`
+	if _, err := task.Run(context.Background(), config); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	seen, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("read recorded vault password: %v", err)
+	}
+	if string(seen) != "s3cret" {
+		t.Fatalf("expected the vault password file to contain %q, got %q", "s3cret", seen)
+	}
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// artifactProducingTask writes a file to disk and records it as an
+// artifact of the currently running step.
+type artifactProducingTask struct {
+	dir string
+}
+
+func (t artifactProducingTask) Run(ctx context.Context, config string) (string, error) {
+	path := filepath.Join(t.dir, "report.txt")
+	if err := os.WriteFile(path, []byte("report contents"), 0o644); err != nil {
+		return "", err
+	}
+	if _, err := RecordArtifact(ctx, "report.txt", path); err != nil {
+		return "", err
+	}
+	return "ok", nil
+}
+
+func TestRunRecordsArtifactsProducedByTasks(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("produce", artifactProducingTask{dir: t.TempDir()})
+
+	store := artifact.NewLocalStore(t.TempDir())
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithArtifactStore(store))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "produce"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var artifacts []model.Artifact
+	if err := gormDB.Where("execution_id = ?", execution.ID).Find(&artifacts).Error; err != nil {
+		t.Fatalf("find artifacts: %v", err)
+	}
+	if len(artifacts) != 1 {
+		t.Fatalf("expected exactly one artifact, got %d", len(artifacts))
+	}
+	if artifacts[0].Name != "report.txt" || artifacts[0].Size != int64(len("report contents")) {
+		t.Fatalf("unexpected artifact row: %+v", artifacts[0])
+	}
+}
+
+func TestRecordArtifactWithoutStoreFails(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	dir := t.TempDir()
+	registry.Register("produce", artifactProducingTask{dir: dir})
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "produce"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected an error when no artifact store is configured")
+	}
+}
@@ -0,0 +1,279 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/notify"
+)
+
+// DefaultBatchSize is the CreateInBatches chunk size used when flushing
+// buffered writes, and the default flush interval for StatusWriter.
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = 200 * time.Millisecond
+)
+
+// StatusWriter buffers StepExecution status updates and flushes them to
+// the database in batches, either when the buffer fills or on a timer.
+// This avoids issuing one UPDATE per node transition on large DAGs.
+//
+// A flush that fails for any reason other than model.ErrConcurrentUpdate
+// is treated as transient (a DB outage or network blip) rather than
+// dropped: the batch is re-queued ahead of anything enqueued since, so
+// the next flush retries it in the same order instead of losing it.
+// model.ErrConcurrentUpdate is not retried, since it reports a real
+// conflicting writer rather than an outage, and retrying it would fail
+// identically forever. Failing reports whether the writer is currently
+// in this retrying state, for callers that want to surface a persistent
+// outage rather than wait on it silently.
+//
+// journalPath, if set, makes the in-memory buffer above durable across
+// a process restart: whenever a flush fails for a transient reason, the
+// requeued batch is also mirrored to that file, and a new StatusWriter
+// opened on the same path reloads it into pending before its first
+// flush. This only helps an outage that outlasts the process; an outage
+// that clears up before the process exits is already handled by the
+// in-memory requeue alone.
+type StatusWriter struct {
+	db            *gorm.DB
+	batchSize     int
+	flushInterval time.Duration
+	notifier      notify.Notifier
+	journalPath   string
+	// chaos, if set, is rolled at the start of every Flush attempt; a
+	// non-nil result fails that flush exactly as if the database write
+	// itself had failed, exercising the same requeue/journal retry path
+	// a real outage would. See ChaosConfig.DBWriteErrorProbability.
+	chaos *chaosRoller
+
+	mu                  sync.Mutex
+	pending             []*model.StepExecution
+	consecutiveFailures int
+	lastErr             error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewStatusWriter starts a StatusWriter that flushes in batches of
+// batchSize or every flushInterval, whichever comes first. A zero
+// batchSize or flushInterval falls back to the package defaults. notifier
+// is told about a flush entering and leaving a failing state; a nil
+// notifier falls back to notify.LogNotifier{}. journalPath, if non-empty,
+// persists buffered writes to disk while a flush is failing and reloads
+// them here if this StatusWriter is starting up with a journal already
+// on disk from a previous, interrupted run; an empty journalPath keeps
+// the buffer in-memory only, as before.
+func NewStatusWriter(db *gorm.DB, batchSize int, flushInterval time.Duration, notifier notify.Notifier, journalPath string) *StatusWriter {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	if notifier == nil {
+		notifier = notify.LogNotifier{}
+	}
+	w := &StatusWriter{
+		db:            db,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		notifier:      notifier,
+		journalPath:   journalPath,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	w.loadJournal()
+	go w.loop()
+	return w
+}
+
+func (w *StatusWriter) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Flush()
+		case <-w.stop:
+			w.Flush()
+			return
+		}
+	}
+}
+
+// Enqueue queues se to be saved on the next flush. se is saved as-is, so
+// callers should not mutate it again until it has been flushed.
+func (w *StatusWriter) Enqueue(se *model.StepExecution) {
+	w.mu.Lock()
+	w.pending = append(w.pending, se)
+	full := len(w.pending) >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		w.Flush()
+	}
+}
+
+// Flush writes any buffered StepExecutions to the database immediately,
+// as a single transaction using a prepared statement per unique SQL
+// shape so repeated UPDATEs in the batch don't each re-parse the query.
+// Each save is optimistically locked on its Version field; if one
+// affects no rows, some other writer changed that row first, so the
+// whole batch is rolled back and model.ErrConcurrentUpdate is returned.
+// Select("*") is required here: plain Save falls back to an upsert when
+// its update affects no rows, which would silently defeat the lock.
+//
+// Any other error re-queues batch ahead of whatever was enqueued while
+// it was in flight, rather than dropping it, and is reported through
+// Failing until a later Flush succeeds.
+func (w *StatusWriter) Flush() error {
+	w.mu.Lock()
+	batch := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := w.chaos.rollFlushError(); err != nil {
+		w.requeue(batch, err)
+		return err
+	}
+	err := w.db.Session(&gorm.Session{PrepareStmt: true}).Transaction(func(tx *gorm.DB) error {
+		for _, se := range batch {
+			result := tx.Select("*").Save(se)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return model.ErrConcurrentUpdate
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		w.clearFailure()
+		return nil
+	}
+	if err == model.ErrConcurrentUpdate {
+		return err
+	}
+	w.requeue(batch, err)
+	return err
+}
+
+// requeue puts a batch that failed to flush for a transient reason back
+// at the front of pending, preserving the order it was originally
+// enqueued in relative to anything enqueued since, and records the
+// failure.
+func (w *StatusWriter) requeue(batch []*model.StepExecution, err error) {
+	w.mu.Lock()
+	w.pending = append(batch, w.pending...)
+	w.consecutiveFailures++
+	first := w.consecutiveFailures == 1
+	w.lastErr = err
+	w.persistJournalLocked()
+	w.mu.Unlock()
+	if first {
+		w.notifier.Notify(context.Background(), fmt.Sprintf("status writer: step status updates are failing to persist, buffering locally and retrying: %s", err))
+	}
+}
+
+func (w *StatusWriter) clearFailure() {
+	w.mu.Lock()
+	recovered := w.consecutiveFailures > 0
+	w.consecutiveFailures = 0
+	w.lastErr = nil
+	w.removeJournalLocked()
+	w.mu.Unlock()
+	if recovered {
+		w.notifier.Notify(context.Background(), "status writer: step status updates are persisting again")
+	}
+}
+
+// loadJournal reloads a journal left behind by a previous, interrupted
+// run of this StatusWriter into pending, so buffered writes survive a
+// process restart that happens while the database is still unreachable.
+// A missing or unreadable journal is treated as "nothing buffered",
+// same as journaling being disabled, since there's nothing sensible to
+// do about a corrupt journal other than start fresh.
+func (w *StatusWriter) loadJournal() {
+	if w.journalPath == "" {
+		return
+	}
+	data, err := os.ReadFile(w.journalPath)
+	if err != nil {
+		return
+	}
+	var pending []*model.StepExecution
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return
+	}
+	w.pending = pending
+}
+
+// persistJournalLocked mirrors pending to journalPath so it survives a
+// process restart. It must be called with w.mu held. The write is
+// best-effort: journalPath exists to soften a DB outage, not to
+// guarantee durability against a simultaneously failing disk, so a
+// journal write error is left unreported rather than escalated into
+// the very failure it's meant to help with.
+func (w *StatusWriter) persistJournalLocked() {
+	if w.journalPath == "" {
+		return
+	}
+	data, err := json.Marshal(w.pending)
+	if err != nil {
+		return
+	}
+	tmp := w.journalPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, w.journalPath)
+}
+
+// removeJournalLocked deletes the journal file once pending has fully
+// flushed, so a future restart doesn't reload writes that already made
+// it to the database. It must be called with w.mu held.
+func (w *StatusWriter) removeJournalLocked() {
+	if w.journalPath == "" {
+		return
+	}
+	_ = os.Remove(w.journalPath)
+}
+
+// Failing reports whether the most recent Flush failed for a reason
+// other than a concurrent update and is being retried, along with the
+// error it's failing with, so a caller can tell a genuine outage apart
+// from writes simply not having flushed yet.
+func (w *StatusWriter) Failing() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.consecutiveFailures > 0, w.lastErr
+}
+
+// Close flushes any remaining buffered writes and stops the background
+// flush loop.
+func (w *StatusWriter) Close() error {
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+// CreateStepExecutions inserts all of se in batches of DefaultBatchSize,
+// assigning IDs to each element in place.
+func CreateStepExecutions(db *gorm.DB, se []*model.StepExecution) error {
+	if len(se) == 0 {
+		return nil
+	}
+	return db.CreateInBatches(se, DefaultBatchSize).Error
+}
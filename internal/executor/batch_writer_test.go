@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestStatusWriterFlushesOnBatchSize(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	rows := []*model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending},
+		{ExecutionID: execution.ID, StepName: "b", Status: model.StatusPending},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	w := NewStatusWriter(gormDB, 2, time.Hour, nil, "")
+	defer w.Close()
+	rows[0].Status = model.StatusSucceeded
+	rows[1].Status = model.StatusSucceeded
+	w.Enqueue(rows[0])
+	w.Enqueue(rows[1])
+
+	var got model.StepExecution
+	if err := gormDB.First(&got, rows[0].ID).Error; err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.Status != model.StatusSucceeded {
+		t.Fatalf("expected batch of size 2 to flush immediately, got status %s", got.Status)
+	}
+}
+
+func TestStatusWriterRequeuesATransientFlushFailureInsteadOfDroppingIt(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	rows := []*model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	w := NewStatusWriter(gormDB, DefaultBatchSize, time.Hour, nil, "")
+	defer w.Close()
+	rows[0].Status = model.StatusSucceeded
+	w.Enqueue(rows[0])
+
+	if failing, _ := w.Failing(); failing {
+		t.Fatalf("expected Failing to be false before any flush was attempted")
+	}
+
+	if err := gormDB.Migrator().DropTable(&model.StepExecution{}); err != nil {
+		t.Fatalf("drop step_executions: %v", err)
+	}
+
+	err := w.Flush()
+	if err == nil || errors.Is(err, model.ErrConcurrentUpdate) {
+		t.Fatalf("expected a transient (non-conflict) flush error, got %v", err)
+	}
+	if failing, ferr := w.Failing(); !failing || ferr == nil {
+		t.Fatalf("expected Failing to report the outage, got failing=%v err=%v", failing, ferr)
+	}
+
+	w.mu.Lock()
+	pending := len(w.pending)
+	w.mu.Unlock()
+	if pending != 1 {
+		t.Fatalf("expected the failed batch to be re-queued instead of dropped, got %d pending", pending)
+	}
+}
+
+func TestStatusWriterJournalsARequeuedBatchAndReloadsItOnRestart(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	rows := []*model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	journalPath := filepath.Join(t.TempDir(), "execution.json")
+	w := NewStatusWriter(gormDB, DefaultBatchSize, time.Hour, nil, journalPath)
+	rows[0].Status = model.StatusSucceeded
+	w.Enqueue(rows[0])
+
+	if err := gormDB.Migrator().DropTable(&model.StepExecution{}); err != nil {
+		t.Fatalf("drop step_executions: %v", err)
+	}
+	if err := w.Flush(); err == nil {
+		t.Fatal("expected the flush to fail once step_executions no longer exists")
+	}
+	w.Close()
+
+	if _, err := os.Stat(journalPath); err != nil {
+		t.Fatalf("expected a journal file to exist after a failed flush, got %v", err)
+	}
+
+	restarted := NewStatusWriter(gormDB, DefaultBatchSize, time.Hour, nil, journalPath)
+	defer restarted.Close()
+	restarted.mu.Lock()
+	pending := len(restarted.pending)
+	restarted.mu.Unlock()
+	if pending != 1 {
+		t.Fatalf("expected the journaled batch to reload into pending, got %d", pending)
+	}
+}
+
+func TestStatusWriterRemovesItsJournalOnceItRecovers(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	rows := []*model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	journalPath := filepath.Join(t.TempDir(), "execution.json")
+	w := NewStatusWriter(gormDB, DefaultBatchSize, time.Hour, nil, journalPath)
+	defer w.Close()
+	rows[0].Status = model.StatusSucceeded
+	w.Enqueue(rows[0])
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if _, err := os.Stat(journalPath); !os.IsNotExist(err) {
+		t.Fatalf("expected no journal file once the buffer fully flushed, got %v", err)
+	}
+}
+
+func TestCreateStepExecutionsAssignsIDs(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	rows := []*model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending},
+		{ExecutionID: execution.ID, StepName: "b", Status: model.StatusPending},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+	for _, r := range rows {
+		if r.ID == 0 {
+			t.Fatalf("expected ID to be assigned for step %q", r.StepName)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/fengmingli/orchestrator/internal/queue"
+)
+
+// Bulkhead bounds how many steps run at once across every execution
+// sharing one TaskOrchestrator, so a single execution with a wide DAG
+// layer can't claim the whole pool and starve steps belonging to
+// other, concurrently-running executions. Waiting steps are served
+// round-robin across executions, reusing queue.Queue's fair-share
+// dequeuing (the same mechanism WorkerPool uses to fair-share whole
+// executions across projects) keyed by execution ID instead.
+type Bulkhead struct {
+	sem   chan struct{}
+	queue *queue.Queue
+
+	mu      sync.Mutex
+	waiting map[*queue.Item]chan struct{}
+}
+
+// NewBulkhead returns a Bulkhead allowing at most capacity steps to run
+// at once. A capacity of zero or less means unbounded: Acquire always
+// succeeds immediately and its release func is a no-op.
+func NewBulkhead(capacity int) *Bulkhead {
+	if capacity <= 0 {
+		return nil
+	}
+	return &Bulkhead{
+		sem:     make(chan struct{}, capacity),
+		queue:   queue.New(),
+		waiting: make(map[*queue.Item]chan struct{}),
+	}
+}
+
+// Acquire blocks until a slot is free for executionID's step or ctx is
+// cancelled. A nil Bulkhead (as returned by NewBulkhead(0) or a negative
+// capacity) is unbounded: Acquire returns immediately. The returned
+// release func must be called exactly once to free the slot if err is
+// nil; it's nil otherwise.
+func (b *Bulkhead) Acquire(ctx context.Context, executionID uint) (release func(), err error) {
+	if b == nil {
+		return func() {}, nil
+	}
+
+	item := &queue.Item{ExecutionID: executionID, Project: strconv.FormatUint(uint64(executionID), 10)}
+	ready := make(chan struct{})
+
+	b.mu.Lock()
+	b.waiting[item] = ready
+	b.mu.Unlock()
+	b.queue.Push(item)
+	go b.dispatch()
+
+	select {
+	case <-ready:
+		return func() { <-b.sem }, nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		_, stillWaiting := b.waiting[item]
+		delete(b.waiting, item)
+		b.mu.Unlock()
+		if !stillWaiting {
+			// dispatch already granted this item's slot concurrently
+			// with the cancellation; release it ourselves since
+			// nobody is left to run the step.
+			<-b.sem
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch waits for a free global slot, then hands it to whichever
+// queued step is fairest to run next. Like WorkerPool.dispatchOne, the
+// goroutine that wins the semaphore send isn't necessarily the one
+// that queued the item it ends up granting: any dispatch goroutine may
+// pick up any queued item, which is what makes the sharing fair
+// instead of first-come-first-served.
+func (b *Bulkhead) dispatch() {
+	b.sem <- struct{}{}
+
+	item := b.queue.Pop()
+	if item == nil {
+		<-b.sem
+		return
+	}
+
+	b.mu.Lock()
+	ready, ok := b.waiting[item]
+	delete(b.waiting, item)
+	b.mu.Unlock()
+	if !ok {
+		// The waiter already gave up; free the slot instead of
+		// leaking it.
+		<-b.sem
+		return
+	}
+	close(ready)
+}
@@ -0,0 +1,110 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewBulkheadWithNonPositiveCapacityIsUnbounded(t *testing.T) {
+	b := NewBulkhead(0)
+	release, err := b.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	release()
+}
+
+func TestBulkheadBoundsTotalConcurrentHolders(t *testing.T) {
+	b := NewBulkhead(1)
+
+	release1, err := b.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		release2, err := b.Acquire(context.Background(), 2)
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+			return
+		}
+		close(acquired)
+		release2()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second Acquire should not succeed while the only slot is held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release1()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the second Acquire to succeed after release")
+	}
+}
+
+func TestBulkheadSharesSlotsFairlyAcrossExecutions(t *testing.T) {
+	b := NewBulkhead(1)
+
+	// Give execution 1 a head start: one full acquire/release cycle,
+	// uncontended, so its fair-share counter is already ahead of
+	// execution 2's by the time they both queue up below.
+	release, err := b.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	release()
+
+	// Hold the only slot so both executions below have to queue.
+	holder, err := b.Acquire(context.Background(), 99)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	var order []uint
+	done := make(chan struct{}, 2)
+	queueOne := func(executionID uint) {
+		release, err := b.Acquire(context.Background(), executionID)
+		if err != nil {
+			t.Errorf("Acquire: %v", err)
+			return
+		}
+		order = append(order, executionID)
+		release()
+		done <- struct{}{}
+	}
+
+	go queueOne(1)
+	go queueOne(2)
+	time.Sleep(20 * time.Millisecond)
+
+	holder()
+	<-done
+	<-done
+
+	if len(order) != 2 || order[0] != 2 {
+		t.Fatalf("expected execution 2 (with no prior turns) to be served before execution 1, got %v", order)
+	}
+}
+
+func TestBulkheadAcquireStopsOnContextCancellation(t *testing.T) {
+	b := NewBulkhead(1)
+	holder, err := b.Acquire(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer holder()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := b.Acquire(ctx, 2); err == nil {
+		t.Fatal("expected Acquire to fail once its context is cancelled")
+	}
+}
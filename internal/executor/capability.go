@@ -0,0 +1,60 @@
+package executor
+
+import "strings"
+
+// CapabilityProvider is implemented by a Task that only supports running
+// under certain conditions (e.g. a host label, an available tool), so the
+// orchestrator can refuse to dispatch a step it can't actually satisfy
+// instead of discovering that partway through Run.
+//
+// This orchestrator runs as a single process sharing one task Registry,
+// not a pool of distributed workers each advertising their own
+// capabilities. A Task's Capabilities() describes what is true of this
+// process, so matching a step's TemplateStep.RequiredCapabilities against
+// it means "can this process run this step at all", not "which of
+// several agents should get it".
+type CapabilityProvider interface {
+	// Capabilities lists the labels this Task satisfies, e.g.
+	// []string{"network-zone=prod", "has-docker"}.
+	Capabilities() []string
+}
+
+// missingCapabilities returns which of required (a comma-separated list,
+// in TemplateStep.RequiredCapabilities' format) task does not advertise.
+// A task that doesn't implement CapabilityProvider advertises none, so
+// any non-empty required list is entirely missing.
+func missingCapabilities(task Task, required string) []string {
+	want := splitCapabilities(required)
+	if len(want) == 0 {
+		return nil
+	}
+	have := map[string]bool{}
+	if provider, ok := task.(CapabilityProvider); ok {
+		for _, c := range provider.Capabilities() {
+			have[c] = true
+		}
+	}
+	var missing []string
+	for _, c := range want {
+		if !have[c] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+func splitCapabilities(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	caps := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			caps = append(caps, p)
+		}
+	}
+	return caps
+}
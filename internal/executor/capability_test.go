@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// labeledTask advertises a fixed set of capabilities via CapabilityProvider.
+type labeledTask struct {
+	caps []string
+}
+
+func (t labeledTask) Run(ctx context.Context, config string) (string, error) {
+	return "ok", nil
+}
+
+func (t labeledTask) Capabilities() []string {
+	return t.caps
+}
+
+func TestRunSucceedsWhenTaskSatisfiesRequiredCapabilities(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("prod-shell", labeledTask{caps: []string{"network-zone=prod", "has-docker"}})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "deploy", Type: "prod-shell", RequiredCapabilities: "network-zone=prod"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestRunFailsFastWhenNoTaskSatisfiesRequiredCapabilities(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("prod-shell", labeledTask{caps: []string{"network-zone=staging"}})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "deploy", Type: "prod-shell", RequiredCapabilities: "network-zone=prod"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	err := orchestrator.Run(context.Background(), execution, steps)
+	var preflightErr *PreflightError
+	if !errors.As(err, &preflightErr) {
+		t.Fatalf("expected a *PreflightError when no task satisfies a required capability, got %v", err)
+	}
+	if len(preflightErr.Errors) != 1 || preflightErr.Errors[0].StepName != "deploy" {
+		t.Fatalf("expected step deploy to fail preflight, got %v", preflightErr.Errors)
+	}
+}
+
+func TestRunFailsFastWhenTaskDoesNotImplementCapabilityProvider(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "deploy", Type: "shell", Config: "true", RequiredCapabilities: "has-docker"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to report failure when the registered task advertises no capabilities")
+	}
+}
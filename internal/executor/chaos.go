@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// errChaosDBWrite is returned by StatusWriter's flush in place of a real
+// database error when ChaosConfig.DBWriteErrorProbability fires.
+var errChaosDBWrite = errors.New("chaos: injected database write error")
+
+// ChaosConfig opts an execution into randomized fault injection, so
+// operators can exercise failure policies, retries and recovery against
+// unpredictable failures, not just the fixed scenarios a RunSimulated
+// faults map describes. Every probability below is independent and
+// rolled once per step (or, for DBWriteErrorProbability, once per
+// StatusWriter flush); a step named in RunWithChaos's own faults map, if
+// any, is left to that Fault rather than being rolled for chaos.
+type ChaosConfig struct {
+	// StepFailureProbability is the chance (0-1) any unfaulted step is
+	// failed outright instead of running its real Task.
+	StepFailureProbability float64 `json:"step_failure_probability,omitempty"`
+	// LockLossProbability is the chance (0-1) any unfaulted step is
+	// failed with a simulated lock-loss error instead of running its
+	// real Task, so a template's retry/compensation policy for losing a
+	// held lock mid-step can be exercised without a real lock provider
+	// outage. Checked before StepFailureProbability.
+	LockLossProbability float64 `json:"lock_loss_probability,omitempty"`
+	// StepDelayProbability is the chance (0-1) any step (including one
+	// chosen for StepFailureProbability or LockLossProbability) is
+	// delayed by a random duration up to MaxStepDelay before it runs.
+	StepDelayProbability float64       `json:"step_delay_probability,omitempty"`
+	MaxStepDelay         time.Duration `json:"max_step_delay,omitempty"`
+	// DBWriteErrorProbability is the chance (0-1) a given StatusWriter
+	// flush attempt is failed with a simulated database error instead of
+	// actually writing, exercising StatusWriter's existing requeue and
+	// journal retry path instead of its happy path.
+	DBWriteErrorProbability float64 `json:"db_write_error_probability,omitempty"`
+	// Seed seeds the chaos RNG, so a run can be made reproducible for a
+	// bug report or a CI assertion. Zero picks a random seed.
+	Seed int64 `json:"seed,omitempty"`
+}
+
+// empty reports whether cfg has every probability at zero, i.e. chaos
+// would never actually intervene.
+func (cfg ChaosConfig) empty() bool {
+	return cfg.StepFailureProbability <= 0 && cfg.LockLossProbability <= 0 && cfg.StepDelayProbability <= 0 && cfg.DBWriteErrorProbability <= 0
+}
+
+// chaosRoller rolls a ChaosConfig's probabilities against its own RNG, so
+// chaos decisions don't perturb any other random source and are
+// reproducible given the same Seed. A nil *chaosRoller never intervenes,
+// so call sites don't need a separate "chaos disabled" branch.
+type chaosRoller struct {
+	cfg ChaosConfig
+	rnd *rand.Rand
+}
+
+// newChaosRoller builds a chaosRoller for cfg, or returns nil if cfg
+// would never intervene.
+func newChaosRoller(cfg ChaosConfig) *chaosRoller {
+	if cfg.empty() {
+		return nil
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &chaosRoller{cfg: cfg, rnd: rand.New(rand.NewSource(seed))}
+}
+
+// rollStep rolls chaos for one step, returning the delay it should run
+// after (zero for none) and the Fault it should run under instead of its
+// real Task (its zero value, Mode=="", if chaos didn't force an outcome).
+func (c *chaosRoller) rollStep() (delay time.Duration, fault Fault) {
+	if c == nil {
+		return 0, Fault{}
+	}
+	switch {
+	case c.cfg.LockLossProbability > 0 && c.rnd.Float64() < c.cfg.LockLossProbability:
+		fault = Fault{Mode: FaultLockLoss}
+	case c.cfg.StepFailureProbability > 0 && c.rnd.Float64() < c.cfg.StepFailureProbability:
+		fault = Fault{Mode: FaultForceFail, Message: "chaos: injected step failure"}
+	}
+	if c.cfg.StepDelayProbability > 0 && c.cfg.MaxStepDelay > 0 && c.rnd.Float64() < c.cfg.StepDelayProbability {
+		delay = time.Duration(c.rnd.Int63n(int64(c.cfg.MaxStepDelay) + 1))
+	}
+	return delay, fault
+}
+
+// rollFlushError rolls DBWriteErrorProbability once, for one StatusWriter
+// flush attempt.
+func (c *chaosRoller) rollFlushError() error {
+	if c == nil || c.cfg.DBWriteErrorProbability <= 0 || c.rnd.Float64() >= c.cfg.DBWriteErrorProbability {
+		return nil
+	}
+	return errChaosDBWrite
+}
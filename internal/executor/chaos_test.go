@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestChaosRollerNilIsNoop(t *testing.T) {
+	var c *chaosRoller
+	delay, fault := c.rollStep()
+	if delay != 0 || fault.Mode != "" {
+		t.Fatalf("expected nil roller to be a no-op, got delay=%v fault=%+v", delay, fault)
+	}
+	if err := c.rollFlushError(); err != nil {
+		t.Fatalf("expected nil roller to report no flush error, got %v", err)
+	}
+}
+
+func TestChaosConfigEmptyDisablesRoller(t *testing.T) {
+	if newChaosRoller(ChaosConfig{}) != nil {
+		t.Fatal("expected an empty ChaosConfig to produce a nil roller")
+	}
+}
+
+func TestChaosRollerForcesStepFailure(t *testing.T) {
+	roller := newChaosRoller(ChaosConfig{StepFailureProbability: 1, Seed: 1})
+	_, fault := roller.rollStep()
+	if fault.Mode != FaultForceFail {
+		t.Fatalf("expected a forced failure, got mode=%q", fault.Mode)
+	}
+}
+
+func TestChaosRollerForcesLockLoss(t *testing.T) {
+	roller := newChaosRoller(ChaosConfig{LockLossProbability: 1, Seed: 1})
+	_, fault := roller.rollStep()
+	if fault.Mode != FaultLockLoss {
+		t.Fatalf("expected a forced lock loss, got mode=%q", fault.Mode)
+	}
+}
+
+func TestChaosRollerForcesFlushError(t *testing.T) {
+	roller := newChaosRoller(ChaosConfig{DBWriteErrorProbability: 1, Seed: 1})
+	if err := roller.rollFlushError(); err != errChaosDBWrite {
+		t.Fatalf("expected errChaosDBWrite, got %v", err)
+	}
+}
+
+func TestRunWithChaosForcesEveryStepToFail(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"}, // would really succeed
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	chaos := ChaosConfig{StepFailureProbability: 1, Seed: 1}
+	if err := orchestrator.RunWithChaos(context.Background(), execution, steps, 0, chaos); err == nil {
+		t.Fatal("expected RunWithChaos to report failure")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step: %v", err)
+	}
+	if se.Status != model.StatusFailed {
+		t.Fatalf("expected chaos-forced failure, got status=%s", se.Status)
+	}
+}
+
+func TestRunWithChaosZeroConfigBehavesLikeRunWithSLA(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.RunWithChaos(context.Background(), execution, steps, 0, ChaosConfig{}); err != nil {
+		t.Fatalf("RunWithChaos with zero config: %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// AWSActionTaskConfig is the JSON shape expected in a step's Config for
+// AWSActionTask.
+type AWSActionTaskConfig struct {
+	// Action selects the remediation to perform: "restart_instance",
+	// "invoke_lambda", or "scale_asg".
+	Action string `json:"action"`
+	Region string `json:"region,omitempty"`
+	// Profile names an AWS CLI profile (~/.aws/config) carrying the
+	// credentials or role to assume. AWSActionTask never stores
+	// credentials itself; an empty Profile falls back to whatever the
+	// environment or instance role already resolves to, the same trust
+	// boundary a hand-written ShellTask script calling aws would have.
+	Profile string `json:"profile,omitempty"`
+
+	// InstanceID is required for restart_instance.
+	InstanceID string `json:"instance_id,omitempty"`
+	// FunctionName is required for invoke_lambda. Payload is its
+	// optional JSON-encoded input.
+	FunctionName string `json:"function_name,omitempty"`
+	Payload      string `json:"payload,omitempty"`
+	// AutoScalingGroupName and DesiredCapacity are required for scale_asg.
+	AutoScalingGroupName string `json:"auto_scaling_group_name,omitempty"`
+	DesiredCapacity      *int   `json:"desired_capacity,omitempty"`
+
+	// CostCents, if set, is recorded via RecordCost as this step's cost
+	// in US cents once the action succeeds, e.g. a template author's own
+	// estimate of what this action costs against their AWS bill. Unset
+	// means no cost is recorded.
+	CostCents *int64 `json:"cost_cents,omitempty"`
+}
+
+// AWSActionTask performs a common infrastructure remediation against AWS
+// by shelling out to the aws CLI, so a template doesn't need a
+// hand-written shell script for routine actions like restarting an
+// instance.
+type AWSActionTask struct {
+	// CLI is the executable to invoke, defaulting to "aws". Tests
+	// override it with a stub binary.
+	CLI string
+}
+
+// Run performs the action described by config (a JSON-encoded
+// AWSActionTaskConfig) and returns the CLI's combined output.
+func (t AWSActionTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg AWSActionTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("aws action task: invalid config: %w", err)
+	}
+
+	args, err := awsActionArgs(cfg)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Profile != "" {
+		args = append(args, "--profile", cfg.Profile)
+	}
+	if cfg.Region != "" {
+		args = append(args, "--region", cfg.Region)
+	}
+
+	cli := t.CLI
+	if cli == "" {
+		cli = "aws"
+	}
+	output, err := runCLI(ctx, cli, args)
+	if err == nil && cfg.CostCents != nil {
+		RecordCost(ctx, *cfg.CostCents)
+	}
+	return output, err
+}
+
+func awsActionArgs(cfg AWSActionTaskConfig) ([]string, error) {
+	switch cfg.Action {
+	case "restart_instance":
+		if cfg.InstanceID == "" {
+			return nil, fmt.Errorf("aws action task: instance_id is required for restart_instance")
+		}
+		return []string{"ec2", "reboot-instances", "--instance-ids", cfg.InstanceID}, nil
+	case "invoke_lambda":
+		if cfg.FunctionName == "" {
+			return nil, fmt.Errorf("aws action task: function_name is required for invoke_lambda")
+		}
+		args := []string{"lambda", "invoke", "--function-name", cfg.FunctionName}
+		if cfg.Payload != "" {
+			args = append(args, "--payload", cfg.Payload)
+		}
+		return append(args, "/dev/stdout"), nil
+	case "scale_asg":
+		if cfg.AutoScalingGroupName == "" {
+			return nil, fmt.Errorf("aws action task: auto_scaling_group_name is required for scale_asg")
+		}
+		if cfg.DesiredCapacity == nil {
+			return nil, fmt.Errorf("aws action task: desired_capacity is required for scale_asg")
+		}
+		return []string{
+			"autoscaling", "set-desired-capacity",
+			"--auto-scaling-group-name", cfg.AutoScalingGroupName,
+			"--desired-capacity", strconv.Itoa(*cfg.DesiredCapacity),
+		}, nil
+	case "":
+		return nil, fmt.Errorf("aws action task: action is required")
+	default:
+		return nil, fmt.Errorf("aws action task: unknown action %q", cfg.Action)
+	}
+}
+
+// GCPActionTaskConfig is the JSON shape expected in a step's Config for
+// GCPActionTask.
+type GCPActionTaskConfig struct {
+	// Action selects the remediation to perform: "restart_instance",
+	// "invoke_function", or "resize_instance_group".
+	Action  string `json:"action"`
+	Project string `json:"project,omitempty"`
+	Zone    string `json:"zone,omitempty"`
+	Region  string `json:"region,omitempty"`
+	// ServiceAccount, if set, is impersonated for the call (GCP's
+	// equivalent of AWS's assumed role), via gcloud's
+	// --impersonate-service-account flag. GCPActionTask never stores
+	// credentials itself.
+	ServiceAccount string `json:"service_account,omitempty"`
+
+	// InstanceName is required for restart_instance.
+	InstanceName string `json:"instance_name,omitempty"`
+	// FunctionName is required for invoke_function. Data is its
+	// optional JSON-encoded input.
+	FunctionName string `json:"function_name,omitempty"`
+	Data         string `json:"data,omitempty"`
+	// InstanceGroup and Size are required for resize_instance_group.
+	InstanceGroup string `json:"instance_group,omitempty"`
+	Size          *int   `json:"size,omitempty"`
+
+	// CostCents, if set, is recorded via RecordCost as this step's cost
+	// in US cents once the action succeeds, e.g. a template author's own
+	// estimate of what this action costs against their GCP bill. Unset
+	// means no cost is recorded.
+	CostCents *int64 `json:"cost_cents,omitempty"`
+}
+
+// GCPActionTask performs a common infrastructure remediation against GCP
+// by shelling out to the gcloud CLI, so a template doesn't need a
+// hand-written shell script for routine actions like restarting an
+// instance.
+type GCPActionTask struct {
+	// CLI is the executable to invoke, defaulting to "gcloud". Tests
+	// override it with a stub binary.
+	CLI string
+}
+
+// Run performs the action described by config (a JSON-encoded
+// GCPActionTaskConfig) and returns the CLI's combined output.
+func (t GCPActionTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg GCPActionTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("gcp action task: invalid config: %w", err)
+	}
+
+	args, err := gcpActionArgs(cfg)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Project != "" {
+		args = append(args, "--project", cfg.Project)
+	}
+	if cfg.ServiceAccount != "" {
+		args = append(args, "--impersonate-service-account", cfg.ServiceAccount)
+	}
+
+	cli := t.CLI
+	if cli == "" {
+		cli = "gcloud"
+	}
+	output, err := runCLI(ctx, cli, args)
+	if err == nil && cfg.CostCents != nil {
+		RecordCost(ctx, *cfg.CostCents)
+	}
+	return output, err
+}
+
+func gcpActionArgs(cfg GCPActionTaskConfig) ([]string, error) {
+	switch cfg.Action {
+	case "restart_instance":
+		if cfg.InstanceName == "" {
+			return nil, fmt.Errorf("gcp action task: instance_name is required for restart_instance")
+		}
+		if cfg.Zone == "" {
+			return nil, fmt.Errorf("gcp action task: zone is required for restart_instance")
+		}
+		return []string{"compute", "instances", "reset", cfg.InstanceName, "--zone", cfg.Zone}, nil
+	case "invoke_function":
+		if cfg.FunctionName == "" {
+			return nil, fmt.Errorf("gcp action task: function_name is required for invoke_function")
+		}
+		args := []string{"functions", "call", cfg.FunctionName}
+		if cfg.Region != "" {
+			args = append(args, "--region", cfg.Region)
+		}
+		if cfg.Data != "" {
+			args = append(args, "--data", cfg.Data)
+		}
+		return args, nil
+	case "resize_instance_group":
+		if cfg.InstanceGroup == "" {
+			return nil, fmt.Errorf("gcp action task: instance_group is required for resize_instance_group")
+		}
+		if cfg.Zone == "" {
+			return nil, fmt.Errorf("gcp action task: zone is required for resize_instance_group")
+		}
+		if cfg.Size == nil {
+			return nil, fmt.Errorf("gcp action task: size is required for resize_instance_group")
+		}
+		return []string{
+			"compute", "instance-groups", "managed", "resize", cfg.InstanceGroup,
+			"--zone", cfg.Zone,
+			"--size", strconv.Itoa(*cfg.Size),
+		}, nil
+	case "":
+		return nil, fmt.Errorf("gcp action task: action is required")
+	default:
+		return nil, fmt.Errorf("gcp action task: unknown action %q", cfg.Action)
+	}
+}
+
+// runCLI executes name with args, returning its combined stdout and
+// stderr, the same output contract ShellTask uses.
+func runCLI(ctx context.Context, name string, args []string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
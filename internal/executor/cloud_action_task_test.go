@@ -0,0 +1,127 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCLI writes an executable shell script to dir that echoes its
+// arguments, standing in for the real aws/gcloud binary so tests don't
+// need either installed.
+func fakeCLI(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatalf("write fake CLI: %v", err)
+	}
+	return path
+}
+
+func TestAWSActionTaskRestartsAnInstance(t *testing.T) {
+	cli := fakeCLI(t, t.TempDir(), "fake-aws")
+	task := AWSActionTask{CLI: cli}
+
+	out, err := task.Run(context.Background(), `{"action":"restart_instance","instance_id":"i-0123456789","region":"us-east-1"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(out, "ec2 reboot-instances --instance-ids i-0123456789") {
+		t.Fatalf("unexpected invocation: %q", out)
+	}
+	if !strings.Contains(out, "--region us-east-1") {
+		t.Fatalf("expected --region to be passed, got %q", out)
+	}
+}
+
+func TestAWSActionTaskRejectsScaleAsgWithoutDesiredCapacity(t *testing.T) {
+	task := AWSActionTask{CLI: fakeCLI(t, t.TempDir(), "fake-aws")}
+
+	_, err := task.Run(context.Background(), `{"action":"scale_asg","auto_scaling_group_name":"web"}`)
+	if err == nil {
+		t.Fatal("expected Run to reject scale_asg without desired_capacity")
+	}
+}
+
+func TestAWSActionTaskRejectsAnUnknownAction(t *testing.T) {
+	task := AWSActionTask{CLI: fakeCLI(t, t.TempDir(), "fake-aws")}
+
+	_, err := task.Run(context.Background(), `{"action":"delete_everything"}`)
+	if err == nil {
+		t.Fatal("expected Run to reject an unknown action")
+	}
+}
+
+func TestAWSActionTaskInvokesALambdaWithAPayload(t *testing.T) {
+	cli := fakeCLI(t, t.TempDir(), "fake-aws")
+	task := AWSActionTask{CLI: cli}
+
+	out, err := task.Run(context.Background(), `{"action":"invoke_lambda","function_name":"my-fn","payload":"{\"x\":1}","profile":"ops"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(out, "lambda invoke --function-name my-fn --payload") {
+		t.Fatalf("unexpected invocation: %q", out)
+	}
+	if !strings.Contains(out, "--profile ops") {
+		t.Fatalf("expected --profile to be passed, got %q", out)
+	}
+}
+
+func TestGCPActionTaskResizesAnInstanceGroup(t *testing.T) {
+	cli := fakeCLI(t, t.TempDir(), "fake-gcloud")
+	task := GCPActionTask{CLI: cli}
+
+	out, err := task.Run(context.Background(), `{"action":"resize_instance_group","instance_group":"web-mig","zone":"us-central1-a","size":5,"project":"prod"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(out, "compute instance-groups managed resize web-mig --zone us-central1-a --size 5") {
+		t.Fatalf("unexpected invocation: %q", out)
+	}
+	if !strings.Contains(out, "--project prod") {
+		t.Fatalf("expected --project to be passed, got %q", out)
+	}
+}
+
+func TestGCPActionTaskRejectsRestartInstanceWithoutAZone(t *testing.T) {
+	task := GCPActionTask{CLI: fakeCLI(t, t.TempDir(), "fake-gcloud")}
+
+	_, err := task.Run(context.Background(), `{"action":"restart_instance","instance_name":"web-1"}`)
+	if err == nil {
+		t.Fatal("expected Run to reject restart_instance without a zone")
+	}
+}
+
+func TestAWSActionTaskRecordsCostWhenConfigured(t *testing.T) {
+	cli := fakeCLI(t, t.TempDir(), "fake-aws")
+	task := AWSActionTask{CLI: cli}
+
+	var recorded int64
+	ctx := withCostRecorder(context.Background(), func(cents int64) { recorded += cents })
+	_, err := task.Run(ctx, `{"action":"restart_instance","instance_id":"i-0123456789","cost_cents":42}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if recorded != 42 {
+		t.Fatalf("expected the configured cost_cents to be recorded, got %d", recorded)
+	}
+}
+
+func TestGCPActionTaskInvokesAFunctionImpersonatingAServiceAccount(t *testing.T) {
+	cli := fakeCLI(t, t.TempDir(), "fake-gcloud")
+	task := GCPActionTask{CLI: cli}
+
+	out, err := task.Run(context.Background(), `{"action":"invoke_function","function_name":"purge-cache","region":"us-east1","service_account":"remediator@prod.iam.gserviceaccount.com"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !strings.Contains(out, "functions call purge-cache --region us-east1") {
+		t.Fatalf("unexpected invocation: %q", out)
+	}
+	if !strings.Contains(out, "--impersonate-service-account remediator@prod.iam.gserviceaccount.com") {
+		t.Fatalf("expected service account impersonation flag, got %q", out)
+	}
+}
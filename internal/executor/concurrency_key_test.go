@@ -0,0 +1,136 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunSerializesStepsSharingAConcurrencyKeyAcrossExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	var current, peak int32
+	registry.Register("track", concurrencyTrackingTask{current: &current, peak: &peak})
+	locks := lock.NewMemoryLockProvider()
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithLockProvider(locks))
+
+	run := func(name string) error {
+		execution := &model.WorkflowExecution{Status: model.StatusPending}
+		if err := gormDB.Create(execution).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+		steps := []model.TemplateStep{{Name: name, Type: "track", ConcurrencyKey: "shared"}}
+		return orchestrator.Run(context.Background(), execution, steps)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, name := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			errs[i] = run(name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&peak); got > 1 {
+		t.Fatalf("expected the two steps sharing a ConcurrencyKey to never run at once, observed peak %d", got)
+	}
+}
+
+func TestRunFailsAStepThatCannotAcquireItsConcurrencyKeyInTime(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	locks := lock.NewMemoryLockProvider()
+	if ok, err := locks.TryLock(context.Background(), "step-concurrency:db-migration", "someone-else", time.Minute); err != nil || !ok {
+		t.Fatalf("pre-hold lock: ok=%v err=%v", ok, err)
+	}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithLockProvider(locks))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{
+		Name:                      "a",
+		Type:                      "shell",
+		Config:                    "true",
+		ConcurrencyKey:            "db-migration",
+		ConcurrencyTimeoutSeconds: 1,
+	}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected the run to fail once its ConcurrencyTimeoutSeconds elapsed")
+	}
+
+	var step model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&step).Error; err != nil {
+		t.Fatalf("reload step: %v", err)
+	}
+	if step.Status != model.StatusFailed {
+		t.Fatalf("expected the step to fail once its ConcurrencyTimeoutSeconds elapsed, got %s", step.Status)
+	}
+}
+
+func TestAcquireConcurrencyKeyRenewsItsLockPastTheLockTTL(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	locks := lock.NewMemoryLockProvider()
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithLockProvider(locks))
+
+	// A one-second hard timeout gives acquireConcurrencyKey a one-second
+	// lock TTL (heartbeat interval ~333ms) without this test having to
+	// wait out DefaultConcurrencyLockTTL, since it calls
+	// acquireConcurrencyKey directly rather than going through Run.
+	execution := &model.WorkflowExecution{HardTimeoutSeconds: 1}
+	node := &dag.Node{Name: "a", Step: model.TemplateStep{ConcurrencyKey: "db-migration"}}
+
+	release, err := orchestrator.acquireConcurrencyKey(context.Background(), node, execution, 1)
+	if err != nil {
+		t.Fatalf("acquireConcurrencyKey: %v", err)
+	}
+
+	time.Sleep(2500 * time.Millisecond)
+
+	if ok, err := locks.TryLock(context.Background(), "step-concurrency:db-migration", "someone-else", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	} else if ok {
+		t.Fatal("expected the lock to still be held after outliving its TTL unrenewed")
+	}
+
+	release()
+
+	if ok, err := locks.TryLock(context.Background(), "step-concurrency:db-migration", "someone-else", time.Minute); err != nil || !ok {
+		t.Fatalf("expected the lock to be free once released, ok=%v err=%v", ok, err)
+	}
+}
+
+func TestRunSkipsConcurrencyLockingWhenNoLockProviderIsConfigured(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true", ConcurrencyKey: "db-migration"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("expected ConcurrencyKey to be ignored without a lock.Provider configured, got %v", err)
+	}
+}
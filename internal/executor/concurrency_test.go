@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestActiveStepsReportsRunningStepsAcrossExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+
+	release := make(chan struct{})
+	a := blockingTask{started: make(chan struct{}), release: release}
+	b := blockingTask{started: make(chan struct{}), release: release}
+	registry.Register("a", a)
+	registry.Register("b", b)
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "a"}, {Name: "b", Type: "b"}}
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	select {
+	case <-a.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for step a to start")
+	}
+	select {
+	case <-b.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for step b to start")
+	}
+
+	active := orchestrator.ActiveSteps()
+	if len(active) != 2 {
+		t.Fatalf("expected 2 active steps, got %d: %+v", len(active), active)
+	}
+	for _, step := range active {
+		if step.ExecutionID != execution.ID {
+			t.Fatalf("unexpected execution ID in active step: %+v", step)
+		}
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if active := orchestrator.ActiveSteps(); len(active) != 0 {
+		t.Fatalf("expected no active steps once the run finished, got %+v", active)
+	}
+}
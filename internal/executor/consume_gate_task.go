@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/fengmingli/orchestrator/internal/broker"
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// DefaultConsumeGateTimeout bounds how long ConsumeGateTask waits for a
+// matching message when a step's own Timeout is unset.
+const DefaultConsumeGateTimeout = 15 * time.Minute
+
+// ConsumeGateTaskConfig is the JSON shape expected in a step's Config
+// for ConsumeGateTask.
+type ConsumeGateTaskConfig struct {
+	// Provider names the Consumer the step waits on, looked up in
+	// ConsumeGateTask.Consumers.
+	Provider string `json:"provider"`
+	Topic    string `json:"topic"`
+	// FilterExpression is evaluated with github.com/expr-lang/expr
+	// against every message received on Topic, with an environment of
+	// key, value, headers (the message's own fields) and vars, and must
+	// produce a bool; the first message it's true for ends the wait. An
+	// empty FilterExpression matches every message on Topic.
+	FilterExpression string            `json:"filter_expression,omitempty"`
+	Vars             map[string]string `json:"vars,omitempty"`
+	// Timeout defaults to DefaultConsumeGateTimeout.
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+// ConsumeGateResult is the JSON-encoded output ConsumeGateTask leaves
+// in a step's Output once a matching message arrives.
+type ConsumeGateResult struct {
+	Key     string            `json:"key"`
+	Value   string            `json:"value"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ConsumeGateTask blocks a step until a message matching
+// FilterExpression arrives on Topic, or Timeout elapses, enabling
+// workflows that wait on an asynchronous confirmation event from
+// another system (e.g. a Kafka topic or an AMQP queue) before
+// proceeding.
+type ConsumeGateTask struct {
+	Consumers map[string]broker.Consumer
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+// Run implements Task.
+func (t ConsumeGateTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg ConsumeGateTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("consume gate task: invalid config: %w", err)
+	}
+	if cfg.Provider == "" {
+		return "", fmt.Errorf("consume gate task: provider is required")
+	}
+	if cfg.Topic == "" {
+		return "", fmt.Errorf("consume gate task: topic is required")
+	}
+	consumer, ok := t.Consumers[cfg.Provider]
+	if !ok {
+		return "", fmt.Errorf("consume gate task: unregistered provider %q", cfg.Provider)
+	}
+
+	var program *vm.Program
+	if cfg.FilterExpression != "" {
+		compiled, err := expr.Compile(cfg.FilterExpression, expr.Env(consumeGateFilterEnv(broker.Message{}, cfg.Vars)))
+		if err != nil {
+			return "", fmt.Errorf("consume gate task: compile filter_expression: %w", err)
+		}
+		program = compiled
+	}
+
+	messages, cancel, err := consumer.Subscribe(ctx, cfg.Topic)
+	if err != nil {
+		return "", fmt.Errorf("consume gate task: subscribe to %q: %w", cfg.Topic, err)
+	}
+	defer cancel()
+
+	c := t.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultConsumeGateTimeout
+	}
+	deadline := c.After(timeout)
+
+	for {
+		select {
+		case msg := <-messages:
+			matched, err := matchesConsumeGateFilter(program, msg, cfg.Vars)
+			if err != nil {
+				return "", fmt.Errorf("consume gate task: evaluate filter_expression: %w", err)
+			}
+			if !matched {
+				continue
+			}
+			encoded, err := json.Marshal(ConsumeGateResult{Key: msg.Key, Value: msg.Value, Headers: msg.Headers})
+			if err != nil {
+				return "", err
+			}
+			return string(encoded), nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-deadline:
+			return "", fmt.Errorf("consume gate task: no matching message arrived on %q within %s", cfg.Topic, timeout)
+		}
+	}
+}
+
+func matchesConsumeGateFilter(program *vm.Program, msg broker.Message, vars map[string]string) (bool, error) {
+	if program == nil {
+		return true, nil
+	}
+	result, err := expr.Run(program, consumeGateFilterEnv(msg, vars))
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("filter_expression must produce a bool, got %T", result)
+	}
+	return matched, nil
+}
+
+func consumeGateFilterEnv(msg broker.Message, vars map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"key":     msg.Key,
+		"value":   msg.Value,
+		"headers": msg.Headers,
+		"vars":    vars,
+	}
+}
@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/broker"
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+func TestConsumeGateTaskReturnsTheFirstMatchingMessage(t *testing.T) {
+	consumer := broker.NewMemoryConsumer()
+	task := ConsumeGateTask{Consumers: map[string]broker.Consumer{"memory": consumer}}
+
+	cfg, err := json.Marshal(ConsumeGateTaskConfig{
+		Provider:         "memory",
+		Topic:            "payments",
+		FilterExpression: `value == "confirmed"`,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	done := make(chan struct{})
+	var output string
+	var runErr error
+	go func() {
+		output, runErr = task.Run(context.Background(), string(cfg))
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	consumer.Publish(context.Background(), "payments", broker.Message{Key: "order-1", Value: "pending"})
+	consumer.Publish(context.Background(), "payments", broker.Message{Key: "order-1", Value: "confirmed"})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run")
+	}
+	if runErr != nil {
+		t.Fatalf("Run: %v", runErr)
+	}
+
+	var result ConsumeGateResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.Value != "confirmed" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestConsumeGateTaskMatchesEveryMessageWithoutAFilter(t *testing.T) {
+	consumer := broker.NewMemoryConsumer()
+	task := ConsumeGateTask{Consumers: map[string]broker.Consumer{"memory": consumer}}
+
+	cfg, err := json.Marshal(ConsumeGateTaskConfig{Provider: "memory", Topic: "events"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	done := make(chan struct{})
+	var output string
+	go func() {
+		output, _ = task.Run(context.Background(), string(cfg))
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	consumer.Publish(context.Background(), "events", broker.Message{Value: "anything"})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run")
+	}
+
+	var result ConsumeGateResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.Value != "anything" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestConsumeGateTaskTimesOutWithoutAMatch(t *testing.T) {
+	consumer := broker.NewMemoryConsumer()
+	fakeClock := clock.NewFake(time.Now())
+	task := ConsumeGateTask{Consumers: map[string]broker.Consumer{"memory": consumer}, Clock: fakeClock}
+
+	cfg, err := json.Marshal(ConsumeGateTaskConfig{
+		Provider: "memory",
+		Topic:    "payments",
+		Timeout:  time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		_, runErr = task.Run(context.Background(), string(cfg))
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	fakeClock.Advance(time.Minute)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run")
+	}
+	if runErr == nil {
+		t.Fatal("expected Run to time out without a matching message")
+	}
+}
+
+func TestConsumeGateTaskRejectsAnUnregisteredProvider(t *testing.T) {
+	task := ConsumeGateTask{Consumers: map[string]broker.Consumer{}}
+	cfg, err := json.Marshal(ConsumeGateTaskConfig{Provider: "kafka", Topic: "payments"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject an unregistered provider")
+	}
+}
+
+func TestConsumeGateTaskRejectsAFilterExpressionThatIsntABool(t *testing.T) {
+	consumer := broker.NewMemoryConsumer()
+	task := ConsumeGateTask{Consumers: map[string]broker.Consumer{"memory": consumer}}
+
+	cfg, err := json.Marshal(ConsumeGateTaskConfig{
+		Provider:         "memory",
+		Topic:            "payments",
+		FilterExpression: `value`,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		_, runErr = task.Run(context.Background(), string(cfg))
+		close(done)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	consumer.Publish(context.Background(), "payments", broker.Message{Value: "confirmed"})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run")
+	}
+	if runErr == nil {
+		t.Fatal("expected Run to fail when filter_expression doesn't produce a bool")
+	}
+}
@@ -0,0 +1,169 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+type stepOutputsKey struct{}
+
+// withStepOutputs attaches the outputs produced so far by an execution's
+// completed steps, keyed by step name, so a Task can consult its
+// predecessors' results without the orchestrator changing its Task
+// interface.
+func withStepOutputs(ctx context.Context, outputs map[string]string) context.Context {
+	return context.WithValue(ctx, stepOutputsKey{}, outputs)
+}
+
+// StepOutputsFromContext returns the outputs of every step that has
+// completed so far in the current execution, keyed by step name. It
+// returns nil if ctx carries none, which Tasks not run through
+// TaskOrchestrator should treat as "no predecessor outputs available".
+func StepOutputsFromContext(ctx context.Context) map[string]string {
+	outputs, _ := ctx.Value(stepOutputsKey{}).(map[string]string)
+	return outputs
+}
+
+type workingDirKey struct{}
+
+// withWorkingDir attaches the working directory the step currently
+// running's Task should default to, so a Task like ShellTask can see it
+// without the orchestrator changing its Task interface.
+func withWorkingDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, workingDirKey{}, dir)
+}
+
+// WorkingDirFromContext returns the working directory the step
+// currently running should default to, or "" if ctx was not run through
+// a TaskOrchestrator configured with a Sandbox (see WithSandbox).
+func WorkingDirFromContext(ctx context.Context) string {
+	dir, _ := ctx.Value(workingDirKey{}).(string)
+	return dir
+}
+
+type runAsUserKey struct{}
+
+// withRunAsUser attaches the step currently running's RunAsUser, so a
+// Task like ShellTask can see it without the orchestrator changing its
+// Task interface.
+func withRunAsUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, runAsUserKey{}, user)
+}
+
+// RunAsUserFromContext returns the RunAsUser of the step currently
+// running, or "" if it has none or ctx was not run through a
+// TaskOrchestrator.
+func RunAsUserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(runAsUserKey{}).(string)
+	return user
+}
+
+// artifactRecorder persists a local file as a named artifact of the step
+// currently running, returning the row describing it.
+type artifactRecorder func(name, localPath string) (*model.Artifact, error)
+
+type artifactRecorderKey struct{}
+
+// withArtifactRecorder attaches recorder so a Task can call RecordArtifact
+// to persist a file it produced without the orchestrator changing its
+// Task interface.
+func withArtifactRecorder(ctx context.Context, recorder artifactRecorder) context.Context {
+	return context.WithValue(ctx, artifactRecorderKey{}, recorder)
+}
+
+// RecordArtifact persists the file at localPath as an artifact named name
+// of the step currently running, returning the Artifact row that
+// describes it. It returns an error if ctx was not run through a
+// TaskOrchestrator configured with an artifact.Store.
+func RecordArtifact(ctx context.Context, name, localPath string) (*model.Artifact, error) {
+	recorder, ok := ctx.Value(artifactRecorderKey{}).(artifactRecorder)
+	if !ok {
+		return nil, fmt.Errorf("record artifact %q: no artifact store configured", name)
+	}
+	return recorder(name, localPath)
+}
+
+// dynamicStepRecorder appends steps discovered at runtime to the
+// execution's DAG, to be picked up once the layer containing the step
+// that discovered them finishes.
+type dynamicStepRecorder func(steps []model.TemplateStep)
+
+type dynamicStepsKey struct{}
+
+// withDynamicSteps attaches recorder so a Task can call AddDynamicSteps
+// to grow the running execution's DAG without the orchestrator changing
+// its Task interface.
+func withDynamicSteps(ctx context.Context, recorder dynamicStepRecorder) context.Context {
+	return context.WithValue(ctx, dynamicStepsKey{}, recorder)
+}
+
+// AddDynamicSteps registers new steps to run as part of the current
+// execution, discovered at runtime by the step currently running (e.g. a
+// discovery step deciding what remediation to perform). A new step's
+// DependsOn may name any step already in the template, already added
+// this way, or added alongside it in the same call; a new step with no
+// dependency on the step that added it runs as soon as its own
+// dependencies allow, same as any other step.
+//
+// It is a no-op if ctx was not run through a TaskOrchestrator (e.g. a
+// Task under test calling it directly), since nothing is listening.
+func AddDynamicSteps(ctx context.Context, steps []model.TemplateStep) {
+	if recorder, ok := ctx.Value(dynamicStepsKey{}).(dynamicStepRecorder); ok {
+		recorder(steps)
+	}
+}
+
+// externalInputRecorder persists one external input a step consumed (an
+// HTTP response body, an environment value, ...) as a named input of the
+// step currently running.
+type externalInputRecorder func(name, value string) error
+
+type externalInputRecorderKey struct{}
+
+// withExternalInputRecorder attaches recorder so a Task can call
+// RecordExternalInput to pin data it read from outside the orchestrator
+// without the orchestrator changing its Task interface.
+func withExternalInputRecorder(ctx context.Context, recorder externalInputRecorder) context.Context {
+	return context.WithValue(ctx, externalInputRecorderKey{}, recorder)
+}
+
+// RecordExternalInput persists value as a named external input of the
+// step currently running (e.g. an HTTP response body), so the execution
+// can later be replayed against the exact same external data instead of
+// whatever the live source returns on a retry. It is a no-op returning
+// nil if ctx was not run through a TaskOrchestrator (e.g. a Task under
+// test calling it directly), since nothing is listening.
+func RecordExternalInput(ctx context.Context, name, value string) error {
+	recorder, ok := ctx.Value(externalInputRecorderKey{}).(externalInputRecorder)
+	if !ok {
+		return nil
+	}
+	return recorder(name, value)
+}
+
+// costRecorder adds cents to the running total cost of the step
+// currently running (e.g. a cloud action task annotating the cost of
+// the call it just made).
+type costRecorder func(cents int64)
+
+type costRecorderKey struct{}
+
+// withCostRecorder attaches recorder so a Task can call RecordCost to
+// annotate its own resource cost without the orchestrator changing its
+// Task interface.
+func withCostRecorder(ctx context.Context, recorder costRecorder) context.Context {
+	return context.WithValue(ctx, costRecorderKey{}, recorder)
+}
+
+// RecordCost adds cents to the running cost total of the step currently
+// running, in US cents. A Task may call it more than once (e.g. once per
+// API call it makes); the amounts accumulate. It is a no-op if ctx was
+// not run through a TaskOrchestrator (e.g. a Task under test calling it
+// directly), since nothing is listening.
+func RecordCost(ctx context.Context, cents int64) {
+	if recorder, ok := ctx.Value(costRecorderKey{}).(costRecorder); ok {
+		recorder(cents)
+	}
+}
@@ -0,0 +1,85 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// costReportingTask records two cost annotations, to confirm they
+// accumulate on the same StepExecution rather than one overwriting the
+// other.
+type costReportingTask struct{}
+
+func (costReportingTask) Run(ctx context.Context, config string) (string, error) {
+	RecordCost(ctx, 150)
+	RecordCost(ctx, 25)
+	return "ok", nil
+}
+
+func TestRunAccumulatesCostRecordedByATask(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("billed", costReportingTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "billed"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("find step execution: %v", err)
+	}
+	if se.CostCents != 175 {
+		t.Fatalf("expected CostCents to accumulate to 175, got %d", se.CostCents)
+	}
+}
+
+// advancingCostTask advances a Fake clock before returning, the same
+// pattern sla_test.go uses to give a step a deterministic, non-zero
+// duration without a real sleep.
+type advancingCostTask struct {
+	clock *clock.Fake
+	by    time.Duration
+}
+
+func (t advancingCostTask) Run(ctx context.Context, config string) (string, error) {
+	t.clock.Advance(t.by)
+	return "ok", nil
+}
+
+func TestRunRecordsAgentRuntimeSeparatelyFromQueueingDelay(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	registry.Register("slow", advancingCostTask{clock: fakeClock, by: 5 * time.Second})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "slow"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("find step execution: %v", err)
+	}
+	if se.AgentRuntimeSeconds < 5 {
+		t.Fatalf("expected AgentRuntimeSeconds to cover the 5s advance, got %v", se.AgentRuntimeSeconds)
+	}
+}
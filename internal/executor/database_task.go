@@ -0,0 +1,318 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// DatabaseBackupTaskConfig is the JSON shape expected in a step's
+// Config for DatabaseBackupTask.
+type DatabaseBackupTaskConfig struct {
+	// Engine is "mysql" or "postgres".
+	Engine   string `json:"engine"`
+	Host     string `json:"host,omitempty"`
+	Port     string `json:"port,omitempty"`
+	Database string `json:"database"`
+	User     string `json:"user,omitempty"`
+	// PasswordEnvVar names the environment variable holding the
+	// database password, if auth isn't already configured out of band
+	// (a .my.cnf, a .pgpass). DatabaseBackupTask never stores the
+	// password itself.
+	PasswordEnvVar string `json:"password_env_var,omitempty"`
+	// Path is the local file the dump is written to.
+	Path string `json:"path"`
+	// ArtifactName, if set, records the dump at Path as a named
+	// artifact of this step once it completes.
+	ArtifactName string   `json:"artifact_name,omitempty"`
+	ExtraArgs    []string `json:"extra_args,omitempty"`
+}
+
+// DatabaseBackupResult is the JSON-encoded output DatabaseBackupTask
+// leaves in a step's Output.
+type DatabaseBackupResult struct {
+	Path         string `json:"path"`
+	Bytes        int64  `json:"bytes"`
+	SHA256       string `json:"sha256"`
+	ArtifactID   uint   `json:"artifact_id,omitempty"`
+	ArtifactName string `json:"artifact_name,omitempty"`
+}
+
+// DatabaseBackupTask runs mysqldump or pg_dump against a database and
+// writes the dump to a local file, checksumming it the same way
+// FileTransferTask does so a downstream restore step (or a human) can
+// verify it wasn't corrupted or tampered with in transit.
+type DatabaseBackupTask struct {
+	// CLI overrides the dump binary ("mysqldump" or "pg_dump" by
+	// default, chosen by Engine).
+	CLI string
+}
+
+// Run implements Task.
+func (t DatabaseBackupTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg DatabaseBackupTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("database backup task: invalid config: %w", err)
+	}
+	if cfg.Database == "" {
+		return "", fmt.Errorf("database backup task: database is required")
+	}
+	if cfg.Path == "" {
+		return "", fmt.Errorf("database backup task: path is required")
+	}
+
+	cli, args, env, err := databaseDumpCommand(cfg)
+	if err != nil {
+		return "", err
+	}
+	if t.CLI != "" {
+		cli = t.CLI
+	}
+
+	if err := runDump(ctx, cli, args, env, cfg.Path); err != nil {
+		return "", fmt.Errorf("database backup task: %w", err)
+	}
+
+	sum, size, err := hashFile(cfg.Path)
+	if err != nil {
+		return "", fmt.Errorf("database backup task: %w", err)
+	}
+
+	result := DatabaseBackupResult{Path: cfg.Path, Bytes: size, SHA256: sum}
+	if cfg.ArtifactName != "" {
+		art, err := RecordArtifact(ctx, cfg.ArtifactName, cfg.Path)
+		if err != nil {
+			return "", fmt.Errorf("database backup task: %w", err)
+		}
+		result.ArtifactID = art.ID
+		result.ArtifactName = cfg.ArtifactName
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// DatabaseRestoreTaskConfig is the JSON shape expected in a step's
+// Config for DatabaseRestoreTask.
+type DatabaseRestoreTaskConfig struct {
+	// Engine is "mysql" or "postgres".
+	Engine         string `json:"engine"`
+	Host           string `json:"host,omitempty"`
+	Port           string `json:"port,omitempty"`
+	Database       string `json:"database"`
+	User           string `json:"user,omitempty"`
+	PasswordEnvVar string `json:"password_env_var,omitempty"`
+	// Path is the local dump file to restore from.
+	Path string `json:"path"`
+	// SHA256, if set, must match Path's checksum before the restore
+	// runs, catching a dump that was truncated or swapped out from
+	// under the step between backup and restore.
+	SHA256 string `json:"sha256,omitempty"`
+	// Confirm must be explicitly set true, since a restore overwrites
+	// Database's existing data; DatabaseRestoreTask refuses to run
+	// without it rather than treating an unset field as "confirmed".
+	Confirm   bool     `json:"confirm"`
+	ExtraArgs []string `json:"extra_args,omitempty"`
+}
+
+// DatabaseRestoreResult is the JSON-encoded output DatabaseRestoreTask
+// leaves in a step's Output.
+type DatabaseRestoreResult struct {
+	Database string `json:"database"`
+	Path     string `json:"path"`
+	Bytes    int64  `json:"bytes"`
+	SHA256   string `json:"sha256"`
+}
+
+// DatabaseRestoreTask restores a mysqldump or pg_dump SQL file into a
+// database, requiring an explicit confirmation and, optionally, a
+// checksum match before running, since a restore is destructive and
+// irreversible against whatever Database already held.
+type DatabaseRestoreTask struct {
+	// CLI overrides the restore binary ("mysql" or "psql" by default,
+	// chosen by Engine).
+	CLI string
+}
+
+// Run implements Task.
+func (t DatabaseRestoreTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg DatabaseRestoreTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("database restore task: invalid config: %w", err)
+	}
+	if cfg.Database == "" {
+		return "", fmt.Errorf("database restore task: database is required")
+	}
+	if cfg.Path == "" {
+		return "", fmt.Errorf("database restore task: path is required")
+	}
+	if !cfg.Confirm {
+		return "", fmt.Errorf("database restore task: refusing to restore over %q without confirm: true", cfg.Database)
+	}
+
+	sum, size, err := hashFile(cfg.Path)
+	if err != nil {
+		return "", fmt.Errorf("database restore task: %w", err)
+	}
+	if cfg.SHA256 != "" && sum != cfg.SHA256 {
+		return "", fmt.Errorf("database restore task: checksum mismatch on %s: expected %s, got %s", cfg.Path, cfg.SHA256, sum)
+	}
+
+	cli, args, env, err := databaseRestoreCommand(cfg)
+	if err != nil {
+		return "", err
+	}
+	if t.CLI != "" {
+		cli = t.CLI
+	}
+
+	if err := runRestore(ctx, cli, args, env, cfg.Path); err != nil {
+		return "", fmt.Errorf("database restore task: %w", err)
+	}
+
+	encoded, err := json.Marshal(DatabaseRestoreResult{Database: cfg.Database, Path: cfg.Path, Bytes: size, SHA256: sum})
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func databaseDumpCommand(cfg DatabaseBackupTaskConfig) (cli string, args []string, env []string, err error) {
+	switch cfg.Engine {
+	case "", "mysql":
+		args = mysqlConnectionArgs(cfg.Host, cfg.Port, cfg.User)
+		args = append(args, cfg.ExtraArgs...)
+		args = append(args, cfg.Database)
+		env, err = mysqlPasswordEnv(cfg.PasswordEnvVar)
+		return "mysqldump", args, env, err
+	case "postgres":
+		args = postgresConnectionArgs(cfg.Host, cfg.Port, cfg.User)
+		args = append(args, cfg.ExtraArgs...)
+		args = append(args, cfg.Database)
+		env, err = postgresPasswordEnv(cfg.PasswordEnvVar)
+		return "pg_dump", args, env, err
+	default:
+		return "", nil, nil, fmt.Errorf("database backup task: unsupported engine %q", cfg.Engine)
+	}
+}
+
+func databaseRestoreCommand(cfg DatabaseRestoreTaskConfig) (cli string, args []string, env []string, err error) {
+	switch cfg.Engine {
+	case "", "mysql":
+		args = mysqlConnectionArgs(cfg.Host, cfg.Port, cfg.User)
+		args = append(args, cfg.ExtraArgs...)
+		args = append(args, cfg.Database)
+		env, err = mysqlPasswordEnv(cfg.PasswordEnvVar)
+		return "mysql", args, env, err
+	case "postgres":
+		args = postgresConnectionArgs(cfg.Host, cfg.Port, cfg.User)
+		args = append(args, cfg.ExtraArgs...)
+		args = append(args, "-d", cfg.Database)
+		env, err = postgresPasswordEnv(cfg.PasswordEnvVar)
+		return "psql", args, env, err
+	default:
+		return "", nil, nil, fmt.Errorf("database restore task: unsupported engine %q", cfg.Engine)
+	}
+}
+
+func mysqlConnectionArgs(host, port, user string) []string {
+	var args []string
+	if host != "" {
+		args = append(args, "-h", host)
+	}
+	if port != "" {
+		args = append(args, "-P", port)
+	}
+	if user != "" {
+		args = append(args, "-u", user)
+	}
+	return args
+}
+
+func postgresConnectionArgs(host, port, user string) []string {
+	var args []string
+	if host != "" {
+		args = append(args, "-h", host)
+	}
+	if port != "" {
+		args = append(args, "-p", port)
+	}
+	if user != "" {
+		args = append(args, "-U", user)
+	}
+	return args
+}
+
+func mysqlPasswordEnv(passwordEnvVar string) ([]string, error) {
+	if passwordEnvVar == "" {
+		return nil, nil
+	}
+	password := os.Getenv(passwordEnvVar)
+	if password == "" {
+		return nil, fmt.Errorf("%s is not set", passwordEnvVar)
+	}
+	return []string{"MYSQL_PWD=" + password}, nil
+}
+
+func postgresPasswordEnv(passwordEnvVar string) ([]string, error) {
+	if passwordEnvVar == "" {
+		return nil, nil
+	}
+	password := os.Getenv(passwordEnvVar)
+	if password == "" {
+		return nil, fmt.Errorf("%s is not set", passwordEnvVar)
+	}
+	return []string{"PGPASSWORD=" + password}, nil
+}
+
+// runDump runs cli with args, writing its stdout to destPath and
+// returning its stderr as part of any error, the way mysqldump/pg_dump
+// stream the backup to stdout by convention.
+func runDump(ctx context.Context, cli string, args, env []string, destPath string) error {
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, cli, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdout = out
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cli, err, stderr.String())
+	}
+	return nil
+}
+
+// runRestore runs cli with args, feeding srcPath in on stdin, the way
+// mysql/psql read a plain-SQL dump by convention.
+func runRestore(ctx context.Context, cli string, args, env []string, srcPath string) error {
+	in, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	cmd := exec.CommandContext(ctx, cli, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	cmd.Stdin = in
+	var combined bytes.Buffer
+	cmd.Stdout = &combined
+	cmd.Stderr = &combined
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cli, err, combined.String())
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func fakeDumpCLI(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fake-dump")
+	script := fmt.Sprintf("#!/bin/sh\nprintf '%%s' '%s'\n", body)
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake dump CLI: %v", err)
+	}
+	return path
+}
+
+func TestDatabaseBackupTaskWritesAChecksummedDump(t *testing.T) {
+	cli := fakeDumpCLI(t, "-- mysqldump output")
+	task := DatabaseBackupTask{CLI: cli}
+	destPath := filepath.Join(t.TempDir(), "dump.sql")
+
+	cfg, err := json.Marshal(DatabaseBackupTaskConfig{Engine: "mysql", Database: "orders", Path: destPath})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result DatabaseBackupResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.Bytes == 0 || result.SHA256 == "" {
+		t.Fatalf("expected a non-empty dump and checksum, got %+v", result)
+	}
+	sum, _, err := hashFile(destPath)
+	if err != nil {
+		t.Fatalf("hashFile: %v", err)
+	}
+	if sum != result.SHA256 {
+		t.Fatalf("result checksum %s doesn't match the file on disk %s", result.SHA256, sum)
+	}
+}
+
+func TestDatabaseBackupTaskRecordsAnArtifactWhenNamed(t *testing.T) {
+	cli := fakeDumpCLI(t, "-- pg_dump output")
+	destPath := filepath.Join(t.TempDir(), "dump.sql")
+
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("db_backup", DatabaseBackupTask{CLI: cli})
+	store := artifact.NewLocalStore(t.TempDir())
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithArtifactStore(store))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	cfg, err := json.Marshal(DatabaseBackupTaskConfig{
+		Engine: "postgres", Database: "orders", Path: destPath, ArtifactName: "db_dump",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "backup", Type: "db_backup", Config: string(cfg)}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "backup").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	var result DatabaseBackupResult
+	if err := json.Unmarshal([]byte(se.Output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.ArtifactName != "db_dump" || result.ArtifactID == 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	var art model.Artifact
+	if err := gormDB.First(&art, result.ArtifactID).Error; err != nil {
+		t.Fatalf("load artifact: %v", err)
+	}
+	if art.Size != result.Bytes {
+		t.Fatalf("expected artifact size %d, got %d", result.Bytes, art.Size)
+	}
+}
+
+func TestDatabaseBackupTaskRejectsAnUnsupportedEngine(t *testing.T) {
+	task := DatabaseBackupTask{}
+	cfg, err := json.Marshal(DatabaseBackupTaskConfig{Engine: "oracle", Database: "orders", Path: "/tmp/out.sql"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject an unsupported engine")
+	}
+}
+
+func TestDatabaseRestoreTaskRefusesWithoutConfirm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte("-- dump"), 0o644); err != nil {
+		t.Fatalf("write dump: %v", err)
+	}
+
+	task := DatabaseRestoreTask{}
+	cfg, err := json.Marshal(DatabaseRestoreTaskConfig{Engine: "mysql", Database: "orders", Path: path})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to refuse a restore without confirm: true")
+	}
+}
+
+func TestDatabaseRestoreTaskRejectsAChecksumMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte("-- dump"), 0o644); err != nil {
+		t.Fatalf("write dump: %v", err)
+	}
+
+	task := DatabaseRestoreTask{}
+	cfg, err := json.Marshal(DatabaseRestoreTaskConfig{
+		Engine: "mysql", Database: "orders", Path: path, Confirm: true, SHA256: "deadbeef",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject a checksum mismatch")
+	}
+}
+
+func TestDatabaseRestoreTaskRunsTheRestoreWhenConfirmedAndChecksumMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte("-- dump"), 0o644); err != nil {
+		t.Fatalf("write dump: %v", err)
+	}
+	sum, _, err := hashFile(path)
+	if err != nil {
+		t.Fatalf("hashFile: %v", err)
+	}
+
+	dir := t.TempDir()
+	cliPath := filepath.Join(dir, "fake-mysql")
+	if err := os.WriteFile(cliPath, []byte("#!/bin/sh\ncat > /dev/null\n"), 0o755); err != nil {
+		t.Fatalf("write fake mysql: %v", err)
+	}
+
+	task := DatabaseRestoreTask{CLI: cliPath}
+	cfg, err := json.Marshal(DatabaseRestoreTaskConfig{
+		Engine: "mysql", Database: "orders", Path: path, Confirm: true, SHA256: sum,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result DatabaseRestoreResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.SHA256 != sum {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
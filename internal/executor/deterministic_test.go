@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// orderRecordingTask appends its step name to a shared, mutex-protected
+// slice, so the test can assert run order without a data race.
+type orderRecordingTask struct {
+	mu    *sync.Mutex
+	order *[]string
+	name  string
+}
+
+func (t orderRecordingTask) Run(ctx context.Context, config string) (string, error) {
+	t.mu.Lock()
+	*t.order = append(*t.order, t.name)
+	t.mu.Unlock()
+	return "", nil
+}
+
+func TestDeterministicRunsLayerStepsInOrder(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	var mu sync.Mutex
+	var order []string
+	for _, name := range []string{"a", "b", "c"} {
+		registry.Register(name, orderRecordingTask{mu: &mu, order: &order, name: name})
+	}
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "a"},
+		{Name: "b", Type: "b"},
+		{Name: "c", Type: "c"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic())
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := []string{"a", "b", "c"}; len(order) != 3 || order[0] != got[0] || order[1] != got[1] || order[2] != got[2] {
+		t.Fatalf("expected steps to run in stable name order %v, got %v", got, order)
+	}
+}
+
+// TestDeterministicOrderIsStableAcrossRuns guards against the layer order
+// coming from Go's randomized map iteration instead of dag.Graph.Layers'
+// name sort: if it ever regressed, this would flake instead of always
+// passing or always failing.
+func TestDeterministicOrderIsStableAcrossRuns(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		gormDB := newTestDB(t)
+		registry := NewRegistry()
+		var mu sync.Mutex
+		var order []string
+		for _, name := range []string{"z", "m", "a", "q"} {
+			registry.Register(name, orderRecordingTask{mu: &mu, order: &order, name: name})
+		}
+
+		execution := &model.WorkflowExecution{Status: model.StatusPending}
+		if err := gormDB.Create(execution).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+		steps := []model.TemplateStep{
+			{Name: "z", Type: "z"},
+			{Name: "m", Type: "m"},
+			{Name: "a", Type: "a"},
+			{Name: "q", Type: "q"},
+		}
+
+		orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic())
+		if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		want := []string{"a", "m", "q", "z"}
+		for i, name := range want {
+			if order[i] != name {
+				t.Fatalf("run %d: expected order %v, got %v", i, want, order)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestDuplicateGuardRejectsConcurrentRunOfSameDefinition(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	task := blockingTask{started: make(chan struct{}), release: make(chan struct{})}
+	registry.Register("block", task)
+
+	steps := []model.TemplateStep{{Name: "a", Type: "block", Config: "same"}}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDuplicateGuard())
+
+	first := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(first).Error; err != nil {
+		t.Fatalf("create first execution: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), first, steps) }()
+
+	select {
+	case <-task.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("first run never reached the task")
+	}
+
+	second := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(second).Error; err != nil {
+		t.Fatalf("create second execution: %v", err)
+	}
+	err := orchestrator.Run(context.Background(), second, steps)
+	var dup *DuplicateRunError
+	if !errors.As(err, &dup) {
+		t.Fatalf("expected DuplicateRunError, got %v", err)
+	}
+
+	close(task.release)
+	if err := <-done; err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+}
+
+func TestDuplicateGuardReleasesHashAfterRunFinishes(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDuplicateGuard())
+
+	first := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(first).Error; err != nil {
+		t.Fatalf("create first execution: %v", err)
+	}
+	if err := orchestrator.Run(context.Background(), first, steps); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+
+	// The first run has already finished, so its hash was released; a
+	// second run of the identical definition should now be allowed.
+	second := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(second).Error; err != nil {
+		t.Fatalf("create second execution: %v", err)
+	}
+	if err := orchestrator.Run(context.Background(), second, steps); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+}
+
+func TestWithoutDuplicateGuardConcurrentRunsOfSameDefinitionAreAllowed(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	first := &model.WorkflowExecution{Status: model.StatusPending}
+	second := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(first).Error; err != nil {
+		t.Fatalf("create first execution: %v", err)
+	}
+	if err := gormDB.Create(second).Error; err != nil {
+		t.Fatalf("create second execution: %v", err)
+	}
+	if err := orchestrator.Run(context.Background(), first, steps); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if err := orchestrator.Run(context.Background(), second, steps); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+}
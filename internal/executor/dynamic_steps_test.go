@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// discoveryTask adds remediation steps downstream of itself the first
+// time it runs, so the test can assert the orchestrator picks them up
+// without them ever being part of the template's own steps.
+type discoveryTask struct {
+	added []model.TemplateStep
+}
+
+func (t discoveryTask) Run(ctx context.Context, config string) (string, error) {
+	AddDynamicSteps(ctx, t.added)
+	return "discovered", nil
+}
+
+func TestRunSimulatedPicksUpDynamicallyAddedSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("discover", discoveryTask{added: []model.TemplateStep{
+		{Name: "remediate", Type: "shell", Config: "true", DependsOn: "discover"},
+	}})
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "discover", Type: "discover"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "remediate").First(&se).Error; err != nil {
+		t.Fatalf("expected the dynamically added step to have run: %v", err)
+	}
+	if se.Status != model.StatusSucceeded {
+		t.Fatalf("expected the dynamically added step to succeed, got %s", se.Status)
+	}
+}
+
+func TestRunSimulatedSkipsDynamicStepBlockedByFailedDependency(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("discover", discoveryTask{added: []model.TemplateStep{
+		{Name: "remediate", Type: "shell", Config: "true", DependsOn: "fail_hard"},
+	}})
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "discover", Type: "discover"},
+		{Name: "fail_hard", Type: "shell", Config: "false"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to report failure")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "remediate").First(&se).Error; err != nil {
+		t.Fatalf("expected the dynamically added step to still be scheduled: %v", err)
+	}
+	if se.Status != model.StatusSkipped {
+		t.Fatalf("expected the dynamically added step to be skipped, got %s", se.Status)
+	}
+}
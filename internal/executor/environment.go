@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/fengmingli/orchestrator/internal/buildinfo"
+)
+
+// Environment identifies the process a step ran in, recorded on every
+// StepExecution so a step that only fails on one replica can be traced
+// back to which one ran it.
+type Environment struct {
+	Hostname            string
+	OS                  string
+	Arch                string
+	OrchestratorVersion string
+	EnvFingerprint      string
+}
+
+// CaptureEnvironment reads this process's hostname, platform and
+// buildinfo.Version, and fingerprints its environment variables. The
+// fingerprint hashes only variable *names*, sorted, never their values,
+// so it can reveal that two replicas are configured differently without
+// ever persisting a value that might be a secret.
+func CaptureEnvironment() Environment {
+	hostname, _ := os.Hostname()
+	return Environment{
+		Hostname:            hostname,
+		OS:                  runtime.GOOS,
+		Arch:                runtime.GOARCH,
+		OrchestratorVersion: buildinfo.Version,
+		EnvFingerprint:      envFingerprint(os.Environ()),
+	}
+}
+
+// envFingerprint hashes the sorted, de-duplicated set of variable names
+// in env (each entry "NAME=value"), ignoring values.
+func envFingerprint(env []string) string {
+	names := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sum := sha256.Sum256([]byte(strings.Join(names, ",")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,26 @@
+package executor
+
+import "testing"
+
+func TestEnvFingerprintIgnoresOrderAndValues(t *testing.T) {
+	a := envFingerprint([]string{"FOO=1", "BAR=2"})
+	b := envFingerprint([]string{"BAR=999", "FOO=anything"})
+	if a != b {
+		t.Fatalf("expected fingerprints to match regardless of order or value, got %q and %q", a, b)
+	}
+}
+
+func TestEnvFingerprintDiffersOnDifferentNames(t *testing.T) {
+	a := envFingerprint([]string{"FOO=1"})
+	b := envFingerprint([]string{"BAR=1"})
+	if a == b {
+		t.Fatal("expected different variable names to produce different fingerprints")
+	}
+}
+
+func TestCaptureEnvironmentPopulatesFields(t *testing.T) {
+	env := CaptureEnvironment()
+	if env.OS == "" || env.Arch == "" || env.OrchestratorVersion == "" || env.EnvFingerprint == "" {
+		t.Fatalf("expected every field to be populated, got %+v", env)
+	}
+}
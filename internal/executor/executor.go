@@ -0,0 +1,1401 @@
+// Package executor runs a WorkflowExecution layer by layer according to
+// its DAG, recording step outcomes and an append-only event stream.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/fieldcrypt"
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/logging"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/notify"
+	"github.com/fengmingli/orchestrator/internal/stepsign"
+)
+
+var engineLog = logging.New("engine")
+
+// TaskOrchestrator drives WorkflowExecutions to completion against a DB
+// and a Task Registry.
+type TaskOrchestrator struct {
+	db        *gorm.DB
+	registry  *Registry
+	clock     clock.Clock
+	notifier  notify.Notifier
+	artifacts artifact.Store
+	// deterministic, when true, runs the steps within each DAG layer one
+	// at a time in a fixed order instead of concurrently, trading
+	// throughput for reproducible step ordering in tests.
+	deterministic bool
+	// duplicateGuard, when true, rejects a RunSimulated call whose step
+	// set hashes the same as one already running in this orchestrator.
+	duplicateGuard bool
+	// bulkhead caps how many steps run at once across every execution
+	// this orchestrator drives, independent of each execution's own
+	// MaxParallel. Nil means unbounded.
+	bulkhead *Bulkhead
+	// crypt decrypts a step's Config and DiagnosticConfig before it's
+	// run, transparently undoing the encryption service.TemplateService
+	// applies at rest. Nil means steps are stored and run as plaintext.
+	crypt *fieldcrypt.KeyRing
+	// redactor scrubs secrets out of a step's output and the event
+	// messages derived from it before they're persisted. Nil means
+	// nothing is redacted.
+	redactor Redactor
+	// verifier checks a step's ConfigSignature against its current
+	// definition before it's run, refusing to run a step whose stored
+	// row was tampered with after it was signed. Nil means steps run
+	// unverified.
+	verifier *stepsign.Signer
+	// statusJournalPath, if set, is the directory each execution's
+	// StatusWriter journals its buffered StepExecution writes to while a
+	// flush is failing, so they survive a process restart during a DB
+	// outage rather than being lost with the process. Empty means no
+	// journal: a restart during an outage loses whatever was still
+	// buffered in memory, same as before this option existed.
+	statusJournalPath string
+	// teamRouter, if set, routes a failed execution's notification
+	// through notify.TeamRouter by its template's OnCallRoutingKey
+	// instead of through notifier, so different templates' failures can
+	// reach different teams. Nil means every failure still goes through
+	// notifier, same as before this option existed.
+	teamRouter *notify.TeamRouter
+	// externalWaiters tracks StepExecutions an "external" step has left
+	// pending-external, so CompleteExternalStep can find and complete
+	// them.
+	externalWaiters *ExternalWaiters
+	// hooks observe orchestration-level lifecycle events (execution
+	// start/end, layer boundaries, state transitions) across every
+	// execution this orchestrator drives, so integrations like metrics
+	// or external locking don't need to wrap every Task.
+	hooks []Hook
+	// environment identifies this process, stamped onto every
+	// StepExecution it precreates. Captured once at construction via
+	// CaptureEnvironment rather than per step, since it doesn't change
+	// over the orchestrator's lifetime.
+	environment Environment
+	// store is where this orchestrator's ExecutionEvent stream is always
+	// recorded, and where its StepExecution updates are also recorded
+	// whenever customStore is true. Defaults to a GORMStateStore over
+	// db; see WithStateStore.
+	store StateStore
+	// customStore is true once WithStateStore has overridden store away
+	// from its default GORMStateStore over db. It exists so finishStep
+	// and skipStep only pay for an extra StateStore.SaveNodeState call
+	// when store might be something other than the same db StatusWriter
+	// already batches its StepExecution writes against (see
+	// StateStore's doc comment for why the default path skips it).
+	customStore bool
+	// sandbox, if set, gives every step its own working directory
+	// instead of leaving ShellTask (and any other Task that honors
+	// WorkingDirFromContext) to run against the orchestrator process's
+	// own working directory. Nil means no isolation, same as before
+	// this existed.
+	sandbox *Sandbox
+	// locks backs every step's ConcurrencyKey mutex: a step naming one
+	// blocks in LockWithWait until it can hold it exclusively, across
+	// every execution this orchestrator (or any other process sharing
+	// the same Provider) is running. Nil means a step's ConcurrencyKey
+	// is ignored and it runs immediately, same as before this existed.
+	locks lock.Provider
+
+	mu  sync.Mutex
+	seq map[uint]uint64 // per-execution event sequence counter
+
+	runningMu sync.Mutex
+	running   map[string]struct{} // structural hashes of in-flight runs, set only when duplicateGuard is on
+
+	activeMu sync.Mutex
+	active   map[uint]map[string]activeStep // executionID -> step name -> tracked run
+}
+
+// activeStep is the bookkeeping trackStepStart keeps for one running
+// step: when it started, for ActiveSteps, and how to cancel its run
+// context, for ForceFailStep.
+type activeStep struct {
+	startedAt time.Time
+	cancel    context.CancelFunc
+}
+
+// Option configures a TaskOrchestrator built by NewTaskOrchestrator.
+type Option func(*TaskOrchestrator)
+
+// WithClock overrides the TaskOrchestrator's Clock, which defaults to
+// clock.Real{}. Tests typically pass a *clock.Fake.
+func WithClock(c clock.Clock) Option {
+	return func(o *TaskOrchestrator) { o.clock = c }
+}
+
+// WithEnvironment overrides the TaskOrchestrator's Environment, which
+// defaults to CaptureEnvironment(). Tests pass a fixed Environment so
+// assertions on a StepExecution's captured fields don't depend on the
+// host running the test.
+func WithEnvironment(env Environment) Option {
+	return func(o *TaskOrchestrator) { o.environment = env }
+}
+
+// WithDeterministic runs each DAG layer's steps sequentially in a fixed
+// order rather than concurrently, for reproducible engine tests.
+func WithDeterministic() Option {
+	return func(o *TaskOrchestrator) { o.deterministic = true }
+}
+
+// WithNotifier overrides the TaskOrchestrator's Notifier, which defaults
+// to notify.LogNotifier{}, used to alert on SLA breaches.
+func WithNotifier(n notify.Notifier) Option {
+	return func(o *TaskOrchestrator) { o.notifier = n }
+}
+
+// WithTeamRouter routes a failed execution's notification through
+// router by its template's OnCallRoutingKey, instead of unconditionally
+// through the Notifier configured by WithNotifier.
+func WithTeamRouter(router *notify.TeamRouter) Option {
+	return func(o *TaskOrchestrator) { o.teamRouter = router }
+}
+
+// WithArtifactStore configures where files a Task produces via
+// RecordArtifact are persisted. Without one, RecordArtifact returns an
+// error and steps cannot register artifacts.
+func WithArtifactStore(store artifact.Store) Option {
+	return func(o *TaskOrchestrator) { o.artifacts = store }
+}
+
+// WithHook registers h to observe every execution this orchestrator
+// drives. Hooks run synchronously in registration order on the calling
+// goroutine, in between the work they're observing, so a slow or
+// panicking hook affects every execution; keep them fast and safe.
+func WithHook(h Hook) Option {
+	return func(o *TaskOrchestrator) { o.hooks = append(o.hooks, h) }
+}
+
+// WithDuplicateGuard rejects a RunSimulated call with a *DuplicateRunError
+// if a run with the identical structural hash (dag.Graph.StructuralHash)
+// is already in flight on this orchestrator, so two goroutines racing to
+// start the same workflow can't both execute its steps.
+func WithDuplicateGuard() Option {
+	return func(o *TaskOrchestrator) { o.duplicateGuard = true }
+}
+
+// WithBulkhead caps how many steps run at once across every execution
+// this orchestrator drives, on top of each execution's own MaxParallel,
+// so one execution with a wide DAG layer can't consume the orchestrator's
+// entire capacity and starve steps belonging to other executions
+// running concurrently (e.g. dispatched through the same WorkerPool).
+// Waiting steps are served fairly across executions rather than
+// first-come-first-served. A capacity of zero or less leaves the
+// orchestrator unbounded, which is also the default.
+func WithBulkhead(capacity int) Option {
+	return func(o *TaskOrchestrator) { o.bulkhead = NewBulkhead(capacity) }
+}
+
+// WithFieldCrypt configures the KeyRing used to decrypt a step's Config
+// and DiagnosticConfig before running it. Without one, those fields are
+// run exactly as stored, which only works if service.TemplateService
+// wasn't configured to encrypt them either.
+func WithFieldCrypt(ring *fieldcrypt.KeyRing) Option {
+	return func(o *TaskOrchestrator) { o.crypt = ring }
+}
+
+// Redactor scrubs secrets out of text belonging to project before the
+// orchestrator persists it as a step's output or an event message, or
+// hands it to the Notifier. Without one, text is stored and notified
+// exactly as produced.
+type Redactor interface {
+	Redact(project, text string) string
+}
+
+// WithRedactor configures the Redactor every execution this
+// orchestrator drives uses to scrub its steps' output and event
+// messages, scoped by each execution's Project.
+func WithRedactor(r Redactor) Option {
+	return func(o *TaskOrchestrator) { o.redactor = r }
+}
+
+// WithStepSigner configures the Signer used to verify a step's
+// ConfigSignature against its current definition before it's run.
+// Without one, steps run regardless of whether (or how) they were
+// signed.
+func WithStepSigner(signer *stepsign.Signer) Option {
+	return func(o *TaskOrchestrator) { o.verifier = signer }
+}
+
+// WithStatusJournal makes every execution's StatusWriter durable across
+// a process restart: its buffered, not-yet-flushed StepExecution writes
+// are mirrored to a file under dir while a flush is failing, and
+// reloaded into a fresh StatusWriter's buffer on the next restart, so a
+// DB outage that outlasts the process (not just the goroutine's own
+// retry loop) doesn't lose step status that was already decided, only
+// delay when it's persisted. Without this, StatusWriter still survives
+// a DB outage that clears up before the process exits (see requeue); it
+// just has nowhere to put buffered writes if the process dies first.
+// dir must already exist; each execution journals to its own file
+// within it, keyed by execution ID, since several executions' writers
+// run concurrently under one orchestrator.
+func WithStatusJournal(dir string) Option {
+	return func(o *TaskOrchestrator) { o.statusJournalPath = dir }
+}
+
+// WithStateStore overrides the StateStore every StepExecution update and
+// ExecutionEvent this orchestrator records is persisted through, which
+// defaults to a GORMStateStore over db. Tests typically pass an
+// InMemoryStateStore to assert on recorded state without a real
+// *gorm.DB.
+func WithStateStore(store StateStore) Option {
+	return func(o *TaskOrchestrator) {
+		o.store = store
+		o.customStore = true
+	}
+}
+
+// StateStore returns the StateStore this orchestrator persists its
+// StepExecution updates and events through, for callers (e.g.
+// service.ExecutionService's stage operations) that need to save or
+// reload the same engine-level state without duplicating its
+// optimistic-locking or query logic.
+func (o *TaskOrchestrator) StateStore() StateStore {
+	return o.store
+}
+
+// WithSandbox gives every step this orchestrator runs its own working
+// directory under sandbox.Dir (see Sandbox), so concurrent steps never
+// trample a shared path. It's surfaced to a step's Task via
+// WorkingDirFromContext; ShellTask honors it as its command's default
+// working directory. Without one, steps run with no WorkingDir override,
+// same as before this existed.
+func WithSandbox(sandbox *Sandbox) Option {
+	return func(o *TaskOrchestrator) { o.sandbox = sandbox }
+}
+
+// WithLockProvider configures the lock.Provider a step's ConcurrencyKey
+// is acquired against. Without one, ConcurrencyKey is ignored and every
+// step runs immediately regardless of what it names.
+func WithLockProvider(provider lock.Provider) Option {
+	return func(o *TaskOrchestrator) { o.locks = provider }
+}
+
+// statusJournalPathFor returns the journal file execution's StatusWriter
+// should use, or "" (disabling the journal) if no journal directory was
+// configured.
+func (o *TaskOrchestrator) statusJournalPathFor(execution *model.WorkflowExecution) string {
+	if o.statusJournalPath == "" {
+		return ""
+	}
+	return filepath.Join(o.statusJournalPath, fmt.Sprintf("execution-%d.json", execution.ID))
+}
+
+// NewTaskOrchestrator builds a TaskOrchestrator backed by db and registry.
+func NewTaskOrchestrator(db *gorm.DB, registry *Registry, opts ...Option) *TaskOrchestrator {
+	o := &TaskOrchestrator{db: db, registry: registry, clock: clock.Real{}, notifier: notify.LogNotifier{}, seq: make(map[uint]uint64), externalWaiters: NewExternalWaiters(), environment: CaptureEnvironment(), store: NewGORMStateStore(db)}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// CompleteExternalStep delivers result to the "external" step's Task
+// blocked in ExternalTask.Run on stepExecutionID, if token matches the
+// one it was given when it started waiting. See ExternalWaiters.Complete.
+func (o *TaskOrchestrator) CompleteExternalStep(stepExecutionID uint, token string, result ExternalResult) error {
+	return o.externalWaiters.Complete(stepExecutionID, token, result)
+}
+
+// DuplicateRunError reports that RunSimulated was asked to run a step set
+// that's already running in this orchestrator, identified by
+// dag.Graph.StructuralHash rather than execution ID, since the guard is
+// meant to catch two callers racing to start the *same definition*, not
+// just the same execution row.
+type DuplicateRunError struct {
+	Hash string
+}
+
+func (e *DuplicateRunError) Error() string {
+	return fmt.Sprintf("definition hash %s is already running in this orchestrator", e.Hash)
+}
+
+// StepPreflightError is one step's failure during RunSimulated's
+// preflight pass, identified by name since the step has no
+// StepExecution row yet at that point.
+type StepPreflightError struct {
+	StepName string
+	Err      error
+}
+
+func (e *StepPreflightError) Error() string {
+	return fmt.Sprintf("step %q: %s", e.StepName, e.Err)
+}
+
+func (e *StepPreflightError) Unwrap() error { return e.Err }
+
+// PreflightError reports every step that failed RunSimulated's preflight
+// pass, so a caller sees every problem with a DAG at once instead of
+// learning about step 2's bad config only after step 1 already ran for
+// real.
+type PreflightError struct {
+	Errors []*StepPreflightError
+}
+
+func (e *PreflightError) Error() string {
+	names := make([]string, len(e.Errors))
+	for i, stepErr := range e.Errors {
+		names[i] = stepErr.StepName
+	}
+	return fmt.Sprintf("%d step(s) failed preflight: %s", len(e.Errors), strings.Join(names, ", "))
+}
+
+// preflight warm-starts steps before an execution is flipped to
+// Running: it looks up each step's Task, resolves its config (decrypting
+// it the same way runStep would) and, if the Task implements
+// Validatable, runs Validate against the resolved config. A step named
+// in faults is skipped, the same way runStep never touches its real Task
+// either, since its outcome is forced rather than actually run. Every
+// other step is checked regardless of earlier failures, so a long DAG
+// fails fast with every problem at once rather than stopping at the
+// first one found, and regardless of the order steps would actually run
+// in, since a config or signature problem in a later layer is just as
+// worth catching up front as one in the first.
+func (o *TaskOrchestrator) preflight(steps []model.TemplateStep, faults map[string]Fault) error {
+	var errs []*StepPreflightError
+	for _, step := range steps {
+		if _, faulted := faults[step.Name]; faulted {
+			continue
+		}
+		if err := o.preflightStep(step); err != nil {
+			errs = append(errs, &StepPreflightError{StepName: step.Name, Err: err})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &PreflightError{Errors: errs}
+}
+
+func (o *TaskOrchestrator) preflightStep(step model.TemplateStep) error {
+	task, ok := o.registry.Lookup(step.Type)
+	if !ok {
+		return fmt.Errorf("no task registered for type %q", step.Type)
+	}
+	if missing := missingCapabilities(task, step.RequiredCapabilities); len(missing) > 0 {
+		return fmt.Errorf("no task registered for type %q satisfies required capabilities: %s", step.Type, strings.Join(missing, ", "))
+	}
+	if err := o.verifyStepSignature(step); err != nil {
+		return err
+	}
+	config, err := o.decryptConfig(step.Config)
+	if err != nil {
+		return err
+	}
+	if validator, ok := task.(Validatable); ok {
+		return validator.Validate(config)
+	}
+	return nil
+}
+
+// StatusPersistenceError reports that RunSimulated's closing StatusWriter
+// Flush still failed after every retry the writer attempted in the
+// background while the run was in progress, so execution's step history
+// in the database may be stale or incomplete even though the run itself
+// genuinely finished. Callers can use errors.As to alert on this
+// distinctly from a normal step failure, since it means the database
+// was unreachable rather than that anything about the workflow went
+// wrong.
+type StatusPersistenceError struct {
+	ExecutionID uint
+	Err         error
+}
+
+func (e *StatusPersistenceError) Error() string {
+	return fmt.Sprintf("execution %d: step status updates could not be persisted: %s", e.ExecutionID, e.Err)
+}
+
+func (e *StatusPersistenceError) Unwrap() error {
+	return e.Err
+}
+
+// NodeResult is one step's terminal state from a RunSimulated call. A
+// step that never ran because a dependency aborted has Skipped set and
+// carries no Error; a step that ran and returned an error has neither
+// Skipped nor Error empty.
+type NodeResult struct {
+	Status  model.ExecutionStatus `json:"status"`
+	Skipped bool                  `json:"skipped,omitempty"`
+	Error   string                `json:"error,omitempty"`
+}
+
+// RunResult reports RunSimulated's per-node outcome instead of
+// collapsing every failure into a single message, so callers can tell a
+// step that actually failed apart from one merely skipped because a
+// dependency failed. It implements error so RunSimulated's existing
+// signature is unchanged; callers that need the detail can recover it
+// with errors.As.
+type RunResult struct {
+	ExecutionID uint
+	Nodes       map[string]NodeResult
+}
+
+// Error summarizes the run for callers that only log err.Error().
+func (r *RunResult) Error() string {
+	return fmt.Sprintf("execution %d: %s", r.ExecutionID, r.summary())
+}
+
+// summary describes which steps failed or were skipped, without the
+// execution ID, so that identical failures across different executions
+// (e.g. the same flaky remediation step failing on every run) produce
+// the same notification text and dedup naturally through a
+// notify.RateLimitedNotifier, instead of every execution's unique ID
+// making them look like distinct failures.
+func (r *RunResult) summary() string {
+	names := make([]string, 0, len(r.Nodes))
+	for name, node := range r.Nodes {
+		if node.Status == model.StatusFailed || node.Skipped {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("%d step(s) failed or were skipped: %s", len(names), strings.Join(names, ", "))
+}
+
+// Errors returns one error per step that actually failed, in a stable
+// order by step name. Skipped steps have no error of their own to
+// report; they failed only because a dependency did.
+func (r *RunResult) Errors() []error {
+	names := make([]string, 0, len(r.Nodes))
+	for name, node := range r.Nodes {
+		if node.Status == model.StatusFailed && !node.Skipped {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("step %s: %s", name, r.Nodes[name].Error))
+	}
+	return errs
+}
+
+// acquireRun reserves hash for the caller if no run with that hash is
+// already in flight, returning false if one is.
+func (o *TaskOrchestrator) acquireRun(hash string) bool {
+	o.runningMu.Lock()
+	defer o.runningMu.Unlock()
+	if o.running == nil {
+		o.running = make(map[string]struct{})
+	}
+	if _, ok := o.running[hash]; ok {
+		return false
+	}
+	o.running[hash] = struct{}{}
+	return true
+}
+
+// ActiveStep is one step currently executing its Task, for a debug view
+// of exactly which nodes every worker in this orchestrator is running
+// right now, as opposed to LiveDAG's database-backed (and therefore
+// slightly lagged, per StatusWriter's batching) view of one execution.
+type ActiveStep struct {
+	ExecutionID uint      `json:"execution_id"`
+	StepName    string    `json:"step_name"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// ActiveSteps returns every step currently executing its Task across
+// every execution this orchestrator is driving, in no particular order.
+func (o *TaskOrchestrator) ActiveSteps() []ActiveStep {
+	o.activeMu.Lock()
+	defer o.activeMu.Unlock()
+	var steps []ActiveStep
+	for executionID, byName := range o.active {
+		for name, step := range byName {
+			steps = append(steps, ActiveStep{ExecutionID: executionID, StepName: name, StartedAt: step.startedAt})
+		}
+	}
+	return steps
+}
+
+// ForceFailStep cancels the run context of executionID's step name if
+// it's currently active, failing it the same way a hard per-step
+// timeout would: runStep sees ctx.Err() set, records an
+// EventNodeHardTimeout/EventNodeFailed pair, and transitions it to
+// StatusFailed through the normal finishStep path on the goroutine
+// already running it, rather than this caller mutating its status
+// directly. It reports whether a running step by that name was found.
+func (o *TaskOrchestrator) ForceFailStep(executionID uint, name string) bool {
+	o.activeMu.Lock()
+	step, ok := o.active[executionID][name]
+	o.activeMu.Unlock()
+	if !ok {
+		return false
+	}
+	step.cancel()
+	return true
+}
+
+// trackStepStart records that executionID's step name started running
+// and how to cancel its run context, for ActiveSteps and ForceFailStep.
+func (o *TaskOrchestrator) trackStepStart(executionID uint, name string, startedAt time.Time, cancel context.CancelFunc) {
+	o.activeMu.Lock()
+	defer o.activeMu.Unlock()
+	if o.active == nil {
+		o.active = make(map[uint]map[string]activeStep)
+	}
+	if o.active[executionID] == nil {
+		o.active[executionID] = make(map[string]activeStep)
+	}
+	o.active[executionID][name] = activeStep{startedAt: startedAt, cancel: cancel}
+}
+
+// trackStepEnd removes the bookkeeping trackStepStart added once
+// executionID's step name has finished running, regardless of outcome.
+func (o *TaskOrchestrator) trackStepEnd(executionID uint, name string) {
+	o.activeMu.Lock()
+	defer o.activeMu.Unlock()
+	delete(o.active[executionID], name)
+	if len(o.active[executionID]) == 0 {
+		delete(o.active, executionID)
+	}
+}
+
+// releaseRun frees hash so a later run with the same definition can proceed.
+func (o *TaskOrchestrator) releaseRun(hash string) {
+	o.runningMu.Lock()
+	defer o.runningMu.Unlock()
+	delete(o.running, hash)
+}
+
+// precreateStepExecutions inserts a pending StepExecution row for every
+// node in layer in a single CreateInBatches call, instead of one INSERT
+// per node, and returns them keyed by step name. Each row's Attempt is
+// one past the highest Attempt already recorded for that step name
+// under executionID, so a step rerun via Resume/RerunStage/RerunStep
+// gets a fresh, correctly numbered row instead of colliding with its
+// earlier attempt.
+func (o *TaskOrchestrator) precreateStepExecutions(executionID uint, layer []*dag.Node) (map[string]*model.StepExecution, error) {
+	names := make([]string, len(layer))
+	for i, node := range layer {
+		names[i] = node.Name
+	}
+	priorAttempts, err := o.latestAttempts(executionID, names)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*model.StepExecution, len(layer))
+	for i, node := range layer {
+		rows[i] = &model.StepExecution{
+			ExecutionID:         executionID,
+			StepName:            node.Name,
+			Attempt:             priorAttempts[node.Name] + 1,
+			Status:              model.StatusPending,
+			Hostname:            o.environment.Hostname,
+			OS:                  o.environment.OS,
+			Arch:                o.environment.Arch,
+			OrchestratorVersion: o.environment.OrchestratorVersion,
+			EnvFingerprint:      o.environment.EnvFingerprint,
+		}
+	}
+	if err := CreateStepExecutions(o.db, rows); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*model.StepExecution, len(rows))
+	for _, row := range rows {
+		byName[row.StepName] = row
+	}
+	return byName, nil
+}
+
+// latestAttempts returns, for each of names, the highest Attempt already
+// recorded for executionID under that step name, or 0 if it has never
+// run. A single GROUP BY query regardless of how many names are asked
+// for, since a layer can contain many steps.
+func (o *TaskOrchestrator) latestAttempts(executionID uint, names []string) (map[string]int, error) {
+	var rows []struct {
+		StepName string
+		Max      int
+	}
+	if err := o.db.Model(&model.StepExecution{}).
+		Select("step_name, max(attempt) as max").
+		Where("execution_id = ? AND step_name IN ?", executionID, names).
+		Group("step_name").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	attempts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		attempts[row.StepName] = row.Max
+	}
+	return attempts, nil
+}
+
+// Run executes every step of template against execution, updating
+// execution and its StepExecution rows as it goes, and returns the first
+// error encountered (if any). Steps within a DAG layer run concurrently;
+// a failing step aborts the layers that depend on it but lets sibling
+// steps in the same layer finish.
+func (o *TaskOrchestrator) Run(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep) error {
+	return o.run(ctx, execution, steps, nil, ChaosConfig{})
+}
+
+// RunWithSLA behaves like Run, but additionally marks execution Late and
+// notifies via the orchestrator's Notifier if its runtime exceeds
+// slaSeconds, even though it still returns the same result Run would
+// have. slaSeconds of zero or less disables SLA tracking.
+func (o *TaskOrchestrator) RunWithSLA(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep, slaSeconds int64) error {
+	runErr := o.Run(ctx, execution, steps)
+	o.checkSLA(ctx, execution, slaSeconds)
+	return runErr
+}
+
+// RunWithChaos behaves like RunWithSLA, except every step not already
+// named in the template's own faults (there are none here; RunSimulated
+// is the entrypoint for those) is subject to chaos's randomized fault
+// injection, so operators can verify failure policies, retries and
+// recovery against unpredictable failures instead of only the fixed
+// scenarios RunSimulated's faults describe. A zero ChaosConfig behaves
+// exactly like RunWithSLA.
+func (o *TaskOrchestrator) RunWithChaos(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep, slaSeconds int64, chaos ChaosConfig) error {
+	runErr := o.run(ctx, execution, steps, nil, chaos)
+	o.checkSLA(ctx, execution, slaSeconds)
+	return runErr
+}
+
+// checkSLA marks execution Late and fires a notification if its runtime
+// exceeded slaSeconds. It is best-effort: a failure to persist or send
+// the notification is logged by the Notifier, not returned, since an
+// SLA breach should never turn a completed execution into an error.
+func (o *TaskOrchestrator) checkSLA(ctx context.Context, execution *model.WorkflowExecution, slaSeconds int64) {
+	if slaSeconds <= 0 || execution.StartedAt == nil || execution.FinishedAt == nil {
+		return
+	}
+	elapsed := execution.FinishedAt.Sub(*execution.StartedAt)
+	if elapsed <= time.Duration(slaSeconds)*time.Second {
+		return
+	}
+
+	execution.Late = true
+	result := o.db.Model(execution).Update("late", true)
+	if result.Error == nil && result.RowsAffected > 0 {
+		execution.BumpVersion()
+	}
+
+	message := fmt.Sprintf("execution %d exceeded its SLA of %ds (took %s)", execution.ID, slaSeconds, elapsed)
+	o.recordEvent(execution.ID, "", model.EventExecutionLate, message)
+	o.notifier.Notify(ctx, message)
+}
+
+// RunSimulated behaves like Run, except any step named in faults is not
+// handed to its real Task: instead its outcome is determined by the
+// matching Fault, so template authors can validate failure policies and
+// compensation paths without touching real systems.
+//
+// A running step can also grow the DAG itself by calling
+// AddDynamicSteps, e.g. a discovery step deciding what remediation to
+// run next: once its layer finishes, the newly added steps are merged
+// into the remaining schedule and picked up like any other step.
+func (o *TaskOrchestrator) RunSimulated(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep, faults map[string]Fault) error {
+	return o.run(ctx, execution, steps, faults, ChaosConfig{})
+}
+
+// run is Run, RunSimulated and RunWithChaos's shared implementation.
+// faults forces specific steps' outcomes (RunSimulated's use case);
+// chaos randomizes everyone else's (RunWithChaos's). A step named in
+// faults is never subject to chaos, so a template author's explicit
+// scenario always wins over a random one.
+func (o *TaskOrchestrator) run(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep, faults map[string]Fault, chaosCfg ChaosConfig) error {
+	graph, err := dag.Build(steps)
+	if err != nil {
+		return err
+	}
+	layers, err := graph.Layers()
+	if err != nil {
+		return err
+	}
+
+	hash := graph.StructuralHash()
+	if o.duplicateGuard {
+		if !o.acquireRun(hash) {
+			return &DuplicateRunError{Hash: hash}
+		}
+		defer o.releaseRun(hash)
+	}
+
+	if err := o.preflight(steps, faults); err != nil {
+		return err
+	}
+
+	startEvent, startMessage := model.EventExecutionStarted, "execution started"
+	if execution.Status == model.StatusFailed {
+		startEvent, startMessage = model.EventExecutionResumed, "execution resumed"
+	}
+	if err := o.transitionExecution(ctx, execution, model.StatusRunning, startEvent, startMessage); err != nil {
+		engineLog.With("execution_id", execution.ID).Errorf("failed to transition to running, aborting run: %v", err)
+		return err
+	}
+
+	now := o.clock.Now()
+	execution.StartedAt = &now
+	execution.DAGHash = hash
+	if err := o.saveExecution(execution); err != nil {
+		engineLog.With("execution_id", execution.ID).Errorf("failed to save start of run, aborting: %v", err)
+		return err
+	}
+
+	o.fireExecutionStart(ctx, execution)
+
+	chaos := newChaosRoller(chaosCfg)
+
+	statusWriter := NewStatusWriter(o.db, DefaultBatchSize, DefaultFlushInterval, o.notifier, o.statusJournalPathFor(execution))
+	statusWriter.chaos = chaos
+	defer statusWriter.Close()
+
+	// A nil sem never blocks a send on it below; that's how a
+	// MaxParallel of zero or less means "unbounded" without needing a
+	// separate branch in the dispatch loop.
+	var sem chan struct{}
+	if execution.MaxParallel > 0 {
+		sem = make(chan struct{}, execution.MaxParallel)
+	}
+
+	allSteps := append([]model.TemplateStep(nil), steps...)
+	remainingLayers := layers
+	done := make(map[string]bool, len(allSteps))
+	failed := make(map[string]bool)
+	outputs := make(map[string]string)
+	nodes := make(map[string]NodeResult)
+	var mu sync.Mutex
+	for len(remainingLayers) > 0 {
+		layer := remainingLayers[0]
+		remainingLayers = remainingLayers[1:]
+
+		rows, err := o.precreateStepExecutions(execution.ID, layer)
+		if err != nil {
+			return err
+		}
+
+		var newSteps []model.TemplateStep
+		layerCtx := withDynamicSteps(withStepOutputs(ctx, outputs), func(added []model.TemplateStep) {
+			mu.Lock()
+			newSteps = append(newSteps, added...)
+			mu.Unlock()
+		})
+		var wg sync.WaitGroup
+		for _, node := range layer {
+			node := node
+			se := rows[node.Name]
+			blocked := false
+			for _, dep := range node.DependsOn {
+				if failed[dep] {
+					blocked = true
+					break
+				}
+			}
+			if blocked {
+				if err := o.skipStep(statusWriter, se); err != nil {
+					return err
+				}
+				mu.Lock()
+				failed[node.Name] = true
+				nodes[node.Name] = NodeResult{Status: model.StatusSkipped, Skipped: true}
+				mu.Unlock()
+				continue
+			}
+			run := func() {
+				if sem != nil {
+					sem <- struct{}{}
+					defer func() { <-sem }()
+				}
+				fault := faults[node.Name]
+				var delay time.Duration
+				if _, faulted := faults[node.Name]; !faulted {
+					var chaosFault Fault
+					delay, chaosFault = chaos.rollStep()
+					if chaosFault.Mode != "" {
+						fault = chaosFault
+					}
+				}
+				if delay > 0 {
+					select {
+					case <-o.clock.After(delay):
+					case <-layerCtx.Done():
+					}
+				}
+				runErr := o.runStep(layerCtx, statusWriter, se, node, fault, execution)
+				mu.Lock()
+				if runErr != nil {
+					failed[node.Name] = true
+					nodes[node.Name] = NodeResult{Status: model.StatusFailed, Error: runErr.Error()}
+				} else {
+					nodes[node.Name] = NodeResult{Status: se.Status}
+				}
+				mu.Unlock()
+			}
+			if o.deterministic {
+				run()
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				run()
+			}()
+		}
+		wg.Wait()
+		layerNodes := make(map[string]NodeResult, len(layer))
+		for _, node := range layer {
+			outputs[node.Name] = rows[node.Name].Output
+			done[node.Name] = true
+			layerNodes[node.Name] = nodes[node.Name]
+		}
+		o.fireLayerComplete(ctx, execution, layerNodes)
+
+		if len(newSteps) == 0 {
+			continue
+		}
+		allSteps = append(allSteps, newSteps...)
+		graph, err := dag.Build(allSteps)
+		if err != nil {
+			return fmt.Errorf("dynamic steps: %w", err)
+		}
+		fullLayers, err := graph.Layers()
+		if err != nil {
+			return fmt.Errorf("dynamic steps: %w", err)
+		}
+		remainingLayers = remainingLayers[:0]
+		for _, l := range fullLayers {
+			var pending []*dag.Node
+			for _, n := range l {
+				if !done[n.Name] {
+					pending = append(pending, n)
+				}
+			}
+			if len(pending) > 0 {
+				remainingLayers = append(remainingLayers, pending)
+			}
+		}
+	}
+	if err := statusWriter.Flush(); err != nil {
+		persistErr := &StatusPersistenceError{ExecutionID: execution.ID, Err: err}
+		o.notifier.Notify(ctx, persistErr.Error())
+		// Best-effort: if the database is genuinely unreachable this
+		// also fails, but if Flush failed on something narrower (e.g. a
+		// single bad row) the execution record still ends up explaining
+		// why its step history stops short of the run's real outcome.
+		o.db.Model(execution).Update("error", persistErr.Error())
+		return persistErr
+	}
+
+	finished := o.clock.Now()
+	execution.FinishedAt = &finished
+	finalStatus := model.StatusSucceeded
+	if len(failed) > 0 {
+		finalStatus = model.StatusFailed
+		execution.Error = fmt.Sprintf("%d step(s) failed or were skipped", len(failed))
+	}
+	if err := o.transitionExecution(ctx, execution, finalStatus, model.EventExecutionFinished, string(finalStatus)); err != nil {
+		engineLog.With("execution_id", execution.ID).Errorf("failed to transition to final status %s: %v", finalStatus, err)
+		return err
+	}
+	if err := o.saveExecution(execution); err != nil {
+		engineLog.With("execution_id", execution.ID).Errorf("failed to save final status %s: %v", finalStatus, err)
+		return err
+	}
+
+	var runErr error
+	if len(failed) > 0 {
+		result := &RunResult{ExecutionID: execution.ID, Nodes: nodes}
+		runErr = result
+		if o.teamRouter != nil {
+			o.teamRouter.NotifyTeam(ctx, execution.OnCallRoutingKey, result.summary())
+		} else {
+			o.notifier.Notify(ctx, result.summary())
+		}
+	}
+	o.fireExecutionEnd(ctx, execution, runErr)
+	return runErr
+}
+
+// saveExecution persists execution with its current in-memory Version,
+// then bumps that Version to match what the row becomes in the database
+// on success, so a later save of the same execution is checked against
+// the right value instead of falsely conflicting with itself. Select("*")
+// is required here: plain Save falls back to an upsert when its update
+// affects no rows, which would silently defeat the optimistic lock.
+func (o *TaskOrchestrator) saveExecution(execution *model.WorkflowExecution) error {
+	result := o.db.Select("*").Save(execution)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrConcurrentUpdate
+	}
+	execution.BumpVersion()
+	return nil
+}
+
+// runStep, finishStep and skipStep each snapshot se before enqueueing it
+// with statusWriter and bump se's own Version immediately afterwards,
+// rather than waiting for the batched write to actually land. This keeps
+// the WHERE version=... condition each snapshot carries correct relative
+// to the one enqueued before it, even though StatusWriter may not have
+// flushed yet: the snapshot matches the database's current version, and
+// se is advanced to the version that snapshot's save will produce. A
+// real conflicting writer still causes StatusWriter.Flush to see
+// RowsAffected == 0 and fail the whole batch with model.ErrConcurrentUpdate.
+func (o *TaskOrchestrator) runStep(ctx context.Context, statusWriter *StatusWriter, se *model.StepExecution, node *dag.Node, fault Fault, execution *model.WorkflowExecution) error {
+	if err := model.ValidateTransition(se.Status, model.StatusRunning); err != nil {
+		return err
+	}
+	start := o.clock.Now()
+	se.Status = model.StatusRunning
+	se.StartedAt = &start
+	snapshot := *se
+	se.BumpVersion()
+	statusWriter.Enqueue(&snapshot)
+	o.saveNodeState(&snapshot)
+	o.recordEvent(se.ExecutionID, node.Name, model.EventNodeStarted, "")
+	ctx = withArtifactRecorder(ctx, o.artifactRecorderFor(ctx, se.ExecutionID, node.Name))
+	ctx = withExternalInputRecorder(ctx, o.externalInputRecorderFor(se.ExecutionID, node.Name))
+	ctx = withExternalWait(ctx, o.externalWaitFuncFor(statusWriter, se))
+	ctx = withExternalReminder(ctx, o.externalReminderFuncFor(se))
+	ctx = withRunAsUser(ctx, node.Step.RunAsUser)
+	ctx = withCostRecorder(ctx, func(cents int64) { se.CostCents += cents })
+	if o.sandbox != nil {
+		dir, err := o.sandbox.dirFor(se.ExecutionID, node.Name, se.Attempt)
+		if err != nil {
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, err.Error())
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", err.Error())
+		}
+		ctx = withWorkingDir(ctx, dir)
+		defer o.cleanupSandbox(dir, se.ExecutionID, node.Name)
+	}
+
+	ctx, cancel, stopEscalation := o.watchTimeouts(ctx, se.ExecutionID, node, execution)
+	defer cancel()
+	defer stopEscalation()
+
+	release, acquireErr := o.bulkhead.Acquire(ctx, execution.ID)
+	if acquireErr != nil {
+		o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, acquireErr.Error())
+		return o.finishStep(statusWriter, se, model.StatusFailed, "", acquireErr.Error())
+	}
+	defer release()
+
+	if node.Step.ConcurrencyKey != "" && o.locks != nil {
+		releaseKey, err := o.acquireConcurrencyKey(ctx, node, execution, se.ExecutionID)
+		if err != nil {
+			msg := fmt.Sprintf("concurrency key %q: %v", node.Step.ConcurrencyKey, err)
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, msg)
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", msg)
+		}
+		defer releaseKey()
+	}
+
+	o.trackStepStart(se.ExecutionID, node.Name, o.clock.Now(), cancel)
+	defer o.trackStepEnd(se.ExecutionID, node.Name)
+
+	var output string
+	var err error
+	agentStart := o.clock.Now()
+	if fault.Mode != "" {
+		output, err = fault.apply(ctx, o.clock)
+	} else {
+		task, ok := o.registry.Lookup(node.Step.Type)
+		if !ok {
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", fmt.Sprintf("no task registered for type %q", node.Step.Type))
+		}
+		if missing := missingCapabilities(task, node.Step.RequiredCapabilities); len(missing) > 0 {
+			msg := fmt.Sprintf("no task registered for type %q satisfies required capabilities: %s", node.Step.Type, strings.Join(missing, ", "))
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, msg)
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", msg)
+		}
+		if verifyErr := o.verifyStepSignature(node.Step); verifyErr != nil {
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, verifyErr.Error())
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", verifyErr.Error())
+		}
+		config, decryptErr := o.decryptConfig(node.Step.Config)
+		if decryptErr != nil {
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, decryptErr.Error())
+			return o.finishStep(statusWriter, se, model.StatusFailed, "", decryptErr.Error())
+		}
+		output, err = task.Run(ctx, config)
+	}
+	se.AgentRuntimeSeconds = o.clock.Now().Sub(agentStart).Seconds()
+	output = o.redact(execution.Project, output)
+	if err != nil {
+		errMsg := o.redact(execution.Project, err.Error())
+		if ctx.Err() != nil {
+			o.recordEvent(se.ExecutionID, node.Name, model.EventNodeHardTimeout, errMsg)
+		}
+		o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFailed, errMsg)
+		return o.finishStep(statusWriter, se, model.StatusFailed, output, errMsg)
+	}
+	o.recordEvent(se.ExecutionID, node.Name, model.EventNodeFinished, "")
+	return o.finishStep(statusWriter, se, model.StatusSucceeded, output, "")
+}
+
+// redact scrubs text for project under o.redactor, or returns it
+// unchanged if the orchestrator has no Redactor configured.
+func (o *TaskOrchestrator) redact(project, text string) string {
+	if o.redactor == nil || text == "" {
+		return text
+	}
+	return o.redactor.Redact(project, text)
+}
+
+// verifyStepSignature checks step's ConfigSignature against its
+// current definition under o.verifier, a no-op if the orchestrator has
+// no Signer configured.
+func (o *TaskOrchestrator) verifyStepSignature(step model.TemplateStep) error {
+	if o.verifier == nil {
+		return nil
+	}
+	return o.verifier.Verify(step)
+}
+
+// decryptConfig returns raw decrypted under o.crypt, or unchanged if
+// the orchestrator has no KeyRing configured, so field encryption stays
+// opt-in and symmetric with whether service.TemplateService encrypted
+// it in the first place.
+func (o *TaskOrchestrator) decryptConfig(raw string) (string, error) {
+	if o.crypt == nil {
+		return raw, nil
+	}
+	return o.crypt.Decrypt(raw)
+}
+
+// stepTimeouts resolves node's effective soft and hard timeout, in
+// seconds: the step's own override if set, else execution's policy.
+// Zero means that tier is disabled.
+func stepTimeouts(node *dag.Node, execution *model.WorkflowExecution) (soft, hard int64) {
+	soft, hard = execution.SoftTimeoutSeconds, execution.HardTimeoutSeconds
+	if node.Step.SoftTimeoutSeconds != 0 {
+		soft = node.Step.SoftTimeoutSeconds
+	}
+	if node.Step.HardTimeoutSeconds != 0 {
+		hard = node.Step.HardTimeoutSeconds
+	}
+	return soft, hard
+}
+
+// DefaultConcurrencyLockTTL bounds how long a step's ConcurrencyKey lock
+// can go unrenewed before it lapses when neither the step nor its
+// execution set a hard timeout to derive a tighter one from.
+const DefaultConcurrencyLockTTL = time.Hour
+
+// acquireConcurrencyKey blocks until node's ConcurrencyKey can be held
+// exclusively against o.locks, or ctx is done, or the step's own
+// ConcurrencyTimeoutSeconds elapses first if it set one. Once held, the
+// lock is renewed by a lock.Session for as long as the step keeps
+// running, so a step without a hard timeout that legitimately outlives
+// the lock's TTL doesn't lose it mid-run to a second execution's step
+// with the same key. The TTL is the step's effective hard timeout if it
+// has one, else DefaultConcurrencyLockTTL; it only bounds how long the
+// key stays locked after a process dies mid-step without the chance to
+// call release, since a dead process's Session stops renewing along
+// with it.
+func (o *TaskOrchestrator) acquireConcurrencyKey(ctx context.Context, node *dag.Node, execution *model.WorkflowExecution, executionID uint) (release func(), err error) {
+	waitCtx := ctx
+	if node.Step.ConcurrencyTimeoutSeconds > 0 {
+		var cancelWait context.CancelFunc
+		waitCtx, cancelWait = context.WithTimeout(ctx, time.Duration(node.Step.ConcurrencyTimeoutSeconds)*time.Second)
+		defer cancelWait()
+	}
+
+	key := fmt.Sprintf("step-concurrency:%s", node.Step.ConcurrencyKey)
+	owner := fmt.Sprintf("%d/%s", executionID, node.Name)
+	_, hard := stepTimeouts(node, execution)
+	ttl := DefaultConcurrencyLockTTL
+	if hard > 0 {
+		ttl = time.Duration(hard) * time.Second
+	}
+
+	session := lock.NewSession(o.locks, owner, ttl)
+	if err := session.Acquire(waitCtx, key); err != nil {
+		session.Close(context.Background())
+		return nil, err
+	}
+	return func() { session.Close(context.Background()) }, nil
+}
+
+// watchTimeouts arranges node's soft and hard timeout escalation: at the
+// soft timeout it records a warning event and runs node's diagnostic
+// hook, if configured, without affecting the step itself; at the hard
+// timeout it cancels the context returned for the caller to run the
+// step's Task against. Both timers are driven by o.clock rather than
+// real time, so tests can exercise them with a clock.Fake. The returned
+// stop func must be called once the step has finished, to stop a soft
+// timer that never fired from leaking past the step's own lifetime.
+func (o *TaskOrchestrator) watchTimeouts(ctx context.Context, executionID uint, node *dag.Node, execution *model.WorkflowExecution) (runCtx context.Context, cancel context.CancelFunc, stop func()) {
+	soft, hard := stepTimeouts(node, execution)
+	runCtx, cancel = context.WithCancel(ctx)
+	done := make(chan struct{})
+	stop = func() { close(done) }
+
+	if hard > 0 {
+		go func() {
+			select {
+			case <-o.clock.After(time.Duration(hard) * time.Second):
+				cancel()
+			case <-done:
+			}
+		}()
+	}
+	if soft > 0 {
+		go func() {
+			select {
+			case <-o.clock.After(time.Duration(soft) * time.Second):
+				o.escalateSoftTimeout(ctx, execution, node, soft)
+			case <-done:
+			}
+		}()
+	}
+	return runCtx, cancel, stop
+}
+
+// escalateSoftTimeout records node's soft timeout warning and, if it
+// configures one, runs its diagnostic hook. The diagnostic's own
+// outcome is only ever recorded as an event; it can't fail or affect
+// the step it's diagnosing.
+func (o *TaskOrchestrator) escalateSoftTimeout(ctx context.Context, execution *model.WorkflowExecution, node *dag.Node, soft int64) {
+	executionID := execution.ID
+	message := fmt.Sprintf("step %q has been running for more than %ds", node.Name, soft)
+	o.recordEvent(executionID, node.Name, model.EventNodeSoftTimeout, message)
+	o.notifier.Notify(ctx, message)
+
+	if node.Step.DiagnosticType == "" {
+		return
+	}
+	diagnostic, ok := o.registry.Lookup(node.Step.DiagnosticType)
+	if !ok {
+		o.recordEvent(executionID, node.Name, model.EventNodeSoftTimeout, fmt.Sprintf("diagnostic type %q is not registered", node.Step.DiagnosticType))
+		return
+	}
+	diagnosticConfig, err := o.decryptConfig(node.Step.DiagnosticConfig)
+	if err != nil {
+		o.recordEvent(executionID, node.Name, model.EventNodeSoftTimeout, fmt.Sprintf("diagnostic config: %s", err.Error()))
+		return
+	}
+	output, err := diagnostic.Run(ctx, diagnosticConfig)
+	if err != nil {
+		o.recordEvent(executionID, node.Name, model.EventNodeSoftTimeout, o.redact(execution.Project, fmt.Sprintf("diagnostic failed: %s", err.Error())))
+		return
+	}
+	o.recordEvent(executionID, node.Name, model.EventNodeSoftTimeout, o.redact(execution.Project, fmt.Sprintf("diagnostic output: %s", output)))
+}
+
+// saveNodeState mirrors snapshot into o.store when it might be
+// something other than the db StatusWriter already writes this same
+// update to: the default GORMStateStore over db would just redo the
+// same write a second time for no benefit, so this is a no-op unless
+// WithStateStore configured a different store. Errors are swallowed,
+// same as recordEvent: a custom StateStore mirroring engine state for
+// tests or a future backend isn't allowed to fail the step it's
+// observing.
+func (o *TaskOrchestrator) saveNodeState(snapshot *model.StepExecution) {
+	if !o.customStore {
+		return
+	}
+	o.store.SaveNodeState(context.Background(), snapshot)
+}
+
+func (o *TaskOrchestrator) finishStep(statusWriter *StatusWriter, se *model.StepExecution, status model.ExecutionStatus, output, errMsg string) error {
+	if err := model.ValidateTransition(se.Status, status); err != nil {
+		return err
+	}
+	finished := o.clock.Now()
+	se.Status = status
+	se.Output = output
+	se.Error = errMsg
+	se.FinishedAt = &finished
+	snapshot := *se
+	se.BumpVersion()
+	statusWriter.Enqueue(&snapshot)
+	o.saveNodeState(&snapshot)
+	if status == model.StatusFailed {
+		return fmt.Errorf("step %q: %s", se.StepName, errMsg)
+	}
+	return nil
+}
+
+func (o *TaskOrchestrator) skipStep(statusWriter *StatusWriter, se *model.StepExecution) error {
+	if err := model.ValidateTransition(se.Status, model.StatusSkipped); err != nil {
+		return err
+	}
+	now := o.clock.Now()
+	se.Status = model.StatusSkipped
+	se.StartedAt = &now
+	se.FinishedAt = &now
+	snapshot := *se
+	se.BumpVersion()
+	statusWriter.Enqueue(&snapshot)
+	o.saveNodeState(&snapshot)
+	o.recordEvent(se.ExecutionID, se.StepName, model.EventNodeSkipped, "upstream dependency failed")
+	return nil
+}
+
+// transitionExecution validates and applies a WorkflowExecution status
+// change, recording eventType with message if the transition is legal.
+// It rejects the change outright rather than applying it if the state
+// machine in model.ValidateTransition forbids it.
+func (o *TaskOrchestrator) transitionExecution(ctx context.Context, execution *model.WorkflowExecution, next model.ExecutionStatus, eventType model.ExecutionEventType, message string) error {
+	if err := model.ValidateTransition(execution.Status, next); err != nil {
+		return err
+	}
+	from := execution.Status
+	execution.Status = next
+	o.recordEvent(execution.ID, "", eventType, message)
+	o.fireStateTransition(ctx, execution, from, next)
+	return nil
+}
+
+// cleanupSandbox disposes of dir, a step's Sandbox working directory,
+// once the step has finished. Without o.sandbox.Retain set it's simply
+// removed. With it set, every regular file left in it is persisted as an
+// artifact of stepName via artifactRecorderFor before dir is removed, if
+// the orchestrator has an artifact.Store configured; without one, dir is
+// left on disk for manual inspection instead, since there's nowhere to
+// persist its contents to. It runs against a background context rather
+// than the step's own, which may already be cancelled by the time this
+// is deferred.
+func (o *TaskOrchestrator) cleanupSandbox(dir string, executionID uint, stepName string) {
+	if !o.sandbox.Retain {
+		os.RemoveAll(dir)
+		return
+	}
+	if o.artifacts == nil {
+		return
+	}
+	recorder := o.artifactRecorderFor(context.Background(), executionID, stepName)
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, relErr := filepath.Rel(dir, path)
+		if relErr != nil {
+			return nil
+		}
+		recorder(rel, path)
+		return nil
+	})
+	os.RemoveAll(dir)
+}
+
+// artifactRecorderFor builds the artifactRecorder a step's Task sees via
+// RecordArtifact: it uploads the file at localPath to o.artifacts under a
+// key namespaced by execution and step, then persists a model.Artifact
+// row describing it.
+func (o *TaskOrchestrator) artifactRecorderFor(ctx context.Context, executionID uint, stepName string) artifactRecorder {
+	return func(name, localPath string) (*model.Artifact, error) {
+		if o.artifacts == nil {
+			return nil, fmt.Errorf("record artifact %q: no artifact store configured", name)
+		}
+		file, err := os.Open(localPath)
+		if err != nil {
+			return nil, fmt.Errorf("record artifact %q: %w", name, err)
+		}
+		defer file.Close()
+
+		key := fmt.Sprintf("%d/%s/%s", executionID, stepName, name)
+		size, err := o.artifacts.Put(ctx, key, file)
+		if err != nil {
+			return nil, fmt.Errorf("record artifact %q: %w", name, err)
+		}
+
+		art := &model.Artifact{
+			ExecutionID: executionID,
+			StepName:    stepName,
+			Name:        name,
+			Size:        size,
+			StorageKey:  key,
+		}
+		if err := o.db.Create(art).Error; err != nil {
+			return nil, fmt.Errorf("record artifact %q: %w", name, err)
+		}
+		return art, nil
+	}
+}
+
+// externalInputRecorderFor builds the externalInputRecorder a step's
+// Task sees via RecordExternalInput: it persists a model.ExternalInput
+// row describing the named value the step consumed.
+func (o *TaskOrchestrator) externalInputRecorderFor(executionID uint, stepName string) externalInputRecorder {
+	return func(name, value string) error {
+		input := &model.ExternalInput{
+			ExecutionID: executionID,
+			StepName:    stepName,
+			Name:        name,
+			Value:       value,
+		}
+		if err := o.db.Create(input).Error; err != nil {
+			return fmt.Errorf("record external input %q: %w", name, err)
+		}
+		return nil
+	}
+}
+
+// externalWaitFuncFor builds the externalWaitFunc an ExternalTask's Run
+// calls to mark se pending-external and block until CompleteExternalStep
+// delivers a result. The transition to StatusPendingExternal happens here,
+// the first and only time Run calls it, rather than up front in runStep,
+// so a step whose Task never calls wait never touches that status at all.
+func (o *TaskOrchestrator) externalWaitFuncFor(statusWriter *StatusWriter, se *model.StepExecution) externalWaitFunc {
+	return func(ctx context.Context) (<-chan ExternalResult, func(), error) {
+		if err := model.ValidateTransition(se.Status, model.StatusPendingExternal); err != nil {
+			return nil, nil, err
+		}
+		token, result := o.externalWaiters.register(se.ID)
+		se.Status = model.StatusPendingExternal
+		snapshot := *se
+		se.BumpVersion()
+		statusWriter.Enqueue(&snapshot)
+		o.saveNodeState(&snapshot)
+
+		o.recordEvent(se.ExecutionID, se.StepName, model.EventNodeAwaitingExternal, fmt.Sprintf("awaiting external completion, token=%s", token))
+
+		cancel := func() { o.externalWaiters.unregister(se.ID) }
+		return result, cancel, nil
+	}
+}
+
+// externalReminderFuncFor builds the externalReminderFunc an ExternalTask's
+// Run calls each time its configured reminder interval elapses while the
+// step is still waiting to be completed.
+func (o *TaskOrchestrator) externalReminderFuncFor(se *model.StepExecution) externalReminderFunc {
+	return func(ctx context.Context) {
+		message := fmt.Sprintf("step %q is still awaiting external completion", se.StepName)
+		o.recordEvent(se.ExecutionID, se.StepName, model.EventNodeExternalReminder, message)
+		o.notifier.Notify(ctx, message)
+	}
+}
+
+// recordEvent appends an ExecutionEvent for the given execution, assigning
+// it the next monotonic sequence number for that execution. Failures to
+// persist an event are swallowed: the event stream is an audit aid, not a
+// gate on execution progress.
+func (o *TaskOrchestrator) recordEvent(executionID uint, stepName string, typ model.ExecutionEventType, message string) {
+	o.mu.Lock()
+	o.seq[executionID]++
+	seq := o.seq[executionID]
+	o.mu.Unlock()
+
+	event := &model.ExecutionEvent{
+		ExecutionID: executionID,
+		StepName:    stepName,
+		Type:        typ,
+		Message:     message,
+		Sequence:    seq,
+		CreatedAt:   o.clock.Now(),
+	}
+	o.store.AppendEvent(context.Background(), event)
+}
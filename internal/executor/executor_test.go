@@ -0,0 +1,126 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(
+		&model.WorkflowTemplate{},
+		&model.TemplateStep{},
+		&model.WorkflowExecution{},
+		&model.StepExecution{},
+		&model.ExecutionEvent{},
+		&model.Artifact{},
+		&model.ExternalInput{},
+		&model.RedactionRule{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestRunRecordsEventStream(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var events []model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ?", execution.ID).Order("sequence asc").Find(&events).Error; err != nil {
+		t.Fatalf("load events: %v", err)
+	}
+	if len(events) == 0 {
+		t.Fatal("expected events to be recorded")
+	}
+	if events[0].Type != model.EventExecutionStarted {
+		t.Fatalf("expected first event to be execution_started, got %s", events[0].Type)
+	}
+	last := events[len(events)-1]
+	if last.Type != model.EventExecutionFinished {
+		t.Fatalf("expected last event to be execution_finished, got %s", last.Type)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Sequence <= events[i-1].Sequence {
+			t.Fatalf("events not monotonically ordered at index %d", i)
+		}
+	}
+}
+
+func TestRunStampsStepExecutionsWithTheOrchestratorEnvironment(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}
+
+	env := Environment{Hostname: "replica-7", OS: "linux", Arch: "amd64", OrchestratorVersion: "1.2.3", EnvFingerprint: "abc"}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithEnvironment(env))
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var step model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&step).Error; err != nil {
+		t.Fatalf("load step: %v", err)
+	}
+	if step.Hostname != "replica-7" || step.OS != "linux" || step.Arch != "amd64" || step.OrchestratorVersion != "1.2.3" || step.EnvFingerprint != "abc" {
+		t.Fatalf("expected the step to be stamped with the orchestrator's environment, got %+v", step)
+	}
+}
+
+func TestRunSkipsDownstreamOfFailure(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "false"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to return an error")
+	}
+
+	var b model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "b").First(&b).Error; err != nil {
+		t.Fatalf("load step b: %v", err)
+	}
+	if b.Status != model.StatusSkipped {
+		t.Fatalf("expected step b to be skipped, got %s", b.Status)
+	}
+}
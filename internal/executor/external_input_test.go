@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunRecordsHTTPTaskResponseAsExternalInput(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	cfg, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "ping", Type: "http", Config: string(cfg)}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var inputs []model.ExternalInput
+	if err := gormDB.Where("execution_id = ?", execution.ID).Find(&inputs).Error; err != nil {
+		t.Fatalf("find external inputs: %v", err)
+	}
+	if len(inputs) != 1 {
+		t.Fatalf("expected exactly one external input, got %d", len(inputs))
+	}
+	if inputs[0].StepName != "ping" || inputs[0].Name != "response_body" || inputs[0].Value != "pong" {
+		t.Fatalf("unexpected external input row: %+v", inputs[0])
+	}
+}
+
+func TestRecordExternalInputIsNoopOutsideOrchestrator(t *testing.T) {
+	if err := RecordExternalInput(context.Background(), "response_body", "pong"); err != nil {
+		t.Fatalf("expected RecordExternalInput to be a no-op, got error: %v", err)
+	}
+}
+
+func TestReplayHTTPClientServesRecordedBodyInsteadOfARealRequest(t *testing.T) {
+	task := HTTPTask{Client: ReplayHTTPClient("recorded response")}
+	cfg, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: "http://example.invalid/should-not-be-dialed"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "recorded response" {
+		t.Fatalf("expected replayed body, got %q", output)
+	}
+}
@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// ExternalTaskConfig is the JSON shape expected in a step's Config for
+// ExternalTask.
+type ExternalTaskConfig struct {
+	// ReminderInterval, if positive, makes Run record an
+	// EventNodeExternalReminder event (and notify via the
+	// orchestrator's Notifier) every time it elapses while the step is
+	// still waiting to be completed. Zero disables reminders.
+	ReminderInterval time.Duration `json:"reminder_interval"`
+}
+
+// ExternalResult is what a caller hands back to CompleteExternalStep to
+// finish a StepExecution an ExternalTask left pending-external: Output
+// becomes the step's output on success, and a non-empty Error fails the
+// step with that message.
+type ExternalResult struct {
+	Output string
+	Error  string
+}
+
+// ExternalTask marks itself pending-external and blocks until a human
+// or third-party system completes it via the execution's
+// external-completion API (POST /executions/:id/steps/:stepId/complete),
+// or until ctx is cancelled, e.g. by the step's own hard timeout. It
+// relies entirely on hooks the TaskOrchestrator attaches to ctx rather
+// than on fields of its own, since a Task value is registered once,
+// before any execution (and so any StepExecution ID) exists.
+type ExternalTask struct {
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+// Run marks the step currently running pending-external, then waits for
+// it to be completed by CompleteExternalStep, for ctx to be cancelled,
+// or (if config sets reminder_interval) periodically records a reminder
+// event while it keeps waiting.
+func (t ExternalTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg ExternalTaskConfig
+	if config != "" {
+		if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+			return "", fmt.Errorf("external task: invalid config: %w", err)
+		}
+	}
+
+	wait, ok := externalWaitFromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("external task: not running under a TaskOrchestrator that supports external steps")
+	}
+	result, cancel, err := wait(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer cancel()
+
+	c := t.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+	var reminder <-chan time.Time
+	if cfg.ReminderInterval > 0 {
+		reminder = c.After(cfg.ReminderInterval)
+	}
+
+	for {
+		select {
+		case res := <-result:
+			if res.Error != "" {
+				return res.Output, fmt.Errorf("external task: %s", res.Error)
+			}
+			return res.Output, nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-reminder:
+			remind, ok := externalReminderFromContext(ctx)
+			if ok {
+				remind(ctx)
+			}
+			reminder = c.After(cfg.ReminderInterval)
+		}
+	}
+}
+
+// externalWaitFunc is attached to a step's context by the
+// TaskOrchestrator, letting ExternalTask mark that step pending-external
+// and block until CompleteExternalStep delivers a result, without
+// ExternalTask needing a reference to the orchestrator or its
+// ExternalWaiters registry. The returned cancel func must be called
+// exactly once, however Run returns, to unregister the wait.
+type externalWaitFunc func(ctx context.Context) (result <-chan ExternalResult, cancel func(), err error)
+
+type externalWaitKey struct{}
+
+func withExternalWait(ctx context.Context, wait externalWaitFunc) context.Context {
+	return context.WithValue(ctx, externalWaitKey{}, wait)
+}
+
+func externalWaitFromContext(ctx context.Context) (externalWaitFunc, bool) {
+	wait, ok := ctx.Value(externalWaitKey{}).(externalWaitFunc)
+	return wait, ok
+}
+
+// externalReminderFunc is attached to a step's context alongside
+// externalWaitFunc, letting ExternalTask record a reminder event (and
+// notify) each time its configured reminder_interval elapses.
+type externalReminderFunc func(ctx context.Context)
+
+type externalReminderKey struct{}
+
+func withExternalReminder(ctx context.Context, remind externalReminderFunc) context.Context {
+	return context.WithValue(ctx, externalReminderKey{}, remind)
+}
+
+func externalReminderFromContext(ctx context.Context) (externalReminderFunc, bool) {
+	remind, ok := ctx.Value(externalReminderKey{}).(externalReminderFunc)
+	return remind, ok
+}
+
+// ExternalWaiters tracks StepExecutions an ExternalTask has left
+// pending-external, keyed by StepExecution ID, so CompleteExternalStep
+// can find the right one and deliver its result. It's purely in-process
+// state: a restart loses track of anything still waiting, the same way
+// TaskOrchestrator's duplicate-run guard does.
+type ExternalWaiters struct {
+	mu      sync.Mutex
+	waiting map[uint]externalWaiter
+}
+
+type externalWaiter struct {
+	token  string
+	result chan ExternalResult
+}
+
+// NewExternalWaiters returns an empty ExternalWaiters.
+func NewExternalWaiters() *ExternalWaiters {
+	return &ExternalWaiters{waiting: make(map[uint]externalWaiter)}
+}
+
+// register starts tracking stepExecutionID, returning a random token
+// the caller must present to Complete and a channel that receives
+// exactly one ExternalResult once Complete succeeds.
+func (w *ExternalWaiters) register(stepExecutionID uint) (token string, result <-chan ExternalResult) {
+	ch := make(chan ExternalResult, 1)
+	token = generateExternalToken()
+
+	w.mu.Lock()
+	w.waiting[stepExecutionID] = externalWaiter{token: token, result: ch}
+	w.mu.Unlock()
+
+	return token, ch
+}
+
+// unregister stops tracking stepExecutionID, e.g. once its Task has
+// returned because it was completed, its context was cancelled, or it
+// was resolved some other way. It's a no-op if nothing is waiting on
+// stepExecutionID, so it's safe to call unconditionally on cleanup.
+func (w *ExternalWaiters) unregister(stepExecutionID uint) {
+	w.mu.Lock()
+	delete(w.waiting, stepExecutionID)
+	w.mu.Unlock()
+}
+
+// Complete delivers result to the ExternalTask waiting on
+// stepExecutionID, if token matches the one it was registered with. It
+// returns an error without delivering anything if stepExecutionID isn't
+// currently waiting (already completed, timed out, or never started)
+// or if token doesn't match.
+func (w *ExternalWaiters) Complete(stepExecutionID uint, token string, result ExternalResult) error {
+	w.mu.Lock()
+	waiter, ok := w.waiting[stepExecutionID]
+	if ok {
+		delete(w.waiting, stepExecutionID)
+	}
+	w.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("step %d is not awaiting external completion", stepExecutionID)
+	}
+	if waiter.token != token {
+		return fmt.Errorf("step %d: invalid completion token", stepExecutionID)
+	}
+	waiter.result <- result
+	return nil
+}
+
+// generateExternalToken returns a random 32-character hex token
+// identifying one external wait, so CompleteExternalStep can't be
+// guessed or replayed against the wrong step.
+func generateExternalToken() string {
+	const alphabet = "0123456789abcdef"
+	b := make([]byte, 32)
+	for i := range b {
+		b[i] = alphabet[rand.Intn(len(alphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestExternalWaitersCompleteDeliversResultOnce(t *testing.T) {
+	w := NewExternalWaiters()
+	token, result := w.register(1)
+
+	if err := w.Complete(1, token, ExternalResult{Output: "done"}); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+
+	select {
+	case res := <-result:
+		if res.Output != "done" {
+			t.Fatalf("expected output %q, got %q", "done", res.Output)
+		}
+	default:
+		t.Fatal("expected a result to be ready")
+	}
+
+	if err := w.Complete(1, token, ExternalResult{}); err == nil {
+		t.Fatal("expected a second Complete for the same step to fail")
+	}
+}
+
+func TestExternalWaitersCompleteRejectsWrongToken(t *testing.T) {
+	w := NewExternalWaiters()
+	_, _ = w.register(1)
+
+	if err := w.Complete(1, "wrong-token", ExternalResult{}); err == nil {
+		t.Fatal("expected an invalid token to be rejected")
+	}
+}
+
+func TestExternalWaitersCompleteRejectsUnknownStep(t *testing.T) {
+	w := NewExternalWaiters()
+	if err := w.Complete(99, "anything", ExternalResult{}); err == nil {
+		t.Fatal("expected an unknown step to be rejected")
+	}
+}
+
+func TestExternalWaitersUnregisterIsSafeOnAbsentStep(t *testing.T) {
+	w := NewExternalWaiters()
+	w.unregister(123)
+}
+
+func TestRunStepWithExternalTaskWaitsThenCompletes(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("external", ExternalTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "approve", Type: "external"}}
+	executionID := execution.ID
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	var se model.StepExecution
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := gormDB.Where("execution_id = ? AND step_name = ?", executionID, "approve").First(&se).Error; err == nil && se.Status == model.StatusPendingExternal {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for step to go pending-external")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	var event model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ? AND type = ?", executionID, model.EventNodeAwaitingExternal).First(&event).Error; err != nil {
+		t.Fatalf("expected a node_awaiting_external event: %v", err)
+	}
+
+	if err := orchestrator.CompleteExternalStep(se.ID, extractToken(event.Message), ExternalResult{Output: "approved"}); err != nil {
+		t.Fatalf("CompleteExternalStep: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected Run to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+
+	if err := gormDB.First(&se, se.ID).Error; err != nil {
+		t.Fatalf("reload step: %v", err)
+	}
+	if se.Status != model.StatusSucceeded || se.Output != "approved" {
+		t.Fatalf("expected succeeded/approved, got status=%s output=%q", se.Status, se.Output)
+	}
+}
+
+func TestRunStepWithExternalTaskFailsOnErrorResult(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("external", ExternalTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "approve", Type: "external"}}
+	executionID := execution.ID
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	var se model.StepExecution
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := gormDB.Where("execution_id = ? AND step_name = ?", executionID, "approve").First(&se).Error; err == nil && se.Status == model.StatusPendingExternal {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for step to go pending-external")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	var event model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ? AND type = ?", executionID, model.EventNodeAwaitingExternal).First(&event).Error; err != nil {
+		t.Fatalf("expected a node_awaiting_external event: %v", err)
+	}
+
+	if err := orchestrator.CompleteExternalStep(se.ID, extractToken(event.Message), ExternalResult{Error: "rejected"}); err != nil {
+		t.Fatalf("CompleteExternalStep: %v", err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected Run to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+}
+
+func TestRunStepWithExternalTaskSendsReminders(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	registry.Register("external", ExternalTask{Clock: fakeClock})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "approve", Type: "external", Config: `{"reminder_interval": 60000000000}`}}
+	executionID := execution.ID
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	var se model.StepExecution
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := gormDB.Where("execution_id = ? AND step_name = ?", executionID, "approve").First(&se).Error; err == nil && se.Status == model.StatusPendingExternal {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for step to go pending-external")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	fakeClock.Advance(time.Minute)
+
+	var event model.ExecutionEvent
+	reminderDeadline := time.After(2 * time.Second)
+	for {
+		if err := gormDB.Where("execution_id = ? AND type = ?", executionID, model.EventNodeExternalReminder).First(&event).Error; err == nil {
+			break
+		}
+		select {
+		case <-reminderDeadline:
+			t.Fatal("timed out waiting for a node_external_reminder event")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	var awaiting model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ? AND type = ?", executionID, model.EventNodeAwaitingExternal).First(&awaiting).Error; err != nil {
+		t.Fatalf("expected a node_awaiting_external event: %v", err)
+	}
+	if err := orchestrator.CompleteExternalStep(se.ID, extractToken(awaiting.Message), ExternalResult{Output: "ok"}); err != nil {
+		t.Fatalf("CompleteExternalStep: %v", err)
+	}
+	<-done
+}
+
+// extractToken pulls the token out of a node_awaiting_external event's
+// message, which is formatted as "awaiting external completion,
+// token=<token>".
+func extractToken(message string) string {
+	const prefix = "awaiting external completion, token="
+	if len(message) <= len(prefix) {
+		return ""
+	}
+	return message[len(prefix):]
+}
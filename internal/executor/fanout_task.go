@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/expr-lang/expr"
+)
+
+// FanOutTaskConfig is the JSON shape expected in a step's Config for
+// FanOutTask.
+type FanOutTaskConfig struct {
+	// ItemsExpression is evaluated with github.com/expr-lang/expr against
+	// an environment of outputs (predecessor step outputs, via
+	// StepOutputsFromContext) and vars (static values from the config),
+	// the same environment ScriptTask uses. It must produce either a
+	// list directly, or a string holding a JSON-encoded list, since a
+	// predecessor step's raw output is itself always a string.
+	ItemsExpression string `json:"items_expression"`
+	// InnerType is the Task type registered under this name that runs
+	// once per item.
+	InnerType string `json:"inner_type"`
+	// InnerConfigExpression is evaluated once per item, in an
+	// environment like ItemsExpression's plus item (the current list
+	// element), and produces the inner Task's Config for that instance.
+	InnerConfigExpression string            `json:"inner_config_expression"`
+	Vars                  map[string]string `json:"vars"`
+	// MaxFanOut caps how many instances run at once. Zero or less means
+	// unbounded (one goroutine per item).
+	MaxFanOut int `json:"max_fan_out"`
+}
+
+// fanOutResult is one instance's outcome, in ItemsExpression's order.
+type fanOutResult struct {
+	Item   interface{} `json:"item"`
+	Output string      `json:"output,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// FanOutTask expands into N parallel instances of another registered
+// Task at runtime, one per element of a list computed from the
+// execution's context (e.g. one HTTP call per host returned by a
+// previous step), aggregating every instance's output into its own
+// single output rather than growing the template's DAG by one node per
+// item, which would require knowing the item count ahead of time.
+type FanOutTask struct {
+	Registry *Registry
+}
+
+// Run evaluates config's ItemsExpression to get the list to fan out
+// over, then runs InnerType once per item concurrently (bounded by
+// MaxFanOut), returning a JSON-encoded list of fanOutResult in item
+// order. It returns an error if any instance failed, but still returns
+// every instance's result (including the ones that succeeded) as its
+// output, so a caller can see exactly which items failed.
+func (t FanOutTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg FanOutTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("fan-out task: invalid config: %w", err)
+	}
+	if cfg.InnerType == "" {
+		return "", fmt.Errorf("fan-out task: inner_type is required")
+	}
+	inner, ok := t.Registry.Lookup(cfg.InnerType)
+	if !ok {
+		return "", fmt.Errorf("fan-out task: unregistered inner_type %q", cfg.InnerType)
+	}
+
+	items, err := fanOutItems(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	results := make([]fanOutResult, len(items))
+	sem := make(chan struct{}, fanOutConcurrency(cfg.MaxFanOut, len(items)))
+	var wg sync.WaitGroup
+	for i, item := range items {
+		i, item := i, item
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = runFanOutInstance(ctx, inner, cfg, item)
+		}()
+	}
+	wg.Wait()
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return "", fmt.Errorf("fan-out task: encode results: %w", err)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return string(encoded), fmt.Errorf("fan-out task: %d of %d instance(s) failed", failed, len(results))
+	}
+	return string(encoded), nil
+}
+
+// fanOutConcurrency caps concurrent fan-out instances at maxFanOut, or
+// at total (effectively unbounded, since there's never more work than
+// that anyway) if maxFanOut is zero or less.
+func fanOutConcurrency(maxFanOut, total int) int {
+	if maxFanOut <= 0 || maxFanOut > total {
+		return total
+	}
+	return maxFanOut
+}
+
+// fanOutItems evaluates cfg.ItemsExpression and coerces its result into
+// a list: either directly, or by parsing it as a JSON-encoded list if
+// it came back as a string.
+func fanOutItems(ctx context.Context, cfg FanOutTaskConfig) ([]interface{}, error) {
+	if cfg.ItemsExpression == "" {
+		return nil, fmt.Errorf("fan-out task: items_expression is required")
+	}
+	env := map[string]interface{}{
+		"outputs": StepOutputsFromContext(ctx),
+		"vars":    cfg.Vars,
+	}
+	program, err := expr.Compile(cfg.ItemsExpression, expr.Env(env))
+	if err != nil {
+		return nil, fmt.Errorf("fan-out task: compile items_expression: %w", err)
+	}
+	result, err := expr.Run(program, env)
+	if err != nil {
+		return nil, fmt.Errorf("fan-out task: evaluate items_expression: %w", err)
+	}
+
+	switch v := result.(type) {
+	case []interface{}:
+		return v, nil
+	case string:
+		var items []interface{}
+		if err := json.Unmarshal([]byte(v), &items); err != nil {
+			return nil, fmt.Errorf("fan-out task: items_expression produced a string that isn't a JSON list: %w", err)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("fan-out task: items_expression must produce a list, got %T", result)
+	}
+}
+
+// runFanOutInstance evaluates cfg.InnerConfigExpression for item and
+// runs inner with the resulting config, never returning an error
+// itself: any failure is captured in the returned fanOutResult so one
+// instance's failure can't take down the others still running.
+func runFanOutInstance(ctx context.Context, inner Task, cfg FanOutTaskConfig, item interface{}) fanOutResult {
+	env := map[string]interface{}{
+		"outputs": StepOutputsFromContext(ctx),
+		"vars":    cfg.Vars,
+		"item":    item,
+	}
+	innerConfig, err := evalToConfigString(cfg.InnerConfigExpression, env)
+	if err != nil {
+		return fanOutResult{Item: item, Error: err.Error()}
+	}
+
+	output, err := inner.Run(ctx, innerConfig)
+	if err != nil {
+		return fanOutResult{Item: item, Output: output, Error: err.Error()}
+	}
+	return fanOutResult{Item: item, Output: output}
+}
+
+// evalToConfigString evaluates expression against env and stringifies
+// its result the same way ScriptTask does: a string result is passed
+// through verbatim, anything else is JSON-encoded.
+func evalToConfigString(expression string, env map[string]interface{}) (string, error) {
+	if expression == "" {
+		return "", fmt.Errorf("inner_config_expression is required")
+	}
+	program, err := expr.Compile(expression, expr.Env(env))
+	if err != nil {
+		return "", fmt.Errorf("compile inner_config_expression: %w", err)
+	}
+	result, err := expr.Run(program, env)
+	if err != nil {
+		return "", fmt.Errorf("evaluate inner_config_expression: %w", err)
+	}
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("encode inner_config_expression result: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+// countingTask runs calls, and returns an error for any item equal to
+// "bad" so FanOutTask's partial-failure aggregation can be exercised.
+type countingTask struct {
+	calls *int32
+}
+
+func (t countingTask) Run(ctx context.Context, config string) (string, error) {
+	atomic.AddInt32(t.calls, 1)
+	if config == "bad" {
+		return "", fmt.Errorf("instance failed")
+	}
+	return config, nil
+}
+
+func newFanOutRegistry(calls *int32) *Registry {
+	registry := NewRegistry()
+	registry.Register("counting", countingTask{calls: calls})
+	return registry
+}
+
+func TestFanOutTaskRunsOneInstancePerItem(t *testing.T) {
+	var calls int32
+	registry := newFanOutRegistry(&calls)
+	task := FanOutTask{Registry: registry}
+
+	config, _ := json.Marshal(FanOutTaskConfig{
+		ItemsExpression:       `["a", "b", "c"]`,
+		InnerType:             "counting",
+		InnerConfigExpression: "item",
+	})
+	output, err := task.Run(context.Background(), string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 instances run, got %d", calls)
+	}
+	var results []fanOutResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if len(results) != 3 || results[0].Item != "a" || results[0].Output != "a" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
+
+func TestFanOutTaskParsesAPredecessorsJSONStringOutputAsItems(t *testing.T) {
+	var calls int32
+	registry := newFanOutRegistry(&calls)
+	task := FanOutTask{Registry: registry}
+	ctx := withStepOutputs(context.Background(), map[string]string{"hosts": `["h1", "h2"]`})
+
+	config, _ := json.Marshal(FanOutTaskConfig{
+		ItemsExpression:       "outputs.hosts",
+		InnerType:             "counting",
+		InnerConfigExpression: "item",
+	})
+	if _, err := task.Run(ctx, string(config)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 instances run, got %d", calls)
+	}
+}
+
+func TestFanOutTaskReportsPartialFailures(t *testing.T) {
+	var calls int32
+	registry := newFanOutRegistry(&calls)
+	task := FanOutTask{Registry: registry}
+
+	config, _ := json.Marshal(FanOutTaskConfig{
+		ItemsExpression:       `["good", "bad"]`,
+		InnerType:             "counting",
+		InnerConfigExpression: "item",
+	})
+	output, err := task.Run(context.Background(), string(config))
+	if err == nil {
+		t.Fatal("expected an error summarizing the failed instance")
+	}
+	var results []fanOutResult
+	if err := json.Unmarshal([]byte(output), &results); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if len(results) != 2 || results[1].Error == "" {
+		t.Fatalf("expected the second result to record an error, got %+v", results)
+	}
+}
+
+func TestFanOutTaskRejectsUnregisteredInnerType(t *testing.T) {
+	task := FanOutTask{Registry: NewRegistry()}
+	config, _ := json.Marshal(FanOutTaskConfig{
+		ItemsExpression: `["a"]`,
+		InnerType:       "missing",
+	})
+	if _, err := task.Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for an unregistered inner_type")
+	}
+}
+
+func TestFanOutTaskHonorsMaxFanOut(t *testing.T) {
+	var current, peak int32
+	registry := NewRegistry()
+	registry.Register("tracking", concurrencyTrackingTask{current: &current, peak: &peak})
+	task := FanOutTask{Registry: registry}
+
+	config, _ := json.Marshal(FanOutTaskConfig{
+		ItemsExpression:       `["a", "b", "c", "d"]`,
+		InnerType:             "tracking",
+		InnerConfigExpression: "item",
+		MaxFanOut:             2,
+	})
+	if _, err := task.Run(context.Background(), string(config)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if peak > 2 {
+		t.Fatalf("expected at most 2 concurrent instances, got %d", peak)
+	}
+}
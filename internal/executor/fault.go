@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// FaultMode selects how a simulated step's outcome is forced.
+type FaultMode string
+
+const (
+	FaultForceFail    FaultMode = "fail"
+	FaultForceSucceed FaultMode = "succeed"
+	FaultDelay        FaultMode = "delay"
+	// FaultLockLoss fails a step with a fixed "simulated lock loss"
+	// error, for exercising a template's retry/compensation policy for
+	// losing a held lock mid-step. See ChaosConfig.LockLossProbability.
+	FaultLockLoss FaultMode = "lock_loss"
+)
+
+// Fault overrides a single step's outcome during a simulated run,
+// instead of invoking its real Task, so template authors can exercise
+// failure policies and compensation paths without touching real
+// systems.
+type Fault struct {
+	Mode    FaultMode     `json:"mode"`
+	Message string        `json:"message,omitempty"`
+	Delay   time.Duration `json:"delay,omitempty"`
+	Output  string        `json:"output,omitempty"`
+}
+
+// apply produces the (output, err) pair the step should report given
+// this Fault, honoring ctx cancellation during a FaultDelay.
+func (f Fault) apply(ctx context.Context, c clock.Clock) (string, error) {
+	switch f.Mode {
+	case FaultForceFail:
+		msg := f.Message
+		if msg == "" {
+			msg = "simulated failure"
+		}
+		return f.Output, errors.New(msg)
+	case FaultLockLoss:
+		return f.Output, errors.New("simulated lock loss")
+	case FaultDelay:
+		select {
+		case <-c.After(f.Delay):
+		case <-ctx.Done():
+			return f.Output, ctx.Err()
+		}
+		return f.Output, nil
+	default: // FaultForceSucceed or unset
+		return f.Output, nil
+	}
+}
@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunSimulatedForcesOutcomes(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "false"}, // would really fail
+	}
+	faults := map[string]Fault{
+		"a": {Mode: FaultForceSucceed, Output: "simulated ok"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.RunSimulated(context.Background(), execution, steps, faults); err != nil {
+		t.Fatalf("RunSimulated: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step: %v", err)
+	}
+	if se.Status != model.StatusSucceeded || se.Output != "simulated ok" {
+		t.Fatalf("expected forced success, got status=%s output=%q", se.Status, se.Output)
+	}
+}
+
+func TestRunSimulatedForcesFailure(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"}, // would really succeed
+	}
+	faults := map[string]Fault{
+		"a": {Mode: FaultForceFail, Message: "boom"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.RunSimulated(context.Background(), execution, steps, faults); err == nil {
+		t.Fatal("expected RunSimulated to report failure")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step: %v", err)
+	}
+	if se.Status != model.StatusFailed || se.Error != "boom" {
+		t.Fatalf("expected forced failure with message 'boom', got status=%s error=%q", se.Status, se.Error)
+	}
+}
@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/fieldcrypt"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunDecryptsAnEncryptedStepConfigBeforeRunningIt(t *testing.T) {
+	gormDB := newTestDB(t)
+	ring, err := fieldcrypt.NewKeyRing("v1", map[string][]byte{"v1": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithFieldCrypt(ring))
+
+	encrypted, err := ring.Encrypt("echo secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: encrypted}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Status != model.StatusSucceeded {
+		t.Fatalf("expected the step to succeed once its Config was decrypted, got %s (%s)", se.Status, se.Error)
+	}
+	if se.Output != "secret\n" {
+		t.Fatalf("expected the decrypted command's output, got %q", se.Output)
+	}
+}
+
+func TestRunFailsAStepWhoseConfigCannotBeDecrypted(t *testing.T) {
+	gormDB := newTestDB(t)
+	ring, err := fieldcrypt.NewKeyRing("v1", map[string][]byte{"v1": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithFieldCrypt(ring))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "echo not encrypted"}}
+
+	err = orchestrator.Run(context.Background(), execution, steps)
+	var preflightErr *PreflightError
+	if !errors.As(err, &preflightErr) {
+		t.Fatalf("expected a *PreflightError when a step's Config can't be decrypted, got %v", err)
+	}
+	if len(preflightErr.Errors) != 1 || preflightErr.Errors[0].StepName != "a" {
+		t.Fatalf("expected step a to fail preflight, got %v", preflightErr.Errors)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.StepExecution{}).Count(&count).Error; err != nil {
+		t.Fatalf("count step executions: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no StepExecution rows since preflight caught this before the run started, got %d", count)
+	}
+}
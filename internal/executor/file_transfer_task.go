@@ -0,0 +1,214 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// FileTransferDirection selects whether a FileTransferTask downloads a
+// remote file to local disk or uploads a local file to a remote URL.
+type FileTransferDirection string
+
+const (
+	FileTransferDownload FileTransferDirection = "download"
+	FileTransferUpload   FileTransferDirection = "upload"
+)
+
+// FileTransferTaskConfig is the JSON shape expected in a step's Config
+// for FileTransferTask.
+type FileTransferTaskConfig struct {
+	// Direction defaults to FileTransferDownload.
+	Direction FileTransferDirection `json:"direction"`
+	URL       string                `json:"url"`
+	// Path is where a download is written, or where an upload reads from.
+	Path string `json:"path"`
+	// SHA256 is the expected checksum of a downloaded file, hex-encoded.
+	// Empty skips verification.
+	SHA256 string `json:"sha256,omitempty"`
+	// MaxBytes caps how much a download may write, aborting once
+	// exceeded. Zero means no limit.
+	MaxBytes int64 `json:"max_bytes,omitempty"`
+}
+
+// FileTransferResult is the JSON-encoded output FileTransferTask leaves
+// in a step's Output for downstream steps to consume.
+type FileTransferResult struct {
+	Path   string `json:"path"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"`
+}
+
+// FileTransferTask downloads a remote file to local disk (resuming a
+// previous partial download via a Range request, and verifying a
+// checksum if one is configured) or uploads a local file as a
+// multipart/form-data request.
+type FileTransferTask struct {
+	Client *http.Client
+}
+
+// Run performs the transfer described by config (a JSON-encoded
+// FileTransferTaskConfig) and returns a JSON-encoded FileTransferResult.
+func (t FileTransferTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg FileTransferTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("file transfer task: invalid config: %w", err)
+	}
+	if cfg.URL == "" {
+		return "", fmt.Errorf("file transfer task: url is required")
+	}
+	if cfg.Path == "" {
+		return "", fmt.Errorf("file transfer task: path is required")
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if cfg.Direction == FileTransferUpload {
+		return t.upload(ctx, client, cfg)
+	}
+	return t.download(ctx, client, cfg)
+}
+
+func (t FileTransferTask) download(ctx context.Context, client *http.Client, cfg FileTransferTaskConfig) (string, error) {
+	var offset int64
+	if info, err := os.Stat(cfg.Path); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.URL, nil)
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	default:
+		return "", fmt.Errorf("file transfer task: unexpected status %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(cfg.Path, flags, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("file transfer task: open %s: %w", cfg.Path, err)
+	}
+	defer file.Close()
+
+	body := resp.Body
+	if cfg.MaxBytes > 0 {
+		remaining := cfg.MaxBytes - offset
+		if remaining < 0 {
+			remaining = 0
+		}
+		limited := io.LimitReader(body, remaining+1)
+		n, err := io.Copy(file, limited)
+		if err != nil {
+			return "", fmt.Errorf("file transfer task: write %s: %w", cfg.Path, err)
+		}
+		if n > remaining {
+			return "", fmt.Errorf("file transfer task: download exceeded max_bytes (%d)", cfg.MaxBytes)
+		}
+	} else {
+		if _, err := io.Copy(file, body); err != nil {
+			return "", fmt.Errorf("file transfer task: write %s: %w", cfg.Path, err)
+		}
+	}
+
+	sum, size, err := hashFile(cfg.Path)
+	if err != nil {
+		return "", err
+	}
+	if cfg.SHA256 != "" && sum != cfg.SHA256 {
+		return "", fmt.Errorf("file transfer task: checksum mismatch: expected %s, got %s", cfg.SHA256, sum)
+	}
+
+	return encodeFileTransferResult(cfg.Path, size, sum)
+}
+
+func (t FileTransferTask) upload(ctx context.Context, client *http.Client, cfg FileTransferTaskConfig) (string, error) {
+	file, err := os.Open(cfg.Path)
+	if err != nil {
+		return "", fmt.Errorf("file transfer task: open %s: %w", cfg.Path, err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(cfg.Path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("file transfer task: unexpected status %d", resp.StatusCode)
+	}
+
+	sum, size, err := hashFile(cfg.Path)
+	if err != nil {
+		return "", err
+	}
+	return encodeFileTransferResult(cfg.Path, size, sum)
+}
+
+func encodeFileTransferResult(path string, size int64, sum string) (string, error) {
+	out, err := json.Marshal(FileTransferResult{Path: path, Bytes: size, SHA256: sum})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func hashFile(path string) (sum string, size int64, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, file)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), n, nil
+}
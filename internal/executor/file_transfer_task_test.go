@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileTransferTaskDownloadsAndVerifiesChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	config, _ := json.Marshal(FileTransferTaskConfig{
+		URL:    server.URL,
+		Path:   dest,
+		SHA256: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+	})
+
+	output, err := (FileTransferTask{}).Run(context.Background(), string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var result FileTransferResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.Bytes != int64(len("hello world")) {
+		t.Fatalf("expected 11 bytes, got %d", result.Bytes)
+	}
+
+	contents, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read dest: %v", err)
+	}
+	if string(contents) != "hello world" {
+		t.Fatalf("expected %q, got %q", "hello world", contents)
+	}
+}
+
+func TestFileTransferTaskRejectsChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	config, _ := json.Marshal(FileTransferTaskConfig{
+		URL:    server.URL,
+		Path:   dest,
+		SHA256: "deadbeef",
+	})
+
+	if _, err := (FileTransferTask{}).Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected a checksum mismatch error")
+	}
+}
+
+func TestFileTransferTaskResumesPartialDownload(t *testing.T) {
+	full := "hello world"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rng := r.Header.Get("Range"); rng != "" {
+			w.Header().Set("Content-Range", "bytes 6-10/11")
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write([]byte(full[6:]))
+			return
+		}
+		w.Write([]byte(full))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "out.txt")
+	if err := os.WriteFile(dest, []byte(full[:6]), 0o644); err != nil {
+		t.Fatalf("seed partial file: %v", err)
+	}
+
+	config, _ := json.Marshal(FileTransferTaskConfig{URL: server.URL, Path: dest})
+	output, err := (FileTransferTask{}).Run(context.Background(), string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var result FileTransferResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	contents, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read dest: %v", err)
+	}
+	if string(contents) != full {
+		t.Fatalf("expected resumed download %q, got %q", full, contents)
+	}
+}
+
+func TestFileTransferTaskUploadsMultipart(t *testing.T) {
+	var receivedFilename string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("parse multipart form: %v", err)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("FormFile: %v", err)
+			return
+		}
+		defer file.Close()
+		receivedFilename = header.Filename
+	}))
+	defer server.Close()
+
+	src := filepath.Join(t.TempDir(), "upload.txt")
+	if err := os.WriteFile(src, []byte("payload"), 0o644); err != nil {
+		t.Fatalf("write src: %v", err)
+	}
+
+	config, _ := json.Marshal(FileTransferTaskConfig{
+		Direction: FileTransferUpload,
+		URL:       server.URL,
+		Path:      src,
+	})
+	if _, err := (FileTransferTask{}).Run(context.Background(), string(config)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if receivedFilename != "upload.txt" {
+		t.Fatalf("expected filename %q, got %q", "upload.txt", receivedFilename)
+	}
+}
+
+func TestFileTransferTaskRejectsMissingURL(t *testing.T) {
+	config, _ := json.Marshal(FileTransferTaskConfig{Path: "/tmp/x"})
+	if _, err := (FileTransferTask{}).Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for a missing url")
+	}
+}
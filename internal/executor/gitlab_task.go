@@ -0,0 +1,210 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// GitLabPipelineTaskConfig is the JSON shape expected in a step's
+// Config for GitLabPipelineTask.
+type GitLabPipelineTaskConfig struct {
+	BaseURL   string            `json:"base_url"`
+	ProjectID string            `json:"project_id"`
+	Ref       string            `json:"ref"`
+	Variables map[string]string `json:"variables,omitempty"`
+	// TriggerTokenEnvVar names the environment variable holding the
+	// pipeline trigger token used to start the pipeline. APITokenEnvVar
+	// names the one holding a personal/project access token used to
+	// poll its status, since a trigger token alone can't read the API.
+	// GitLabPipelineTask never stores either token itself.
+	TriggerTokenEnvVar string `json:"trigger_token_env_var"`
+	APITokenEnvVar     string `json:"api_token_env_var,omitempty"`
+
+	// PollInterval defaults to DefaultCIPollInterval. MaxAttempts
+	// defaults to DefaultCIMaxPollAttempts.
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+	MaxAttempts  int           `json:"max_attempts,omitempty"`
+}
+
+// GitLabPipelineResult is the JSON-encoded output GitLabPipelineTask
+// leaves in a step's Output once the triggered pipeline finishes.
+type GitLabPipelineResult struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	WebURL string `json:"web_url"`
+}
+
+// gitLabTerminalStatuses are the pipeline statuses GitLab never
+// transitions out of, at which point polling stops.
+var gitLabTerminalStatuses = map[string]bool{
+	"success":  true,
+	"failed":   true,
+	"canceled": true,
+	"skipped":  true,
+}
+
+// GitLabPipelineTask triggers a GitLab pipeline with variables, polls
+// it until it reaches a terminal status, and maps that status into the
+// step's output.
+type GitLabPipelineTask struct {
+	Client *http.Client
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+// Run triggers the pipeline described by config (a JSON-encoded
+// GitLabPipelineTaskConfig), polls it to completion, and returns a
+// JSON-encoded GitLabPipelineResult.
+func (t GitLabPipelineTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg GitLabPipelineTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("gitlab pipeline task: invalid config: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return "", fmt.Errorf("gitlab pipeline task: base_url is required")
+	}
+	if cfg.ProjectID == "" {
+		return "", fmt.Errorf("gitlab pipeline task: project_id is required")
+	}
+	if cfg.Ref == "" {
+		return "", fmt.Errorf("gitlab pipeline task: ref is required")
+	}
+	if cfg.TriggerTokenEnvVar == "" {
+		return "", fmt.Errorf("gitlab pipeline task: trigger_token_env_var is required")
+	}
+
+	pipeline, err := t.trigger(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	pipeline, err = t.pollUntilTerminal(ctx, cfg, pipeline.ID)
+	if err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(pipeline)
+	if err != nil {
+		return "", err
+	}
+	output := string(encoded)
+	if pipeline.Status != "success" {
+		return output, fmt.Errorf("gitlab pipeline task: pipeline finished with status %q", pipeline.Status)
+	}
+	return output, nil
+}
+
+func (t GitLabPipelineTask) trigger(ctx context.Context, cfg GitLabPipelineTaskConfig) (GitLabPipelineResult, error) {
+	token := os.Getenv(cfg.TriggerTokenEnvVar)
+	if token == "" {
+		return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: %s is not set", cfg.TriggerTokenEnvVar)
+	}
+
+	values := url.Values{}
+	values.Set("token", token)
+	values.Set("ref", cfg.Ref)
+	for name, value := range cfg.Variables {
+		values.Set(fmt.Sprintf("variables[%s]", name), value)
+	}
+
+	triggerURL := fmt.Sprintf("%s/api/v4/projects/%s/trigger/pipeline", strings.TrimRight(cfg.BaseURL, "/"), url.PathEscape(cfg.ProjectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, triggerURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return GitLabPipelineResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return GitLabPipelineResult{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: trigger pipeline: %w", &StatusError{StatusCode: resp.StatusCode})
+	}
+
+	var pipeline GitLabPipelineResult
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: decode trigger response: %w", err)
+	}
+	return pipeline, nil
+}
+
+func (t GitLabPipelineTask) pollUntilTerminal(ctx context.Context, cfg GitLabPipelineTaskConfig, pipelineID int) (GitLabPipelineResult, error) {
+	c := t.clock()
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultCIPollInterval
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultCIMaxPollAttempts
+	}
+
+	statusURL := fmt.Sprintf("%s/api/v4/projects/%s/pipelines/%d", strings.TrimRight(cfg.BaseURL, "/"), url.PathEscape(cfg.ProjectID), pipelineID)
+
+	for attempt := 1; ; attempt++ {
+		pipeline, err := t.fetchStatus(ctx, cfg, statusURL)
+		if err != nil {
+			return GitLabPipelineResult{}, err
+		}
+		if gitLabTerminalStatuses[pipeline.Status] {
+			return pipeline, nil
+		}
+		if attempt >= maxAttempts {
+			return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: pipeline never reached a terminal status after %d attempt(s), last was %q", attempt, pipeline.Status)
+		}
+		select {
+		case <-ctx.Done():
+			return GitLabPipelineResult{}, ctx.Err()
+		case <-c.After(interval):
+		}
+	}
+}
+
+func (t GitLabPipelineTask) fetchStatus(ctx context.Context, cfg GitLabPipelineTaskConfig, statusURL string) (GitLabPipelineResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL, nil)
+	if err != nil {
+		return GitLabPipelineResult{}, err
+	}
+	if cfg.APITokenEnvVar != "" {
+		req.Header.Set("PRIVATE-TOKEN", os.Getenv(cfg.APITokenEnvVar))
+	}
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return GitLabPipelineResult{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: poll pipeline: %w", &StatusError{StatusCode: resp.StatusCode})
+	}
+
+	var pipeline GitLabPipelineResult
+	if err := json.NewDecoder(resp.Body).Decode(&pipeline); err != nil {
+		return GitLabPipelineResult{}, fmt.Errorf("gitlab pipeline task: decode pipeline status: %w", err)
+	}
+	return pipeline, nil
+}
+
+func (t GitLabPipelineTask) client() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
+func (t GitLabPipelineTask) clock() clock.Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return clock.Real{}
+}
@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGitLabPipelineTaskTriggersPollsAndReturnsTheFinalStatus(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN", "trigger-tok")
+
+	var polls int32
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/api/v4/projects/42/trigger/pipeline", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":99,"status":"pending","web_url":"https://gitlab.example/pipelines/99"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/42/pipelines/99", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 2 {
+			w.Write([]byte(`{"id":99,"status":"running","web_url":"https://gitlab.example/pipelines/99"}`))
+			return
+		}
+		w.Write([]byte(`{"id":99,"status":"success","web_url":"https://gitlab.example/pipelines/99"}`))
+	})
+
+	task := GitLabPipelineTask{}
+	cfg, err := json.Marshal(GitLabPipelineTaskConfig{
+		BaseURL:            server.URL,
+		ProjectID:          "42",
+		Ref:                "main",
+		TriggerTokenEnvVar: "ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN",
+		PollInterval:       time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result GitLabPipelineResult
+	if jsonErr := json.Unmarshal([]byte(output), &result); jsonErr != nil {
+		t.Fatalf("unmarshal output: %v", jsonErr)
+	}
+	if result.Status != "success" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestGitLabPipelineTaskFailsOnAFailedPipeline(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN_2", "trigger-tok")
+
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/api/v4/projects/7/trigger/pipeline", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"status":"pending"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/7/pipelines/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"status":"failed"}`))
+	})
+
+	task := GitLabPipelineTask{}
+	cfg, err := json.Marshal(GitLabPipelineTaskConfig{
+		BaseURL:            server.URL,
+		ProjectID:          "7",
+		Ref:                "main",
+		TriggerTokenEnvVar: "ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN_2",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	_, err = task.Run(context.Background(), string(cfg))
+	if err == nil {
+		t.Fatal("expected Run to fail on a failed pipeline")
+	}
+}
+
+func TestGitLabPipelineTaskSendsTheAPITokenHeaderWhilePolling(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN_3", "trigger-tok")
+	t.Setenv("ORCHESTRATOR_TEST_GITLAB_API_TOKEN", "api-tok")
+
+	var gotHeader string
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/api/v4/projects/3/trigger/pipeline", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":5,"status":"pending"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/3/pipelines/5", func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("PRIVATE-TOKEN")
+		w.Write([]byte(`{"id":5,"status":"success"}`))
+	})
+
+	task := GitLabPipelineTask{}
+	cfg, err := json.Marshal(GitLabPipelineTaskConfig{
+		BaseURL:            server.URL,
+		ProjectID:          "3",
+		Ref:                "main",
+		TriggerTokenEnvVar: "ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN_3",
+		APITokenEnvVar:     "ORCHESTRATOR_TEST_GITLAB_API_TOKEN",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if _, err := task.Run(context.Background(), string(cfg)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if gotHeader != "api-tok" {
+		t.Fatalf("expected PRIVATE-TOKEN header %q, got %q", "api-tok", gotHeader)
+	}
+}
+
+func TestGitLabPipelineTaskRejectsAnUnsetTriggerToken(t *testing.T) {
+	task := GitLabPipelineTask{}
+	cfg, err := json.Marshal(GitLabPipelineTaskConfig{
+		BaseURL:            "http://example.invalid",
+		ProjectID:          "1",
+		Ref:                "main",
+		TriggerTokenEnvVar: "ORCHESTRATOR_TEST_GITLAB_TRIGGER_TOKEN_UNSET",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject an unset trigger token")
+	}
+}
@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/expr-lang/expr"
+)
+
+// GraphQLTaskConfig is the JSON shape expected in a step's Config for
+// GraphQLTask.
+type GraphQLTaskConfig struct {
+	Endpoint string `json:"endpoint"`
+	Query    string `json:"query"`
+	// Variables maps a GraphQL variable name to an expr-lang expression
+	// evaluated against outputs (predecessor step outputs, via
+	// StepOutputsFromContext) and vars, the same convention ScriptTask
+	// uses for its Expression, so a variable can reference an earlier
+	// step's output without a separate templating syntax.
+	Variables map[string]string `json:"variables"`
+	Vars      map[string]string `json:"vars"`
+	// AuthHeader, if set, is sent verbatim as the request's
+	// Authorization header (e.g. "Bearer <token>").
+	AuthHeader string `json:"auth_header"`
+}
+
+type graphQLRequestBody struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type graphQLResponseError struct {
+	Message string `json:"message"`
+}
+
+type graphQLResponseBody struct {
+	Data   json.RawMessage        `json:"data"`
+	Errors []graphQLResponseError `json:"errors,omitempty"`
+}
+
+// GraphQLError reports the errors a GraphQL response returned in its
+// "errors" array, which GraphQL servers can send alongside a 200
+// response (and even alongside partial data), so a caller can't rely on
+// the HTTP status code alone to detect failure.
+type GraphQLError struct {
+	Messages []string
+}
+
+func (e *GraphQLError) Error() string {
+	return fmt.Sprintf("graphql task: server returned %d error(s): %s", len(e.Messages), strings.Join(e.Messages, "; "))
+}
+
+// GraphQLTask issues a single GraphQL query or mutation over HTTP POST
+// and returns its "data" object as output. A response whose "errors"
+// array is non-empty is treated as a failure even when data is also
+// present.
+type GraphQLTask struct {
+	Client *http.Client
+}
+
+// Run issues the GraphQL request described by config (a JSON-encoded
+// GraphQLTaskConfig) and returns the response's data object, JSON-encoded.
+func (t GraphQLTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg GraphQLTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("graphql task: invalid config: %w", err)
+	}
+	if cfg.Endpoint == "" {
+		return "", fmt.Errorf("graphql task: endpoint is required")
+	}
+	if cfg.Query == "" {
+		return "", fmt.Errorf("graphql task: query is required")
+	}
+
+	variables, err := resolveGraphQLVariables(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(graphQLRequestBody{Query: cfg.Query, Variables: variables})
+	if err != nil {
+		return "", fmt.Errorf("graphql task: encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.AuthHeader != "" {
+		req.Header.Set("Authorization", cfg.AuthHeader)
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("graphql task: %w", &StatusError{StatusCode: resp.StatusCode, Body: string(body)})
+	}
+
+	var parsed graphQLResponseBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return "", fmt.Errorf("graphql task: invalid response: %w", err)
+	}
+	if len(parsed.Errors) > 0 {
+		messages := make([]string, len(parsed.Errors))
+		for i, respErr := range parsed.Errors {
+			messages[i] = respErr.Message
+		}
+		return "", &GraphQLError{Messages: messages}
+	}
+
+	if recErr := RecordExternalInput(ctx, "response_body", string(body)); recErr != nil {
+		return string(parsed.Data), fmt.Errorf("graphql task: %w", recErr)
+	}
+	return string(parsed.Data), nil
+}
+
+// resolveGraphQLVariables evaluates each of cfg.Variables' expr-lang
+// expressions against ctx's predecessor outputs and cfg.Vars.
+func resolveGraphQLVariables(ctx context.Context, cfg GraphQLTaskConfig) (map[string]interface{}, error) {
+	if len(cfg.Variables) == 0 {
+		return nil, nil
+	}
+	env := map[string]interface{}{
+		"outputs": StepOutputsFromContext(ctx),
+		"vars":    cfg.Vars,
+	}
+	resolved := make(map[string]interface{}, len(cfg.Variables))
+	for name, expression := range cfg.Variables {
+		program, err := expr.Compile(expression, expr.Env(env))
+		if err != nil {
+			return nil, fmt.Errorf("graphql task: compile variable %q: %w", name, err)
+		}
+		value, err := expr.Run(program, env)
+		if err != nil {
+			return nil, fmt.Errorf("graphql task: evaluate variable %q: %w", name, err)
+		}
+		resolved[name] = value
+	}
+	return resolved, nil
+}
@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestGraphQLTaskReturnsTheDataObject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"viewer":{"login":"octocat"}}}`))
+	}))
+	defer server.Close()
+
+	task := GraphQLTask{}
+	config := `{"endpoint":"` + server.URL + `","query":"{ viewer { login } }"}`
+	output, err := task.Run(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != `{"viewer":{"login":"octocat"}}` {
+		t.Fatalf("unexpected output: %q", output)
+	}
+}
+
+func TestGraphQLTaskFailsOnAResponseWithErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":null,"errors":[{"message":"field \"login\" is not defined"}]}`))
+	}))
+	defer server.Close()
+
+	task := GraphQLTask{}
+	config := `{"endpoint":"` + server.URL + `","query":"{ viewer { login } }"}`
+	_, err := task.Run(context.Background(), config)
+	if err == nil {
+		t.Fatal("expected Run to fail on a response carrying GraphQL errors")
+	}
+	if _, ok := err.(*GraphQLError); !ok {
+		t.Fatalf("expected a *GraphQLError, got %T: %v", err, err)
+	}
+}
+
+func TestGraphQLTaskResolvesVariablesFromPredecessorOutputs(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Write([]byte(`{"data":{"ok":true}}`))
+	}))
+	defer server.Close()
+
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("script", ScriptTask{})
+	registry.Register("graphql", GraphQLTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	gqlConfig := `{"endpoint":"` + server.URL + `","query":"mutation($id: ID!) { close(id: $id) }","variables":{"id":"outputs.lookup"}}`
+	steps := []model.TemplateStep{
+		{Name: "lookup", Type: "script", Config: `{"expression":"\"issue-42\""}`},
+		{Name: "close", Type: "graphql", Config: gqlConfig, DependsOn: "lookup"},
+	}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var body struct {
+		Variables map[string]string `json:"variables"`
+	}
+	if err := json.Unmarshal(gotBody, &body); err != nil {
+		t.Fatalf("unmarshal request body: %v", err)
+	}
+	if body.Variables["id"] != "issue-42" {
+		t.Fatalf("expected variable id to be resolved to %q, got %q", "issue-42", body.Variables["id"])
+	}
+}
+
+func TestGraphQLTaskSendsTheConfiguredAuthHeader(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	task := GraphQLTask{}
+	config := `{"endpoint":"` + server.URL + `","query":"{ viewer { login } }","auth_header":"Bearer test-token"}`
+	if _, err := task.Run(context.Background(), config); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if gotAuth != "Bearer test-token" {
+		t.Fatalf("expected the configured Authorization header, got %q", gotAuth)
+	}
+}
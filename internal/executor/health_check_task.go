@@ -0,0 +1,174 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// DefaultHealthCheckConsecutiveSuccesses, DefaultHealthCheckInterval and
+// DefaultHealthCheckMaxDuration bound a HealthCheckTask when a step's
+// own fields are unset.
+const DefaultHealthCheckConsecutiveSuccesses = 3
+const DefaultHealthCheckInterval = 5 * time.Second
+const DefaultHealthCheckMaxDuration = 5 * time.Minute
+
+// HealthCheckTaskConfig is the JSON shape expected in a step's Config
+// for HealthCheckTask.
+type HealthCheckTaskConfig struct {
+	// InnerType is the registered Task type re-run on each check (e.g.
+	// "http" to poll an endpoint, "shell" to run a command).
+	InnerType string `json:"inner_type"`
+	// InnerConfig is the Config passed to InnerType on every check,
+	// unchanged across checks.
+	InnerConfig string `json:"inner_config"`
+	// SuccessExpression is evaluated with github.com/expr-lang/expr
+	// after every check that didn't error, against an environment of
+	// output (the check's raw output) and vars, and must produce a
+	// bool. An empty SuccessExpression treats any check that didn't
+	// error as healthy, e.g. an HTTPTask that didn't return a
+	// *StatusError.
+	SuccessExpression string            `json:"success_expression,omitempty"`
+	Vars              map[string]string `json:"vars,omitempty"`
+	// ConsecutiveSuccesses is how many checks in a row must be healthy
+	// before the step succeeds. Defaults to
+	// DefaultHealthCheckConsecutiveSuccesses.
+	ConsecutiveSuccesses int `json:"consecutive_successes,omitempty"`
+	// Interval is how long to wait between checks.
+	Interval time.Duration `json:"interval,omitempty"`
+	// MaxDuration caps how long the whole verification may run,
+	// measured from the first check. Defaults to
+	// DefaultHealthCheckMaxDuration.
+	MaxDuration time.Duration `json:"max_duration,omitempty"`
+}
+
+// healthCheckAttempt records one check's outcome.
+type healthCheckAttempt struct {
+	Attempt int    `json:"attempt"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Healthy bool   `json:"healthy"`
+}
+
+// HealthCheckResult is the JSON-encoded output HealthCheckTask leaves
+// in a step's Output either way.
+type HealthCheckResult struct {
+	Healthy  bool                 `json:"healthy"`
+	Attempts []healthCheckAttempt `json:"attempts"`
+}
+
+// HealthCheckTask re-runs another registered Task (typically "http" or
+// "shell") at an interval until it's healthy for ConsecutiveSuccesses
+// checks in a row within MaxDuration, unlike a plain HTTPTask or
+// PollTask which both stop at the first success. A single transient
+// failure resets the streak rather than failing the whole check, since
+// a flapping endpoint shouldn't read as healthy.
+type HealthCheckTask struct {
+	Registry *Registry
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+// Run implements Task.
+func (t HealthCheckTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg HealthCheckTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("health check task: invalid config: %w", err)
+	}
+	if cfg.InnerType == "" {
+		return "", fmt.Errorf("health check task: inner_type is required")
+	}
+	inner, ok := t.Registry.Lookup(cfg.InnerType)
+	if !ok {
+		return "", fmt.Errorf("health check task: unregistered inner_type %q", cfg.InnerType)
+	}
+
+	consecutive := cfg.ConsecutiveSuccesses
+	if consecutive <= 0 {
+		consecutive = DefaultHealthCheckConsecutiveSuccesses
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	maxDuration := cfg.MaxDuration
+	if maxDuration <= 0 {
+		maxDuration = DefaultHealthCheckMaxDuration
+	}
+
+	var program *vm.Program
+	if cfg.SuccessExpression != "" {
+		compiled, err := expr.Compile(cfg.SuccessExpression, expr.Env(healthCheckEnv("", cfg.Vars)))
+		if err != nil {
+			return "", fmt.Errorf("health check task: compile success_expression: %w", err)
+		}
+		program = compiled
+	}
+
+	c := t.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+	deadline := c.Now().Add(maxDuration)
+
+	var attempts []healthCheckAttempt
+	var streak int
+	for attemptNum := 1; ; attemptNum++ {
+		output, runErr := inner.Run(ctx, cfg.InnerConfig)
+		a := healthCheckAttempt{Attempt: attemptNum, Output: output}
+
+		healthy := runErr == nil
+		if runErr != nil {
+			a.Error = runErr.Error()
+		} else if program != nil {
+			result, evalErr := expr.Run(program, healthCheckEnv(output, cfg.Vars))
+			if evalErr != nil {
+				a.Error = evalErr.Error()
+				healthy = false
+			} else if b, ok := result.(bool); ok {
+				healthy = b
+			} else {
+				return encodeHealthCheckResult(false, attempts), fmt.Errorf("health check task: success_expression must produce a bool, got %T", result)
+			}
+		}
+		a.Healthy = healthy
+		attempts = append(attempts, a)
+
+		if healthy {
+			streak++
+		} else {
+			streak = 0
+		}
+		if streak >= consecutive {
+			return encodeHealthCheckResult(true, attempts), nil
+		}
+
+		if !c.Now().Before(deadline) {
+			return encodeHealthCheckResult(false, attempts), fmt.Errorf("health check task: never healthy for %d consecutive check(s) within %s, after %d attempt(s)", consecutive, maxDuration, attemptNum)
+		}
+
+		select {
+		case <-ctx.Done():
+			return encodeHealthCheckResult(false, attempts), ctx.Err()
+		case <-c.After(interval):
+		}
+	}
+}
+
+func healthCheckEnv(output string, vars map[string]string) map[string]interface{} {
+	return map[string]interface{}{"output": output, "vars": vars}
+}
+
+func encodeHealthCheckResult(healthy bool, attempts []healthCheckAttempt) string {
+	encoded, err := json.Marshal(HealthCheckResult{Healthy: healthy, Attempts: attempts})
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,163 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckTaskSucceedsAfterConsecutiveHealthyChecks(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	task := HealthCheckTask{Registry: registry}
+
+	inner, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("marshal inner config: %v", err)
+	}
+	cfg, err := json.Marshal(HealthCheckTaskConfig{
+		InnerType: "http", InnerConfig: string(inner), ConsecutiveSuccesses: 2, Interval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result HealthCheckResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if !result.Healthy {
+		t.Fatalf("expected healthy result: %+v", result)
+	}
+	// The first check failed (503), resetting the streak, so it takes 3
+	// calls total to see 2 healthy checks in a row.
+	if len(result.Attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d: %+v", len(result.Attempts), result.Attempts)
+	}
+}
+
+func TestHealthCheckTaskResetsTheStreakOnAFlap(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	task := HealthCheckTask{Registry: registry}
+
+	inner, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("marshal inner config: %v", err)
+	}
+	cfg, err := json.Marshal(HealthCheckTaskConfig{
+		InnerType: "http", InnerConfig: string(inner), ConsecutiveSuccesses: 2, Interval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result HealthCheckResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	// check 1 (healthy), check 2 (unhealthy, resets streak), check 3 and
+	// 4 (healthy, healthy) complete the required streak of 2.
+	if len(result.Attempts) != 4 {
+		t.Fatalf("expected 4 attempts, got %d: %+v", len(result.Attempts), result.Attempts)
+	}
+}
+
+func TestHealthCheckTaskFailsAfterMaxDurationWithoutAHealthyStreak(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	task := HealthCheckTask{Registry: registry}
+
+	inner, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("marshal inner config: %v", err)
+	}
+	cfg, err := json.Marshal(HealthCheckTaskConfig{
+		InnerType: "http", InnerConfig: string(inner), ConsecutiveSuccesses: 2,
+		Interval: time.Millisecond, MaxDuration: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to fail once max_duration elapses without a healthy streak")
+	}
+}
+
+func TestHealthCheckTaskHonorsASuccessExpression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"degraded"}`))
+	}))
+	defer server.Close()
+
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	task := HealthCheckTask{Registry: registry}
+
+	inner, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL})
+	if err != nil {
+		t.Fatalf("marshal inner config: %v", err)
+	}
+	cfg, err := json.Marshal(HealthCheckTaskConfig{
+		InnerType: "http", InnerConfig: string(inner), ConsecutiveSuccesses: 1,
+		Interval: time.Millisecond, MaxDuration: 5 * time.Millisecond,
+		SuccessExpression: `output contains "\"status\":\"ok\""`,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to fail when success_expression never matches")
+	}
+}
+
+func TestHealthCheckTaskRejectsAnUnregisteredInnerType(t *testing.T) {
+	task := HealthCheckTask{Registry: NewRegistry()}
+	cfg, err := json.Marshal(HealthCheckTaskConfig{InnerType: "nope", InnerConfig: "{}"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject an unregistered inner_type")
+	}
+}
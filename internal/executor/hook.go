@@ -0,0 +1,69 @@
+package executor
+
+import (
+	"context"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// Hook observes a TaskOrchestrator's orchestration-level lifecycle,
+// as opposed to a Task's per-step work: execution start and end, DAG
+// layer boundaries, and execution state transitions. Register one with
+// WithHook so an integration (metrics, notifications, external locks)
+// can react to every execution without wrapping every Task.
+//
+// Embed NoopHook to implement only the methods a particular integration
+// cares about.
+type Hook interface {
+	// OnExecutionStart fires once RunSimulated has validated execution
+	// and is about to start running its first layer.
+	OnExecutionStart(ctx context.Context, execution *model.WorkflowExecution)
+	// OnExecutionEnd fires once, after execution's final status and
+	// FinishedAt are persisted. err is whatever RunSimulated is about to
+	// return: nil on success, otherwise a *RunResult.
+	OnExecutionEnd(ctx context.Context, execution *model.WorkflowExecution, err error)
+	// OnLayerComplete fires after every node in one DAG layer has either
+	// run or been skipped, before the next layer (if any) starts.
+	OnLayerComplete(ctx context.Context, execution *model.WorkflowExecution, nodes map[string]NodeResult)
+	// OnStateTransition fires after execution.Status changes, including
+	// the start (StatusPending/StatusFailed -> StatusRunning) and end
+	// (StatusRunning -> StatusSucceeded/StatusFailed) transitions that
+	// OnExecutionStart/OnExecutionEnd also bracket.
+	OnStateTransition(ctx context.Context, execution *model.WorkflowExecution, from, to model.ExecutionStatus)
+}
+
+// NoopHook implements Hook with no-ops, so a type embedding it only
+// needs to define the methods it actually cares about.
+type NoopHook struct{}
+
+func (NoopHook) OnExecutionStart(ctx context.Context, execution *model.WorkflowExecution) {}
+func (NoopHook) OnExecutionEnd(ctx context.Context, execution *model.WorkflowExecution, err error) {
+}
+func (NoopHook) OnLayerComplete(ctx context.Context, execution *model.WorkflowExecution, nodes map[string]NodeResult) {
+}
+func (NoopHook) OnStateTransition(ctx context.Context, execution *model.WorkflowExecution, from, to model.ExecutionStatus) {
+}
+
+func (o *TaskOrchestrator) fireExecutionStart(ctx context.Context, execution *model.WorkflowExecution) {
+	for _, h := range o.hooks {
+		h.OnExecutionStart(ctx, execution)
+	}
+}
+
+func (o *TaskOrchestrator) fireExecutionEnd(ctx context.Context, execution *model.WorkflowExecution, err error) {
+	for _, h := range o.hooks {
+		h.OnExecutionEnd(ctx, execution, err)
+	}
+}
+
+func (o *TaskOrchestrator) fireLayerComplete(ctx context.Context, execution *model.WorkflowExecution, nodes map[string]NodeResult) {
+	for _, h := range o.hooks {
+		h.OnLayerComplete(ctx, execution, nodes)
+	}
+}
+
+func (o *TaskOrchestrator) fireStateTransition(ctx context.Context, execution *model.WorkflowExecution, from, to model.ExecutionStatus) {
+	for _, h := range o.hooks {
+		h.OnStateTransition(ctx, execution, from, to)
+	}
+}
@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// recordingHook records which lifecycle events fired, guarded by a
+// mutex since OnLayerComplete can run concurrently with further step
+// dispatch in non-deterministic mode.
+type recordingHook struct {
+	NoopHook
+	mu          sync.Mutex
+	started     int
+	ended       int
+	endErr      error
+	layers      []map[string]NodeResult
+	transitions []string
+}
+
+func (h *recordingHook) OnExecutionStart(ctx context.Context, execution *model.WorkflowExecution) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started++
+}
+
+func (h *recordingHook) OnExecutionEnd(ctx context.Context, execution *model.WorkflowExecution, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.ended++
+	h.endErr = err
+}
+
+func (h *recordingHook) OnLayerComplete(ctx context.Context, execution *model.WorkflowExecution, nodes map[string]NodeResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.layers = append(h.layers, nodes)
+}
+
+func (h *recordingHook) OnStateTransition(ctx context.Context, execution *model.WorkflowExecution, from, to model.ExecutionStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.transitions = append(h.transitions, string(from)+"->"+string(to))
+}
+
+func TestHooksFireAcrossAnExecutionsLifecycle(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+
+	hook := &recordingHook{}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithHook(hook), WithDeterministic())
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if hook.started != 1 {
+		t.Fatalf("expected OnExecutionStart to fire once, got %d", hook.started)
+	}
+	if hook.ended != 1 || hook.endErr != nil {
+		t.Fatalf("expected OnExecutionEnd to fire once with a nil error, got %d calls, err=%v", hook.ended, hook.endErr)
+	}
+	if len(hook.layers) != 2 {
+		t.Fatalf("expected OnLayerComplete to fire once per layer, got %d", len(hook.layers))
+	}
+	if _, ok := hook.layers[0]["a"]; !ok {
+		t.Fatalf("expected the first layer's results to include step a, got %v", hook.layers[0])
+	}
+	if _, ok := hook.layers[1]["b"]; !ok {
+		t.Fatalf("expected the second layer's results to include step b, got %v", hook.layers[1])
+	}
+
+	foundStart := false
+	for _, tr := range hook.transitions {
+		if tr == "pending->running" {
+			foundStart = true
+		}
+	}
+	if !foundStart {
+		t.Fatalf("expected a pending->running transition, got %v", hook.transitions)
+	}
+}
+
+func TestHooksReportRunResultOnFailure(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "false"},
+	}
+
+	hook := &recordingHook{}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithHook(hook))
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to report failure")
+	}
+
+	if hook.ended != 1 {
+		t.Fatalf("expected OnExecutionEnd to fire once, got %d", hook.ended)
+	}
+	if hook.endErr == nil {
+		t.Fatal("expected OnExecutionEnd to see a non-nil error")
+	}
+	if _, ok := hook.endErr.(*RunResult); !ok {
+		t.Fatalf("expected a *RunResult, got %T", hook.endErr)
+	}
+}
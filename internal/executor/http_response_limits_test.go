@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestHTTPTaskCompactsAJSONResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write([]byte("  {\"ok\"  : true}  "))
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	output, err := task.Run(context.Background(), `{"url":"`+server.URL+`"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != `{"ok":true}` {
+		t.Fatalf("expected a compacted JSON body, got %q", output)
+	}
+}
+
+func TestHTTPTaskRejectsAResponseThatClaimsJSONButIsnt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	if _, err := task.Run(context.Background(), `{"url":"`+server.URL+`"}`); err == nil {
+		t.Fatal("expected Run to reject a body that isn't valid JSON despite its content type")
+	}
+}
+
+func TestHTTPTaskLeavesNonJSONBodiesUntouched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	output, err := task.Run(context.Background(), `{"url":"`+server.URL+`"}`)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "pong" {
+		t.Fatalf("expected %q, got %q", "pong", output)
+	}
+}
+
+func TestHTTPTaskFailsAnOversizedResponseWithoutAnArtifactStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte("x"), 20))
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	config := `{"url":"` + server.URL + `","max_response_bytes":10}`
+	if _, err := task.Run(context.Background(), config); err == nil {
+		t.Fatal("expected Run to reject an oversized response with no artifact store configured")
+	}
+}
+
+func TestRunStreamsAnOversizedHTTPResponseToAnArtifact(t *testing.T) {
+	body := strings.Repeat("y", 20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("http", HTTPTask{})
+	store := artifact.NewLocalStore(t.TempDir())
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithArtifactStore(store))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	cfg, err := json.Marshal(HTTPTaskConfig{Method: "GET", URL: server.URL, MaxResponseBytes: 10})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "fetch", Type: "http", Config: string(cfg)}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "fetch").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	var result HTTPTaskResult
+	if err := json.Unmarshal([]byte(se.Output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.ArtifactName != "response_body" || result.Bytes != int64(len(body)) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	var art model.Artifact
+	if err := gormDB.First(&art, result.ArtifactID).Error; err != nil {
+		t.Fatalf("load artifact: %v", err)
+	}
+	if art.Size != int64(len(body)) {
+		t.Fatalf("expected artifact size %d, got %d", len(body), art.Size)
+	}
+}
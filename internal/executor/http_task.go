@@ -0,0 +1,275 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPMaxResponseBytes caps how much of a response body HTTPTask
+// reads into memory when a step's own MaxResponseBytes is unset. A
+// response past the cap streams to an artifact instead, or fails the
+// step if no artifact store is configured.
+const DefaultHTTPMaxResponseBytes = 10 << 20 // 10MiB
+
+// StatusError is returned by a Task whose failure is a non-2xx HTTP
+// response, so callers like RetryableExecutor's retry classifiers can
+// distinguish a permanent client error from a transient server one
+// without parsing error strings.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// RetryAfter is parsed from the response's Retry-After header (in
+	// seconds), or zero if it was absent or unparseable.
+	// RetryableExecutor waits this long instead of its own jittered
+	// backoff when it's set.
+	RetryAfter time.Duration
+	// Retryable, if non-nil, overrides DefaultRetryClassifier's generic
+	// 5xx/429 rule for this specific error, set by HTTPTask from the
+	// step's own RetryStatusCodes/FailFastStatusCodes. Nil means "use
+	// the generic rule".
+	Retryable *bool
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.StatusCode)
+}
+
+// HTTPTaskConfig is the JSON shape expected in a step's Config for
+// HTTPTask.
+type HTTPTaskConfig struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body"`
+
+	// RetryStatusCodes lists response status codes RetryableExecutor
+	// should retry for this step, overriding
+	// DefaultRetryClassifier's generic "5xx or 429" rule for codes
+	// outside that range (e.g. a 409 that's safe to retry here).
+	RetryStatusCodes []int `json:"retry_status_codes,omitempty"`
+	// FailFastStatusCodes lists response status codes that must never
+	// be retried for this step, even a 5xx or one also listed in
+	// RetryStatusCodes, e.g. 401/403 where retrying can't help.
+	// FailFastStatusCodes takes priority over RetryStatusCodes.
+	FailFastStatusCodes []int `json:"fail_fast_status_codes,omitempty"`
+
+	// MaxResponseBytes caps how much of the response body is read into
+	// memory, falling back to DefaultHTTPMaxResponseBytes if zero. A
+	// response past the cap streams to an artifact named
+	// "response_body" instead of being returned inline.
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty"`
+}
+
+// HTTPTaskResult is the JSON-encoded output HTTPTask leaves in a step's
+// Output when a response body exceeds MaxResponseBytes and is streamed
+// to an artifact instead of returned inline.
+type HTTPTaskResult struct {
+	ArtifactID   uint   `json:"artifact_id"`
+	ArtifactName string `json:"artifact_name"`
+	Bytes        int64  `json:"bytes"`
+}
+
+// HTTPTask issues a single HTTP request and returns the response body.
+// A non-2xx response is treated as a failure.
+type HTTPTask struct {
+	Client *http.Client
+}
+
+// httpTaskConfigSchema is the JSON Schema for HTTPTaskConfig, kept in
+// sync with its struct tags by hand since there's no generator for it.
+const httpTaskConfigSchema = `{
+	"type": "object",
+	"required": ["method", "url"],
+	"properties": {
+		"method": {"type": "string"},
+		"url": {"type": "string"},
+		"body": {"type": "string"},
+		"retry_status_codes": {"type": "array", "items": {"type": "integer"}},
+		"fail_fast_status_codes": {"type": "array", "items": {"type": "integer"}},
+		"max_response_bytes": {"type": "integer"}
+	}
+}`
+
+// ConfigSchema returns the JSON Schema HTTPTaskConfig must satisfy, so
+// the Registry can publish it via SchemaProvider.
+func (t HTTPTask) ConfigSchema() string {
+	return httpTaskConfigSchema
+}
+
+// Run issues the HTTP request described by config (a JSON-encoded
+// HTTPTaskConfig) and returns the response body.
+func (t HTTPTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg HTTPTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("http task: invalid config: %w", err)
+	}
+	if cfg.Method == "" {
+		cfg.Method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	max := cfg.MaxResponseBytes
+	if max <= 0 {
+		max = DefaultHTTPMaxResponseBytes
+	}
+	read, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(read)) > max {
+		return t.spillResponseToArtifact(ctx, read, resp.Body, max)
+	}
+
+	output := string(read)
+	if isJSONContentType(resp.Header.Get("Content-Type")) {
+		var decoded json.RawMessage
+		if err := json.Unmarshal(read, &decoded); err != nil {
+			return output, fmt.Errorf("http task: response declared a JSON content type but its body isn't valid JSON: %w", err)
+		}
+		compact, err := json.Marshal(decoded)
+		if err != nil {
+			return output, err
+		}
+		output = string(compact)
+	}
+
+	if recErr := RecordExternalInput(ctx, "response_body", output); recErr != nil {
+		return output, fmt.Errorf("http task: %w", recErr)
+	}
+	if resp.StatusCode >= 300 {
+		statusErr := &StatusError{
+			StatusCode: resp.StatusCode,
+			Body:       output,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+		switch {
+		case containsStatus(cfg.FailFastStatusCodes, resp.StatusCode):
+			statusErr.Retryable = boolPtr(false)
+		case containsStatus(cfg.RetryStatusCodes, resp.StatusCode):
+			statusErr.Retryable = boolPtr(true)
+		}
+		return output, fmt.Errorf("http task: %w", statusErr)
+	}
+	return output, nil
+}
+
+// spillResponseToArtifact persists a response body that exceeded max to
+// an artifact named "response_body" instead of buffering all of it in
+// memory, writing the portion already read plus whatever remains of
+// body to a temp file before handing that file to RecordArtifact.
+func (t HTTPTask) spillResponseToArtifact(ctx context.Context, alreadyRead []byte, remainder io.Reader, max int64) (string, error) {
+	tmp, err := os.CreateTemp("", "orchestrator-http-response-*")
+	if err != nil {
+		return "", fmt.Errorf("http task: response exceeded max_response_bytes (%d): %w", max, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	written, err := tmp.Write(alreadyRead)
+	if err != nil {
+		return "", fmt.Errorf("http task: response exceeded max_response_bytes (%d): %w", max, err)
+	}
+	rest, err := io.Copy(tmp, remainder)
+	if err != nil {
+		return "", fmt.Errorf("http task: response exceeded max_response_bytes (%d): %w", max, err)
+	}
+	total := int64(written) + rest
+
+	artifact, err := RecordArtifact(ctx, "response_body", tmp.Name())
+	if err != nil {
+		return "", fmt.Errorf("http task: response exceeded max_response_bytes (%d) and couldn't be spilled to an artifact: %w", max, err)
+	}
+
+	out, err := json.Marshal(HTTPTaskResult{ArtifactID: artifact.ID, ArtifactName: artifact.Name, Bytes: total})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// isJSONContentType reports whether contentType (a Content-Type header
+// value) names a JSON media type, so Run knows to decode and re-encode
+// the body instead of returning it as opaque text.
+func isJSONContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// parseRetryAfter parses a Retry-After header's seconds form, returning
+// zero if raw is empty or not a plain integer. The HTTP-date form isn't
+// supported, since every server this orchestrator talks to in practice
+// sends the seconds form.
+func parseRetryAfter(raw string) time.Duration {
+	if raw == "" {
+		return 0
+	}
+	seconds, err := strconv.Atoi(raw)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func containsStatus(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+// ReplayHTTPClient returns an *http.Client that serves body for every
+// request instead of making a real one, for driving HTTPTask against a
+// step's recorded ExternalInput (e.g. "response_body") when diagnosing a
+// flaky remediation step in a debug sandbox instead of hitting the live
+// endpoint again.
+func ReplayHTTPClient(body string) *http.Client {
+	return &http.Client{Transport: replayTransport{body: body}}
+}
+
+type replayTransport struct {
+	body string
+}
+
+func (t replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(t.body)),
+		Request:    req,
+	}, nil
+}
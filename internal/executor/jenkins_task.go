@@ -0,0 +1,246 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// DefaultCIPollInterval is how often JenkinsJobTask and
+// GitLabPipelineTask check a triggered job's status when a step's own
+// PollInterval is unset.
+const DefaultCIPollInterval = 5 * time.Second
+
+// DefaultCIMaxPollAttempts caps how many times JenkinsJobTask and
+// GitLabPipelineTask check a triggered job's status when a step's own
+// MaxAttempts is unset.
+const DefaultCIMaxPollAttempts = 60
+
+// JenkinsJobTaskConfig is the JSON shape expected in a step's Config for
+// JenkinsJobTask.
+type JenkinsJobTaskConfig struct {
+	BaseURL string            `json:"base_url"`
+	JobName string            `json:"job_name"`
+	Params  map[string]string `json:"params,omitempty"`
+	// UserEnvVar and TokenEnvVar name the environment variables holding
+	// the Jenkins username and API token used for HTTP basic auth.
+	// JenkinsJobTask never stores credentials itself.
+	UserEnvVar  string `json:"user_env_var,omitempty"`
+	TokenEnvVar string `json:"token_env_var,omitempty"`
+
+	// PollInterval defaults to DefaultCIPollInterval. MaxAttempts
+	// defaults to DefaultCIMaxPollAttempts.
+	PollInterval time.Duration `json:"poll_interval,omitempty"`
+	MaxAttempts  int           `json:"max_attempts,omitempty"`
+}
+
+// JenkinsJobResult is the JSON-encoded output JenkinsJobTask leaves in a
+// step's Output once the triggered build finishes.
+type JenkinsJobResult struct {
+	BuildNumber int    `json:"build_number"`
+	URL         string `json:"url"`
+	Result      string `json:"result"`
+}
+
+// JenkinsJobTask triggers a Jenkins job build with parameters, polls its
+// queue item and then its build until Jenkins reports a result, and
+// maps that result into the step's output.
+type JenkinsJobTask struct {
+	Client *http.Client
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+type jenkinsQueueItem struct {
+	Executable *struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	} `json:"executable"`
+	Cancelled bool `json:"cancelled"`
+}
+
+type jenkinsBuild struct {
+	Building bool   `json:"building"`
+	Result   string `json:"result"`
+}
+
+// Run triggers the job described by config (a JSON-encoded
+// JenkinsJobTaskConfig), polls it to completion, and returns a
+// JSON-encoded JenkinsJobResult.
+func (t JenkinsJobTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg JenkinsJobTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("jenkins job task: invalid config: %w", err)
+	}
+	if cfg.BaseURL == "" {
+		return "", fmt.Errorf("jenkins job task: base_url is required")
+	}
+	if cfg.JobName == "" {
+		return "", fmt.Errorf("jenkins job task: job_name is required")
+	}
+
+	queueURL, err := t.trigger(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	buildURL, err := t.pollQueue(ctx, cfg, queueURL)
+	if err != nil {
+		return "", err
+	}
+
+	build, err := t.pollBuild(ctx, cfg, buildURL)
+	if err != nil {
+		return "", err
+	}
+
+	result := JenkinsJobResult{URL: buildURL, Result: build.Result}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	output := string(encoded)
+	if build.Result != "SUCCESS" {
+		return output, fmt.Errorf("jenkins job task: build finished with result %q", build.Result)
+	}
+	return output, nil
+}
+
+func (t JenkinsJobTask) trigger(ctx context.Context, cfg JenkinsJobTaskConfig) (string, error) {
+	values := url.Values{}
+	for name, value := range cfg.Params {
+		values.Set(name, value)
+	}
+	triggerURL := fmt.Sprintf("%s/job/%s/buildWithParameters?%s", strings.TrimRight(cfg.BaseURL, "/"), url.PathEscape(cfg.JobName), values.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, triggerURL, nil)
+	if err != nil {
+		return "", err
+	}
+	t.authenticate(req, cfg)
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("jenkins job task: trigger build: %w", &StatusError{StatusCode: resp.StatusCode})
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("jenkins job task: trigger response had no Location header")
+	}
+	return location, nil
+}
+
+// pollQueue waits for Jenkins to schedule the queued build onto an
+// executor, returning the build's own URL once it has one.
+func (t JenkinsJobTask) pollQueue(ctx context.Context, cfg JenkinsJobTaskConfig, queueURL string) (string, error) {
+	c := t.clock()
+	interval, maxAttempts := t.pollLimits(cfg)
+
+	for attempt := 1; ; attempt++ {
+		var item jenkinsQueueItem
+		if err := t.getJSON(ctx, cfg, strings.TrimRight(queueURL, "/")+"/api/json", &item); err != nil {
+			return "", fmt.Errorf("jenkins job task: poll queue item: %w", err)
+		}
+		if item.Cancelled {
+			return "", fmt.Errorf("jenkins job task: build was cancelled while queued")
+		}
+		if item.Executable != nil {
+			return item.Executable.URL, nil
+		}
+		if attempt >= maxAttempts {
+			return "", fmt.Errorf("jenkins job task: build was never scheduled after %d attempt(s)", attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-c.After(interval):
+		}
+	}
+}
+
+// pollBuild waits for the build itself to finish.
+func (t JenkinsJobTask) pollBuild(ctx context.Context, cfg JenkinsJobTaskConfig, buildURL string) (jenkinsBuild, error) {
+	c := t.clock()
+	interval, maxAttempts := t.pollLimits(cfg)
+
+	for attempt := 1; ; attempt++ {
+		var build jenkinsBuild
+		if err := t.getJSON(ctx, cfg, strings.TrimRight(buildURL, "/")+"/api/json", &build); err != nil {
+			return jenkinsBuild{}, fmt.Errorf("jenkins job task: poll build: %w", err)
+		}
+		if !build.Building {
+			return build, nil
+		}
+		if attempt >= maxAttempts {
+			return jenkinsBuild{}, fmt.Errorf("jenkins job task: build never finished after %d attempt(s)", attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return jenkinsBuild{}, ctx.Err()
+		case <-c.After(interval):
+		}
+	}
+}
+
+func (t JenkinsJobTask) getJSON(ctx context.Context, cfg JenkinsJobTaskConfig, jsonURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jsonURL, nil)
+	if err != nil {
+		return err
+	}
+	t.authenticate(req, cfg)
+
+	resp, err := t.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (t JenkinsJobTask) authenticate(req *http.Request, cfg JenkinsJobTaskConfig) {
+	if cfg.UserEnvVar == "" || cfg.TokenEnvVar == "" {
+		return
+	}
+	req.SetBasicAuth(os.Getenv(cfg.UserEnvVar), os.Getenv(cfg.TokenEnvVar))
+}
+
+func (t JenkinsJobTask) client() *http.Client {
+	if t.Client != nil {
+		return t.Client
+	}
+	return http.DefaultClient
+}
+
+func (t JenkinsJobTask) clock() clock.Clock {
+	if t.Clock != nil {
+		return t.Clock
+	}
+	return clock.Real{}
+}
+
+func (t JenkinsJobTask) pollLimits(cfg JenkinsJobTaskConfig) (time.Duration, int) {
+	interval := cfg.PollInterval
+	if interval <= 0 {
+		interval = DefaultCIPollInterval
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultCIMaxPollAttempts
+	}
+	return interval, maxAttempts
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJenkinsJobTaskTriggersPollsAndReturnsTheBuildResult(t *testing.T) {
+	var queuePolls, buildPolls int32
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/job/deploy/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", server.URL+"/queue/item/42/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/queue/item/42/api/json", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&queuePolls, 1) < 2 {
+			w.Write([]byte(`{"executable":null}`))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(`{"executable":{"number":7,"url":%q}}`, server.URL+"/job/deploy/7/")))
+	})
+	mux.HandleFunc("/job/deploy/7/api/json", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&buildPolls, 1) < 2 {
+			w.Write([]byte(`{"building":true}`))
+			return
+		}
+		w.Write([]byte(`{"building":false,"result":"SUCCESS"}`))
+	})
+
+	task := JenkinsJobTask{}
+	cfg, err := json.Marshal(JenkinsJobTaskConfig{BaseURL: server.URL, JobName: "deploy", PollInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var result JenkinsJobResult
+	if jsonErr := json.Unmarshal([]byte(output), &result); jsonErr != nil {
+		t.Fatalf("unmarshal output: %v", jsonErr)
+	}
+	if result.Result != "SUCCESS" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestJenkinsJobTaskFailsOnANonSuccessResult(t *testing.T) {
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/job/deploy/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", server.URL+"/queue/item/1/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/queue/item/1/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"executable":{"number":1,"url":%q}}`, server.URL+"/job/deploy/1/")))
+	})
+	mux.HandleFunc("/job/deploy/1/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"building":false,"result":"FAILURE"}`))
+	})
+
+	task := JenkinsJobTask{}
+	config := fmt.Sprintf(`{"base_url":%q,"job_name":"deploy"}`, server.URL)
+	_, err := task.Run(context.Background(), config)
+	if err == nil {
+		t.Fatal("expected Run to fail on a FAILURE result")
+	}
+}
+
+func TestJenkinsJobTaskSendsBasicAuthFromConfiguredEnvVars(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_TEST_JENKINS_USER", "alice")
+	t.Setenv("ORCHESTRATOR_TEST_JENKINS_TOKEN", "tok123")
+
+	var gotUser, gotPass string
+	var mux http.ServeMux
+	server := httptest.NewServer(&mux)
+	defer server.Close()
+
+	mux.HandleFunc("/job/deploy/buildWithParameters", func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, _ = r.BasicAuth()
+		w.Header().Set("Location", server.URL+"/queue/item/1/")
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("/queue/item/1/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(fmt.Sprintf(`{"executable":{"number":1,"url":%q}}`, server.URL+"/job/deploy/1/")))
+	})
+	mux.HandleFunc("/job/deploy/1/api/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"building":false,"result":"SUCCESS"}`))
+	})
+
+	task := JenkinsJobTask{}
+	config := fmt.Sprintf(`{"base_url":%q,"job_name":"deploy","user_env_var":"ORCHESTRATOR_TEST_JENKINS_USER","token_env_var":"ORCHESTRATOR_TEST_JENKINS_TOKEN"}`, server.URL)
+	if _, err := task.Run(context.Background(), config); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if gotUser != "alice" || gotPass != "tok123" {
+		t.Fatalf("expected basic auth alice/tok123, got %s/%s", gotUser, gotPass)
+	}
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunFailsWhenExecutionVersionConflictsWithConcurrentWriter(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	// Simulate another writer updating this execution after it was
+	// loaded into memory here, so the in-memory Version is now stale.
+	if err := gormDB.Model(&model.WorkflowExecution{}).Where("id = ?", execution.ID).Update("version", execution.Version.Int64+1).Error; err != nil {
+		t.Fatalf("simulate concurrent writer: %v", err)
+	}
+
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	err := orchestrator.Run(context.Background(), execution, steps)
+	if !errors.Is(err, model.ErrConcurrentUpdate) {
+		t.Fatalf("expected ErrConcurrentUpdate, got %v", err)
+	}
+}
+
+func TestRunAdvancesExecutionAndStepVersionsAcrossSequentialSaves(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic())
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Two sequential saves happened on the execution (start, finish), so
+	// its version must have advanced by more than one without conflict.
+	var stored model.WorkflowExecution
+	if err := gormDB.First(&stored, execution.ID).Error; err != nil {
+		t.Fatalf("reload execution: %v", err)
+	}
+	if stored.Version.Int64 < 2 {
+		t.Fatalf("expected execution version to advance across its start/finish saves, got %d", stored.Version.Int64)
+	}
+	if stored.Version.Int64 != execution.Version.Int64 {
+		t.Fatalf("in-memory execution version %d out of sync with database %d", execution.Version.Int64, stored.Version.Int64)
+	}
+
+	var step model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&step).Error; err != nil {
+		t.Fatalf("reload step: %v", err)
+	}
+	if step.Version.Int64 < 2 {
+		t.Fatalf("expected step version to advance across its running/succeeded saves, got %d", step.Version.Int64)
+	}
+}
+
+func TestStatusWriterFlushFailsWhenStepVersionConflicts(t *testing.T) {
+	gormDB := newTestDB(t)
+
+	execution := &model.WorkflowExecution{Status: model.StatusRunning}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	step := &model.StepExecution{ExecutionID: execution.ID, StepName: "a", Status: model.StatusPending}
+	if err := gormDB.Create(step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	// Simulate another writer updating this step after it was loaded,
+	// so the version carried by our stale in-memory copy no longer
+	// matches the database.
+	if err := gormDB.Model(&model.StepExecution{}).Where("id = ?", step.ID).Update("version", step.Version.Int64+1).Error; err != nil {
+		t.Fatalf("simulate concurrent writer: %v", err)
+	}
+
+	writer := NewStatusWriter(gormDB, DefaultBatchSize, DefaultFlushInterval, nil, "")
+	defer writer.Close()
+	step.Status = model.StatusRunning
+	writer.Enqueue(step)
+
+	if err := writer.Flush(); !errors.Is(err, model.ErrConcurrentUpdate) {
+		t.Fatalf("expected ErrConcurrentUpdate, got %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package executor
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// concurrencyTrackingTask records how many instances of it are running
+// at once, so a test can assert an upper bound was respected.
+type concurrencyTrackingTask struct {
+	current *int32
+	peak    *int32
+}
+
+func (t concurrencyTrackingTask) Run(ctx context.Context, config string) (string, error) {
+	n := atomic.AddInt32(t.current, 1)
+	for {
+		peak := atomic.LoadInt32(t.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(t.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	atomic.AddInt32(t.current, -1)
+	return "", nil
+}
+
+func TestRunSimulatedHonorsExecutionMaxParallel(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	var current, peak int32
+	registry.Register("track", concurrencyTrackingTask{current: &current, peak: &peak})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, MaxParallel: 2}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "track"},
+		{Name: "b", Type: "track"},
+		{Name: "c", Type: "track"},
+		{Name: "d", Type: "track"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&peak); got > 2 {
+		t.Fatalf("expected at most 2 concurrent steps, observed %d", got)
+	}
+}
+
+func TestRunSimulatedUnboundedWhenMaxParallelIsZero(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	var current, peak int32
+	registry.Register("track", concurrencyTrackingTask{current: &current, peak: &peak})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "track"},
+		{Name: "b", Type: "track"},
+		{Name: "c", Type: "track"},
+		{Name: "d", Type: "track"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&peak); got != 4 {
+		t.Fatalf("expected all 4 independent steps to run at once with no cap, peak was %d", got)
+	}
+}
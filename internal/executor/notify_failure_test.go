@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/notify"
+)
+
+type failingTask struct{}
+
+func (failingTask) Run(ctx context.Context, config string) (string, error) {
+	return "", errors.New("boom")
+}
+
+func TestRunNotifiesOnceWithAFailureSummaryWhenStepsFail(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("fail", failingTask{})
+	registry.Register("shell", ShellTask{})
+
+	notifier := &recordingNotifier{}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic(), WithNotifier(notifier))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "fail"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+
+	if len(notifier.messages) != 1 {
+		t.Fatalf("expected exactly one failure notification, got %v", notifier.messages)
+	}
+	want := "2 step(s) failed or were skipped: a, b"
+	if notifier.messages[0] != want {
+		t.Fatalf("expected %q, got %q", want, notifier.messages[0])
+	}
+}
+
+func TestRunFailureNotificationDedupsAcrossExecutionsWithTheSameFailure(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("fail", failingTask{})
+
+	recorder := &recordingNotifier{}
+	notifier := notify.NewRateLimitedNotifier(recorder, time.Hour, 10)
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic(), WithNotifier(notifier))
+
+	for i := 0; i < 2; i++ {
+		execution := &model.WorkflowExecution{Status: model.StatusPending}
+		if err := gormDB.Create(execution).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+		steps := []model.TemplateStep{{Name: "a", Type: "fail"}}
+		if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+			t.Fatal("expected the run to fail")
+		}
+	}
+
+	if len(recorder.messages) != 1 {
+		t.Fatalf("expected the second execution's identical failure to be deduped, got %v", recorder.messages)
+	}
+}
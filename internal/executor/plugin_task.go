@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// PluginRequest is sent as a single JSON document on the plugin
+// process's stdin.
+type PluginRequest struct {
+	Config string `json:"config"`
+}
+
+// PluginResponse is read as a single JSON document from the plugin
+// process's stdout.
+type PluginResponse struct {
+	Output string `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// PluginTask runs an external process as a step's implementation,
+// letting operators register custom step types at deploy time without
+// recompiling the orchestrator. The step's config is written to the
+// process's stdin as a PluginRequest, and its result is read from stdout
+// as a PluginResponse.
+type PluginTask struct {
+	Command string
+	Args    []string
+}
+
+// NewPluginTask builds a PluginTask that invokes command with args for
+// every step run.
+func NewPluginTask(command string, args ...string) PluginTask {
+	return PluginTask{Command: command, Args: args}
+}
+
+// Run launches the plugin process, feeds it config and returns its
+// reported output or error.
+func (t PluginTask) Run(ctx context.Context, config string) (string, error) {
+	req, err := json.Marshal(PluginRequest{Config: config})
+	if err != nil {
+		return "", fmt.Errorf("plugin task: encode request: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, t.Command, t.Args...)
+	cmd.Stdin = bytes.NewReader(req)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("plugin task: %w: %s", err, stderr.String())
+	}
+
+	var resp PluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return "", fmt.Errorf("plugin task: decode response: %w", err)
+	}
+	if resp.Error != "" {
+		return resp.Output, fmt.Errorf("plugin task: %s", resp.Error)
+	}
+	return resp.Output, nil
+}
+
+// PluginConfig declares one external step type to register at startup,
+// e.g. loaded from a deploy-time config file or environment variable.
+type PluginConfig struct {
+	Type    string   `json:"type"`
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// RegisterPlugins registers every config in configs as a PluginTask on
+// registry, keyed by its step type.
+func RegisterPlugins(registry *Registry, configs []PluginConfig) {
+	for _, c := range configs {
+		registry.Register(c.Type, NewPluginTask(c.Command, c.Args...))
+	}
+}
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPluginTaskRunsExternalProcess(t *testing.T) {
+	task := NewPluginTask("sh", "-c", `read _; echo '{"output":"hello from plugin"}'`)
+	output, err := task.Run(context.Background(), "ignored")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "hello from plugin" {
+		t.Fatalf("expected plugin output, got %q", output)
+	}
+}
+
+func TestPluginTaskPropagatesReportedError(t *testing.T) {
+	task := NewPluginTask("sh", "-c", `read _; echo '{"error":"plugin failed"}'`)
+	_, err := task.Run(context.Background(), "ignored")
+	if err == nil {
+		t.Fatal("expected an error from the plugin")
+	}
+}
+
+func TestRegisterPluginsAddsToRegistry(t *testing.T) {
+	registry := NewRegistry()
+	RegisterPlugins(registry, []PluginConfig{
+		{Type: "custom", Command: "sh", Args: []string{"-c", "read _; echo '{}'"}},
+	})
+	if _, ok := registry.Lookup("custom"); !ok {
+		t.Fatal("expected custom plugin to be registered")
+	}
+}
@@ -0,0 +1,138 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/expr-lang/expr"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// PollTaskConfig is the JSON shape expected in a step's Config for
+// PollTask.
+type PollTaskConfig struct {
+	// InnerType is the registered Task type re-run on each attempt.
+	InnerType string `json:"inner_type"`
+	// InnerConfig is the Config passed to InnerType on every attempt,
+	// unchanged across attempts (e.g. the URL of the status endpoint to
+	// keep checking).
+	InnerConfig string `json:"inner_config"`
+	// ExitExpression is evaluated with github.com/expr-lang/expr after
+	// every successful attempt, against an environment of output (the
+	// attempt's raw output) and vars, and must produce a bool; polling
+	// stops once it's true.
+	ExitExpression string            `json:"exit_expression"`
+	Vars           map[string]string `json:"vars"`
+	// Interval is how long to wait between attempts.
+	Interval time.Duration `json:"interval"`
+	// MaxAttempts caps the number of attempts. Zero or less means no cap
+	// (MaxDuration should then be set, or polling can run forever).
+	MaxAttempts int `json:"max_attempts"`
+	// MaxDuration caps how long polling may run in total, measured from
+	// the first attempt. Zero or less means no cap.
+	MaxDuration time.Duration `json:"max_duration"`
+}
+
+// pollAttempt records one attempt's outcome.
+type pollAttempt struct {
+	Attempt int    `json:"attempt"`
+	Output  string `json:"output,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PollTask re-runs another registered Task at an interval until
+// ExitExpression is true on its output, or MaxAttempts / MaxDuration is
+// reached, recording every attempt (e.g. polling a deployment status
+// endpoint until it reports ready).
+type PollTask struct {
+	Registry *Registry
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+// Run re-runs config's InnerType against InnerConfig at Interval until
+// ExitExpression is true on an attempt's output, returning a
+// JSON-encoded list of pollAttempt in attempt order either way.
+func (t PollTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg PollTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("poll task: invalid config: %w", err)
+	}
+	if cfg.InnerType == "" {
+		return "", fmt.Errorf("poll task: inner_type is required")
+	}
+	if cfg.ExitExpression == "" {
+		return "", fmt.Errorf("poll task: exit_expression is required")
+	}
+	inner, ok := t.Registry.Lookup(cfg.InnerType)
+	if !ok {
+		return "", fmt.Errorf("poll task: unregistered inner_type %q", cfg.InnerType)
+	}
+
+	c := t.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+
+	program, err := expr.Compile(cfg.ExitExpression, expr.Env(pollExitEnv("", cfg.Vars)))
+	if err != nil {
+		return "", fmt.Errorf("poll task: compile exit_expression: %w", err)
+	}
+
+	var deadline time.Time
+	if cfg.MaxDuration > 0 {
+		deadline = c.Now().Add(cfg.MaxDuration)
+	}
+
+	var attempts []pollAttempt
+	for attempt := 1; ; attempt++ {
+		output, runErr := inner.Run(ctx, cfg.InnerConfig)
+		a := pollAttempt{Attempt: attempt, Output: output}
+		if runErr != nil {
+			a.Error = runErr.Error()
+		}
+		attempts = append(attempts, a)
+
+		if runErr == nil {
+			result, evalErr := expr.Run(program, pollExitEnv(output, cfg.Vars))
+			if evalErr != nil {
+				return encodePollAttempts(attempts), fmt.Errorf("poll task: evaluate exit_expression: %w", evalErr)
+			}
+			done, ok := result.(bool)
+			if !ok {
+				return encodePollAttempts(attempts), fmt.Errorf("poll task: exit_expression must produce a bool, got %T", result)
+			}
+			if done {
+				return encodePollAttempts(attempts), nil
+			}
+		}
+
+		if cfg.MaxAttempts > 0 && attempt >= cfg.MaxAttempts {
+			return encodePollAttempts(attempts), fmt.Errorf("poll task: exit_expression never became true after %d attempt(s)", attempt)
+		}
+		if !deadline.IsZero() && !c.Now().Before(deadline) {
+			return encodePollAttempts(attempts), fmt.Errorf("poll task: max_duration elapsed after %d attempt(s)", attempt)
+		}
+
+		select {
+		case <-ctx.Done():
+			return encodePollAttempts(attempts), ctx.Err()
+		case <-c.After(cfg.Interval):
+		}
+	}
+}
+
+func pollExitEnv(output string, vars map[string]string) map[string]interface{} {
+	return map[string]interface{}{"output": output, "vars": vars}
+}
+
+func encodePollAttempts(attempts []pollAttempt) string {
+	encoded, err := json.Marshal(attempts)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// sequenceTask returns outputs[calls], clamped to the last entry once
+// exhausted, so a test can script a status endpoint that only reports
+// "ready" after a few attempts.
+type sequenceTask struct {
+	outputs []string
+	calls   *int32
+}
+
+func (t sequenceTask) Run(ctx context.Context, config string) (string, error) {
+	n := int(atomic.AddInt32(t.calls, 1)) - 1
+	if n >= len(t.outputs) {
+		n = len(t.outputs) - 1
+	}
+	return t.outputs[n], nil
+}
+
+func waitForCallCount(t *testing.T, calls *int32, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if atomic.LoadInt32(calls) >= want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d call(s), got %d", want, atomic.LoadInt32(calls))
+}
+
+func TestPollTaskStopsOnceExitExpressionIsTrue(t *testing.T) {
+	var calls int32
+	registry := NewRegistry()
+	registry.Register("seq", sequenceTask{outputs: []string{"pending", "pending", "ready"}, calls: &calls})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	task := PollTask{Registry: registry, Clock: fakeClock}
+
+	config, _ := json.Marshal(PollTaskConfig{
+		InnerType:      "seq",
+		ExitExpression: `output == "ready"`,
+		Interval:       time.Second,
+	})
+
+	done := make(chan struct{})
+	var output string
+	var runErr error
+	go func() {
+		output, runErr = task.Run(context.Background(), string(config))
+		close(done)
+	}()
+
+	waitForCallCount(t, &calls, 1)
+	fakeClock.Advance(time.Second)
+	waitForCallCount(t, &calls, 2)
+	fakeClock.Advance(time.Second)
+
+	<-done
+	if runErr != nil {
+		t.Fatalf("Run: %v", runErr)
+	}
+	var attempts []pollAttempt
+	if err := json.Unmarshal([]byte(output), &attempts); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(attempts))
+	}
+	if attempts[2].Output != "ready" {
+		t.Fatalf("expected the final attempt's output to be %q, got %q", "ready", attempts[2].Output)
+	}
+}
+
+func TestPollTaskFailsAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	registry := NewRegistry()
+	registry.Register("seq", sequenceTask{outputs: []string{"pending"}, calls: &calls})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	task := PollTask{Registry: registry, Clock: fakeClock}
+
+	config, _ := json.Marshal(PollTaskConfig{
+		InnerType:      "seq",
+		ExitExpression: `output == "ready"`,
+		Interval:       time.Second,
+		MaxAttempts:    2,
+	})
+
+	done := make(chan struct{})
+	var output string
+	var runErr error
+	go func() {
+		output, runErr = task.Run(context.Background(), string(config))
+		close(done)
+	}()
+
+	waitForCallCount(t, &calls, 1)
+	fakeClock.Advance(time.Second)
+
+	<-done
+	if runErr == nil {
+		t.Fatal("expected an error once max_attempts is reached without the exit condition becoming true")
+	}
+	var attempts []pollAttempt
+	if err := json.Unmarshal([]byte(output), &attempts); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+}
+
+func TestPollTaskFailsAfterMaxDuration(t *testing.T) {
+	var calls int32
+	registry := NewRegistry()
+	registry.Register("seq", sequenceTask{outputs: []string{"pending"}, calls: &calls})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	task := PollTask{Registry: registry, Clock: fakeClock}
+
+	config, _ := json.Marshal(PollTaskConfig{
+		InnerType:      "seq",
+		ExitExpression: `output == "ready"`,
+		Interval:       time.Second,
+		MaxDuration:    90 * time.Second,
+	})
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		_, runErr = task.Run(context.Background(), string(config))
+		close(done)
+	}()
+
+	waitForCallCount(t, &calls, 1)
+	fakeClock.Advance(time.Minute)
+	waitForCallCount(t, &calls, 2)
+	fakeClock.Advance(time.Minute)
+
+	<-done
+	if runErr == nil {
+		t.Fatal("expected an error once max_duration elapses without the exit condition becoming true")
+	}
+}
+
+func TestPollTaskRejectsMissingExitExpression(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("seq", sequenceTask{outputs: []string{"ready"}, calls: new(int32)})
+	task := PollTask{Registry: registry}
+
+	config, _ := json.Marshal(PollTaskConfig{InnerType: "seq"})
+	if _, err := task.Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for a missing exit_expression")
+	}
+}
+
+func TestPollTaskRejectsUnregisteredInnerType(t *testing.T) {
+	task := PollTask{Registry: NewRegistry()}
+	config, _ := json.Marshal(PollTaskConfig{InnerType: "missing", ExitExpression: "output == \"ready\""})
+	if _, err := task.Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for an unregistered inner_type")
+	}
+}
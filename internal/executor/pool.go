@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/queue"
+)
+
+// BlackoutChecker reports whether starting an execution for project
+// (and its specific templateID) is blocked right now by a maintenance
+// window, so a WorkerPool can hold a queued execution back rather than
+// run it the moment a worker frees up. service.BlackoutService
+// implements this.
+type BlackoutChecker interface {
+	// Blocked reports whether project/templateID currently falls inside
+	// an active blackout, and if so, how long until it's worth checking
+	// again (typically the time remaining until the window ends).
+	Blocked(project string, templateID uint) (retryAfter time.Duration, blocked bool)
+}
+
+// WorkerPool bounds how many executions a TaskOrchestrator runs at once,
+// dispatching queued executions in priority order so a saturated pool
+// runs high-priority work (e.g. incident remediation) ahead of
+// low-priority batch executions, with fair-share across projects at
+// equal priority. Priority only reorders executions that are still
+// queued: once a worker picks one up it runs to completion rather than
+// being preempted mid-flight.
+type WorkerPool struct {
+	orchestrator *TaskOrchestrator
+	queue        *queue.Queue
+	sem          chan struct{}
+	active       int32 // atomic: workers currently running an execution
+	// blackouts, if set, is consulted right before a queued execution
+	// would start running: one blocked by an active window is put back
+	// on the queue and retried later instead of starting, so a blackout
+	// that begins after Submit was called still takes effect. An
+	// execution submitted with override true skips this check, the same
+	// way it skips ExecutionService.Start's own check.
+	blackouts BlackoutChecker
+
+	mu      sync.Mutex
+	pending map[uint]pendingExecution
+}
+
+// PoolOption configures a WorkerPool built by NewWorkerPool.
+type PoolOption func(*WorkerPool)
+
+// WithBlackoutChecker makes every dispatch wait out an active blackout
+// window, as reported by checker, instead of running the moment a
+// worker slot is free. Without this, a WorkerPool only reflects the
+// blackout state a caller already checked at Submit time via
+// ExecutionService.Start.
+func WithBlackoutChecker(checker BlackoutChecker) PoolOption {
+	return func(p *WorkerPool) { p.blackouts = checker }
+}
+
+// PoolStats is a point-in-time snapshot of a WorkerPool's utilization,
+// for tuning its concurrency.
+type PoolStats struct {
+	// ActiveWorkers is how many executions are running right now.
+	ActiveWorkers int `json:"active_workers"`
+	// Capacity is the pool's configured concurrency, from NewWorkerPool.
+	Capacity int `json:"capacity"`
+	// QueueDepth is how many executions are waiting for a free worker.
+	QueueDepth int `json:"queue_depth"`
+}
+
+// Stats returns a snapshot of the pool's current utilization.
+func (p *WorkerPool) Stats() PoolStats {
+	return PoolStats{
+		ActiveWorkers: int(atomic.LoadInt32(&p.active)),
+		Capacity:      cap(p.sem),
+		QueueDepth:    p.queue.Len(),
+	}
+}
+
+type pendingExecution struct {
+	execution  *model.WorkflowExecution
+	steps      []model.TemplateStep
+	slaSeconds int64
+	// override skips the blackouts check at dispatch time, the same way
+	// ExecutionService.Start's own blackout check is skipped for an
+	// admin-authorized override: Start already let this execution past
+	// an active window once, so queueing it behind the pool shouldn't
+	// hold it back until the window ends anyway.
+	override bool
+	done     chan error
+}
+
+// NewWorkerPool builds a WorkerPool that runs at most concurrency
+// executions at once via orchestrator.
+func NewWorkerPool(orchestrator *TaskOrchestrator, concurrency int, opts ...PoolOption) *WorkerPool {
+	p := &WorkerPool{
+		orchestrator: orchestrator,
+		queue:        queue.New(),
+		sem:          make(chan struct{}, concurrency),
+		pending:      make(map[uint]pendingExecution),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Submit enqueues execution to run once a worker is free, ahead of any
+// already-queued execution with a lower priority, and returns a channel
+// that receives its Run result exactly once. slaSeconds of zero or less
+// disables SLA tracking for this execution. override, if true, exempts
+// this execution from the blackouts check at dispatch time (see
+// pendingExecution.override).
+func (p *WorkerPool) Submit(ctx context.Context, execution *model.WorkflowExecution, steps []model.TemplateStep, project string, priority int, slaSeconds int64, override bool) <-chan error {
+	done := make(chan error, 1)
+
+	p.mu.Lock()
+	p.pending[execution.ID] = pendingExecution{execution: execution, steps: steps, slaSeconds: slaSeconds, override: override, done: done}
+	p.mu.Unlock()
+
+	p.queue.Push(&queue.Item{ExecutionID: execution.ID, Project: project, Priority: priority})
+	go p.dispatchOne(ctx)
+	return done
+}
+
+// dispatchOne waits for a free worker slot, then runs whichever queued
+// execution currently has the highest priority. It is not necessarily
+// the execution that triggered this call: any of the goroutines waiting
+// on the semaphore may pick up any queued item, which is what lets a
+// later, higher-priority Submit jump ahead of earlier, lower-priority
+// ones still waiting for a slot.
+//
+// If blackouts is configured and the execution it popped is currently
+// blocked, it puts the item back on the queue and retries after
+// retryAfter rather than running it or giving up the slot to another
+// item that's just as blocked, since most blackouts cover every
+// execution in a project at once. An execution submitted with override
+// true is exempt from this and runs immediately once it has a slot.
+func (p *WorkerPool) dispatchOne(ctx context.Context) {
+	p.sem <- struct{}{}
+
+	item := p.queue.Pop()
+	if item == nil {
+		<-p.sem
+		return
+	}
+
+	p.mu.Lock()
+	pe, ok := p.pending[item.ExecutionID]
+	p.mu.Unlock()
+	if !ok {
+		<-p.sem
+		return
+	}
+
+	if p.blackouts != nil && !pe.override {
+		if retryAfter, blocked := p.blackouts.Blocked(pe.execution.Project, pe.execution.TemplateID); blocked {
+			if retryAfter <= 0 {
+				retryAfter = time.Second
+			}
+			p.queue.Push(item)
+			<-p.sem
+			time.AfterFunc(retryAfter, func() { p.dispatchOne(ctx) })
+			return
+		}
+	}
+
+	p.mu.Lock()
+	delete(p.pending, item.ExecutionID)
+	p.mu.Unlock()
+
+	atomic.AddInt32(&p.active, 1)
+	defer func() {
+		atomic.AddInt32(&p.active, -1)
+		<-p.sem
+	}()
+	pe.done <- p.orchestrator.RunWithSLA(ctx, pe.execution, pe.steps, pe.slaSeconds)
+}
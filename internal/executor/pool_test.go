@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// blockingTask signals started once Run begins, then waits for release
+// before returning, so a test can hold a WorkerPool's only slot while it
+// queues up further work to observe dispatch order.
+type blockingTask struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (t blockingTask) Run(ctx context.Context, config string) (string, error) {
+	close(t.started)
+	<-t.release
+	return "", nil
+}
+
+func TestWorkerPoolDispatchesHighestPriorityFirst(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+
+	block := blockingTask{started: make(chan struct{}), release: make(chan struct{})}
+	registry.Register("block", block)
+
+	var mu sync.Mutex
+	var order []string
+	registry.Register("a", orderRecordingTask{mu: &mu, order: &order, name: "low"})
+	registry.Register("b", orderRecordingTask{mu: &mu, order: &order, name: "high"})
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	pool := NewWorkerPool(orchestrator, 1)
+
+	blocker := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(blocker).Error; err != nil {
+		t.Fatalf("create blocker execution: %v", err)
+	}
+	blockerDone := pool.Submit(context.Background(), blocker, []model.TemplateStep{{Name: "blocker", Type: "block"}}, "p", 0, 0, false)
+
+	select {
+	case <-block.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the pool's only worker to start")
+	}
+
+	low := &model.WorkflowExecution{Status: model.StatusPending}
+	high := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(low).Error; err != nil {
+		t.Fatalf("create low execution: %v", err)
+	}
+	if err := gormDB.Create(high).Error; err != nil {
+		t.Fatalf("create high execution: %v", err)
+	}
+
+	lowDone := pool.Submit(context.Background(), low, []model.TemplateStep{{Name: "low", Type: "a"}}, "p", 0, 0, false)
+	highDone := pool.Submit(context.Background(), high, []model.TemplateStep{{Name: "high", Type: "b"}}, "p", 10, 0, false)
+
+	close(block.release)
+	if err := <-blockerDone; err != nil {
+		t.Fatalf("blocker run: %v", err)
+	}
+	if err := <-highDone; err != nil {
+		t.Fatalf("high run: %v", err)
+	}
+	if err := <-lowDone; err != nil {
+		t.Fatalf("low run: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "high" || order[1] != "low" {
+		t.Fatalf("expected the higher-priority execution to run first, got %v", order)
+	}
+}
+
+func TestWorkerPoolStatsReportsActiveWorkersAndQueueDepth(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+
+	release := make(chan struct{})
+	blocker1 := blockingTask{started: make(chan struct{}), release: release}
+	blocker2 := blockingTask{started: make(chan struct{}), release: release}
+	registry.Register("block1", blocker1)
+	registry.Register("block2", blocker2)
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	pool := NewWorkerPool(orchestrator, 1)
+
+	if stats := pool.Stats(); stats.ActiveWorkers != 0 || stats.Capacity != 1 || stats.QueueDepth != 0 {
+		t.Fatalf("expected an idle pool, got %+v", stats)
+	}
+
+	blocker := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(blocker).Error; err != nil {
+		t.Fatalf("create blocker execution: %v", err)
+	}
+	blockerDone := pool.Submit(context.Background(), blocker, []model.TemplateStep{{Name: "blocker", Type: "block1"}}, "p", 0, 0, false)
+
+	select {
+	case <-blocker1.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the pool's only worker to start")
+	}
+
+	queued := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(queued).Error; err != nil {
+		t.Fatalf("create queued execution: %v", err)
+	}
+	queuedDone := pool.Submit(context.Background(), queued, []model.TemplateStep{{Name: "queued", Type: "block2"}}, "p", 0, 0, false)
+
+	waitForQueueDepth(t, pool, 1)
+	if stats := pool.Stats(); stats.ActiveWorkers != 1 || stats.QueueDepth != 1 {
+		t.Fatalf("expected 1 active worker and 1 queued execution, got %+v", stats)
+	}
+
+	close(release)
+	if err := <-blockerDone; err != nil {
+		t.Fatalf("blocker run: %v", err)
+	}
+	if err := <-queuedDone; err != nil {
+		t.Fatalf("queued run: %v", err)
+	}
+}
+
+// fakeBlackoutChecker lets a test flip whether every execution is
+// blocked, without depending on a real BlackoutService or wall clock.
+type fakeBlackoutChecker struct {
+	mu      sync.Mutex
+	blocked bool
+}
+
+func (f *fakeBlackoutChecker) Blocked(project string, templateID uint) (time.Duration, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return 50 * time.Millisecond, f.blocked
+}
+
+func (f *fakeBlackoutChecker) setBlocked(v bool) {
+	f.mu.Lock()
+	f.blocked = v
+	f.mu.Unlock()
+}
+
+func TestWorkerPoolHoldsABlockedExecutionUntilTheWindowClears(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	checker := &fakeBlackoutChecker{blocked: true}
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	pool := NewWorkerPool(orchestrator, 1, WithBlackoutChecker(checker))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	done := pool.Submit(context.Background(), execution, []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}, "p", 0, 0, false)
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected the execution to be held by the blackout, got result %v", err)
+	case <-time.After(150 * time.Millisecond):
+	}
+
+	checker.setBlocked(false)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the execution to run once the blackout cleared")
+	}
+}
+
+// TestWorkerPoolRunsAnOverriddenExecutionDespiteAnActiveBlackout
+// reproduces the bug a blackout override would otherwise hit once
+// WithBlackoutChecker is wired up: an execution Start already let past
+// an active window via an admin-authorized override must not then sit
+// re-queued by the pool until the window ends anyway.
+func TestWorkerPoolRunsAnOverriddenExecutionDespiteAnActiveBlackout(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	checker := &fakeBlackoutChecker{blocked: true}
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	pool := NewWorkerPool(orchestrator, 1, WithBlackoutChecker(checker))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	done := pool.Submit(context.Background(), execution, []model.TemplateStep{{Name: "a", Type: "shell", Config: "true"}}, "p", 0, 0, true)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the overridden execution to run despite the active blackout")
+	}
+}
+
+// waitForQueueDepth polls pool.Stats until its QueueDepth reaches want,
+// since Submit's dispatch goroutine races with the caller observing it.
+func waitForQueueDepth(t *testing.T, pool *WorkerPool, want int) {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		if pool.Stats().QueueDepth == want {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("timed out waiting for queue depth %d, last stats: %+v", want, pool.Stats())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// validatingTask fails preflight whenever its config is the literal
+// string "bad", so tests can force a Validate failure without depending
+// on a real Task's parsing rules.
+type validatingTask struct{}
+
+func (validatingTask) Run(ctx context.Context, config string) (string, error) {
+	return "ok", nil
+}
+
+func (validatingTask) Validate(config string) error {
+	if config == "bad" {
+		return fmt.Errorf("config is bad")
+	}
+	return nil
+}
+
+func TestRunFailsFastOnAStepThatFailsValidate(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("checked", validatingTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "checked", Config: "bad"}}
+
+	err := orchestrator.Run(context.Background(), execution, steps)
+	var preflightErr *PreflightError
+	if !errors.As(err, &preflightErr) {
+		t.Fatalf("expected a *PreflightError when a step fails Validate, got %v", err)
+	}
+	if len(preflightErr.Errors) != 1 || preflightErr.Errors[0].StepName != "a" {
+		t.Fatalf("expected step a to fail preflight, got %v", preflightErr.Errors)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.StepExecution{}).Count(&count).Error; err != nil {
+		t.Fatalf("count step executions: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no StepExecution rows since preflight caught this before the run started, got %d", count)
+	}
+}
+
+func TestRunReportsEveryFailingStepsPreflightErrorAtOnce(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("checked", validatingTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "checked", Config: "bad"},
+		{Name: "b", Type: "unregistered"},
+		{Name: "c", Type: "checked", Config: "good"},
+	}
+
+	err := orchestrator.Run(context.Background(), execution, steps)
+	var preflightErr *PreflightError
+	if !errors.As(err, &preflightErr) {
+		t.Fatalf("expected a *PreflightError, got %v", err)
+	}
+	if len(preflightErr.Errors) != 2 {
+		t.Fatalf("expected both failing steps reported together, got %v", preflightErr.Errors)
+	}
+}
+
+func TestRunSucceedsWhenEveryStepPassesValidate(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("checked", validatingTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "checked", Config: "good"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// stubRedactor scrubs occurrences of "secret" within project "scrub",
+// leaving every other project untouched, so tests can assert the
+// orchestrator scopes redaction by the execution's Project.
+type stubRedactor struct{}
+
+func (stubRedactor) Redact(project, text string) string {
+	if project != "scrub" {
+		return text
+	}
+	return strings.ReplaceAll(text, "secret", "[redacted]")
+}
+
+type echoTask struct{ text string }
+
+func (t echoTask) Run(ctx context.Context, config string) (string, error) {
+	return t.text, nil
+}
+
+func TestRunScrubsStepOutputUsingTheExecutionsProjectRedactor(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("echo", echoTask{text: "the secret is secret"})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithRedactor(stubRedactor{}))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, Project: "scrub"}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "echo"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Output != "the [redacted] is [redacted]" {
+		t.Fatalf("expected the step's stored output to be scrubbed, got %q", se.Output)
+	}
+}
+
+func TestRunLeavesOutputUnscrubbedForAProjectWithNoMatchingRules(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("echo", echoTask{text: "the secret is secret"})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithRedactor(stubRedactor{}))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, Project: "other"}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "echo"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Output != "the secret is secret" {
+		t.Fatalf("expected the step's stored output to be left unscrubbed, got %q", se.Output)
+	}
+}
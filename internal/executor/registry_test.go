@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"context"
+	"testing"
+)
+
+// schemaTask implements SchemaProvider with a fixed schema, for testing
+// that Registry.Types surfaces it.
+type schemaTask struct{}
+
+func (t schemaTask) Run(ctx context.Context, config string) (string, error) {
+	return "ok", nil
+}
+
+func (t schemaTask) ConfigSchema() string {
+	return `{"type":"object"}`
+}
+
+func TestRegistryTypesReportsSchemaOnlyForSchemaProviders(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	registry.Register("with-schema", schemaTask{})
+
+	types := registry.Types()
+	if len(types) != 2 {
+		t.Fatalf("expected 2 registered types, got %d", len(types))
+	}
+	if types[0].Name != "shell" || types[0].ConfigSchema != "" {
+		t.Errorf("shell: got %+v, want no schema", types[0])
+	}
+	if types[1].Name != "with-schema" || types[1].ConfigSchema == "" {
+		t.Errorf("with-schema: got %+v, want a schema", types[1])
+	}
+}
+
+func TestRegistryDisableBlocksLookupWithoutUnregistering(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	registry.Disable("shell")
+	if _, ok := registry.Lookup("shell"); ok {
+		t.Fatal("expected Lookup to fail for a disabled type")
+	}
+	types := registry.Types()
+	if len(types) != 1 || types[0].Enabled {
+		t.Fatalf("expected shell to still be listed as disabled, got %+v", types)
+	}
+
+	registry.Enable("shell")
+	if _, ok := registry.Lookup("shell"); !ok {
+		t.Fatal("expected Lookup to succeed after Enable")
+	}
+}
+
+func TestRegistryDisableOfAnUnregisteredTypeIsHarmless(t *testing.T) {
+	registry := NewRegistry()
+	registry.Disable("nonexistent")
+	if _, ok := registry.Lookup("nonexistent"); ok {
+		t.Fatal("expected Lookup to fail for an unregistered type")
+	}
+}
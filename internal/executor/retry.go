@@ -0,0 +1,165 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// RetryClassifier decides whether an error returned by a Task's Run is
+// worth retrying (e.g. a 5xx response or a network timeout) or
+// permanent (e.g. a 4xx response), so RetryableExecutor can stop early
+// on errors another attempt can't fix.
+type RetryClassifier func(error) bool
+
+// DefaultRetryClassifier retries any error that isn't a *StatusError in
+// the 4xx range: a 5xx or 429 StatusError is retried, any other
+// StatusError is treated as permanent, and every other error (a
+// connection failure, a context deadline, ...) is retried, since those
+// are usually transient. A StatusError whose Retryable field is set
+// (from a step's own RetryStatusCodes/FailFastStatusCodes) overrides
+// this generic rule.
+func DefaultRetryClassifier(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.Retryable != nil {
+			return *statusErr.Retryable
+		}
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// NeverRetry treats every error as permanent. It's the default
+// classifier for task types whose failures don't get better on retry,
+// such as ShellTask's nonzero exit codes.
+func NeverRetry(error) bool { return false }
+
+// defaultRetryClassifierFor picks DefaultRetryClassifier for task types
+// whose errors it knows how to classify and NeverRetry for ones whose
+// failures are reliably reproducible, so NewRetryableExecutor does the
+// right thing out of the box for every built-in Task without the
+// caller having to know which is which.
+func defaultRetryClassifierFor(task Task) RetryClassifier {
+	switch task.(type) {
+	case ShellTask, ScriptTask, WASMTask, AWSActionTask, GCPActionTask, AnsiblePlaybookTask, ServiceControlTask, DatabaseBackupTask, DatabaseRestoreTask:
+		return NeverRetry
+	default:
+		return DefaultRetryClassifier
+	}
+}
+
+// RetryableExecutor wraps another Task, retrying its Run with jittered
+// exponential backoff on errors its RetryClassifier considers
+// transient, so a step type doesn't need retry logic of its own.
+type RetryableExecutor struct {
+	Task Task
+
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	retryOn     RetryClassifier
+	clock       clock.Clock
+	rand        *rand.Rand
+}
+
+// RetryOption configures a RetryableExecutor built by NewRetryableExecutor.
+type RetryOption func(*RetryableExecutor)
+
+// WithMaxAttempts sets how many times Run tries task, including the
+// first attempt. The default is 3.
+func WithMaxAttempts(n int) RetryOption {
+	return func(e *RetryableExecutor) { e.maxAttempts = n }
+}
+
+// WithBackoff sets the base and maximum delay between attempts. A
+// retry after attempt n waits a random duration in
+// [0, min(max, base*2^(n-1))) ("full jitter"). The defaults are 100ms
+// and 5s.
+func WithBackoff(base, max time.Duration) RetryOption {
+	return func(e *RetryableExecutor) { e.baseDelay = base; e.maxDelay = max }
+}
+
+// WithRetryOn overrides which errors are considered retryable. The
+// default depends on the wrapped Task's type; see
+// defaultRetryClassifierFor.
+func WithRetryOn(classifier RetryClassifier) RetryOption {
+	return func(e *RetryableExecutor) { e.retryOn = classifier }
+}
+
+// WithRetryClock overrides the clock used to wait between attempts.
+// The default is clock.Real{}.
+func WithRetryClock(c clock.Clock) RetryOption {
+	return func(e *RetryableExecutor) { e.clock = c }
+}
+
+// WithRetryRand overrides the source of jitter, for deterministic
+// tests. The default draws from the global math/rand source.
+func WithRetryRand(r *rand.Rand) RetryOption {
+	return func(e *RetryableExecutor) { e.rand = r }
+}
+
+// NewRetryableExecutor wraps task with retry logic, configured by opts.
+func NewRetryableExecutor(task Task, opts ...RetryOption) *RetryableExecutor {
+	e := &RetryableExecutor{
+		Task:        task,
+		maxAttempts: 3,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+		retryOn:     defaultRetryClassifierFor(task),
+		clock:       clock.Real{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run executes the wrapped Task, retrying with jittered exponential
+// backoff while attempts remain and the configured RetryClassifier
+// still considers the latest error retryable. A *StatusError carrying a
+// RetryAfter (parsed from the response's Retry-After header) is waited
+// out exactly instead of the jittered backoff.
+func (e *RetryableExecutor) Run(ctx context.Context, config string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		output, err := e.Task.Run(ctx, config)
+		if err == nil {
+			return output, nil
+		}
+		if attempt >= e.maxAttempts || !e.retryOn(err) {
+			return output, err
+		}
+
+		delay := e.backoff(attempt)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			delay = statusErr.RetryAfter
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-e.clock.After(delay):
+		}
+	}
+}
+
+// backoff returns a full-jitter delay for the given attempt number
+// (1-based): a random duration in [0, min(maxDelay, baseDelay*2^(attempt-1))).
+func (e *RetryableExecutor) backoff(attempt int) time.Duration {
+	ceiling := e.maxDelay
+	if shifted := e.baseDelay << (attempt - 1); shifted > 0 && shifted < ceiling {
+		ceiling = shifted
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	if e.rand != nil {
+		return time.Duration(e.rand.Int63n(int64(ceiling)))
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)))
+}
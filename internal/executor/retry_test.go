@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// failNTimesTask fails its first n calls with err, then succeeds with
+// output "done".
+type failNTimesTask struct {
+	n     int32
+	err   error
+	calls *int32
+}
+
+func (t failNTimesTask) Run(ctx context.Context, config string) (string, error) {
+	if atomic.AddInt32(t.calls, 1) <= t.n {
+		return "", t.err
+	}
+	return "done", nil
+}
+
+func TestRetryableExecutorRetriesUntilSuccess(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 2, err: errors.New("transient"), calls: &calls}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(5), WithRetryClock(fakeClock), WithRetryRand(rand.New(rand.NewSource(1))))
+
+	done := make(chan struct{})
+	var output string
+	var runErr error
+	go func() {
+		output, runErr = executor.Run(context.Background(), "")
+		close(done)
+	}()
+
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Advance(time.Minute)
+	for atomic.LoadInt32(&calls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Advance(time.Minute)
+
+	<-done
+	if runErr != nil {
+		t.Fatalf("Run: %v", runErr)
+	}
+	if output != "done" {
+		t.Fatalf("expected %q, got %q", "done", output)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryableExecutorStopsAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 10, err: errors.New("transient"), calls: &calls}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(3), WithRetryClock(fakeClock))
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		_, runErr = executor.Run(context.Background(), "")
+		close(done)
+	}()
+
+	for i := 0; i < 2; i++ {
+		for atomic.LoadInt32(&calls) < int32(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+		fakeClock.Advance(time.Minute)
+	}
+
+	<-done
+	if runErr == nil {
+		t.Fatal("expected an error once attempts are exhausted")
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryableExecutorDoesNotRetryPermanentErrors(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 10, err: &StatusError{StatusCode: 404}, calls: &calls}
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(5))
+
+	if _, err := executor.Run(context.Background(), ""); err == nil {
+		t.Fatal("expected a 404 to surface as an error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 call for a permanent error, got %d", calls)
+	}
+}
+
+func TestRetryableExecutorRetriesServerErrors(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 1, err: &StatusError{StatusCode: 503}, calls: &calls}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(3), WithRetryClock(fakeClock))
+
+	done := make(chan struct{})
+	var runErr error
+	go func() {
+		_, runErr = executor.Run(context.Background(), "")
+		close(done)
+	}()
+
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Advance(time.Minute)
+
+	<-done
+	if runErr != nil {
+		t.Fatalf("Run: %v", runErr)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetryableExecutorHonorsWithRetryOn(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 10, err: errors.New("whatever"), calls: &calls}
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(5), WithRetryOn(NeverRetry))
+
+	if _, err := executor.Run(context.Background(), ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Fatalf("expected NeverRetry to stop after 1 call, got %d", calls)
+	}
+}
+
+func TestRetryableExecutorStopsOnContextCancellation(t *testing.T) {
+	var calls int32
+	inner := failNTimesTask{n: 10, err: errors.New("transient"), calls: &calls}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	executor := NewRetryableExecutor(inner, WithMaxAttempts(10), WithRetryClock(fakeClock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := executor.Run(ctx, "")
+		done <- err
+	}()
+
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for Run to return after cancellation")
+	}
+}
+
+func TestDefaultRetryClassifierForShellTaskNeverRetries(t *testing.T) {
+	executor := NewRetryableExecutor(ShellTask{})
+	if executor.retryOn(errors.New("exit status 1")) {
+		t.Fatal("expected ShellTask's default classifier to treat errors as permanent")
+	}
+}
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestRunSimulatedAllSuccessReturnsNil(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "true"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	if err := orchestrator.RunSimulated(context.Background(), execution, steps, nil); err != nil {
+		t.Fatalf("RunSimulated: %v", err)
+	}
+}
+
+func TestRunSimulatedReturnsRunResultDistinguishingFailedFromSkipped(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "false"},
+		{Name: "b", Type: "shell", Config: "true", DependsOn: "a"},
+		{Name: "c", Type: "shell", Config: "true"},
+	}
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+	err := orchestrator.RunSimulated(context.Background(), execution, steps, nil)
+	if err == nil {
+		t.Fatal("expected RunSimulated to report failure")
+	}
+
+	var result *RunResult
+	if !errors.As(err, &result) {
+		t.Fatalf("expected a *RunResult, got %T: %v", err, err)
+	}
+
+	if node := result.Nodes["a"]; node.Status != model.StatusFailed || node.Skipped || node.Error == "" {
+		t.Fatalf("expected %q to be a reported failure with a message, got %+v", "a", node)
+	}
+	if node := result.Nodes["b"]; !node.Skipped || node.Status != model.StatusSkipped {
+		t.Fatalf("expected %q to be skipped because its dependency failed, got %+v", "b", node)
+	}
+	if node := result.Nodes["c"]; node.Status != model.StatusSucceeded || node.Skipped {
+		t.Fatalf("expected %q to succeed independently of the failure, got %+v", "c", node)
+	}
+
+	errs := result.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected exactly one error for the one step that actually failed, got %v", errs)
+	}
+}
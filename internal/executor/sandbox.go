@@ -0,0 +1,59 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox creates an isolated working directory per step attempt, rooted
+// under Dir, so concurrent steps -- even belonging to different
+// executions running at once -- never share a filesystem path.
+type Sandbox struct {
+	// Dir is the root every step's own working directory is created
+	// under. It must already exist.
+	Dir string
+	// Retain keeps a step's working directory's contents once the step
+	// finishes, persisted as artifacts if the orchestrator has an
+	// artifact.Store configured (see WithArtifactStore), or simply left
+	// on disk for manual inspection otherwise. Without Retain, the
+	// directory and everything a step left in it is removed as soon as
+	// the step finishes.
+	Retain bool
+}
+
+// NewSandbox builds a Sandbox rooted at dir.
+func NewSandbox(dir string, retain bool) *Sandbox {
+	return &Sandbox{Dir: dir, Retain: retain}
+}
+
+// dirFor creates and returns the working directory for one step
+// attempt, namespaced by execution ID, step name and attempt so a step
+// rerun via Resume/RerunStage/RerunStep gets its own fresh directory
+// instead of colliding with an earlier attempt still being retained.
+func (s *Sandbox) dirFor(executionID uint, stepName string, attempt int) (string, error) {
+	if err := validatePathComponent(stepName); err != nil {
+		return "", fmt.Errorf("sandbox: step name %q: %w", stepName, err)
+	}
+	dir := filepath.Join(s.Dir, fmt.Sprintf("%d", executionID), fmt.Sprintf("%s-%d", stepName, attempt))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("sandbox: create working directory for %q: %w", stepName, err)
+	}
+	return dir, nil
+}
+
+// validatePathComponent rejects a step name that isn't safe to use
+// verbatim as a single filesystem path component, since nothing
+// upstream of Sandbox restricts what a template step can be named: a
+// name containing a path separator or ".." could otherwise make dirFor
+// build a path that escapes s.Dir entirely (e.g. "../../../../tmp/x").
+func validatePathComponent(name string) error {
+	if name == "" {
+		return fmt.Errorf("empty")
+	}
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, os.PathSeparator) || strings.Contains(name, "..") {
+		return fmt.Errorf("must not contain a path separator or \"..\"")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestShellTaskRunsInTheWorkingDirFromContext(t *testing.T) {
+	task := ShellTask{}
+	dir := t.TempDir()
+	ctx := withWorkingDir(context.Background(), dir)
+
+	out, err := task.Run(ctx, "pwd")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, err := filepath.EvalSymlinks(out[:len(out)-1])
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	want, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %v", err)
+	}
+	if got != want {
+		t.Fatalf("expected the shell to run in %q, got %q", want, got)
+	}
+}
+
+func TestRunGivesConcurrentStepsIsolatedSandboxDirectories(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	root := t.TempDir()
+	// Retain so the per-step directories are still there to inspect once
+	// Run returns, instead of being cleaned up the moment each step
+	// finishes.
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithSandbox(NewSandbox(root, true)))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", Config: "touch marker"},
+		{Name: "b", Type: "shell", Config: "touch marker"},
+	}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(root, fmt.Sprintf("%d", execution.ID)))
+	if err != nil {
+		t.Fatalf("read execution sandbox root: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected a separate directory per step, got %v", entries)
+	}
+}
+
+func TestDirForRejectsAStepNameThatWouldEscapeTheSandboxRoot(t *testing.T) {
+	sandbox := NewSandbox(t.TempDir(), false)
+
+	for _, name := range []string{"../../../../tmp/x", "a/b", "a" + string(os.PathSeparator) + "b", ".."} {
+		if _, err := sandbox.dirFor(1, name, 1); err == nil {
+			t.Fatalf("expected dirFor to reject step name %q", name)
+		}
+	}
+}
+
+func TestRunFailsAStepWhoseNameWouldEscapeTheSandboxRoot(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	root := t.TempDir()
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithSandbox(NewSandbox(root, false)))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "../../../../tmp/escape", Type: "shell", Config: "echo hi"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to fail the step instead of escaping the sandbox root")
+	}
+}
+
+func TestRunRemovesTheSandboxDirWithoutRetain(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	root := t.TempDir()
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithSandbox(NewSandbox(root, false)))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "echo hi"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, fmt.Sprintf("%d", execution.ID), "a-1")); !os.IsNotExist(err) {
+		t.Fatalf("expected the sandbox dir to be removed, stat err: %v", err)
+	}
+}
+
+func TestRunRecordsARetainedSandboxDirAsArtifacts(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	root := t.TempDir()
+	artifacts := artifact.NewLocalStore(t.TempDir())
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithSandbox(NewSandbox(root, true)), WithArtifactStore(artifacts))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "echo hi > output.txt"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.Artifact{}).Where("execution_id = ? AND step_name = ?", execution.ID, "a").Count(&count).Error; err != nil {
+		t.Fatalf("count artifacts: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the retained sandbox file to be recorded as one artifact, got %d", count)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, fmt.Sprintf("%d", execution.ID), "a-1")); !os.IsNotExist(err) {
+		t.Fatalf("expected the sandbox dir to be removed after recording its contents as artifacts, stat err: %v", err)
+	}
+}
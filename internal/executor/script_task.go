@@ -0,0 +1,61 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/expr-lang/expr"
+)
+
+// ScriptTaskConfig is the JSON shape expected in a step's Config for
+// ScriptTask.
+type ScriptTaskConfig struct {
+	// Expression is evaluated with github.com/expr-lang/expr. It can
+	// reference outputs (a map of predecessor step name to output, via
+	// StepOutputsFromContext) and vars (static values from the template).
+	Expression string            `json:"expression"`
+	Vars       map[string]string `json:"vars"`
+}
+
+// ScriptTask evaluates a small expression against its predecessors'
+// outputs, letting templates transform data or compute a branching
+// condition without a round trip to an external service. Its result is
+// returned as output: strings are passed through verbatim, everything
+// else is JSON-encoded so downstream steps get a stable representation.
+type ScriptTask struct{}
+
+// Run evaluates the expression in config against an environment built
+// from ctx's predecessor outputs and the config's static vars.
+func (ScriptTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg ScriptTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("script task: invalid config: %w", err)
+	}
+	if cfg.Expression == "" {
+		return "", fmt.Errorf("script task: expression is required")
+	}
+
+	env := map[string]interface{}{
+		"outputs": StepOutputsFromContext(ctx),
+		"vars":    cfg.Vars,
+	}
+
+	program, err := expr.Compile(cfg.Expression, expr.Env(env))
+	if err != nil {
+		return "", fmt.Errorf("script task: compile expression: %w", err)
+	}
+	result, err := expr.Run(program, env)
+	if err != nil {
+		return "", fmt.Errorf("script task: evaluate expression: %w", err)
+	}
+
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("script task: encode result: %w", err)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestScriptTaskReadsPredecessorOutputs(t *testing.T) {
+	ctx := withStepOutputs(context.Background(), map[string]string{"fetch": "42"})
+	config, _ := json.Marshal(ScriptTaskConfig{Expression: `outputs.fetch + "!"`})
+
+	output, err := (ScriptTask{}).Run(ctx, string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "42!" {
+		t.Fatalf("expected %q, got %q", "42!", output)
+	}
+}
+
+func TestScriptTaskEncodesNonStringResults(t *testing.T) {
+	config, _ := json.Marshal(ScriptTaskConfig{Expression: "1 + 1"})
+	output, err := (ScriptTask{}).Run(context.Background(), string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "2" {
+		t.Fatalf("expected %q, got %q", "2", output)
+	}
+}
+
+func TestScriptTaskRejectsMissingExpression(t *testing.T) {
+	config, _ := json.Marshal(ScriptTaskConfig{})
+	if _, err := (ScriptTask{}).Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for a missing expression")
+	}
+}
@@ -0,0 +1,237 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// DefaultServiceVerifyInterval and DefaultServiceVerifyAttempts bound
+// how long ServiceControlTask waits for a service to reach its expected
+// state when a step's own VerifyInterval/VerifyAttempts is unset.
+const DefaultServiceVerifyInterval = 2 * time.Second
+const DefaultServiceVerifyAttempts = 10
+
+// ServiceControlTaskConfig is the JSON shape expected in a step's
+// Config for ServiceControlTask.
+type ServiceControlTaskConfig struct {
+	Host    string `json:"host"`
+	Service string `json:"service"`
+	// Action is one of "start", "stop", "restart" or "status".
+	Action string `json:"action"`
+	// Platform is "linux" (systemd, the default) or "windows" (sc.exe).
+	Platform string `json:"platform,omitempty"`
+	// SSHUser and SSHArgs configure the ssh invocation used to reach
+	// Host; credentials (a key, an agent socket) are expected to
+	// already be set up in the environment ssh runs in, the same as
+	// every other CLI-wrapping task in this package.
+	SSHUser string   `json:"ssh_user,omitempty"`
+	SSHArgs []string `json:"ssh_args,omitempty"`
+
+	VerifyInterval time.Duration `json:"verify_interval,omitempty"`
+	VerifyAttempts int           `json:"verify_attempts,omitempty"`
+}
+
+// ServiceControlResult is the JSON-encoded output ServiceControlTask
+// leaves in a step's Output.
+type ServiceControlResult struct {
+	Host    string `json:"host"`
+	Service string `json:"service"`
+	State   string `json:"state"`
+}
+
+// ServiceControlTask starts, stops, restarts or queries a systemd or
+// Windows service on a target host over SSH, verifying the service
+// reached its expected state afterward rather than trusting the
+// control command's exit code alone (a restart can exit 0 and still
+// leave the service crash-looping). This orchestrator's Agent inventory
+// is reporting-only (see model.Agent), so remote execution goes through
+// SSH the same way AnsiblePlaybookTask does, rather than through Agent.
+type ServiceControlTask struct {
+	// CLI defaults to "ssh".
+	CLI string
+	// Clock defaults to clock.Real{} if nil.
+	Clock clock.Clock
+}
+
+var serviceControlExpectedState = map[string]map[string]string{
+	"linux":   {"start": "active", "restart": "active", "stop": "inactive"},
+	"windows": {"start": "RUNNING", "restart": "RUNNING", "stop": "STOPPED"},
+}
+
+// Run implements Task.
+func (t ServiceControlTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg ServiceControlTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("service control task: invalid config: %w", err)
+	}
+	if cfg.Host == "" {
+		return "", fmt.Errorf("service control task: host is required")
+	}
+	if cfg.Service == "" {
+		return "", fmt.Errorf("service control task: service is required")
+	}
+	platform := cfg.Platform
+	if platform == "" {
+		platform = "linux"
+	}
+	if platform != "linux" && platform != "windows" {
+		return "", fmt.Errorf("service control task: unsupported platform %q", platform)
+	}
+
+	statusCmd, err := serviceControlStatusCommand(platform, cfg.Service)
+	if err != nil {
+		return "", err
+	}
+
+	switch cfg.Action {
+	case "status":
+		state, _ := t.queryState(ctx, cfg, platform, statusCmd)
+		return encodeServiceControlResult(cfg, state)
+	case "start", "stop", "restart":
+		actionCmd, err := serviceControlActionCommand(platform, cfg.Action, cfg.Service)
+		if err != nil {
+			return "", err
+		}
+		if _, err := t.runSSH(ctx, cfg, actionCmd); err != nil {
+			return "", fmt.Errorf("service control task: %s %s on %s: %w", cfg.Action, cfg.Service, cfg.Host, err)
+		}
+	default:
+		return "", fmt.Errorf("service control task: unsupported action %q", cfg.Action)
+	}
+
+	expected := serviceControlExpectedState[platform][cfg.Action]
+	state, err := t.verifyState(ctx, cfg, platform, statusCmd, expected)
+	if err != nil {
+		return encodeOrEmptyServiceControlResult(cfg, state), err
+	}
+	return encodeServiceControlResult(cfg, state)
+}
+
+// verifyState polls statusCmd until it reports expected, or
+// VerifyAttempts is exhausted.
+func (t ServiceControlTask) verifyState(ctx context.Context, cfg ServiceControlTaskConfig, platform, statusCmd, expected string) (string, error) {
+	c := t.Clock
+	if c == nil {
+		c = clock.Real{}
+	}
+	interval := cfg.VerifyInterval
+	if interval <= 0 {
+		interval = DefaultServiceVerifyInterval
+	}
+	maxAttempts := cfg.VerifyAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultServiceVerifyAttempts
+	}
+
+	var state string
+	for attempt := 1; ; attempt++ {
+		state, _ = t.queryState(ctx, cfg, platform, statusCmd)
+		if state == expected {
+			return state, nil
+		}
+		if attempt >= maxAttempts {
+			return state, fmt.Errorf("service control task: %s on %s never reached state %q after %d attempt(s), last seen %q", cfg.Service, cfg.Host, expected, attempt, state)
+		}
+		select {
+		case <-ctx.Done():
+			return state, ctx.Err()
+		case <-c.After(interval):
+		}
+	}
+}
+
+func (t ServiceControlTask) queryState(ctx context.Context, cfg ServiceControlTaskConfig, platform, statusCmd string) (string, error) {
+	out, err := t.runSSH(ctx, cfg, statusCmd)
+	state := parseServiceControlState(platform, out)
+	if err != nil && state == "" {
+		return "", fmt.Errorf("service control task: query state of %s on %s: %w", cfg.Service, cfg.Host, err)
+	}
+	return state, nil
+}
+
+func (t ServiceControlTask) runSSH(ctx context.Context, cfg ServiceControlTaskConfig, remoteCommand string) (string, error) {
+	cli := t.CLI
+	if cli == "" {
+		cli = "ssh"
+	}
+	args := append([]string{}, cfg.SSHArgs...)
+	if cfg.SSHUser != "" {
+		args = append(args, "-l", cfg.SSHUser)
+	}
+	args = append(args, cfg.Host, remoteCommand)
+	return runCLI(ctx, cli, args)
+}
+
+func serviceControlActionCommand(platform, action, service string) (string, error) {
+	switch platform {
+	case "linux":
+		switch action {
+		case "start", "stop", "restart":
+			return fmt.Sprintf("sudo systemctl %s %s", action, service), nil
+		}
+	case "windows":
+		switch action {
+		case "start":
+			return fmt.Sprintf("sc start %s", service), nil
+		case "stop":
+			return fmt.Sprintf("sc stop %s", service), nil
+		case "restart":
+			return fmt.Sprintf("sc stop %s & sc start %s", service, service), nil
+		}
+	}
+	return "", fmt.Errorf("service control task: unsupported action %q for platform %q", action, platform)
+}
+
+func serviceControlStatusCommand(platform, service string) (string, error) {
+	switch platform {
+	case "linux":
+		return fmt.Sprintf("systemctl is-active %s", service), nil
+	case "windows":
+		return fmt.Sprintf("sc query %s", service), nil
+	}
+	return "", fmt.Errorf("service control task: unsupported platform %q", platform)
+}
+
+// parseServiceControlState extracts a single state token from a status
+// command's combined output: systemctl is-active prints the state on
+// its own line (e.g. "active"); sc query prints a multi-line report
+// with a "STATE" line ending in the state name (e.g. "RUNNING").
+func parseServiceControlState(platform, output string) string {
+	switch platform {
+	case "linux":
+		return strings.TrimSpace(output)
+	case "windows":
+		for _, line := range strings.Split(output, "\n") {
+			if !strings.Contains(line, "STATE") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			return fields[len(fields)-1]
+		}
+	}
+	return ""
+}
+
+func encodeServiceControlResult(cfg ServiceControlTaskConfig, state string) (string, error) {
+	encoded, err := json.Marshal(ServiceControlResult{Host: cfg.Host, Service: cfg.Service, State: state})
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func encodeOrEmptyServiceControlResult(cfg ServiceControlTaskConfig, state string) string {
+	output, err := encodeServiceControlResult(cfg, state)
+	if err != nil {
+		return ""
+	}
+	return output
+}
@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeSSH writes a fake "ssh" script that tracks a service's simulated
+// state in a file, so tests can exercise ServiceControlTask's
+// action-then-verify loop without a real host. scripted maps a
+// substring of the remote command to the state it should leave behind
+// ("" means "just report the current state").
+func fakeSSH(t *testing.T, stateFile string, scripted map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fake-ssh")
+
+	script := "#!/bin/bash\ncmd=\"${@: -1}\"\n"
+	for substr, newState := range scripted {
+		if newState == "" {
+			continue
+		}
+		script += "case \"$cmd\" in *'" + substr + "'*) echo '" + newState + "' > '" + stateFile + "' ;; esac\n"
+	}
+	script += "state=$(cat '" + stateFile + "' 2>/dev/null || echo unknown)\n"
+	script += "case \"$cmd\" in\n"
+	script += "  *'is-active'*) echo \"$state\"; [ \"$state\" = active ] && exit 0 || exit 3 ;;\n"
+	script += "  *'sc query'*) echo \"        STATE              : 4  $state\" ;;\n"
+	script += "esac\n"
+	script += "exit 0\n"
+
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ssh: %v", err)
+	}
+	return path
+}
+
+func TestServiceControlTaskStartsAndVerifiesALinuxService(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	os.WriteFile(stateFile, []byte("inactive"), 0o644)
+	ssh := fakeSSH(t, stateFile, map[string]string{"systemctl start": "active"})
+
+	task := ServiceControlTask{CLI: ssh}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{
+		Host: "web-1", Service: "nginx", Action: "start", VerifyInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result ServiceControlResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.State != "active" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestServiceControlTaskFailsIfTheServiceNeverReachesTheExpectedState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	os.WriteFile(stateFile, []byte("inactive"), 0o644)
+	// The restart command doesn't flip the state file, simulating a
+	// crash loop where the control command exits 0 but the service
+	// never actually comes up.
+	ssh := fakeSSH(t, stateFile, map[string]string{})
+
+	task := ServiceControlTask{CLI: ssh}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{
+		Host: "web-1", Service: "nginx", Action: "restart",
+		VerifyInterval: time.Millisecond, VerifyAttempts: 3,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to fail when the service never reaches the expected state")
+	}
+}
+
+func TestServiceControlTaskReportsStatusWithoutChangingAnything(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	os.WriteFile(stateFile, []byte("active"), 0o644)
+	ssh := fakeSSH(t, stateFile, map[string]string{"systemctl start": "active", "systemctl stop": "inactive"})
+
+	task := ServiceControlTask{CLI: ssh}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{Host: "web-1", Service: "nginx", Action: "status"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result ServiceControlResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.State != "active" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestServiceControlTaskControlsAWindowsService(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "state")
+	os.WriteFile(stateFile, []byte("STOPPED"), 0o644)
+	ssh := fakeSSH(t, stateFile, map[string]string{"sc start": "RUNNING"})
+
+	task := ServiceControlTask{CLI: ssh}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{
+		Host: "win-1", Service: "MyService", Action: "start", Platform: "windows", VerifyInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := task.Run(context.Background(), string(cfg))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var result ServiceControlResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+	if result.State != "RUNNING" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestServiceControlTaskRejectsAnUnsupportedAction(t *testing.T) {
+	task := ServiceControlTask{}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{Host: "web-1", Service: "nginx", Action: "reload"})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if _, err := task.Run(context.Background(), string(cfg)); err == nil {
+		t.Fatal("expected Run to reject an unsupported action")
+	}
+}
+
+func TestServiceControlTaskPassesSSHUserAndExtraArgs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fake-ssh")
+	recordPath := filepath.Join(dir, "args.txt")
+	script := "#!/bin/sh\necho \"$@\" > '" + recordPath + "'\necho active\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatalf("write fake ssh: %v", err)
+	}
+
+	task := ServiceControlTask{CLI: path}
+	cfg, err := json.Marshal(ServiceControlTaskConfig{
+		Host: "web-1", Service: "nginx", Action: "status", SSHUser: "deploy", SSHArgs: []string{"-i", "/dev/null"},
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	if _, err := task.Run(context.Background(), string(cfg)); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, err := os.ReadFile(recordPath)
+	if err != nil {
+		t.Fatalf("read recorded args: %v", err)
+	}
+	want := "-i /dev/null -l deploy web-1 systemctl is-active nginx\n"
+	if string(got) != want {
+		t.Fatalf("unexpected ssh args: got %q, want %q", string(got), want)
+	}
+}
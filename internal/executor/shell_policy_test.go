@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/shellpolicy"
+)
+
+func TestShellTaskRejectsAConfigThatViolatesItsPolicy(t *testing.T) {
+	policy, err := shellpolicy.Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	task := ShellTask{Policy: policy}
+
+	if _, err := task.Run(context.Background(), "rm -rf /"); err == nil {
+		t.Fatal("expected Run to reject a command matching the denylist")
+	}
+}
+
+func TestShellTaskRunsAConfigThatSatisfiesItsPolicy(t *testing.T) {
+	policy, err := shellpolicy.Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	task := ShellTask{Policy: policy}
+
+	out, err := task.Run(context.Background(), "echo ok")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out != "ok\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestRunFailsAStepWhoseConfigViolatesTheShellPolicy(t *testing.T) {
+	gormDB := newTestDB(t)
+	policy, err := shellpolicy.Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{Policy: policy})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "rm -rf /"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to report the step's failure")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Status != model.StatusFailed {
+		t.Fatalf("expected the step to fail, got %s", se.Status)
+	}
+}
@@ -0,0 +1,63 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestShellTaskRejectsARunAsUserNotInTheAllowlist(t *testing.T) {
+	task := ShellTask{AllowedRunAsUsers: "deploy,release"}
+	ctx := withRunAsUser(context.Background(), "root")
+
+	if _, err := task.Run(ctx, "echo hi"); err == nil {
+		t.Fatal("expected Run to reject a run-as user outside the allowlist")
+	}
+}
+
+func TestShellTaskRejectsAnyRunAsUserWhenTheAllowlistIsEmpty(t *testing.T) {
+	task := ShellTask{}
+	ctx := withRunAsUser(context.Background(), "deploy")
+
+	if _, err := task.Run(ctx, "echo hi"); err == nil {
+		t.Fatal("expected Run to reject a run-as user when no allowlist is configured")
+	}
+}
+
+func TestShellTaskRunsUnimpersonatedWhenNoRunAsUserIsSet(t *testing.T) {
+	task := ShellTask{}
+
+	out, err := task.Run(context.Background(), "echo hi")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out != "hi\n" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+func TestRunThreadsAStepsRunAsUserIntoShellTask(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{AllowedRunAsUsers: "deploy"})
+	orchestrator := NewTaskOrchestrator(gormDB, registry)
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "echo hi", RunAsUser: "operator"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err == nil {
+		t.Fatal("expected Run to report the step's failure")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Status != model.StatusFailed {
+		t.Fatalf("expected the step to fail because its run-as user isn't allowlisted, got %s", se.Status)
+	}
+}
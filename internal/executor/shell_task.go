@@ -0,0 +1,67 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/fengmingli/orchestrator/internal/shellpolicy"
+)
+
+// ShellTask runs its step config as a shell command.
+type ShellTask struct {
+	// Policy, if set, is checked against config before it's run,
+	// rejecting a script that violates it instead of executing it. A
+	// nil Policy runs every script unchecked.
+	Policy *shellpolicy.Policy
+
+	// AllowedRunAsUsers is a comma-separated allowlist of users a step's
+	// RunAsUser may name. A step naming a user outside it is rejected
+	// rather than run. Empty means no step may set RunAsUser, so every
+	// script runs as the orchestrator's own service account.
+	AllowedRunAsUsers string
+}
+
+// Run validates config against t.Policy (if set) and, if it passes,
+// executes it as `sh -c config`, returning its combined output. If the
+// step currently running set RunAsUser, the command instead runs as
+// that user via `sudo -u`, provided it's in t.AllowedRunAsUsers. The
+// command's working directory defaults to whatever the orchestrator's
+// Sandbox assigned the step (see WithSandbox, WorkingDirFromContext), or
+// the orchestrator process's own working directory if none was.
+func (t ShellTask) Run(ctx context.Context, config string) (string, error) {
+	if t.Policy != nil {
+		if err := t.Policy.Validate(config); err != nil {
+			return "", err
+		}
+	}
+
+	name, args := "sh", []string{"-c", config}
+	if user := RunAsUserFromContext(ctx); user != "" {
+		if !containsUser(t.AllowedRunAsUsers, user) {
+			return "", fmt.Errorf("shell task: run-as user %q is not in the allowlist", user)
+		}
+		name, args = "sudo", []string{"-u", user, "--", "sh", "-c", config}
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = WorkingDirFromContext(ctx)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// containsUser reports whether user appears in allowlist, a
+// comma-separated list of usernames.
+func containsUser(allowlist, user string) bool {
+	for _, allowed := range strings.Split(allowlist, ",") {
+		if strings.TrimSpace(allowed) == user {
+			return true
+		}
+	}
+	return false
+}
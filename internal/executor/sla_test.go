@@ -0,0 +1,94 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// advancingTask advances a Fake clock before returning, so a
+// deterministic run can simulate a step that takes longer than an SLA
+// without a real sleep.
+type advancingTask struct {
+	clock *clock.Fake
+	by    time.Duration
+}
+
+func (t advancingTask) Run(ctx context.Context, config string) (string, error) {
+	t.clock.Advance(t.by)
+	return "", nil
+}
+
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestRunWithSLAMarksLateAndNotifies(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	registry.Register("slow", advancingTask{clock: fakeClock, by: 2 * time.Minute})
+
+	notifier := &recordingNotifier{}
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic(), WithNotifier(notifier))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "slow"}}
+
+	if err := orchestrator.RunWithSLA(context.Background(), execution, steps, 60); err != nil {
+		t.Fatalf("RunWithSLA: %v", err)
+	}
+
+	if !execution.Late {
+		t.Fatal("expected execution to be marked late")
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("expected exactly one notification, got %v", notifier.messages)
+	}
+
+	var reloaded model.WorkflowExecution
+	if err := gormDB.First(&reloaded, execution.ID).Error; err != nil {
+		t.Fatalf("reload execution: %v", err)
+	}
+	if !reloaded.Late {
+		t.Fatal("expected Late to be persisted")
+	}
+
+	var lateEvent model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ? AND type = ?", execution.ID, model.EventExecutionLate).First(&lateEvent).Error; err != nil {
+		t.Fatalf("expected an execution_late event to be recorded: %v", err)
+	}
+}
+
+func TestRunWithSLADoesNotMarkLateWithinBudget(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	registry.Register("fast", advancingTask{clock: fakeClock, by: time.Second})
+
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "fast"}}
+
+	if err := orchestrator.RunWithSLA(context.Background(), execution, steps, 60); err != nil {
+		t.Fatalf("RunWithSLA: %v", err)
+	}
+	if execution.Late {
+		t.Fatal("expected execution not to be marked late")
+	}
+}
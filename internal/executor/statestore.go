@@ -0,0 +1,158 @@
+package executor
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// StateStore persists the engine-level state a WorkflowExecution
+// accumulates as it runs: each step's outcome and the execution's
+// append-only event stream. TaskOrchestrator's event stream always goes
+// through a StateStore (see recordEvent), and so does every step
+// outcome once WithStateStore has configured one that isn't the
+// default GORMStateStore over the same db StatusWriter already batches
+// its StepExecution writes against -- routing the default path through
+// it too would just redo the same write a second time for no benefit.
+// Anything outside the engine that needs to save or reload that same
+// state -- service.ExecutionService's stage operations, for instance --
+// should go through a StateStore too, rather than re-implementing the
+// optimistic-locking or query logic against a *gorm.DB of its own.
+type StateStore interface {
+	// SaveNodeState persists se's current fields, optimistically locked
+	// on its Version: a save that affects no rows (some other writer
+	// updated se first) returns model.ErrConcurrentUpdate rather than
+	// silently upserting a new row.
+	SaveNodeState(ctx context.Context, se *model.StepExecution) error
+	// AppendEvent persists event as the next entry in its execution's
+	// event stream. The caller is responsible for assigning
+	// event.Sequence first; only TaskOrchestrator's own per-execution
+	// counter (see recordEvent) knows the next value to use.
+	AppendEvent(ctx context.Context, event *model.ExecutionEvent) error
+	// LoadSnapshot returns executionID's current engine-level state: its
+	// StepExecutions, keeping only the most recent attempt per step
+	// name, and its full event stream in Sequence order.
+	LoadSnapshot(ctx context.Context, executionID uint) (*Snapshot, error)
+}
+
+// Snapshot is one execution's engine-level state as of the moment
+// LoadSnapshot was called.
+type Snapshot struct {
+	// Steps holds executionID's most recent StepExecution per step
+	// name, sorted by step name.
+	Steps []model.StepExecution
+	// Events holds executionID's full event stream, oldest first.
+	Events []model.ExecutionEvent
+}
+
+// GORMStateStore is the StateStore every TaskOrchestrator uses unless
+// overridden by WithStateStore: SaveNodeState and AppendEvent write
+// straight through to db, and LoadSnapshot reads back from it.
+type GORMStateStore struct {
+	db *gorm.DB
+}
+
+// NewGORMStateStore builds a GORMStateStore backed by db.
+func NewGORMStateStore(db *gorm.DB) *GORMStateStore {
+	return &GORMStateStore{db: db}
+}
+
+// SaveNodeState implements StateStore. Select("*") is required here for
+// the same reason it is in StatusWriter.Flush: plain Save falls back to
+// an upsert when its update affects no rows, which would silently defeat
+// the optimistic lock on Version.
+func (s *GORMStateStore) SaveNodeState(ctx context.Context, se *model.StepExecution) error {
+	result := s.db.WithContext(ctx).Select("*").Save(se)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return model.ErrConcurrentUpdate
+	}
+	return nil
+}
+
+// AppendEvent implements StateStore.
+func (s *GORMStateStore) AppendEvent(ctx context.Context, event *model.ExecutionEvent) error {
+	return s.db.WithContext(ctx).Create(event).Error
+}
+
+// LoadSnapshot implements StateStore.
+func (s *GORMStateStore) LoadSnapshot(ctx context.Context, executionID uint) (*Snapshot, error) {
+	var rows []model.StepExecution
+	if err := s.db.WithContext(ctx).Where("execution_id = ?", executionID).Order("id asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	latest := make(map[string]model.StepExecution, len(rows))
+	for _, row := range rows {
+		latest[row.StepName] = row
+	}
+	steps := make([]model.StepExecution, 0, len(latest))
+	for _, se := range latest {
+		steps = append(steps, se)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].StepName < steps[j].StepName })
+
+	var events []model.ExecutionEvent
+	if err := s.db.WithContext(ctx).Where("execution_id = ?", executionID).Order("sequence asc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return &Snapshot{Steps: steps, Events: events}, nil
+}
+
+// InMemoryStateStore is a StateStore that keeps every execution's state
+// in memory instead of a database, for tests that want to exercise
+// TaskOrchestrator's StateStore wiring without a real *gorm.DB.
+type InMemoryStateStore struct {
+	mu     sync.Mutex
+	steps  map[uint]map[string]model.StepExecution
+	events map[uint][]model.ExecutionEvent
+}
+
+// NewInMemoryStateStore builds an empty InMemoryStateStore.
+func NewInMemoryStateStore() *InMemoryStateStore {
+	return &InMemoryStateStore{
+		steps:  make(map[uint]map[string]model.StepExecution),
+		events: make(map[uint][]model.ExecutionEvent),
+	}
+}
+
+// SaveNodeState implements StateStore. There is only ever one writer per
+// execution in practice, so unlike GORMStateStore this never returns
+// model.ErrConcurrentUpdate; it simply overwrites whatever was stored for
+// se's step name.
+func (s *InMemoryStateStore) SaveNodeState(ctx context.Context, se *model.StepExecution) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.steps[se.ExecutionID] == nil {
+		s.steps[se.ExecutionID] = make(map[string]model.StepExecution)
+	}
+	s.steps[se.ExecutionID][se.StepName] = *se
+	return nil
+}
+
+// AppendEvent implements StateStore.
+func (s *InMemoryStateStore) AppendEvent(ctx context.Context, event *model.ExecutionEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[event.ExecutionID] = append(s.events[event.ExecutionID], *event)
+	return nil
+}
+
+// LoadSnapshot implements StateStore.
+func (s *InMemoryStateStore) LoadSnapshot(ctx context.Context, executionID uint) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	steps := make([]model.StepExecution, 0, len(s.steps[executionID]))
+	for _, se := range s.steps[executionID] {
+		steps = append(steps, se)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].StepName < steps[j].StepName })
+	events := append([]model.ExecutionEvent(nil), s.events[executionID]...)
+	return &Snapshot{Steps: steps, Events: events}, nil
+}
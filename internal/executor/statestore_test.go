@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestInMemoryStateStoreRoundTripsStepsAndEvents(t *testing.T) {
+	store := NewInMemoryStateStore()
+	ctx := context.Background()
+
+	se := &model.StepExecution{ExecutionID: 1, StepName: "a", Status: model.StatusRunning}
+	if err := store.SaveNodeState(ctx, se); err != nil {
+		t.Fatalf("SaveNodeState: %v", err)
+	}
+	se.Status = model.StatusSucceeded
+	if err := store.SaveNodeState(ctx, se); err != nil {
+		t.Fatalf("SaveNodeState (update): %v", err)
+	}
+
+	event := &model.ExecutionEvent{ExecutionID: 1, StepName: "a", Type: model.EventNodeFinished, Sequence: 1}
+	if err := store.AppendEvent(ctx, event); err != nil {
+		t.Fatalf("AppendEvent: %v", err)
+	}
+
+	snapshot, err := store.LoadSnapshot(ctx, 1)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if len(snapshot.Steps) != 1 || snapshot.Steps[0].Status != model.StatusSucceeded {
+		t.Fatalf("expected one step with its latest status, got %v", snapshot.Steps)
+	}
+	if len(snapshot.Events) != 1 || snapshot.Events[0].Type != model.EventNodeFinished {
+		t.Fatalf("expected one node_finished event, got %v", snapshot.Events)
+	}
+}
+
+func TestGORMStateStoreLoadSnapshotKeepsLatestAttemptPerStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	store := NewGORMStateStore(gormDB)
+	ctx := context.Background()
+
+	rows := []*model.StepExecution{
+		{ExecutionID: 1, StepName: "a", Attempt: 1, Status: model.StatusFailed},
+		{ExecutionID: 1, StepName: "a", Attempt: 2, Status: model.StatusSucceeded},
+	}
+	if err := CreateStepExecutions(gormDB, rows); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	snapshot, err := store.LoadSnapshot(ctx, 1)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if len(snapshot.Steps) != 1 || snapshot.Steps[0].Attempt != 2 || snapshot.Steps[0].Status != model.StatusSucceeded {
+		t.Fatalf("expected only the latest attempt of step a, got %v", snapshot.Steps)
+	}
+}
+
+func TestGORMStateStoreSaveNodeStateDetectsConcurrentUpdate(t *testing.T) {
+	gormDB := newTestDB(t)
+	store := NewGORMStateStore(gormDB)
+	ctx := context.Background()
+
+	se := &model.StepExecution{ExecutionID: 1, StepName: "a", Status: model.StatusRunning}
+	if err := CreateStepExecutions(gormDB, []*model.StepExecution{se}); err != nil {
+		t.Fatalf("CreateStepExecutions: %v", err)
+	}
+
+	stale := *se
+	stale.Status = model.StatusFailed
+
+	se.Status = model.StatusSucceeded
+	if err := store.SaveNodeState(ctx, se); err != nil {
+		t.Fatalf("SaveNodeState: %v", err)
+	}
+
+	if err := store.SaveNodeState(ctx, &stale); err != model.ErrConcurrentUpdate {
+		t.Fatalf("expected ErrConcurrentUpdate saving a stale row, got %v", err)
+	}
+}
+
+func TestWithStateStoreRoutesStepStateAndEventsThroughIt(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	store := NewInMemoryStateStore()
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithDeterministic(), WithStateStore(store))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "shell", Config: "exit 0"}}
+
+	if err := orchestrator.Run(context.Background(), execution, steps); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	snapshot, err := store.LoadSnapshot(context.Background(), execution.ID)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if len(snapshot.Events) == 0 {
+		t.Fatal("expected events recorded during the run to reach the configured StateStore")
+	}
+	if len(snapshot.Steps) != 1 || snapshot.Steps[0].Status != model.StatusSucceeded {
+		t.Fatalf("expected step state recorded during the run to reach the configured StateStore, got %v", snapshot.Steps)
+	}
+
+	var dbEvents []model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ?", execution.ID).Find(&dbEvents).Error; err != nil {
+		t.Fatalf("load db events: %v", err)
+	}
+	if len(dbEvents) != 0 {
+		t.Fatalf("expected no events in the database once the orchestrator is configured with a different StateStore, got %d", len(dbEvents))
+	}
+}
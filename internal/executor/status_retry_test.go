@@ -0,0 +1,141 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+func TestDefaultRetryClassifierHonorsFailFastStatusCodes(t *testing.T) {
+	no := false
+	err := fmtWrap(&StatusError{StatusCode: 503, Retryable: &no})
+	if DefaultRetryClassifier(err) {
+		t.Fatal("expected FailFastStatusCodes to override the generic 5xx retry rule")
+	}
+}
+
+func TestDefaultRetryClassifierHonorsRetryStatusCodes(t *testing.T) {
+	yes := true
+	err := fmtWrap(&StatusError{StatusCode: 409, Retryable: &yes})
+	if !DefaultRetryClassifier(err) {
+		t.Fatal("expected RetryStatusCodes to override the generic rule for a non-5xx/429 code")
+	}
+}
+
+func TestDefaultRetryClassifierFallsBackToTheGenericRuleWithoutAnOverride(t *testing.T) {
+	if !DefaultRetryClassifier(fmtWrap(&StatusError{StatusCode: 503})) {
+		t.Fatal("expected a 503 to be retried under the generic rule")
+	}
+	if DefaultRetryClassifier(fmtWrap(&StatusError{StatusCode: 401})) {
+		t.Fatal("expected a 401 to be treated as permanent under the generic rule")
+	}
+}
+
+func fmtWrap(statusErr *StatusError) error {
+	return errWrap{statusErr}
+}
+
+type errWrap struct{ err error }
+
+func (e errWrap) Error() string { return e.err.Error() }
+func (e errWrap) Unwrap() error { return e.err }
+
+func TestHTTPTaskSetsRetryableFromFailFastStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	config := `{"url":"` + server.URL + `","fail_fast_status_codes":[403]}`
+	_, err := task.Run(context.Background(), config)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected a *StatusError, got %v", err)
+	}
+	if statusErr.Retryable == nil || *statusErr.Retryable {
+		t.Fatal("expected Retryable to be false for a fail-fast status code")
+	}
+}
+
+func TestHTTPTaskSetsRetryableFromRetryStatusCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	config := `{"url":"` + server.URL + `","retry_status_codes":[409]}`
+	_, err := task.Run(context.Background(), config)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected a *StatusError, got %v", err)
+	}
+	if statusErr.Retryable == nil || !*statusErr.Retryable {
+		t.Fatal("expected Retryable to be true for a retry status code")
+	}
+}
+
+func TestHTTPTaskParsesRetryAfterHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	task := HTTPTask{}
+	_, err := task.Run(context.Background(), `{"url":"`+server.URL+`"}`)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected a *StatusError, got %v", err)
+	}
+	if statusErr.RetryAfter != 30*time.Second {
+		t.Fatalf("expected a 30s RetryAfter, got %s", statusErr.RetryAfter)
+	}
+}
+
+func TestRetryableExecutorWaitsExactlyRetryAfterInsteadOfJitteredBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	exec := NewRetryableExecutor(HTTPTask{}, WithRetryClock(fakeClock), WithBackoff(time.Hour, time.Hour))
+
+	done := make(chan struct{})
+	var output string
+	var runErr error
+	go func() {
+		output, runErr = exec.Run(context.Background(), `{"url":"`+server.URL+`"}`)
+		close(done)
+	}()
+
+	for atomic.LoadInt32(&calls) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	fakeClock.Advance(7 * time.Second)
+
+	<-done
+	if runErr != nil {
+		t.Fatalf("Run: %v", runErr)
+	}
+	if output != "ok" {
+		t.Fatalf("expected %q, got %q", "ok", output)
+	}
+}
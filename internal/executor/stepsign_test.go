@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/stepsign"
+)
+
+func testSigner(t *testing.T) *stepsign.Signer {
+	t.Helper()
+	signer, err := stepsign.NewSigner([]byte("a-test-signing-key"))
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer
+}
+
+func TestRunFailsAStepWhoseConfigSignatureDoesNotMatchItsDefinition(t *testing.T) {
+	gormDB := newTestDB(t)
+	signer := testSigner(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithStepSigner(signer))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	step := model.TemplateStep{Name: "a", Type: "shell", Config: "echo hi"}
+	step.ConfigSignature = signer.Sign(step)
+	step.Config = "echo tampered"
+
+	err := orchestrator.Run(context.Background(), execution, []model.TemplateStep{step})
+	var preflightErr *PreflightError
+	if !errors.As(err, &preflightErr) {
+		t.Fatalf("expected a *PreflightError when a step's signature doesn't match, got %v", err)
+	}
+	if len(preflightErr.Errors) != 1 || preflightErr.Errors[0].StepName != "a" {
+		t.Fatalf("expected step a to fail preflight, got %v", preflightErr.Errors)
+	}
+}
+
+func TestRunSucceedsAStepWithAMatchingConfigSignature(t *testing.T) {
+	gormDB := newTestDB(t)
+	signer := testSigner(t)
+	registry := NewRegistry()
+	registry.Register("shell", ShellTask{})
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithStepSigner(signer))
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	step := model.TemplateStep{Name: "a", Type: "shell", Config: "echo hi"}
+	step.ConfigSignature = signer.Sign(step)
+
+	if err := orchestrator.Run(context.Background(), execution, []model.TemplateStep{step}); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if se.Status != model.StatusSucceeded {
+		t.Fatalf("expected the step to succeed, got %s (%s)", se.Status, se.Error)
+	}
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"context"
+	"sort"
+)
+
+// Task is the interface every step type implements to actually perform
+// work. Config is the step's raw, type-specific configuration string.
+type Task interface {
+	// Run executes the task and returns its output or an error.
+	Run(ctx context.Context, config string) (output string, err error)
+}
+
+// Validatable is implemented by a Task that can check a config for
+// obvious problems (a malformed URL, a missing required field) without
+// actually running it, so RunSimulated's preflight pass can catch a step
+// that would definitely fail before the execution starts, instead of
+// only discovering it partway through a long run. A Task that doesn't
+// implement Validatable is assumed to always pass preflight.
+type Validatable interface {
+	Validate(config string) error
+}
+
+// SchemaProvider is implemented by a Task that can describe the shape
+// its config must have as a JSON Schema document (see package schema),
+// so callers introspecting the Registry (e.g. an admin API) can publish
+// it alongside the step type's name instead of having to read the
+// Task's source to learn what it expects. A Task that doesn't implement
+// SchemaProvider is assumed to have no schema to publish.
+type SchemaProvider interface {
+	ConfigSchema() string
+}
+
+// RegisteredType describes one step type a Registry knows how to run,
+// for a caller listing the Registry's contents rather than looking up a
+// specific type.
+type RegisteredType struct {
+	// Name is the step type string, as used in a step's Type field.
+	Name string
+	// Enabled is false if Disable has turned this type off; Lookup
+	// fails for it until a matching Enable.
+	Enabled bool
+	// ConfigSchema is the Task's config schema, from SchemaProvider, or
+	// "" if it doesn't implement that interface.
+	ConfigSchema string
+}
+
+// Registry maps step type names to the Task implementation that runs
+// them, and which of those types are currently enabled.
+type Registry struct {
+	tasks    map[string]Task
+	disabled map[string]bool
+}
+
+// NewRegistry returns an empty task Registry.
+func NewRegistry() *Registry {
+	return &Registry{tasks: make(map[string]Task), disabled: make(map[string]bool)}
+}
+
+// Register associates a step type name with a Task implementation.
+func (r *Registry) Register(stepType string, task Task) {
+	r.tasks[stepType] = task
+}
+
+// Disable turns off lookups for stepType without unregistering it, so a
+// deployment can forbid a step type it would otherwise ship with (e.g.
+// shell) by name, while Types still reports it as known to the binary.
+// Disabling a type that was never registered is a harmless no-op.
+func (r *Registry) Disable(stepType string) {
+	r.disabled[stepType] = true
+}
+
+// Enable reverses a prior Disable, so stepType's lookups succeed again.
+func (r *Registry) Enable(stepType string) {
+	delete(r.disabled, stepType)
+}
+
+// Lookup returns the Task registered for stepType, if any, and if it
+// hasn't been turned off with Disable.
+func (r *Registry) Lookup(stepType string) (Task, bool) {
+	if r.disabled[stepType] {
+		return nil, false
+	}
+	t, ok := r.tasks[stepType]
+	return t, ok
+}
+
+// Types lists every step type Register has been called with, in name
+// order, along with whether it's currently enabled and its config
+// schema, if it has one.
+func (r *Registry) Types() []RegisteredType {
+	names := make([]string, 0, len(r.tasks))
+	for name := range r.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	types := make([]RegisteredType, 0, len(names))
+	for _, name := range names {
+		rt := RegisteredType{Name: name, Enabled: !r.disabled[name]}
+		if sp, ok := r.tasks[name].(SchemaProvider); ok {
+			rt.ConfigSchema = sp.ConfigSchema()
+		}
+		types = append(types, rt)
+	}
+	return types
+}
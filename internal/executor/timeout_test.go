@@ -0,0 +1,170 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// ctxBlockingTask blocks until its context is cancelled or release is
+// closed, so a test can simulate a step that's still running when a
+// timeout tier fires.
+type ctxBlockingTask struct {
+	release chan struct{}
+}
+
+func (t ctxBlockingTask) Run(ctx context.Context, config string) (string, error) {
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-t.release:
+		return "done", nil
+	}
+}
+
+func TestStepTimeoutsStepOverridesExecutionDefaults(t *testing.T) {
+	execution := &model.WorkflowExecution{SoftTimeoutSeconds: 10, HardTimeoutSeconds: 20}
+	node := &dag.Node{Step: model.TemplateStep{SoftTimeoutSeconds: 1}}
+
+	soft, hard := stepTimeouts(node, execution)
+	if soft != 1 || hard != 20 {
+		t.Fatalf("expected soft=1 hard=20, got soft=%d hard=%d", soft, hard)
+	}
+}
+
+func TestRunStepHardTimeoutCancelsAndFailsTheStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	registry.Register("blocking", ctxBlockingTask{release: make(chan struct{})})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, HardTimeoutSeconds: 5}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "blocking"}}
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	// Give the step's timeout-watcher goroutine time to register with the
+	// fake clock before advancing it past the hard timeout.
+	time.Sleep(100 * time.Millisecond)
+	fakeClock.Advance(5 * time.Second)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected the hard timeout to fail the step")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+
+	var event model.ExecutionEvent
+	if err := gormDB.Where("execution_id = ? AND type = ?", execution.ID, model.EventNodeHardTimeout).First(&event).Error; err != nil {
+		t.Fatalf("expected a node_hard_timeout event: %v", err)
+	}
+}
+
+func TestRunStepSoftTimeoutWarnsWithoutFailingTheStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	release := make(chan struct{})
+	registry.Register("blocking", ctxBlockingTask{release: release})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, SoftTimeoutSeconds: 5}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.TemplateStep{{Name: "a", Type: "blocking"}}
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	time.Sleep(100 * time.Millisecond)
+	fakeClock.Advance(5 * time.Second)
+
+	waitForEvent(t, gormDB, execution.ID, model.EventNodeSoftTimeout, "")
+	close(release)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step: %v", err)
+	}
+	if se.Status != model.StatusSucceeded {
+		t.Fatalf("expected the step to still succeed despite the soft timeout warning, got %q", se.Status)
+	}
+}
+
+func TestRunStepSoftTimeoutRunsConfiguredDiagnostic(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := NewRegistry()
+	release := make(chan struct{})
+	registry.Register("blocking", ctxBlockingTask{release: release})
+	registry.Register("diag", ScriptTask{})
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	orchestrator := NewTaskOrchestrator(gormDB, registry, WithClock(fakeClock), WithDeterministic())
+
+	execution := &model.WorkflowExecution{Status: model.StatusPending, SoftTimeoutSeconds: 5}
+	if err := gormDB.Create(execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	diagConfig, _ := json.Marshal(ScriptTaskConfig{Expression: `"diagnostic ran"`})
+	steps := []model.TemplateStep{{
+		Name: "a", Type: "blocking",
+		DiagnosticType:   "diag",
+		DiagnosticConfig: string(diagConfig),
+	}}
+
+	done := make(chan error, 1)
+	go func() { done <- orchestrator.Run(context.Background(), execution, steps) }()
+
+	time.Sleep(100 * time.Millisecond)
+	fakeClock.Advance(5 * time.Second)
+
+	waitForEvent(t, gormDB, execution.ID, model.EventNodeSoftTimeout, "diagnostic ran")
+	close(release)
+	<-done
+}
+
+// waitForEvent polls gormDB until executionID has an ExecutionEvent of
+// typ whose message contains messageContains (ignored if empty), or
+// fails the test after a second.
+func waitForEvent(t *testing.T, gormDB *gorm.DB, executionID uint, typ model.ExecutionEventType, messageContains string) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		var events []model.ExecutionEvent
+		if err := gormDB.Where("execution_id = ? AND type = ?", executionID, typ).Find(&events).Error; err != nil {
+			t.Fatalf("query events: %v", err)
+		}
+		for _, e := range events {
+			if messageContains == "" || strings.Contains(e.Message, messageContains) {
+				return
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for a %s event", typ)
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// WASMTaskConfig is the JSON shape expected in a step's Config for
+// WASMTask.
+type WASMTaskConfig struct {
+	// ModulePath is the filesystem path to a compiled WebAssembly module.
+	ModulePath string `json:"module_path"`
+	// Input is passed verbatim to the module's run function.
+	Input string `json:"input"`
+	// MemoryLimitPages caps the module's linear memory, in 64KiB pages.
+	// Zero means use DefaultWASMMemoryLimitPages.
+	MemoryLimitPages uint32 `json:"memory_limit_pages"`
+}
+
+// DefaultWASMMemoryLimitPages bounds a WASM module's memory to 16MiB
+// unless a step's config requests otherwise.
+const DefaultWASMMemoryLimitPages = 256
+
+// WASMTask runs a WebAssembly module in a sandboxed wazero runtime,
+// letting users ship custom step logic without the host-process access a
+// ShellTask or PluginTask would have. The module must export a linear
+// memory, an "alloc(size uint32) uint32" function, and a
+// "run(ptr uint32, len uint32) uint64" function that reads its input from
+// memory at ptr/len and returns its output packed as (outPtr<<32|outLen).
+type WASMTask struct{}
+
+// Run compiles and instantiates the module named in config and invokes
+// its run export, returning the output it writes back into memory.
+func (WASMTask) Run(ctx context.Context, config string) (string, error) {
+	var cfg WASMTaskConfig
+	if err := json.Unmarshal([]byte(config), &cfg); err != nil {
+		return "", fmt.Errorf("wasm task: invalid config: %w", err)
+	}
+	if cfg.ModulePath == "" {
+		return "", fmt.Errorf("wasm task: module_path is required")
+	}
+	limitPages := cfg.MemoryLimitPages
+	if limitPages == 0 {
+		limitPages = DefaultWASMMemoryLimitPages
+	}
+
+	wasmBytes, err := os.ReadFile(cfg.ModulePath)
+	if err != nil {
+		return "", fmt.Errorf("wasm task: read module: %w", err)
+	}
+
+	runtimeConfig := wazero.NewRuntimeConfig().WithMemoryLimitPages(limitPages)
+	runtime := wazero.NewRuntimeWithConfig(ctx, runtimeConfig)
+	defer runtime.Close(ctx)
+
+	module, err := runtime.Instantiate(ctx, wasmBytes)
+	if err != nil {
+		return "", fmt.Errorf("wasm task: instantiate module: %w", err)
+	}
+
+	output, err := callRun(ctx, module, cfg.Input)
+	if err != nil {
+		return "", fmt.Errorf("wasm task: %w", err)
+	}
+	return output, nil
+}
+
+// callRun writes input into the module's memory via its alloc export,
+// invokes run, and reads the output back out of memory.
+func callRun(ctx context.Context, module api.Module, input string) (string, error) {
+	alloc := module.ExportedFunction("alloc")
+	run := module.ExportedFunction("run")
+	if alloc == nil || run == nil {
+		return "", fmt.Errorf("module must export alloc and run")
+	}
+
+	inBytes := []byte(input)
+	allocResult, err := alloc.Call(ctx, uint64(len(inBytes)))
+	if err != nil {
+		return "", fmt.Errorf("alloc: %w", err)
+	}
+	inPtr := uint32(allocResult[0])
+
+	if !module.Memory().Write(inPtr, inBytes) {
+		return "", fmt.Errorf("writing input out of bounds")
+	}
+
+	runResult, err := run.Call(ctx, uint64(inPtr), uint64(len(inBytes)))
+	if err != nil {
+		return "", fmt.Errorf("run: %w", err)
+	}
+
+	packed := runResult[0]
+	outPtr := uint32(packed >> 32)
+	outLen := uint32(packed)
+	output, ok := module.Memory().Read(outPtr, outLen)
+	if !ok {
+		return "", fmt.Errorf("reading output out of bounds")
+	}
+	return string(output), nil
+}
@@ -0,0 +1,39 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWASMTaskRunsModule(t *testing.T) {
+	config, err := json.Marshal(WASMTaskConfig{
+		ModulePath: "testdata/echo.wasm",
+		Input:      "hello from the host",
+	})
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+
+	output, err := (WASMTask{}).Run(context.Background(), string(config))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "hello from the host" {
+		t.Fatalf("expected module to echo its input, got %q", output)
+	}
+}
+
+func TestWASMTaskRejectsMissingModulePath(t *testing.T) {
+	config, _ := json.Marshal(WASMTaskConfig{Input: "x"})
+	if _, err := (WASMTask{}).Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error when module_path is empty")
+	}
+}
+
+func TestWASMTaskRejectsMissingModule(t *testing.T) {
+	config, _ := json.Marshal(WASMTaskConfig{ModulePath: "testdata/does-not-exist.wasm"})
+	if _, err := (WASMTask{}).Run(context.Background(), string(config)); err == nil {
+		t.Fatal("expected an error for a missing module file")
+	}
+}
@@ -0,0 +1,147 @@
+// Package fieldcrypt provides application-layer AES-GCM encryption for
+// free-text database columns that may carry credentials (e.g. a step's
+// Config, which can embed an HTTP Authorization header or a shell
+// command's environment), so a copy of the database on its own isn't
+// enough to recover them.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeyRing holds every key a field might have been encrypted with, keyed
+// by a short, non-secret ID embedded alongside the ciphertext, plus
+// which one new encryptions should use. Keeping retired keys around
+// (instead of discarding them once rotated out) lets Decrypt keep
+// reading values a still-running executor or an old backup encrypted
+// under them.
+type KeyRing struct {
+	keys      map[string][]byte
+	currentID string
+}
+
+// NewKeyRing builds a KeyRing from keys (ID to raw AES key, 16/24/32
+// bytes for AES-128/192/256) that encrypts new values under currentID.
+// currentID must be present in keys.
+func NewKeyRing(currentID string, keys map[string][]byte) (*KeyRing, error) {
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("fieldcrypt: current key id %q not found in keys", currentID)
+	}
+	for id, key := range keys {
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q: %w", id, err)
+		}
+	}
+	return &KeyRing{keys: keys, currentID: currentID}, nil
+}
+
+// KeyRingFromEnv parses raw, a comma-separated "id:base64key" list in
+// the same style as TemplateStep.DependsOn, with the first entry taken
+// as the current key. Returns (nil, nil) if raw is empty, so
+// encryption stays opt-in for deployments that haven't set it up.
+func KeyRingFromEnv(raw string) (*KeyRing, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte)
+	var currentID string
+	for i, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("fieldcrypt: malformed key entry %q, want \"id:base64key\"", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("fieldcrypt: key %q: %w", id, err)
+		}
+		keys[id] = key
+		if i == 0 {
+			currentID = id
+		}
+	}
+	return NewKeyRing(currentID, keys)
+}
+
+// KeyRingFromEnvVar is KeyRingFromEnv reading its input from the named
+// environment variable.
+func KeyRingFromEnvVar(name string) (*KeyRing, error) {
+	return KeyRingFromEnv(os.Getenv(name))
+}
+
+// Encrypt seals plaintext under the ring's current key, returning
+// "<keyID>:<base64(nonce||ciphertext)>" so Decrypt can find the right
+// key regardless of rotation. An empty plaintext encrypts to an empty
+// string, so an unset Config round-trips as unset rather than gaining a
+// spurious ciphertext.
+func (r *KeyRing) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	gcm, err := r.cipherFor(r.currentID)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return r.currentID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt, using whichever key value's keyID names,
+// even if it's since been rotated out as the current key.
+func (r *KeyRing) Decrypt(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	id, encoded, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", fmt.Errorf("fieldcrypt: malformed ciphertext, missing key id")
+	}
+	gcm, err := r.cipherFor(id)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: decode ciphertext: %w", err)
+	}
+	nonceSize := gcm.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("fieldcrypt: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:nonceSize], sealed[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: decrypt: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func (r *KeyRing) cipherFor(id string) (cipher.AEAD, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("fieldcrypt: unknown key id %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
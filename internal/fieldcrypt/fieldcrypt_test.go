@@ -0,0 +1,118 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func key(b byte) []byte {
+	k := make([]byte, 32)
+	for i := range k {
+		k[i] = b
+	}
+	return k
+}
+
+func TestEncryptDecryptRoundTrips(t *testing.T) {
+	ring, err := NewKeyRing("v1", map[string][]byte{"v1": key(1)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	ciphertext, err := ring.Encrypt("Authorization: Bearer super-secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if ciphertext == "Authorization: Bearer super-secret" {
+		t.Fatal("expected ciphertext to differ from plaintext")
+	}
+
+	plaintext, err := ring.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if plaintext != "Authorization: Bearer super-secret" {
+		t.Fatalf("expected round-tripped plaintext, got %q", plaintext)
+	}
+}
+
+func TestEmptyPlaintextRoundTripsAsEmpty(t *testing.T) {
+	ring, err := NewKeyRing("v1", map[string][]byte{"v1": key(1)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	ciphertext, err := ring.Encrypt("")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if ciphertext != "" {
+		t.Fatalf("expected empty ciphertext for empty plaintext, got %q", ciphertext)
+	}
+	plaintext, err := ring.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if plaintext != "" {
+		t.Fatalf("expected empty plaintext, got %q", plaintext)
+	}
+}
+
+func TestDecryptAfterRotationStillReadsTheOldKey(t *testing.T) {
+	ring, err := NewKeyRing("v1", map[string][]byte{"v1": key(1)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	ciphertext, err := ring.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	rotated, err := NewKeyRing("v2", map[string][]byte{"v1": key(1), "v2": key(2)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+
+	plaintext, err := rotated.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt under rotated ring: %v", err)
+	}
+	if plaintext != "secret" {
+		t.Fatalf("expected %q, got %q", "secret", plaintext)
+	}
+
+	reencrypted, err := rotated.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if reencrypted[:2] != "v2" {
+		t.Fatalf("expected new encryptions to use the current key id v2, got %q", reencrypted)
+	}
+}
+
+func TestKeyRingFromEnvParsesCommaSeparatedIDBase64Pairs(t *testing.T) {
+	k1 := base64.StdEncoding.EncodeToString(key(1))
+	k2 := base64.StdEncoding.EncodeToString(key(2))
+
+	ring, err := KeyRingFromEnv("v2:" + k2 + ",v1:" + k1)
+	if err != nil {
+		t.Fatalf("KeyRingFromEnv: %v", err)
+	}
+	ciphertext, err := ring.Encrypt("secret")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if ciphertext[:2] != "v2" {
+		t.Fatalf("expected the first entry to be current, got %q", ciphertext)
+	}
+}
+
+func TestKeyRingFromEnvReturnsNilForAnEmptyString(t *testing.T) {
+	ring, err := KeyRingFromEnv("")
+	if err != nil {
+		t.Fatalf("KeyRingFromEnv: %v", err)
+	}
+	if ring != nil {
+		t.Fatalf("expected a nil ring for an empty env value, got %+v", ring)
+	}
+}
@@ -0,0 +1,104 @@
+// Package grafana integrates the orchestrator with Grafana's HTTP
+// Annotations API, so remediation actions show up overlaid on the
+// service dashboards they affect.
+package grafana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// Annotator posts a Grafana annotation each time an execution starts,
+// succeeds or fails, tagged with its template and execution IDs.
+// Register one with executor.WithHook. A failure to reach Grafana is
+// logged, not returned: a Hook can't fail the execution it's observing,
+// and a down Grafana instance shouldn't either.
+type Annotator struct {
+	executor.NoopHook
+
+	// BaseURL is Grafana's root URL, e.g. "https://grafana.example.com".
+	BaseURL string
+	// APIToken is sent as a Bearer token if set.
+	APIToken string
+	Client   *http.Client
+}
+
+type annotationPayload struct {
+	Time int64    `json:"time"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+}
+
+// OnExecutionStart posts an annotation marking execution's start.
+func (a Annotator) OnExecutionStart(ctx context.Context, execution *model.WorkflowExecution) {
+	a.post(ctx, execution, "started", timeOf(execution.StartedAt))
+}
+
+// OnExecutionEnd posts an annotation marking execution's finish, tagged
+// "succeeded" or "failed" depending on err.
+func (a Annotator) OnExecutionEnd(ctx context.Context, execution *model.WorkflowExecution, err error) {
+	outcome := "succeeded"
+	if err != nil {
+		outcome = "failed"
+	}
+	a.post(ctx, execution, outcome, timeOf(execution.FinishedAt))
+}
+
+// timeOf returns t in Grafana's millisecond-epoch annotation format, or
+// the current time if t is nil.
+func timeOf(t *time.Time) int64 {
+	if t == nil {
+		return time.Now().UnixMilli()
+	}
+	return t.UnixMilli()
+}
+
+func (a Annotator) post(ctx context.Context, execution *model.WorkflowExecution, outcome string, when int64) {
+	payload := annotationPayload{
+		Time: when,
+		Tags: []string{
+			"orchestrator",
+			fmt.Sprintf("template:%d", execution.TemplateID),
+			fmt.Sprintf("execution:%d", execution.ID),
+			outcome,
+		},
+		Text: fmt.Sprintf("execution %d %s", execution.ID, outcome),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("grafana annotator: encode payload: %v", err)
+		return
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.BaseURL+"/api/annotations", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("grafana annotator: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.APIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.APIToken)
+	}
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("grafana annotator: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("grafana annotator: unexpected status %d", resp.StatusCode)
+	}
+}
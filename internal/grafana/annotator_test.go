@@ -0,0 +1,86 @@
+package grafana
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestOnExecutionStartPostsAnAnnotationTaggedWithTemplateAndExecution(t *testing.T) {
+	var mu sync.Mutex
+	var received annotationPayload
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		authHeader = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	annotator := Annotator{BaseURL: server.URL, APIToken: "secret"}
+	execution := &model.WorkflowExecution{ID: 42, TemplateID: 7}
+	now := time.Now()
+	execution.StartedAt = &now
+
+	annotator.OnExecutionStart(context.Background(), execution)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if authHeader != "Bearer secret" {
+		t.Fatalf("expected Bearer auth header, got %q", authHeader)
+	}
+	wantTags := []string{"orchestrator", "template:7", "execution:42", "started"}
+	if len(received.Tags) != len(wantTags) {
+		t.Fatalf("expected tags %v, got %v", wantTags, received.Tags)
+	}
+	for i, tag := range wantTags {
+		if received.Tags[i] != tag {
+			t.Fatalf("expected tags %v, got %v", wantTags, received.Tags)
+		}
+	}
+}
+
+func TestOnExecutionEndTagsFailedWhenErrIsNonNil(t *testing.T) {
+	var mu sync.Mutex
+	var received annotationPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	annotator := Annotator{BaseURL: server.URL}
+	execution := &model.WorkflowExecution{ID: 1, TemplateID: 2}
+	finished := time.Now()
+	execution.FinishedAt = &finished
+
+	annotator.OnExecutionEnd(context.Background(), execution, errTest)
+
+	mu.Lock()
+	defer mu.Unlock()
+	found := false
+	for _, tag := range received.Tags {
+		if tag == "failed" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a \"failed\" tag, got %v", received.Tags)
+	}
+}
+
+var errTest = &testError{"boom"}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
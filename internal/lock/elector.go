@@ -0,0 +1,200 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/logging"
+)
+
+// ElectorOption configures a LeaderElector built by NewLeaderElector.
+type ElectorOption func(*LeaderElector)
+
+// WithOnElected registers a callback run whenever this elector becomes
+// leader. It runs synchronously on the elector's campaign loop, so it
+// should return quickly and hand off any real work to its own
+// goroutine.
+func WithOnElected(fn func()) ElectorOption {
+	return func(e *LeaderElector) { e.onElected = fn }
+}
+
+// WithOnResigned registers a callback run whenever this elector stops
+// being leader, whether from an explicit Resign or from a failed
+// renewal (e.g. the backing store was unreachable past the lock's
+// TTL). Callers that start work in WithOnElected should use this to
+// stop it.
+func WithOnResigned(fn func()) ElectorOption {
+	return func(e *LeaderElector) { e.onResigned = fn }
+}
+
+// LeaderElector campaigns for leadership of a single key against a
+// Provider, so that singleton background jobs (a scheduler, retention
+// sweep, recovery pass) run on exactly one of several competing
+// process instances at a time. Only one LeaderElector across all
+// processes using the same key holds leadership at once; the others
+// keep retrying in the background until the leader resigns or its
+// lease lapses.
+type LeaderElector struct {
+	provider Provider
+	key      string
+	owner    string
+	ttl      time.Duration
+
+	onElected  func()
+	onResigned func()
+
+	log *logging.Logger
+
+	mu       sync.Mutex
+	leading  bool
+	resigned bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLeaderElector builds a LeaderElector that campaigns for key
+// against provider as owner. Call Campaign to start, and Resign (or
+// cancel the context passed to Campaign) to stop.
+func NewLeaderElector(provider Provider, key, owner string, ttl time.Duration, opts ...ElectorOption) *LeaderElector {
+	e := &LeaderElector{
+		provider: provider,
+		key:      key,
+		owner:    owner,
+		ttl:      ttl,
+		log:      logging.New("lock").With("key", key).With("owner", owner),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Campaign starts the elector's background campaign loop: while ctx is
+// live it repeatedly attempts to acquire the leadership key, running
+// onElected on success and renewing the lease until either ctx is
+// cancelled, Resign is called, or a renewal fails. Campaign returns
+// immediately; the loop runs in its own goroutine until Resign is
+// called or ctx is done.
+func (e *LeaderElector) Campaign(ctx context.Context) {
+	e.mu.Lock()
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	e.resigned = false
+	stop, done := e.stop, e.done
+	e.mu.Unlock()
+
+	go e.run(ctx, stop, done)
+}
+
+func (e *LeaderElector) run(ctx context.Context, stop, done chan struct{}) {
+	defer close(done)
+
+	retryInterval := e.ttl / 3
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+
+	for {
+		ok, err := e.provider.TryLock(ctx, e.key, e.owner, e.ttl)
+		if err != nil {
+			e.log.Warnf("campaign attempt failed: %v", err)
+		}
+		if err == nil && ok {
+			e.becomeLeader()
+			renewed := e.holdLeadershipUntilLost(ctx, stop, retryInterval)
+			e.resign()
+			if !renewed {
+				return
+			}
+			continue
+		}
+
+		timer := time.NewTimer(retryInterval)
+		select {
+		case <-timer.C:
+		case <-stop:
+			timer.Stop()
+			return
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+		timer.Stop()
+	}
+}
+
+// holdLeadershipUntilLost renews the lease on every tick until a
+// renewal fails, stop is closed, or ctx is done. It returns false when
+// the caller should stop campaigning entirely (stop/ctx), and true
+// when it should immediately re-enter the campaign (lost the lease to
+// someone else and should try to win it back).
+func (e *LeaderElector) holdLeadershipUntilLost(ctx context.Context, stop chan struct{}, renewInterval time.Duration) bool {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ok, err := e.provider.TryLock(ctx, e.key, e.owner, e.ttl)
+			if err != nil || !ok {
+				return true
+			}
+		case <-stop:
+			e.provider.Unlock(context.Background(), e.key, e.owner)
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (e *LeaderElector) becomeLeader() {
+	e.mu.Lock()
+	e.leading = true
+	onElected := e.onElected
+	e.mu.Unlock()
+	e.log.Infof("became leader")
+	if onElected != nil {
+		onElected()
+	}
+}
+
+func (e *LeaderElector) resign() {
+	e.mu.Lock()
+	wasLeading := e.leading
+	e.leading = false
+	onResigned := e.onResigned
+	e.mu.Unlock()
+	if wasLeading {
+		e.log.Infof("resigned leadership")
+	}
+	if wasLeading && onResigned != nil {
+		onResigned()
+	}
+}
+
+// IsLeader reports whether this elector currently holds leadership.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leading
+}
+
+// Resign stops the campaign loop started by Campaign and, if this
+// elector was leading, releases the key immediately so the next
+// campaigner doesn't have to wait out its TTL. Resign is safe to call
+// more than once, or when Campaign was never called.
+func (e *LeaderElector) Resign(ctx context.Context) {
+	e.mu.Lock()
+	if e.resigned || e.stop == nil {
+		e.mu.Unlock()
+		return
+	}
+	e.resigned = true
+	stop, done := e.stop, e.done
+	e.mu.Unlock()
+
+	close(stop)
+	<-done
+}
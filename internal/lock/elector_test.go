@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool, timeout time.Duration, what string) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestLeaderElectorSingleCampaignerBecomesLeader(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	var elected, resigned int32
+	elector := NewLeaderElector(provider, "job", "instance-1", 50*time.Millisecond,
+		WithOnElected(func() { atomic.AddInt32(&elected, 1) }),
+		WithOnResigned(func() { atomic.AddInt32(&resigned, 1) }),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	elector.Campaign(ctx)
+	defer elector.Resign(context.Background())
+
+	waitFor(t, elector.IsLeader, time.Second, "elector to become leader")
+	if atomic.LoadInt32(&elected) != 1 {
+		t.Fatalf("expected onElected to have run once, got %d", elected)
+	}
+	if atomic.LoadInt32(&resigned) != 0 {
+		t.Fatalf("expected onResigned not to have run yet, got %d", resigned)
+	}
+}
+
+func TestLeaderElectorResignReleasesKeyAndRunsCallback(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	var resigned int32
+	elector := NewLeaderElector(provider, "job", "instance-1", time.Minute,
+		WithOnResigned(func() { atomic.AddInt32(&resigned, 1) }),
+	)
+
+	ctx := context.Background()
+	elector.Campaign(ctx)
+	waitFor(t, elector.IsLeader, time.Second, "elector to become leader")
+
+	elector.Resign(context.Background())
+	if atomic.LoadInt32(&resigned) != 1 {
+		t.Fatalf("expected onResigned to run once after Resign, got %d", resigned)
+	}
+
+	ok, err := provider.TryLock(context.Background(), "job", "instance-2", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected the key to be free immediately after Resign, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestLeaderElectorSecondCampaignerTakesOverAfterFirstResigns(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	ttl := 30 * time.Millisecond
+
+	var firstElected int32
+	first := NewLeaderElector(provider, "job", "instance-1", ttl, WithOnElected(func() { atomic.AddInt32(&firstElected, 1) }))
+	var secondElected int32
+	second := NewLeaderElector(provider, "job", "instance-2", ttl, WithOnElected(func() { atomic.AddInt32(&secondElected, 1) }))
+
+	ctx := context.Background()
+	first.Campaign(ctx)
+	second.Campaign(ctx)
+	defer first.Resign(context.Background())
+	defer second.Resign(context.Background())
+
+	waitFor(t, func() bool { return first.IsLeader() || second.IsLeader() }, time.Second, "one elector to become leader")
+	if first.IsLeader() && second.IsLeader() {
+		t.Fatal("expected only one elector to be leader at a time")
+	}
+
+	if first.IsLeader() {
+		first.Resign(context.Background())
+	} else {
+		second.Resign(context.Background())
+	}
+
+	waitFor(t, func() bool { return first.IsLeader() || second.IsLeader() }, time.Second, "the other elector to take over")
+}
@@ -0,0 +1,57 @@
+// Package lock provides named, TTL-bounded mutual exclusion, used to
+// make sure only one process at a time performs some piece of work
+// (e.g. a scheduled recovery sweep) even when several orchestrator
+// instances are running.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotOwner is returned by Unlock when key is held by a different
+// owner than the one asking to release it.
+var ErrNotOwner = errors.New("lock: not held by this owner")
+
+// Lock describes a currently held lock.
+type Lock struct {
+	Key        string
+	Owner      string
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+}
+
+// Metrics reports a Provider's lock contention since it was created.
+type Metrics struct {
+	// Contended counts acquisition attempts (TryLock or LockWithWait)
+	// that found the key already held by a different owner.
+	Contended int64
+	// WaitTime sums how long LockWithWait callers spent blocked across
+	// every call, contended or not.
+	WaitTime time.Duration
+}
+
+// Provider acquires and releases named locks. Implementations must be
+// safe for concurrent use.
+type Provider interface {
+	// TryLock attempts to acquire key for owner for ttl, returning
+	// immediately with ok == false if key is already held by a
+	// different owner rather than waiting for it to free up.
+	TryLock(ctx context.Context, key, owner string, ttl time.Duration) (ok bool, err error)
+	// LockWithWait acquires key for owner for ttl, blocking until it
+	// becomes available or ctx is done. It returns ctx.Err() if ctx is
+	// done before the lock could be acquired.
+	LockWithWait(ctx context.Context, key, owner string, ttl time.Duration) error
+	// Unlock releases key if owner currently holds it. Releasing a key
+	// that isn't held, or has already expired, is not an error.
+	Unlock(ctx context.Context, key, owner string) error
+	// ForceUnlock releases key regardless of its current owner, for an
+	// operator clearing a lock stuck behind a crashed process. It
+	// returns the owner key was held by, or "" if it wasn't held.
+	ForceUnlock(ctx context.Context, key string) (priorOwner string, err error)
+	// List returns every lock currently held, for introspection.
+	List(ctx context.Context) ([]Lock, error)
+	// Metrics reports cumulative contention stats for this provider.
+	Metrics() Metrics
+}
@@ -0,0 +1,171 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryLockProvider is an in-process Provider backed by a map, suitable
+// for single-process deployments and tests. LockWithWait blocks on a
+// per-key notification channel rather than polling with sleeps: a
+// waiter wakes as soon as the lock is released, or at its holder's TTL
+// deadline if no one releases it explicitly, whichever comes first.
+type MemoryLockProvider struct {
+	mu      sync.Mutex
+	held    map[string]heldLock
+	waiters map[string]chan struct{}
+
+	contended int64 // atomic
+	waitTime  int64 // atomic, nanoseconds
+}
+
+type heldLock struct {
+	owner      string
+	acquiredAt time.Time
+	expiresAt  time.Time
+}
+
+// NewMemoryLockProvider builds an empty MemoryLockProvider.
+func NewMemoryLockProvider() *MemoryLockProvider {
+	return &MemoryLockProvider{
+		held:    make(map[string]heldLock),
+		waiters: make(map[string]chan struct{}),
+	}
+}
+
+// TryLock implements Provider.
+func (p *MemoryLockProvider) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expireLocked(key)
+	if existing, busy := p.held[key]; busy && existing.owner != owner {
+		atomic.AddInt64(&p.contended, 1)
+		return false, nil
+	}
+	now := time.Now()
+	p.held[key] = heldLock{owner: owner, acquiredAt: now, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// LockWithWait implements Provider.
+func (p *MemoryLockProvider) LockWithWait(ctx context.Context, key, owner string, ttl time.Duration) error {
+	waitStart := time.Now()
+	contended := false
+	defer func() {
+		atomic.AddInt64(&p.waitTime, int64(time.Since(waitStart)))
+		if contended {
+			atomic.AddInt64(&p.contended, 1)
+		}
+	}()
+
+	for {
+		p.mu.Lock()
+		p.expireLocked(key)
+		existing, busy := p.held[key]
+		if !busy || existing.owner == owner {
+			now := time.Now()
+			p.held[key] = heldLock{owner: owner, acquiredAt: now, expiresAt: now.Add(ttl)}
+			p.mu.Unlock()
+			return nil
+		}
+		contended = true
+		waitCh := p.waiterLocked(key)
+		deadline := time.Until(existing.expiresAt)
+		p.mu.Unlock()
+
+		timer := time.NewTimer(deadline)
+		select {
+		case <-waitCh:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// Unlock implements Provider.
+func (p *MemoryLockProvider) Unlock(ctx context.Context, key, owner string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing, held := p.held[key]
+	if !held {
+		return nil
+	}
+	if existing.owner != owner {
+		return ErrNotOwner
+	}
+	delete(p.held, key)
+	p.notifyLocked(key)
+	return nil
+}
+
+// ForceUnlock implements Provider.
+func (p *MemoryLockProvider) ForceUnlock(ctx context.Context, key string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	existing, held := p.held[key]
+	if !held {
+		return "", nil
+	}
+	delete(p.held, key)
+	p.notifyLocked(key)
+	return existing.owner, nil
+}
+
+// List implements Provider.
+func (p *MemoryLockProvider) List(ctx context.Context) ([]Lock, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	locks := make([]Lock, 0, len(p.held))
+	for key, h := range p.held {
+		if time.Now().After(h.expiresAt) {
+			continue
+		}
+		locks = append(locks, Lock{Key: key, Owner: h.owner, AcquiredAt: h.acquiredAt, ExpiresAt: h.expiresAt})
+	}
+	return locks, nil
+}
+
+// Metrics implements Provider.
+func (p *MemoryLockProvider) Metrics() Metrics {
+	return Metrics{
+		Contended: atomic.LoadInt64(&p.contended),
+		WaitTime:  time.Duration(atomic.LoadInt64(&p.waitTime)),
+	}
+}
+
+// expireLocked drops key's lock if its TTL has elapsed and wakes any
+// waiters, as if it had been explicitly unlocked. Callers must hold p.mu.
+func (p *MemoryLockProvider) expireLocked(key string) {
+	existing, held := p.held[key]
+	if !held || time.Now().Before(existing.expiresAt) {
+		return
+	}
+	delete(p.held, key)
+	p.notifyLocked(key)
+}
+
+// notifyLocked wakes every goroutine waiting on key, if any. Callers
+// must hold p.mu.
+func (p *MemoryLockProvider) notifyLocked(key string) {
+	if ch, ok := p.waiters[key]; ok {
+		close(ch)
+		delete(p.waiters, key)
+	}
+}
+
+// waiterLocked returns the channel that will be closed the next time
+// key is released or expires, creating one if none is pending yet.
+// Callers must hold p.mu.
+func (p *MemoryLockProvider) waiterLocked(key string) chan struct{} {
+	ch, ok := p.waiters[key]
+	if !ok {
+		ch = make(chan struct{})
+		p.waiters[key] = ch
+	}
+	return ch
+}
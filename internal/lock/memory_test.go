@@ -0,0 +1,105 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTryLockRejectsASecondOwnerWhileHeld(t *testing.T) {
+	p := NewMemoryLockProvider()
+	ok, err := p.TryLock(context.Background(), "k", "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected first TryLock to succeed, got ok=%v err=%v", ok, err)
+	}
+	ok, err = p.TryLock(context.Background(), "k", "b", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if ok {
+		t.Fatal("expected second owner's TryLock to fail while the lock is held")
+	}
+}
+
+func TestTryLockSucceedsAfterTTLExpires(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if ok, err := p.TryLock(context.Background(), "k", "a", 10*time.Millisecond); err != nil || !ok {
+		t.Fatalf("first TryLock: ok=%v err=%v", ok, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	ok, err := p.TryLock(context.Background(), "k", "b", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected TryLock to succeed once the first owner's TTL expired, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestUnlockByWrongOwnerFails(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if _, err := p.TryLock(context.Background(), "k", "a", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if err := p.Unlock(context.Background(), "k", "b"); err != ErrNotOwner {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+}
+
+func TestUnlockOfAnUnheldKeyIsANoop(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if err := p.Unlock(context.Background(), "k", "a"); err != nil {
+		t.Fatalf("expected no error unlocking an unheld key, got %v", err)
+	}
+}
+
+func TestLockWithWaitWakesImmediatelyOnUnlock(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if _, err := p.TryLock(context.Background(), "k", "a", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- p.LockWithWait(context.Background(), "k", "b", time.Minute) }()
+
+	// Give the waiter a moment to start blocking before releasing, so
+	// this exercises the wake-on-unlock path rather than a lucky race.
+	time.Sleep(10 * time.Millisecond)
+	if err := p.Unlock(context.Background(), "k", "a"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("LockWithWait: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("LockWithWait did not wake up after unlock")
+	}
+}
+
+func TestLockWithWaitWakesAtTTLExpiryWithoutExplicitUnlock(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if _, err := p.TryLock(context.Background(), "k", "a", 20*time.Millisecond); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	start := time.Now()
+	if err := p.LockWithWait(context.Background(), "k", "b", time.Minute); err != nil {
+		t.Fatalf("LockWithWait: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("LockWithWait took %s, expected it to wake near the 20ms TTL", elapsed)
+	}
+}
+
+func TestLockWithWaitReturnsContextErrorWhenCancelled(t *testing.T) {
+	p := NewMemoryLockProvider()
+	if _, err := p.TryLock(context.Background(), "k", "a", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.LockWithWait(ctx, "k", "b", time.Minute); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package lock
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// mysqlTestDB opens a connection to the MySQL instance named by
+// ORCHESTRATOR_MYSQL_TEST_DSN, skipping the test if it isn't set, since
+// these tests need a real server rather than the sqlite used elsewhere
+// in this repo.
+func mysqlTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("ORCHESTRATOR_MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("ORCHESTRATOR_MYSQL_TEST_DSN not set, skipping MySQL lock provider tests")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open mysql: %v", err)
+	}
+	return db
+}
+
+func TestMySQLLockProviderTryLockRejectsASecondOwnerWhileHeld(t *testing.T) {
+	db := mysqlTestDB(t)
+	p, err := NewMySQLLockProvider(db)
+	if err != nil {
+		t.Fatalf("NewMySQLLockProvider: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM orchestrator_locks") })
+
+	ok, err := p.TryLock(context.Background(), "k", "a", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("first TryLock: ok=%v err=%v", ok, err)
+	}
+	ok, err = p.TryLock(context.Background(), "k", "b", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if ok {
+		t.Fatal("expected second owner's TryLock to fail while the lock is held")
+	}
+}
+
+func TestMySQLLockProviderTryLockSucceedsAfterTTLExpires(t *testing.T) {
+	db := mysqlTestDB(t)
+	p, err := NewMySQLLockProvider(db)
+	if err != nil {
+		t.Fatalf("NewMySQLLockProvider: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM orchestrator_locks") })
+
+	if ok, err := p.TryLock(context.Background(), "k", "a", 10*time.Millisecond); err != nil || !ok {
+		t.Fatalf("first TryLock: ok=%v err=%v", ok, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	ok, err := p.TryLock(context.Background(), "k", "b", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected TryLock to succeed once the first owner's TTL expired, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestMySQLLockProviderUnlockByWrongOwnerFails(t *testing.T) {
+	db := mysqlTestDB(t)
+	p, err := NewMySQLLockProvider(db)
+	if err != nil {
+		t.Fatalf("NewMySQLLockProvider: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM orchestrator_locks") })
+
+	if _, err := p.TryLock(context.Background(), "k", "a", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if err := p.Unlock(context.Background(), "k", "b"); err != ErrNotOwner {
+		t.Fatalf("expected ErrNotOwner, got %v", err)
+	}
+}
+
+func TestMySQLLockProviderForceUnlockReturnsPriorOwner(t *testing.T) {
+	db := mysqlTestDB(t)
+	p, err := NewMySQLLockProvider(db)
+	if err != nil {
+		t.Fatalf("NewMySQLLockProvider: %v", err)
+	}
+	t.Cleanup(func() { db.Exec("DELETE FROM orchestrator_locks") })
+
+	if _, err := p.TryLock(context.Background(), "k", "a", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	priorOwner, err := p.ForceUnlock(context.Background(), "k")
+	if err != nil {
+		t.Fatalf("ForceUnlock: %v", err)
+	}
+	if priorOwner != "a" {
+		t.Fatalf("expected prior owner %q, got %q", "a", priorOwner)
+	}
+}
@@ -0,0 +1,146 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Session tracks every lock a single process instance currently holds
+// against a Provider, so they can all be released together on a
+// graceful shutdown instead of making a restarted instance wait out
+// each one's full TTL. While a Session holds a lock it renews it on a
+// fixed heartbeat well before the TTL would expire; if the process
+// crashes instead of calling Close, those heartbeats simply stop and
+// the lock lapses via the Provider's own TTL expiry, which is exactly
+// the "orphaned" state a crashed owner's locks should end up in -
+// there's no separate bookkeeping needed to detect that case.
+type Session struct {
+	provider Provider
+	owner    string
+	ttl      time.Duration
+
+	mu   sync.Mutex
+	held map[string]struct{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSession starts a Session that acquires locks against provider as
+// owner, renewing each held key well before ttl would expire so they
+// don't lapse while this process is alive. Call Close to release
+// everything it holds and stop the heartbeat.
+func NewSession(provider Provider, owner string, ttl time.Duration) *Session {
+	s := &Session{
+		provider: provider,
+		owner:    owner,
+		ttl:      ttl,
+		held:     make(map[string]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.heartbeat()
+	return s
+}
+
+// heartbeatInterval renews each held lock at roughly a third of its TTL,
+// so a single missed renewal (e.g. a slow GC pause) doesn't let it lapse.
+func (s *Session) heartbeatInterval() time.Duration {
+	interval := s.ttl / 3
+	if interval <= 0 {
+		return time.Second
+	}
+	return interval
+}
+
+func (s *Session) heartbeat() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.heartbeatInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.renewAll()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Session) renewAll() {
+	for _, key := range s.heldKeys() {
+		// Best-effort: a renewal failure here just means the next tick
+		// tries again before the TTL actually runs out.
+		s.provider.TryLock(context.Background(), key, s.owner, s.ttl)
+	}
+}
+
+func (s *Session) heldKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.held))
+	for key := range s.held {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// TryAcquire attempts to acquire key without blocking, tracking it for
+// heartbeat renewal and graceful release on success.
+func (s *Session) TryAcquire(ctx context.Context, key string) (bool, error) {
+	ok, err := s.provider.TryLock(ctx, key, s.owner, s.ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+	s.track(key)
+	return true, nil
+}
+
+// Acquire blocks until key is acquired or ctx is done, tracking it for
+// heartbeat renewal and graceful release on success.
+func (s *Session) Acquire(ctx context.Context, key string) error {
+	if err := s.provider.LockWithWait(ctx, key, s.owner, s.ttl); err != nil {
+		return err
+	}
+	s.track(key)
+	return nil
+}
+
+func (s *Session) track(key string) {
+	s.mu.Lock()
+	s.held[key] = struct{}{}
+	s.mu.Unlock()
+}
+
+// Release releases key immediately and stops renewing it.
+func (s *Session) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.held, key)
+	s.mu.Unlock()
+	return s.provider.Unlock(ctx, key, s.owner)
+}
+
+// Close stops the heartbeat and releases every lock this Session still
+// holds, so a controlled shutdown frees them immediately rather than
+// leaving the next holder to wait out their TTLs.
+func (s *Session) Close(ctx context.Context) error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.held))
+	for key := range s.held {
+		keys = append(keys, key)
+	}
+	s.held = make(map[string]struct{})
+	s.mu.Unlock()
+
+	var firstErr error
+	for _, key := range keys {
+		if err := s.provider.Unlock(ctx, key, s.owner); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,87 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSessionCloseReleasesHeldLocksImmediately(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	session := NewSession(provider, "instance-1", time.Minute)
+
+	if ok, err := session.TryAcquire(context.Background(), "k"); err != nil || !ok {
+		t.Fatalf("TryAcquire: ok=%v err=%v", ok, err)
+	}
+
+	if err := session.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	ok, err := provider.TryLock(context.Background(), "k", "someone-else", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected lock to be free immediately after Close, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSessionReleaseStopsRenewingThatKey(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	session := NewSession(provider, "instance-1", time.Minute)
+	defer session.Close(context.Background())
+
+	if ok, err := session.TryAcquire(context.Background(), "k"); err != nil || !ok {
+		t.Fatalf("TryAcquire: ok=%v err=%v", ok, err)
+	}
+	if err := session.Release(context.Background(), "k"); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	ok, err := provider.TryLock(context.Background(), "k", "someone-else", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected lock to be free after Release, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestSessionHeartbeatRenewsLockPastItsOriginalTTL(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	ttl := 30 * time.Millisecond
+	session := NewSession(provider, "instance-1", ttl)
+	defer session.Close(context.Background())
+
+	if ok, err := session.TryAcquire(context.Background(), "k"); err != nil || !ok {
+		t.Fatalf("TryAcquire: ok=%v err=%v", ok, err)
+	}
+
+	// Longer than the original TTL: without renewal the lock would have
+	// lapsed and a competing owner would succeed below.
+	time.Sleep(ttl * 3)
+
+	ok, err := provider.TryLock(context.Background(), "k", "someone-else", time.Minute)
+	if err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+	if ok {
+		t.Fatal("expected the session's heartbeat to have kept the lock alive past its original TTL")
+	}
+}
+
+func TestSessionWithoutCloseLeavesLockToExpireOnItsOwn(t *testing.T) {
+	provider := NewMemoryLockProvider()
+	ttl := 10 * time.Millisecond
+	session := NewSession(provider, "instance-1", ttl)
+
+	if ok, err := session.TryAcquire(context.Background(), "k"); err != nil || !ok {
+		t.Fatalf("TryAcquire: ok=%v err=%v", ok, err)
+	}
+
+	// Simulate a crash: stop the heartbeat without releasing the lock.
+	close(session.stop)
+	<-session.done
+
+	time.Sleep(ttl * 3)
+
+	ok, err := provider.TryLock(context.Background(), "k", "someone-else", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected the lock to lapse on its own once heartbeats stopped, got ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,183 @@
+// Package logging provides the orchestrator's structured logger: a
+// small per-component wrapper around stderr that supports a plain-text
+// or JSON output format and a level threshold configurable separately
+// per component, so an operator can turn on debug logging for, say,
+// the lock elector without drowning the rest of the log stream.
+//
+// A Logger carries a set of key/value fields (With) that are attached
+// to every line it writes, which is how callers thread execution_id,
+// step_id, and template_id correlation through to a log-aggregation
+// pipeline without having to repeat them in every format string.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Level is a logging severity threshold. Levels are ordered from least
+// to most severe; a Logger only writes a line whose Level is at or
+// above its configured threshold.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String renders l the way it appears in both output formats and in
+// ORCHESTRATOR_LOG_LEVEL* environment variables.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// ParseLevel parses one of "debug", "info", "warn", or "error",
+// case-insensitively.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return 0, fmt.Errorf("logging: unknown level %q", s)
+	}
+}
+
+// defaultLevelEnvVar sets the threshold for any component without its
+// own override; componentLevelEnvVar names that per-component override.
+// formatEnvVar selects "json" output; anything else (including unset)
+// keeps the default plain-text format.
+const (
+	defaultLevelEnvVar = "ORCHESTRATOR_LOG_LEVEL"
+	formatEnvVar       = "ORCHESTRATOR_LOG_FORMAT"
+)
+
+func componentLevelEnvVar(component string) string {
+	return "ORCHESTRATOR_LOG_LEVEL_" + strings.ToUpper(component)
+}
+
+// field is one key/value pair attached to a Logger via With.
+type field struct {
+	key   string
+	value any
+}
+
+// Logger writes leveled, component-tagged log lines for one component
+// (e.g. "engine", "lock", "api", "service"). The zero value is not
+// usable; build one with New.
+type Logger struct {
+	component string
+	level     Level
+	json      bool
+	out       io.Writer
+	fields    []field
+}
+
+// New builds a Logger for component, reading its level threshold and
+// output format from the environment: ORCHESTRATOR_LOG_LEVEL_<COMPONENT>
+// (component upper-cased) if set, else ORCHESTRATOR_LOG_LEVEL, else
+// LevelInfo; and ORCHESTRATOR_LOG_FORMAT=json for JSON lines, else
+// plain text. Both are read fresh on every call rather than cached, so
+// components created at different points in startup can still pick up
+// an env var set in between, and so tests can exercise either without
+// any shared state between them.
+func New(component string) *Logger {
+	level := LevelInfo
+	if raw := os.Getenv(componentLevelEnvVar(component)); raw != "" {
+		if parsed, err := ParseLevel(raw); err == nil {
+			level = parsed
+		}
+	} else if raw := os.Getenv(defaultLevelEnvVar); raw != "" {
+		if parsed, err := ParseLevel(raw); err == nil {
+			level = parsed
+		}
+	}
+	return &Logger{
+		component: component,
+		level:     level,
+		json:      strings.EqualFold(os.Getenv(formatEnvVar), "json"),
+		out:       os.Stderr,
+	}
+}
+
+// With returns a copy of l that attaches key=value to every line it
+// writes, in addition to any fields already attached. It does not
+// modify l, so a Logger built once for a component can be specialized
+// per call (e.g. with an execution_id) without the specializations
+// leaking into each other.
+func (l *Logger) With(key string, value any) *Logger {
+	clone := *l
+	clone.fields = append(append([]field(nil), l.fields...), field{key, value})
+	return &clone
+}
+
+// Debugf logs at LevelDebug.
+func (l *Logger) Debugf(format string, args ...any) { l.logf(LevelDebug, format, args...) }
+
+// Infof logs at LevelInfo.
+func (l *Logger) Infof(format string, args ...any) { l.logf(LevelInfo, format, args...) }
+
+// Warnf logs at LevelWarn.
+func (l *Logger) Warnf(format string, args ...any) { l.logf(LevelWarn, format, args...) }
+
+// Errorf logs at LevelError.
+func (l *Logger) Errorf(format string, args ...any) { l.logf(LevelError, format, args...) }
+
+func (l *Logger) logf(level Level, format string, args ...any) {
+	if level < l.level {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if l.json {
+		l.writeJSON(level, msg)
+		return
+	}
+	l.writeText(level, msg)
+}
+
+func (l *Logger) writeJSON(level Level, msg string) {
+	entry := make(map[string]any, 4+len(l.fields))
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["component"] = l.component
+	entry["msg"] = msg
+	for _, f := range l.fields {
+		entry[f.key] = f.value
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(l.out, string(data))
+}
+
+func (l *Logger) writeText(level Level, msg string) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s: %s", time.Now().UTC().Format(time.RFC3339), level.String(), l.component, msg)
+	for _, f := range l.fields {
+		fmt.Fprintf(&b, " %s=%v", f.key, f.value)
+	}
+	fmt.Fprintln(l.out, b.String())
+}
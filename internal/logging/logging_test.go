@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewUsesPerComponentLevelOverrideBeforeTheDefault(t *testing.T) {
+	t.Setenv("ORCHESTRATOR_LOG_LEVEL", "error")
+	t.Setenv("ORCHESTRATOR_LOG_LEVEL_LOCK", "debug")
+
+	lock := New("lock")
+	if lock.level != LevelDebug {
+		t.Fatalf("expected the component override to win, got %s", lock.level)
+	}
+
+	api := New("api")
+	if api.level != LevelError {
+		t.Fatalf("expected api to fall back to the default level, got %s", api.level)
+	}
+}
+
+func TestLoggerFiltersLinesBelowItsConfiguredLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{component: "engine", level: LevelWarn, out: &buf}
+
+	l.Infof("step %s started", "build")
+	if buf.Len() != 0 {
+		t.Fatalf("expected an info line to be filtered at warn level, got %q", buf.String())
+	}
+
+	l.Errorf("step %s failed", "build")
+	if !strings.Contains(buf.String(), "step build failed") {
+		t.Fatalf("expected the error line to be written, got %q", buf.String())
+	}
+}
+
+func TestLoggerJSONOutputIncludesCorrelationFields(t *testing.T) {
+	var buf bytes.Buffer
+	l := &Logger{component: "engine", level: LevelInfo, json: true, out: &buf}
+	l = l.With("execution_id", uint(42)).With("step_id", "build")
+	l.Infof("step finished")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", buf.String(), err)
+	}
+	if entry["component"] != "engine" || entry["msg"] != "step finished" {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if entry["execution_id"] != float64(42) || entry["step_id"] != "build" {
+		t.Fatalf("expected correlation fields to be attached, got %v", entry)
+	}
+}
+
+func TestWithDoesNotMutateTheOriginalLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := &Logger{component: "engine", level: LevelInfo, json: true, out: &buf}
+	scoped := base.With("execution_id", uint(7))
+
+	base.Infof("unscoped")
+	scoped.Infof("scoped")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two log lines, got %d", len(lines))
+	}
+	var first map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("unmarshal first line: %v", err)
+	}
+	if _, ok := first["execution_id"]; ok {
+		t.Fatalf("expected the base logger's own line to carry no execution_id, got %v", first)
+	}
+}
+
+func TestParseLevelRejectsUnknownInput(t *testing.T) {
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("expected an error for an unrecognized level")
+	}
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Agent is one execution worker process that has heartbeated in, so the
+// control plane has an inventory of which hosts are alive, their
+// versions, advertised capabilities and current load.
+//
+// This orchestrator dispatches work in-process against an
+// executor.Registry (see executor.CapabilityProvider for per-Task
+// capability labels); Agent is reporting/inventory only today — nothing
+// here assigns a step to a particular Agent. It exists so a dispatcher
+// running in multi-agent mode has something to read.
+type Agent struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"size:255;not null;uniqueIndex" json:"name"`
+
+	// OS is the agent's reported platform, e.g. "linux", "darwin" or
+	// "windows".
+	OS      string `gorm:"size:32" json:"os,omitempty"`
+	Version string `gorm:"size:64" json:"version,omitempty"`
+
+	// Capabilities is a comma-separated list of labels this agent
+	// advertises, in the same format as
+	// TemplateStep.RequiredCapabilities (e.g. "network-zone=prod,has-docker").
+	Capabilities string `gorm:"type:text" json:"capabilities,omitempty"`
+
+	// CurrentLoad is the agent-reported count of steps it's currently
+	// running, for operators comparing load across the fleet.
+	CurrentLoad int `gorm:"not null;default:0" json:"current_load"`
+
+	LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
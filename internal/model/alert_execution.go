@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// AlertStatus mirrors Alertmanager's own alert status vocabulary.
+type AlertStatus string
+
+const (
+	AlertFiring   AlertStatus = "firing"
+	AlertResolved AlertStatus = "resolved"
+)
+
+// AlertExecution links one Alertmanager alert (identified by its stable
+// fingerprint) to the execution it most recently triggered, so a
+// repeated "firing" delivery for the same alert can be deduplicated
+// instead of starting a second execution, and a "resolved" delivery can
+// annotate the execution it originally triggered instead of starting a
+// new one.
+type AlertExecution struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	Fingerprint string      `gorm:"size:255;not null;uniqueIndex" json:"fingerprint"`
+	ExecutionID uint        `gorm:"not null" json:"execution_id"`
+	Status      AlertStatus `gorm:"size:32;not null" json:"status"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "strings"
+
+// AlertRoute maps an Alertmanager alert's labels to the template that
+// should run to remediate it, the same way an on-call engineer would
+// pick a runbook given an alert's labels. Routes are evaluated in
+// ascending Priority order (ties broken by ID); the first route whose
+// Matchers are all satisfied by an alert's labels wins.
+type AlertRoute struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Name string `gorm:"size:255;not null" json:"name"`
+	// Matchers is a comma-separated list of key=value pairs, e.g.
+	// "severity=critical,service=payments" (the same convention as
+	// executor.CapabilityProvider's labels), every one of which must be
+	// present in an alert's labels for this route to match. Empty
+	// matches every alert, so a route can be used as a catch-all
+	// fallback at the lowest Priority.
+	Matchers   string `gorm:"type:text" json:"matchers,omitempty"`
+	TemplateID uint   `gorm:"not null" json:"template_id"`
+	Priority   int    `gorm:"not null;default:0" json:"priority"`
+}
+
+// Matches reports whether every key=value pair in r.Matchers is present
+// in labels.
+func (r AlertRoute) Matches(labels map[string]string) bool {
+	for _, matcher := range strings.Split(r.Matchers, ",") {
+		matcher = strings.TrimSpace(matcher)
+		if matcher == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(matcher, "=")
+		if !ok || labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package model
+
+import "testing"
+
+func TestAlertRouteMatches(t *testing.T) {
+	route := AlertRoute{Matchers: "service=payments,severity=critical"}
+
+	if !route.Matches(map[string]string{"service": "payments", "severity": "critical", "team": "core"}) {
+		t.Fatal("expected a superset of labels to match")
+	}
+	if route.Matches(map[string]string{"service": "payments"}) {
+		t.Fatal("expected a missing matcher key to fail to match")
+	}
+	if route.Matches(map[string]string{"service": "payments", "severity": "warning"}) {
+		t.Fatal("expected a mismatched matcher value to fail to match")
+	}
+}
+
+func TestAlertRouteWithNoMatchersMatchesEverything(t *testing.T) {
+	route := AlertRoute{}
+	if !route.Matches(map[string]string{"anything": "goes"}) {
+		t.Fatal("expected an empty Matchers route to match any labels")
+	}
+	if !route.Matches(nil) {
+		t.Fatal("expected an empty Matchers route to match even nil labels")
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Artifact records metadata for a file a step produced during an
+// execution. Its bytes live in the orchestrator's configured
+// artifact.Store under StorageKey, not in this row.
+type Artifact struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ExecutionID uint   `gorm:"not null;index" json:"execution_id"`
+	StepName    string `gorm:"size:255;not null" json:"step_name"`
+	Name        string `gorm:"size:255;not null;index" json:"name"`
+	ContentType string `gorm:"size:255" json:"content_type,omitempty"`
+	Size        int64  `gorm:"not null" json:"size"`
+	StorageKey  string `gorm:"size:1024;not null" json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// BlackoutWindow marks a span of time during which new executions of a
+// project's (or, if TemplateID is set, one specific template's)
+// workflows should not start automatically. An empty Project applies
+// the window to every project, mirroring RedactionRule's empty-Project
+// catch-all; TemplateID further narrows an already-matching Project
+// scope, and is ignored (zero) to leave the window project-wide.
+type BlackoutWindow struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Project string `gorm:"size:255;index" json:"project,omitempty"`
+	// TemplateID, if set, scopes the window to one template instead of
+	// every template in Project.
+	TemplateID uint `gorm:"index" json:"template_id,omitempty"`
+
+	StartsAt time.Time `gorm:"not null;index" json:"starts_at"`
+	EndsAt   time.Time `gorm:"not null;index" json:"ends_at"`
+	// Reason describes why the window exists (e.g. "quarterly freeze",
+	// "datacenter migration"), surfaced back to whoever was queued or
+	// rejected because of it.
+	Reason string `gorm:"size:255" json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Covers reports whether the window is in effect at at and applies to
+// an execution of templateID in project: its own Project is empty or
+// matches project, and its TemplateID is zero or matches templateID.
+func (w BlackoutWindow) Covers(project string, templateID uint, at time.Time) bool {
+	if w.Project != "" && w.Project != project {
+		return false
+	}
+	if w.TemplateID != 0 && w.TemplateID != templateID {
+		return false
+	}
+	return !at.Before(w.StartsAt) && at.Before(w.EndsAt)
+}
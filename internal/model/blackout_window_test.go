@@ -0,0 +1,45 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlackoutWindowCoversChecksProjectTemplateAndTimeRange(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	window := BlackoutWindow{Project: "payments", StartsAt: start, EndsAt: end}
+
+	if !window.Covers("payments", 1, start) {
+		t.Fatal("expected the window to cover its own start instant")
+	}
+	if window.Covers("payments", 1, end) {
+		t.Fatal("expected the window to exclude its end instant")
+	}
+	if window.Covers("checkout", 1, start.Add(time.Hour)) {
+		t.Fatal("expected a project-scoped window to not cover a different project")
+	}
+}
+
+func TestBlackoutWindowWithNoProjectCoversEveryProject(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	window := BlackoutWindow{StartsAt: start, EndsAt: end}
+
+	if !window.Covers("anything", 1, start) {
+		t.Fatal("expected an empty Project window to cover every project")
+	}
+}
+
+func TestBlackoutWindowWithTemplateIDOnlyCoversThatTemplate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	window := BlackoutWindow{TemplateID: 5, StartsAt: start, EndsAt: end}
+
+	if !window.Covers("payments", 5, start) {
+		t.Fatal("expected the window to cover its own TemplateID")
+	}
+	if window.Covers("payments", 6, start) {
+		t.Fatal("expected the window to not cover a different TemplateID")
+	}
+}
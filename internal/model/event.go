@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// ExecutionEventType enumerates the kinds of state transitions recorded in
+// the execution event stream.
+type ExecutionEventType string
+
+const (
+	EventNodeStarted  ExecutionEventType = "node_started"
+	EventNodeFinished ExecutionEventType = "node_finished"
+	EventNodeFailed   ExecutionEventType = "node_failed"
+	EventNodeRetried  ExecutionEventType = "node_retried"
+	EventNodeSkipped  ExecutionEventType = "node_skipped"
+	// EventNodeSoftTimeout is recorded when a step has run past its soft
+	// timeout, as a warning; the step keeps running.
+	EventNodeSoftTimeout ExecutionEventType = "node_soft_timeout"
+	// EventNodeHardTimeout is recorded when a step is cancelled for
+	// running past its hard timeout, immediately before it's failed like
+	// any other step error.
+	EventNodeHardTimeout ExecutionEventType = "node_hard_timeout"
+	// EventNodeAwaitingExternal is recorded when an "external" step
+	// starts waiting for a human or third-party system to complete it
+	// via the execution's external-completion API.
+	EventNodeAwaitingExternal ExecutionEventType = "node_awaiting_external"
+	// EventNodeExternalReminder is recorded each time an "external"
+	// step's configured reminder interval elapses while it's still
+	// waiting to be completed.
+	EventNodeExternalReminder ExecutionEventType = "node_external_reminder"
+	EventLockAcquired         ExecutionEventType = "lock_acquired"
+	EventLockRefreshed        ExecutionEventType = "lock_refreshed"
+	EventExecutionStarted     ExecutionEventType = "execution_started"
+	EventExecutionFinished    ExecutionEventType = "execution_finished"
+	// EventExecutionLate is recorded the moment an execution's runtime
+	// exceeds its template's SLA, whether or not it goes on to succeed.
+	EventExecutionLate ExecutionEventType = "execution_late"
+	// EventExecutionQueued is recorded when an execution is handed to a
+	// WorkerPool and is waiting for a free worker slot.
+	EventExecutionQueued ExecutionEventType = "execution_queued"
+	// EventExecutionResumed is recorded when a failed execution is
+	// re-run from ExecutionService.Resume, distinguishing it in the
+	// event stream from an execution's original start.
+	EventExecutionResumed ExecutionEventType = "execution_resumed"
+)
+
+// ExecutionEvent is a single, immutable entry in the append-only event
+// stream for a WorkflowExecution. The full sequence of events for an
+// execution reconstructs its timeline exactly, including node-level
+// transitions and lock activity, and is the basis for audit and replay.
+type ExecutionEvent struct {
+	ID          uint               `gorm:"primaryKey" json:"id"`
+	ExecutionID uint               `gorm:"not null;index" json:"execution_id"`
+	StepName    string             `gorm:"size:255" json:"step_name,omitempty"`
+	Type        ExecutionEventType `gorm:"size:64;not null;index" json:"type"`
+	Message     string             `gorm:"type:text" json:"message,omitempty"`
+
+	// Sequence is monotonically increasing per execution, so events can be
+	// ordered reliably even if two land in the same millisecond.
+	Sequence uint64 `gorm:"not null" json:"sequence"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName pins the table name so event sourcing isn't affected by GORM's
+// pluralization rules changing across versions.
+func (ExecutionEvent) TableName() string {
+	return "workflow_execution_events"
+}
@@ -0,0 +1,177 @@
+// Package model defines this orchestrator's single persisted schema:
+// WorkflowTemplate/TemplateStep describe a DAG of steps, and
+// WorkflowExecution/StepExecution record one run of it. There is no
+// separate, unrelated Execution/Template family elsewhere in the
+// codebase; every package (executor, service, api) reads and writes
+// these same types.
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/optimisticlock"
+)
+
+// ExecutionStatus enumerates the lifecycle states of a WorkflowExecution or
+// StepExecution.
+type ExecutionStatus string
+
+const (
+	StatusPending ExecutionStatus = "pending"
+	// StatusQueued marks a WorkflowExecution as waiting for a free
+	// WorkerPool slot, after it was created but before it starts running.
+	StatusQueued    ExecutionStatus = "queued"
+	StatusRunning   ExecutionStatus = "running"
+	StatusSucceeded ExecutionStatus = "succeeded"
+	StatusFailed    ExecutionStatus = "failed"
+	StatusSkipped   ExecutionStatus = "skipped"
+	StatusCancelled ExecutionStatus = "cancelled"
+	// StatusPaused marks a WorkflowExecution as suspended mid-run. No
+	// code path enters it yet; it exists so the state machine in
+	// statemachine.go already has a slot for a future pause feature.
+	StatusPaused ExecutionStatus = "paused"
+	// StatusPendingExternal marks a StepExecution as blocked on an
+	// "external" step's Task waiting for a human or third-party system
+	// to complete it via the execution's external-completion API,
+	// rather than on a Task actively running.
+	StatusPendingExternal ExecutionStatus = "pending_external"
+)
+
+// WorkflowExecution is one run of a WorkflowTemplate.
+type WorkflowExecution struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	TemplateID uint            `gorm:"not null;index" json:"template_id"`
+	Status     ExecutionStatus `gorm:"size:32;not null;index" json:"status"`
+	Error      string          `gorm:"type:text" json:"error,omitempty"`
+	// Simulated marks executions run in simulation mode, with one or
+	// more steps' outcomes forced by injected faults rather than real
+	// task execution.
+	Simulated bool `gorm:"not null;default:false" json:"simulated"`
+	// Priority orders this execution in a WorkerPool's queue relative to
+	// others still waiting for a free worker; higher runs first.
+	Priority int `gorm:"not null;default:0" json:"priority"`
+	// MaxParallel caps how many of this execution's steps the
+	// TaskOrchestrator runs at once, even within a DAG layer wide
+	// enough to run more of them concurrently. Copied from the
+	// template at start time, optionally overridden per execution.
+	// Zero or negative means unbounded.
+	MaxParallel int `gorm:"not null;default:0" json:"max_parallel,omitempty"`
+	// SoftTimeoutSeconds and HardTimeoutSeconds are this execution's
+	// timeout policy, copied from the template at start time. A step can
+	// override either with its own TemplateStep field; otherwise these
+	// apply. Zero disables that tier.
+	SoftTimeoutSeconds int64 `gorm:"not null;default:0" json:"soft_timeout_seconds,omitempty"`
+	HardTimeoutSeconds int64 `gorm:"not null;default:0" json:"hard_timeout_seconds,omitempty"`
+	// Project is copied from the template at start time, so the
+	// TaskOrchestrator can look up this execution's redaction rules
+	// (and anything else project-scoped) without a join back to the
+	// template on every step.
+	Project string `gorm:"size:255;index" json:"project,omitempty"`
+	// OnCallRoutingKey is copied from the template at start time, same
+	// as Project, so a failure notification can be routed to the
+	// owning team (see notify.TeamRouter) without a join back to the
+	// template.
+	OnCallRoutingKey string `gorm:"size:255" json:"on_call_routing_key,omitempty"`
+	// Late is set once this execution's runtime exceeds its template's
+	// SLASeconds, regardless of whether it goes on to succeed.
+	Late bool `gorm:"not null;default:false;index" json:"late"`
+	// Params is the JSON object this execution was started with,
+	// validated against its template's ParamsSchema at creation time.
+	Params string `gorm:"type:text" json:"params,omitempty"`
+	// ChaosConfig is the JSON encoding of the executor.ChaosConfig this
+	// execution was started with via ExecutionService.StartWithChaos, if
+	// any, recorded so a run affected by randomized fault injection can
+	// be told apart from a genuine failure after the fact. Empty means
+	// chaos wasn't configured for this execution.
+	ChaosConfig string `gorm:"type:text" json:"chaos_config,omitempty"`
+	// DAGHash is the structural hash (dag.Graph.StructuralHash) of the
+	// template's DAG at the moment this execution started, so a later
+	// resume can detect whether the template changed underneath it.
+	DAGHash string `gorm:"size:32" json:"dag_hash,omitempty"`
+	// Version is an optimistic-lock counter: every update is conditioned
+	// on it matching the database's current value and bumps it by one,
+	// so two writers racing to update the same execution (e.g. the
+	// orchestrator finishing a run and an API cancel request) can't
+	// silently clobber each other's change.
+	Version optimisticlock.Version `json:"version"`
+
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	Steps []StepExecution `gorm:"foreignKey:ExecutionID" json:"steps,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BumpVersion advances e's in-memory Version to match what the database
+// holds after a successful optimistic-locked update, so the same
+// in-memory struct can be saved again without conflicting with itself.
+func (e *WorkflowExecution) BumpVersion() {
+	e.Version.Int64++
+	e.Version.Valid = true
+}
+
+// StepExecution records the outcome of running a single TemplateStep within
+// a WorkflowExecution.
+type StepExecution struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ExecutionID uint   `gorm:"not null;index" json:"execution_id"`
+	StepName    string `gorm:"size:255;not null" json:"step_name"`
+	// Attempt counts this row's position among every StepExecution ever
+	// created for the same (ExecutionID, StepName) pair, starting at 1.
+	// A step run more than once (e.g. across a Resume or RerunStep) gets
+	// a fresh row per attempt rather than overwriting the old one; this
+	// is just that existing ordering made explicit instead of implied by
+	// row ID, so callers don't have to assume insertion order is attempt
+	// order.
+	Attempt int             `gorm:"not null;default:1" json:"attempt"`
+	Status  ExecutionStatus `gorm:"size:32;not null;index" json:"status"`
+	Output  string          `gorm:"type:text" json:"output,omitempty"`
+	Error   string          `gorm:"type:text" json:"error,omitempty"`
+
+	// Hostname, OS, Arch and OrchestratorVersion identify the process
+	// that ran this step, captured once when the TaskOrchestrator
+	// starts (see executor.CaptureEnvironment) rather than re-read per
+	// step, so a step that only fails on one replica can be traced back
+	// to which one ran it.
+	Hostname            string `gorm:"size:255" json:"hostname,omitempty"`
+	OS                  string `gorm:"size:32" json:"os,omitempty"`
+	Arch                string `gorm:"size:32" json:"arch,omitempty"`
+	OrchestratorVersion string `gorm:"size:64" json:"orchestrator_version,omitempty"`
+	// EnvFingerprint is a SHA-256 hex digest of the process's sorted
+	// environment variable *names* (never their values, since those can
+	// hold secrets), so two replicas with a different set of env vars
+	// configured show a different fingerprint without either one's
+	// values ever being persisted.
+	EnvFingerprint string `gorm:"size:64" json:"env_fingerprint,omitempty"`
+	// Version is an optimistic-lock counter; see
+	// WorkflowExecution.Version for why it's here.
+	Version optimisticlock.Version `json:"version"`
+
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// AgentRuntimeSeconds is how long the step's Task actually spent
+	// running (just the Task.Run call), as opposed to FinishedAt minus
+	// StartedAt, which also counts time spent waiting for a sandbox
+	// directory, bulkhead slot or ConcurrencyKey lock.
+	AgentRuntimeSeconds float64 `gorm:"not null;default:0" json:"agent_runtime_seconds,omitempty"`
+	// CostCents is this step's resource cost in US cents, as reported by
+	// its Task via RecordCost (e.g. a cloud action task annotating the
+	// cost of the call it made). Zero means no Task reported a cost.
+	CostCents int64 `gorm:"not null;default:0" json:"cost_cents,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BumpVersion advances se's in-memory Version to match what the database
+// holds after a successful optimistic-locked update, so the same
+// in-memory struct can be saved again without conflicting with itself.
+func (se *StepExecution) BumpVersion() {
+	se.Version.Int64++
+	se.Version.Valid = true
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// ExternalInput records one piece of data a step read from outside the
+// orchestrator while it ran (an HTTP response body, an environment
+// value, ...), so a failed execution can later be replayed against the
+// exact same external data instead of whatever a live endpoint or
+// environment happens to return on retry. This is unrelated to
+// StatusPendingExternal/ExternalTask, which is about a step waiting on a
+// human or third-party system to complete it; ExternalInput is about a
+// step recording what it consumed while running normally.
+type ExternalInput struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ExecutionID uint   `gorm:"not null;index" json:"execution_id"`
+	StepName    string `gorm:"size:255;not null" json:"step_name"`
+	Name        string `gorm:"size:255;not null" json:"name"`
+	Value       string `gorm:"type:text" json:"value"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
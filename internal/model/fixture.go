@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// TemplateFixture is a "golden" execution recorded for a WorkflowTemplate:
+// a fixed set of simulation faults, plus the execution that ran them and
+// is trusted as the expected outcome. FixtureService.TestRun replays
+// Faults through StartSimulated and diffs the fresh run against
+// GoldenExecutionID (via report.Compare), so an edit to the template
+// that changes its behavior is caught before anyone notices in
+// production.
+type TemplateFixture struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	TemplateID uint   `gorm:"not null;index" json:"template_id"`
+	Name       string `gorm:"size:255;not null" json:"name"`
+
+	// Faults is replayed on every test run via StartSimulated, so the
+	// comparison stays apples-to-apples against GoldenExecutionID. It's
+	// the JSON encoding of a map[string]executor.Fault; simulated runs
+	// have no other configurable input today.
+	Faults string `gorm:"type:text" json:"faults,omitempty"`
+
+	// GoldenExecutionID is the execution whose outcome this fixture treats
+	// as correct, normally the one FixtureService.CreateFixture itself
+	// produced by running Params/Faults at fixture-creation time.
+	GoldenExecutionID uint `gorm:"not null" json:"golden_execution_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+// LabelOwnerType identifies which kind of resource a Label is attached
+// to.
+type LabelOwnerType string
+
+const (
+	LabelOwnerTemplate  LabelOwnerType = "template"
+	LabelOwnerExecution LabelOwnerType = "execution"
+)
+
+// Label is an arbitrary key/value pair attached to a template or
+// execution, e.g. env=prod or service=payments, used for filtering and
+// bulk operations.
+type Label struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	OwnerType LabelOwnerType `gorm:"size:32;not null;index:idx_label_owner" json:"owner_type"`
+	OwnerID   uint           `gorm:"not null;index:idx_label_owner" json:"owner_id"`
+	Key       string         `gorm:"size:255;not null;index" json:"key"`
+	Value     string         `gorm:"size:255;not null" json:"value"`
+}
+
+// TableName keeps the label table name stable and explicit.
+func (Label) TableName() string {
+	return "labels"
+}
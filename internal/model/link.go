@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// ExecutionLinkKind categorizes an ExecutionLink so a UI or notification
+// can choose an icon/label without parsing the URL. It's not a closed
+// enum enforced anywhere: an operator's ticketing system this repo
+// doesn't know about yet can use LinkKindOther or any other string.
+type ExecutionLinkKind string
+
+const (
+	LinkKindJira      ExecutionLinkKind = "jira"
+	LinkKindPagerDuty ExecutionLinkKind = "pagerduty"
+	LinkKindGrafana   ExecutionLinkKind = "grafana"
+	LinkKindOther     ExecutionLinkKind = "other"
+)
+
+// ExecutionLink attaches an external reference (a ticket, an incident, a
+// dashboard) to a WorkflowExecution, so a failure notification or a
+// postmortem report can point straight at the systems already tracking
+// the same incident instead of just this execution's own ID.
+type ExecutionLink struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	ExecutionID uint              `gorm:"not null;index" json:"execution_id"`
+	Kind        ExecutionLinkKind `gorm:"size:64;not null" json:"kind"`
+	URL         string            `gorm:"size:2048;not null" json:"url"`
+	// Label, if set, is shown instead of URL (e.g. "JIRA-1234").
+	Label string `gorm:"size:255" json:"label,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,9 @@
+package model
+
+import "errors"
+
+// ErrConcurrentUpdate is returned when an optimistic-locked update to a
+// WorkflowExecution or StepExecution affected no rows, meaning another
+// writer changed the row first. Callers should reload the row and retry
+// rather than treat this as a terminal failure.
+var ErrConcurrentUpdate = errors.New("concurrent update: row was modified by another writer")
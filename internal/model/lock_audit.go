@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// LockAuditEvent records an administrative force-release of a stuck
+// lock, so operators can later see who cleared it and why.
+type LockAuditEvent struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Provider string `gorm:"size:255;not null;index" json:"provider"`
+	Key      string `gorm:"size:255;not null;index" json:"key"`
+	// PriorOwner is who held the lock at the time it was force-released,
+	// empty if it was already free.
+	PriorOwner  string `gorm:"size:255" json:"prior_owner,omitempty"`
+	RequestedBy string `gorm:"size:255" json:"requested_by,omitempty"`
+	Reason      string `gorm:"type:text" json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ExecutionNote is a timestamped, freeform comment an operator attaches
+// to a WorkflowExecution, or to one of its steps, to record context a
+// machine wouldn't otherwise capture (e.g. "manually restarted DB at
+// 14:32"). Notes are never read or acted on by the executor; they exist
+// purely for humans reconstructing what happened during an incident.
+type ExecutionNote struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ExecutionID uint   `gorm:"not null;index" json:"execution_id"`
+	// StepName, if set, scopes the note to one step rather than the
+	// execution as a whole.
+	StepName  string `gorm:"size:255" json:"step_name,omitempty"`
+	Message   string `gorm:"type:text;not null" json:"message"`
+	CreatedBy string `gorm:"size:255" json:"created_by,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// TemplateOwnershipEvent records a change to a WorkflowTemplate's
+// Maintainers, so a transfer of ownership leaves an audit trail of who
+// it moved from, to, and why, the same way LockAuditEvent does for a
+// force-released lock.
+type TemplateOwnershipEvent struct {
+	ID         uint `gorm:"primaryKey" json:"id"`
+	TemplateID uint `gorm:"not null;index" json:"template_id"`
+	// PriorMaintainers and NewMaintainers are both comma-separated, in
+	// the same shape as WorkflowTemplate.Maintainers.
+	PriorMaintainers string `gorm:"type:text" json:"prior_maintainers,omitempty"`
+	NewMaintainers   string `gorm:"type:text" json:"new_maintainers,omitempty"`
+	RequestedBy      string `gorm:"size:255" json:"requested_by,omitempty"`
+	Reason           string `gorm:"type:text" json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Quota caps how much execution work a project may run, enforced by
+// ExecutionService before a new WorkflowExecution is created. A zero
+// limit means unlimited for that dimension.
+type Quota struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Project string `gorm:"size:255;uniqueIndex;not null" json:"project"`
+
+	// MaxConcurrentExecutions caps how many of the project's executions
+	// may be running at once.
+	MaxConcurrentExecutions int `gorm:"not null;default:0" json:"max_concurrent_executions"`
+	// MaxExecutionsPerDay caps how many executions the project may start
+	// in a rolling 24 hours.
+	MaxExecutionsPerDay int `gorm:"not null;default:0" json:"max_executions_per_day"`
+	// MaxStepRuntimeSecondsPerDay caps the combined StepExecution runtime
+	// the project may consume in a rolling 24 hours.
+	MaxStepRuntimeSecondsPerDay int64 `gorm:"not null;default:0" json:"max_step_runtime_seconds_per_day"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// RedactionRule scrubs one pattern from a project's stored step output,
+// event messages and notifications before they're persisted. An empty
+// Project applies the rule to every project, mirroring AlertRoute's
+// empty-Matchers catch-all.
+type RedactionRule struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Project string `gorm:"size:255;index" json:"project,omitempty"`
+	// Kind selects how Pattern is interpreted: "regex" (the default,
+	// used if empty) treats Pattern as a Go regexp and replaces every
+	// match; "json_field" treats Pattern as a dot-separated path into a
+	// JSON object (e.g. "data.token") and replaces that field's value
+	// if the text parses as JSON, leaving non-JSON text untouched.
+	Kind string `gorm:"size:32" json:"kind,omitempty"`
+	// Pattern is the regex or JSON field path to redact, depending on
+	// Kind.
+	Pattern string `gorm:"type:text;not null" json:"pattern"`
+	// Replacement substitutes each match. Defaults to "[redacted]" if
+	// empty.
+	Replacement string `gorm:"size:255" json:"replacement,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RedactionKindRegex and RedactionKindJSONField are the two supported
+// RedactionRule.Kind values.
+const (
+	RedactionKindRegex     = "regex"
+	RedactionKindJSONField = "json_field"
+)
+
+// DefaultRedactionReplacement is used in place of an empty
+// RedactionRule.Replacement.
+const DefaultRedactionReplacement = "[redacted]"
@@ -0,0 +1,91 @@
+package model
+
+import "fmt"
+
+// validTransitions enumerates, for each ExecutionStatus, the statuses it
+// may legally move to next. WorkflowExecution.Status and
+// StepExecution.Status are both validated against this one table: the
+// states either type actually uses are a subset of it, so a single table
+// is enough to catch an illegal transition attempted on either.
+var validTransitions = map[ExecutionStatus]map[ExecutionStatus]bool{
+	StatusPending: {
+		StatusQueued:    true,
+		StatusRunning:   true,
+		StatusSkipped:   true,
+		StatusCancelled: true,
+	},
+	StatusQueued: {
+		StatusRunning:   true,
+		StatusCancelled: true,
+	},
+	StatusRunning: {
+		StatusSucceeded:       true,
+		StatusFailed:          true,
+		StatusSkipped:         true,
+		StatusCancelled:       true,
+		StatusPaused:          true,
+		StatusPendingExternal: true,
+	},
+	StatusPaused: {
+		StatusRunning:   true,
+		StatusCancelled: true,
+	},
+	StatusPendingExternal: {
+		StatusSucceeded: true,
+		StatusFailed:    true,
+		StatusSkipped:   true,
+		StatusCancelled: true,
+	},
+	StatusFailed: {
+		// A failed execution may be resumed, which re-enters Running.
+		StatusRunning: true,
+	},
+}
+
+// ValidateTransition returns an error if moving from current to next is
+// not a legal step in the execution/step state machine; Succeeded,
+// Cancelled, and Skipped have no outgoing transitions at all. Moving to
+// the current status is always allowed as a no-op.
+func ValidateTransition(current, next ExecutionStatus) error {
+	if current == next {
+		return nil
+	}
+	if validTransitions[current][next] {
+		return nil
+	}
+	return fmt.Errorf("illegal status transition from %q to %q", current, next)
+}
+
+// rollupPrecedence orders statuses from most to least urgent for
+// RollupStatus: a group's reported status is whichever status present in
+// it sorts first here, e.g. one running step makes the whole group
+// "running" even if the rest already succeeded.
+var rollupPrecedence = []ExecutionStatus{
+	StatusRunning,
+	StatusPendingExternal,
+	StatusFailed,
+	StatusCancelled,
+	StatusQueued,
+	StatusPending,
+	StatusPaused,
+	StatusSkipped,
+	StatusSucceeded,
+}
+
+// RollupStatus summarizes a group of statuses (e.g. every StepExecution
+// in one template stage) as a single status, using rollupPrecedence so
+// the result reflects the most urgent thing happening in the group
+// rather than, say, a majority vote. An empty statuses returns
+// StatusPending, since a group with nothing in it yet hasn't started.
+func RollupStatus(statuses []ExecutionStatus) ExecutionStatus {
+	present := make(map[ExecutionStatus]bool, len(statuses))
+	for _, s := range statuses {
+		present[s] = true
+	}
+	for _, candidate := range rollupPrecedence {
+		if present[candidate] {
+			return candidate
+		}
+	}
+	return StatusPending
+}
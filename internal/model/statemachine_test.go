@@ -0,0 +1,70 @@
+package model
+
+import "testing"
+
+func TestValidateTransitionAllowsTheHappyPath(t *testing.T) {
+	steps := []struct{ from, to ExecutionStatus }{
+		{StatusPending, StatusQueued},
+		{StatusQueued, StatusRunning},
+		{StatusRunning, StatusSucceeded},
+	}
+	for _, s := range steps {
+		if err := ValidateTransition(s.from, s.to); err != nil {
+			t.Fatalf("%s -> %s: unexpected error: %v", s.from, s.to, err)
+		}
+	}
+}
+
+func TestValidateTransitionRejectsSkippingStates(t *testing.T) {
+	if err := ValidateTransition(StatusPending, StatusSucceeded); err == nil {
+		t.Fatal("expected pending -> succeeded to be rejected")
+	}
+}
+
+func TestValidateTransitionRejectsLeavingTerminalStates(t *testing.T) {
+	if err := ValidateTransition(StatusSucceeded, StatusRunning); err == nil {
+		t.Fatal("expected succeeded -> running to be rejected")
+	}
+	if err := ValidateTransition(StatusCancelled, StatusRunning); err == nil {
+		t.Fatal("expected cancelled -> running to be rejected")
+	}
+}
+
+func TestValidateTransitionAllowsResumingAFailedExecution(t *testing.T) {
+	if err := ValidateTransition(StatusFailed, StatusRunning); err != nil {
+		t.Fatalf("expected failed -> running to be allowed for resume, got %v", err)
+	}
+}
+
+func TestValidateTransitionAllowsNoOp(t *testing.T) {
+	if err := ValidateTransition(StatusRunning, StatusRunning); err != nil {
+		t.Fatalf("expected a same-status transition to be a no-op, got %v", err)
+	}
+}
+
+func TestRollupStatusPrefersRunningOverEverythingElse(t *testing.T) {
+	got := RollupStatus([]ExecutionStatus{StatusSucceeded, StatusRunning, StatusFailed})
+	if got != StatusRunning {
+		t.Fatalf("expected StatusRunning, got %q", got)
+	}
+}
+
+func TestRollupStatusPrefersFailedOverSucceededAndSkipped(t *testing.T) {
+	got := RollupStatus([]ExecutionStatus{StatusSucceeded, StatusSkipped, StatusFailed})
+	if got != StatusFailed {
+		t.Fatalf("expected StatusFailed, got %q", got)
+	}
+}
+
+func TestRollupStatusSucceededOnlyWhenEverythingSucceeded(t *testing.T) {
+	got := RollupStatus([]ExecutionStatus{StatusSucceeded, StatusSucceeded})
+	if got != StatusSucceeded {
+		t.Fatalf("expected StatusSucceeded, got %q", got)
+	}
+}
+
+func TestRollupStatusOfEmptyGroupIsPending(t *testing.T) {
+	if got := RollupStatus(nil); got != StatusPending {
+		t.Fatalf("expected StatusPending for an empty group, got %q", got)
+	}
+}
@@ -0,0 +1,172 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkflowTemplate is a reusable, versioned definition of a DAG of steps.
+type WorkflowTemplate struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Name        string `gorm:"size:255;not null;index" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	Version     int    `gorm:"not null;default:1" json:"version"`
+
+	// Published marks the template as part of the shared catalog other
+	// teams can browse and clone, as opposed to a private draft.
+	Published bool `gorm:"not null;default:false;index" json:"published"`
+	// ClonedFromID points at the template this one was cloned from, if
+	// any, so the catalog can show provenance.
+	ClonedFromID *uint `json:"cloned_from_id,omitempty"`
+	// Project groups templates (and the executions started from them) for
+	// quota enforcement and usage reporting. Empty means ungrouped and
+	// subject to no quota.
+	Project string `gorm:"size:255;index" json:"project,omitempty"`
+	// Maintainers is a comma-separated list of users (e.g. usernames or
+	// emails) responsible for this template. The catalog and "my
+	// templates" list filters (see ListQuery.Maintainer) check
+	// membership in this list; TemplateService.TransferOwnership is the
+	// only way to change it, so every change leaves a
+	// TemplateOwnershipEvent. Empty means unowned.
+	Maintainers string `gorm:"type:text" json:"maintainers,omitempty"`
+	// OnCallRoutingKey names which team a failed execution of this
+	// template notifies, via notify.TeamRouter. Empty routes to the
+	// orchestrator's single, non-team-scoped Notifier, same as every
+	// template before this field existed.
+	OnCallRoutingKey string `gorm:"size:255" json:"on_call_routing_key,omitempty"`
+	// SLASeconds is the expected wall-clock duration for a run of this
+	// template. An execution that takes longer is marked Late and a
+	// notification fires, even if it ultimately succeeds. Zero means no
+	// SLA is tracked.
+	SLASeconds int64 `gorm:"not null;default:0" json:"sla_seconds,omitempty"`
+	// MaxParallel caps how many steps of a single execution of this
+	// template run at once, even within a DAG layer wide enough to run
+	// more of them concurrently. Useful for steps that share a scarce
+	// downstream resource (e.g. never more than 2 concurrent
+	// DB-touching steps). Zero or negative means unbounded.
+	MaxParallel int `gorm:"not null;default:0" json:"max_parallel,omitempty"`
+	// SoftTimeoutSeconds is this template's default: once a step has run
+	// this long without finishing, a warning event fires (and a
+	// diagnostic hook runs, if the step configures one), but the step
+	// keeps running. A TemplateStep with its own SoftTimeoutSeconds
+	// overrides this. Zero disables the soft timeout by default.
+	SoftTimeoutSeconds int64 `gorm:"not null;default:0" json:"soft_timeout_seconds,omitempty"`
+	// HardTimeoutSeconds is this template's default: once a step has run
+	// this long without finishing, its context is cancelled and it's
+	// failed like any other step error. A TemplateStep with its own
+	// HardTimeoutSeconds overrides this. Zero disables the hard timeout
+	// by default.
+	HardTimeoutSeconds int64 `gorm:"not null;default:0" json:"hard_timeout_seconds,omitempty"`
+	// ParamsSchema is a JSON Schema document the params an execution is
+	// started with must satisfy. ExecutionService.Start rejects params
+	// that violate it before creating the execution. Empty means any
+	// params are accepted.
+	ParamsSchema string `gorm:"type:text" json:"params_schema,omitempty"`
+	// OutputSchema is a JSON Schema document the execution's outputs (a
+	// JSON object of every step's Output, keyed by step name) must
+	// satisfy once it succeeds, so other templates composing this one as
+	// a sub-workflow can trust its shape without inspecting its steps.
+	// Empty means no contract is checked.
+	OutputSchema string `gorm:"type:text" json:"output_schema,omitempty"`
+
+	Steps []TemplateStep `gorm:"foreignKey:TemplateID" json:"steps,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TemplateStep is a single node in a WorkflowTemplate's DAG.
+type TemplateStep struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	TemplateID uint   `gorm:"not null;index" json:"template_id"`
+	Name       string `gorm:"size:255;not null" json:"name"`
+	Type       string `gorm:"size:64;not null" json:"type"`
+	Config     string `gorm:"type:text" json:"config"`
+
+	// DependsOn holds the names of steps that must finish before this one
+	// starts, serialized as a comma-separated list.
+	DependsOn string `gorm:"type:text" json:"depends_on"`
+
+	// Stage optionally groups steps for display and bulk operations
+	// (e.g. "prepare", "remediate", "verify"), independent of the DAG
+	// edges between them. Empty means ungrouped.
+	Stage string `gorm:"size:255;index" json:"stage,omitempty"`
+
+	// SoftTimeoutSeconds and HardTimeoutSeconds override the
+	// WorkflowExecution's timeout policy for this step specifically.
+	// Zero means "use the execution's policy" rather than "disabled";
+	// set the execution's policy to zero to disable a timeout tier
+	// entirely.
+	SoftTimeoutSeconds int64 `gorm:"not null;default:0" json:"soft_timeout_seconds,omitempty"`
+	HardTimeoutSeconds int64 `gorm:"not null;default:0" json:"hard_timeout_seconds,omitempty"`
+	// DiagnosticType, if set, is a registered Task type run once, in the
+	// background, when this step crosses its soft timeout, to gather
+	// extra information (e.g. dump a stack trace or fetch recent logs).
+	// Its outcome is recorded as an event and never affects the step's
+	// own result, even if the diagnostic itself fails.
+	DiagnosticType   string `gorm:"size:64" json:"diagnostic_type,omitempty"`
+	DiagnosticConfig string `gorm:"type:text" json:"diagnostic_config,omitempty"`
+
+	// RequiredCapabilities holds a comma-separated list of labels
+	// (e.g. "network-zone=prod,has-docker") the Task registered for Type
+	// must advertise via executor.CapabilityProvider before this step is
+	// dispatched. Empty means any registered Task for Type may run it.
+	RequiredCapabilities string `gorm:"type:text" json:"required_capabilities,omitempty"`
+
+	// ConcurrencyKey, if set, names a mutex this step must hold while it
+	// runs: across every execution, only one step holding the same
+	// ConcurrencyKey runs at a time, so conflicting remediation actions
+	// (e.g. two executions both trying to run a "db-migration" step)
+	// never overlap. Empty means the step runs without any such
+	// restriction.
+	ConcurrencyKey string `gorm:"size:255;index" json:"concurrency_key,omitempty"`
+	// ConcurrencyTimeoutSeconds bounds how long this step waits to
+	// acquire ConcurrencyKey before failing rather than running. Zero
+	// means it waits as long as the step's own timeout policy allows,
+	// same as today's behavior.
+	ConcurrencyTimeoutSeconds int64 `gorm:"not null;default:0" json:"concurrency_timeout_seconds,omitempty"`
+
+	// RunAsUser, if set, asks a shell step to run as this user (e.g. via
+	// sudo -u) instead of the orchestrator's own service account.
+	// executor.ShellTask rejects it unless it appears in its configured
+	// allowlist. Empty means the step runs as the orchestrator's service
+	// account.
+	RunAsUser string `gorm:"size:255" json:"run_as_user,omitempty"`
+
+	// PositionX/PositionY are the node's coordinates in the visual DAG
+	// editor's canvas, so a user's layout survives a reload.
+	PositionX float64 `gorm:"not null;default:0" json:"position_x"`
+	PositionY float64 `gorm:"not null;default:0" json:"position_y"`
+
+	// IncludedFromTemplateID records which template this step was
+	// imported from via a create-time include, for provenance. Nil for
+	// a step authored directly on this template.
+	IncludedFromTemplateID *uint `json:"included_from_template_id,omitempty"`
+
+	// ConfigSignature is an HMAC-SHA256 of this step's definition,
+	// computed by stepsign.Signer at authoring time. The executor
+	// recomputes and compares it before running the step, refusing to
+	// run one whose stored definition no longer matches, so a direct
+	// database edit to Config (or any other signed field) is caught
+	// before it executes rather than silently running a different
+	// script than the one that was reviewed. Empty when signing isn't
+	// configured.
+	ConfigSignature string `gorm:"type:text" json:"config_signature,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EdgeLayout persists routing metadata for one DAG edge in the visual
+// editor (e.g. bend points), keyed by the step names it connects.
+type EdgeLayout struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	TemplateID uint   `gorm:"not null;index:idx_edge_layout_template" json:"template_id"`
+	FromStep   string `gorm:"size:255;not null" json:"from_step"`
+	ToStep     string `gorm:"size:255;not null" json:"to_step"`
+	// Waypoints is a JSON-encoded list of {x,y} points the edge should
+	// route through between FromStep and ToStep.
+	Waypoints string `gorm:"type:text" json:"waypoints"`
+}
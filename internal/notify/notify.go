@@ -0,0 +1,28 @@
+// Package notify defines the notification extension point the engine
+// calls into for operational events (currently SLA breaches), and a
+// couple of small implementations.
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Notifier receives operational notifications. Implementations should
+// not block the caller for long or panic; Notify errors are logged by
+// callers rather than treated as fatal, since a failed notification
+// should never take down an execution.
+type Notifier interface {
+	Notify(ctx context.Context, message string) error
+}
+
+// LogNotifier writes notifications to the standard logger. It is the
+// default Notifier so SLA breaches are always visible somewhere even
+// when no external alerting is wired up.
+type LogNotifier struct{}
+
+// Notify logs message and always returns nil.
+func (LogNotifier) Notify(ctx context.Context, message string) error {
+	log.Printf("notify: %s", message)
+	return nil
+}
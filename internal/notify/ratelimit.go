@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+// RateLimitedNotifier wraps a Notifier (a single channel, e.g. one
+// Slack webhook) with deduplication and a rate limit, so a DAG with
+// many failing steps can't spam that channel: an identical message sent
+// again within Window of its first delivery is dropped silently, and no
+// more than MaxPerWindow messages (after dedup) are delivered in any
+// Window: once that many have gone out, further messages are dropped
+// until the window rolls over. Construct with NewRateLimitedNotifier
+// rather than a struct literal, since it carries state that must be
+// initialized.
+type RateLimitedNotifier struct {
+	inner        Notifier
+	window       time.Duration
+	maxPerWindow int
+	clock        clock.Clock
+
+	mu          sync.Mutex
+	seen        map[string]time.Time
+	windowStart time.Time
+	windowCount int
+}
+
+// NewRateLimitedNotifier wraps inner so that, within any window-long
+// period, an identical message is delivered at most once and at most
+// maxPerWindow messages are delivered in total.
+func NewRateLimitedNotifier(inner Notifier, window time.Duration, maxPerWindow int) *RateLimitedNotifier {
+	return &RateLimitedNotifier{
+		inner:        inner,
+		window:       window,
+		maxPerWindow: maxPerWindow,
+		clock:        clock.Real{},
+		seen:         make(map[string]time.Time),
+	}
+}
+
+// WithClock overrides n's Clock, for deterministically testing window
+// rollover without a real sleep.
+func (n *RateLimitedNotifier) WithClock(c clock.Clock) *RateLimitedNotifier {
+	n.clock = c
+	return n
+}
+
+// Notify delivers message to the wrapped Notifier unless it is a
+// duplicate of one already delivered within the current window, or the
+// window's delivery limit has already been reached.
+func (n *RateLimitedNotifier) Notify(ctx context.Context, message string) error {
+	now := n.clock.Now()
+
+	n.mu.Lock()
+	if n.windowStart.IsZero() || now.Sub(n.windowStart) >= n.window {
+		n.windowStart = now
+		n.windowCount = 0
+		n.seen = make(map[string]time.Time)
+	}
+	if last, ok := n.seen[message]; ok && now.Sub(last) < n.window {
+		n.mu.Unlock()
+		return nil
+	}
+	if n.maxPerWindow > 0 && n.windowCount >= n.maxPerWindow {
+		n.mu.Unlock()
+		return nil
+	}
+	n.seen[message] = now
+	n.windowCount++
+	n.mu.Unlock()
+
+	return n.inner.Notify(ctx, message)
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+)
+
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestRateLimitedNotifierDropsDuplicateMessagesWithinWindow(t *testing.T) {
+	recorder := &recordingNotifier{}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	notifier := NewRateLimitedNotifier(recorder, time.Minute, 10).WithClock(fakeClock)
+
+	for i := 0; i < 3; i++ {
+		if err := notifier.Notify(context.Background(), "same failure"); err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+	}
+
+	if len(recorder.messages) != 1 {
+		t.Fatalf("expected the duplicate messages to be dropped, got %v", recorder.messages)
+	}
+}
+
+func TestRateLimitedNotifierAllowsDuplicateAfterWindowRollsOver(t *testing.T) {
+	recorder := &recordingNotifier{}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	notifier := NewRateLimitedNotifier(recorder, time.Minute, 10).WithClock(fakeClock)
+
+	if err := notifier.Notify(context.Background(), "same failure"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	fakeClock.Advance(2 * time.Minute)
+	if err := notifier.Notify(context.Background(), "same failure"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	if len(recorder.messages) != 2 {
+		t.Fatalf("expected the message to be delivered again after the window rolled over, got %v", recorder.messages)
+	}
+}
+
+func TestRateLimitedNotifierCapsDistinctMessagesPerWindow(t *testing.T) {
+	recorder := &recordingNotifier{}
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	notifier := NewRateLimitedNotifier(recorder, time.Minute, 2).WithClock(fakeClock)
+
+	for i, message := range []string{"failure a", "failure b", "failure c"} {
+		if err := notifier.Notify(context.Background(), message); err != nil {
+			t.Fatalf("Notify %d: %v", i, err)
+		}
+	}
+
+	if len(recorder.messages) != 2 {
+		t.Fatalf("expected only 2 distinct messages per window, got %v", recorder.messages)
+	}
+}
@@ -0,0 +1,30 @@
+package notify
+
+import "context"
+
+// TeamRouter dispatches a notification to the Notifier registered for a
+// routing key (see WorkflowTemplate.OnCallRoutingKey), so a failed
+// execution notifies the team that owns its template instead of every
+// failure going to one shared channel. An unset or unregistered key
+// falls back to Default, so a template that hasn't adopted per-team
+// routing behaves exactly as it did before TeamRouter existed.
+type TeamRouter struct {
+	Default Notifier
+	routes  map[string]Notifier
+}
+
+// NewTeamRouter builds a TeamRouter that falls back to def for any
+// routing key not present in routes.
+func NewTeamRouter(def Notifier, routes map[string]Notifier) *TeamRouter {
+	return &TeamRouter{Default: def, routes: routes}
+}
+
+// NotifyTeam delivers message through the Notifier registered for
+// routingKey, or through Default if routingKey is empty or has no
+// registered Notifier.
+func (r *TeamRouter) NotifyTeam(ctx context.Context, routingKey, message string) error {
+	if n, ok := r.routes[routingKey]; ok {
+		return n.Notify(ctx, message)
+	}
+	return r.Default.Notify(ctx, message)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTeamRouterRoutesToTheRegisteredTeam(t *testing.T) {
+	def := &recordingNotifier{}
+	payments := &recordingNotifier{}
+	router := NewTeamRouter(def, map[string]Notifier{"payments": payments})
+
+	if err := router.NotifyTeam(context.Background(), "payments", "it broke"); err != nil {
+		t.Fatalf("NotifyTeam: %v", err)
+	}
+	if len(payments.messages) != 1 || len(def.messages) != 0 {
+		t.Fatalf("expected the message to go only to payments, got payments=%v default=%v", payments.messages, def.messages)
+	}
+}
+
+func TestTeamRouterFallsBackToDefaultForAnUnknownKey(t *testing.T) {
+	def := &recordingNotifier{}
+	router := NewTeamRouter(def, map[string]Notifier{"payments": &recordingNotifier{}})
+
+	if err := router.NotifyTeam(context.Background(), "unregistered", "it broke"); err != nil {
+		t.Fatalf("NotifyTeam: %v", err)
+	}
+	if len(def.messages) != 1 {
+		t.Fatalf("expected the message to fall back to default, got %v", def.messages)
+	}
+}
+
+func TestTeamRouterFallsBackToDefaultForAnEmptyKey(t *testing.T) {
+	def := &recordingNotifier{}
+	router := NewTeamRouter(def, nil)
+
+	if err := router.NotifyTeam(context.Background(), "", "it broke"); err != nil {
+		t.Fatalf("NotifyTeam: %v", err)
+	}
+	if len(def.messages) != 1 {
+		t.Fatalf("expected the message to fall back to default, got %v", def.messages)
+	}
+}
@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// WebhookNotifier posts each notification as a JSON document to a fixed
+// URL, for wiring SLA breaches into Slack/PagerDuty-style incoming
+// webhooks without the orchestrator knowing about any specific provider.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Message string `json:"message"`
+}
+
+// Notify posts message to the webhook URL and treats a non-2xx response
+// as an error.
+func (n WebhookNotifier) Notify(ctx context.Context, message string) error {
+	body, err := json.Marshal(webhookPayload{Message: message})
+	if err != nil {
+		return fmt.Errorf("webhook notifier: encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook notifier: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook notifier: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook notifier: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Package queue provides a priority-ordered, fair-share queue of work
+// items waiting for a free worker, so a saturated pool runs
+// high-priority work (e.g. incident remediation) ahead of low-priority
+// batch work without letting one busy project starve the rest.
+package queue
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// Item is one unit of work waiting in a Queue.
+type Item struct {
+	ExecutionID uint
+	// Project groups items for fair-share dequeuing; items with an equal
+	// Priority are dequeued round-robin across distinct Projects.
+	Project string
+	// Priority orders dequeuing: higher values run first.
+	Priority int
+
+	index int
+}
+
+// innerQueue implements container/heap.Interface. Index 0 is always the
+// highest-priority item.
+type innerQueue struct {
+	items    []*Item
+	dequeued map[string]int
+}
+
+func (q *innerQueue) Len() int { return len(q.items) }
+
+func (q *innerQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return q.dequeued[a.Project] < q.dequeued[b.Project]
+}
+
+func (q *innerQueue) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].index = i
+	q.items[j].index = j
+}
+
+func (q *innerQueue) Push(x any) {
+	item := x.(*Item)
+	item.index = len(q.items)
+	q.items = append(q.items, item)
+}
+
+func (q *innerQueue) Pop() any {
+	n := len(q.items)
+	item := q.items[n-1]
+	q.items[n-1] = nil
+	q.items = q.items[:n-1]
+	return item
+}
+
+// Queue is a concurrency-safe priority queue of Items.
+type Queue struct {
+	mu    sync.Mutex
+	inner innerQueue
+}
+
+// New returns an empty Queue.
+func New() *Queue {
+	return &Queue{inner: innerQueue{dequeued: make(map[string]int)}}
+}
+
+// Push adds item to the queue.
+func (q *Queue) Push(item *Item) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	heap.Push(&q.inner, item)
+}
+
+// Pop removes and returns the highest-priority item, or nil if the queue
+// is empty. Among equally-prioritized items it returns the one whose
+// project has been dequeued least often.
+func (q *Queue) Pop() *Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.inner.Len() == 0 {
+		return nil
+	}
+	item := heap.Pop(&q.inner).(*Item)
+	q.inner.dequeued[item.Project]++
+	return item
+}
+
+// Len returns the number of items currently queued.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inner.Len()
+}
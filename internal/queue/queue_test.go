@@ -0,0 +1,43 @@
+package queue
+
+import "testing"
+
+func TestQueuePopsHighestPriorityFirst(t *testing.T) {
+	q := New()
+	q.Push(&Item{ExecutionID: 1, Project: "a", Priority: 0})
+	q.Push(&Item{ExecutionID: 2, Project: "a", Priority: 10})
+	q.Push(&Item{ExecutionID: 3, Project: "a", Priority: 5})
+
+	if got := q.Pop().ExecutionID; got != 2 {
+		t.Fatalf("expected execution 2 first, got %d", got)
+	}
+	if got := q.Pop().ExecutionID; got != 3 {
+		t.Fatalf("expected execution 3 second, got %d", got)
+	}
+	if got := q.Pop().ExecutionID; got != 1 {
+		t.Fatalf("expected execution 1 last, got %d", got)
+	}
+	if item := q.Pop(); item != nil {
+		t.Fatalf("expected an empty queue, got %+v", item)
+	}
+}
+
+func TestQueueFairSharesEqualPriorityAcrossProjects(t *testing.T) {
+	q := New()
+	q.Push(&Item{ExecutionID: 1, Project: "busy", Priority: 0})
+	q.Push(&Item{ExecutionID: 2, Project: "busy", Priority: 0})
+	q.Push(&Item{ExecutionID: 3, Project: "busy", Priority: 0})
+	q.Push(&Item{ExecutionID: 4, Project: "quiet", Priority: 0})
+
+	var order []string
+	for i := 0; i < 4; i++ {
+		order = append(order, q.Pop().Project)
+	}
+
+	if order[0] != "busy" {
+		t.Fatalf("expected the first dequeue to go to whichever project was pushed first, got %v", order)
+	}
+	if order[1] != "quiet" {
+		t.Fatalf("expected quiet's single item to be dequeued before busy's second item, got %v", order)
+	}
+}
@@ -0,0 +1,114 @@
+// Package redact scrubs secrets out of free text (step output, event
+// messages, notifications) according to a project's configured
+// model.RedactionRules, before any of it is persisted.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// Ruleset is a compiled, ready-to-apply set of model.RedactionRules.
+type Ruleset struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	kind        string
+	regex       *regexp.Regexp
+	jsonPath    []string
+	replacement string
+}
+
+// Build compiles rules into a Ruleset, rejecting any with an invalid
+// regex. Order is preserved: earlier rules run (and can be matched
+// again by later rules) before later ones.
+func Build(rules []model.RedactionRule) (*Ruleset, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = model.DefaultRedactionReplacement
+		}
+
+		kind := rule.Kind
+		if kind == "" {
+			kind = model.RedactionKindRegex
+		}
+
+		switch kind {
+		case model.RedactionKindRegex:
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", rule.ID, err)
+			}
+			compiled = append(compiled, compiledRule{kind: kind, regex: re, replacement: replacement})
+		case model.RedactionKindJSONField:
+			if rule.Pattern == "" {
+				return nil, fmt.Errorf("rule %d: json_field pattern must not be empty", rule.ID)
+			}
+			compiled = append(compiled, compiledRule{kind: kind, jsonPath: strings.Split(rule.Pattern, "."), replacement: replacement})
+		default:
+			return nil, fmt.Errorf("rule %d: unknown kind %q", rule.ID, rule.Kind)
+		}
+	}
+	return &Ruleset{rules: compiled}, nil
+}
+
+// Scrub applies every rule in r to text in order, returning the result.
+func (r *Ruleset) Scrub(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	for _, rule := range r.rules {
+		switch rule.kind {
+		case model.RedactionKindRegex:
+			text = rule.regex.ReplaceAllString(text, rule.replacement)
+		case model.RedactionKindJSONField:
+			text = redactJSONField(text, rule.jsonPath, rule.replacement)
+		}
+	}
+	return text
+}
+
+// redactJSONField replaces the value at path within text's top-level
+// JSON object with replacement, returning text unchanged if it doesn't
+// parse as a JSON object or doesn't contain path.
+func redactJSONField(text string, path []string, replacement string) string {
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return text
+	}
+	if !setField(doc, path, replacement) {
+		return text
+	}
+	scrubbed, err := json.Marshal(doc)
+	if err != nil {
+		return text
+	}
+	return string(scrubbed)
+}
+
+// setField walks doc along path, replacing the final key's value with
+// replacement. Returns false if any segment of path is missing.
+func setField(doc map[string]interface{}, path []string, replacement string) bool {
+	for i, key := range path {
+		if i == len(path)-1 {
+			if _, ok := doc[key]; !ok {
+				return false
+			}
+			doc[key] = replacement
+			return true
+		}
+		next, ok := doc[key].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		doc = next
+	}
+	return false
+}
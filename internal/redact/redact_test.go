@@ -0,0 +1,64 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestScrubAppliesARegexRuleToShellOutput(t *testing.T) {
+	ruleset, err := Build([]model.RedactionRule{{Pattern: `TOKEN=\S+`}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got := ruleset.Scrub("exporting TOKEN=abc123 for deploy")
+	if got != "exporting [redacted] for deploy" {
+		t.Fatalf("unexpected scrub result: %q", got)
+	}
+}
+
+func TestScrubAppliesAJSONFieldRuleToAnHTTPResponseBody(t *testing.T) {
+	ruleset, err := Build([]model.RedactionRule{{Kind: model.RedactionKindJSONField, Pattern: "access_token"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got := ruleset.Scrub(`{"access_token":"abc123","expires_in":3600}`)
+	if got != `{"access_token":"[redacted]","expires_in":3600}` {
+		t.Fatalf("unexpected scrub result: %q", got)
+	}
+}
+
+func TestScrubLeavesNonJSONTextUntouchedForAJSONFieldRule(t *testing.T) {
+	ruleset, err := Build([]model.RedactionRule{{Kind: model.RedactionKindJSONField, Pattern: "access_token"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got := ruleset.Scrub("plain text output, not json")
+	if got != "plain text output, not json" {
+		t.Fatalf("expected non-JSON text to pass through unchanged, got %q", got)
+	}
+}
+
+func TestScrubUsesACustomReplacement(t *testing.T) {
+	ruleset, err := Build([]model.RedactionRule{{Pattern: `secret`, Replacement: "***"}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	got := ruleset.Scrub("the secret is secret")
+	if got != "the *** is ***" {
+		t.Fatalf("unexpected scrub result: %q", got)
+	}
+}
+
+func TestBuildRejectsAnInvalidRegex(t *testing.T) {
+	if _, err := Build([]model.RedactionRule{{Pattern: `(unterminated`}}); err == nil {
+		t.Fatal("expected Build to reject an invalid regex")
+	}
+}
+
+func TestScrubOnANilRulesetReturnsTextUnchanged(t *testing.T) {
+	var ruleset *Ruleset
+	if got := ruleset.Scrub("anything"); got != "anything" {
+		t.Fatalf("expected a nil Ruleset to pass text through, got %q", got)
+	}
+}
@@ -0,0 +1,173 @@
+// Package render draws a template's DAG as an SVG or PNG image, for
+// embedding in reports and notifications without a browser-side
+// renderer.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"sort"
+	"strings"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+)
+
+const (
+	nodeWidth  = 140
+	nodeHeight = 40
+	margin     = 20
+)
+
+// Positions maps step name to its top-left canvas coordinates.
+type Positions map[string]struct{ X, Y float64 }
+
+// bounds returns the minimal image size that fits every node in graph at
+// positions, with margin padding.
+func bounds(graph *dag.Graph, positions Positions) (width, height int) {
+	var maxX, maxY float64
+	for name := range graph.Nodes {
+		pos := positions[name]
+		if pos.X+nodeWidth > maxX {
+			maxX = pos.X + nodeWidth
+		}
+		if pos.Y+nodeHeight > maxY {
+			maxY = pos.Y + nodeHeight
+		}
+	}
+	return int(maxX) + 2*margin, int(maxY) + 2*margin
+}
+
+// SVG renders graph as an SVG document, with nodes placed at positions
+// (falling back to (0,0) for any node missing a position) and edges
+// drawn as straight lines between node centers.
+func SVG(graph *dag.Graph, positions Positions) string {
+	width, height := bounds(graph, positions)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	b.WriteString(`<rect width="100%" height="100%" fill="white"/>`)
+
+	names := sortedNames(graph)
+	for _, name := range names {
+		node := graph.Nodes[name]
+		for _, dep := range node.DependsOn {
+			from, to := positions[dep], positions[name]
+			x1, y1 := from.X+margin+nodeWidth/2, from.Y+margin+nodeHeight/2
+			x2, y2 := to.X+margin+nodeWidth/2, to.Y+margin+nodeHeight/2
+			fmt.Fprintf(&b, `<line x1="%.0f" y1="%.0f" x2="%.0f" y2="%.0f" stroke="black"/>`, x1, y1, x2, y2)
+		}
+	}
+	for _, name := range names {
+		pos := positions[name]
+		x, y := pos.X+margin, pos.Y+margin
+		fmt.Fprintf(&b, `<rect x="%.0f" y="%.0f" width="%d" height="%d" fill="#eef" stroke="black"/>`, x, y, nodeWidth, nodeHeight)
+		fmt.Fprintf(&b, `<text x="%.0f" y="%.0f" text-anchor="middle" dominant-baseline="middle" font-size="12">%s</text>`,
+			x+nodeWidth/2, y+nodeHeight/2, escapeXML(name))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// PNG rasterizes the same layout as SVG into a PNG image. Node labels
+// are not drawn (PNG rendering here is for thumbnails/previews; use SVG
+// when labels matter).
+func PNG(graph *dag.Graph, positions Positions) ([]byte, error) {
+	width, height := bounds(graph, positions)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+
+	names := sortedNames(graph)
+	for _, name := range names {
+		node := graph.Nodes[name]
+		for _, dep := range node.DependsOn {
+			from, to := positions[dep], positions[name]
+			drawLine(img,
+				int(from.X+margin+nodeWidth/2), int(from.Y+margin+nodeHeight/2),
+				int(to.X+margin+nodeWidth/2), int(to.Y+margin+nodeHeight/2),
+				color.Black)
+		}
+	}
+	for _, name := range names {
+		pos := positions[name]
+		r := image.Rect(int(pos.X+margin), int(pos.Y+margin), int(pos.X+margin+nodeWidth), int(pos.Y+margin+nodeHeight))
+		draw.Draw(img, r, &image.Uniform{C: color.RGBA{0xee, 0xee, 0xff, 0xff}}, image.Point{}, draw.Src)
+		drawRectOutline(img, r, color.Black)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sortedNames(graph *dag.Graph) []string {
+	names := make([]string, 0, len(graph.Nodes))
+	for name := range graph.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func escapeXML(s string) string {
+	s = strings.ReplaceAll(s, "&", "&amp;")
+	s = strings.ReplaceAll(s, "<", "&lt;")
+	s = strings.ReplaceAll(s, ">", "&gt;")
+	return s
+}
+
+// drawLine draws a simple Bresenham line between (x0,y0) and (x1,y1).
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	for {
+		img.Set(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func drawRectOutline(img *image.RGBA, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		img.Set(x, r.Min.Y, c)
+		img.Set(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		img.Set(r.Min.X, y, c)
+		img.Set(r.Max.X-1, y, c)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	default:
+		return 0
+	}
+}
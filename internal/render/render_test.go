@@ -0,0 +1,51 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func testGraph(t *testing.T) *dag.Graph {
+	t.Helper()
+	g, err := dag.Build([]model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	return g
+}
+
+func TestSVGIncludesNodesAndEdges(t *testing.T) {
+	g := testGraph(t)
+	positions := Positions{
+		"a": {X: 0, Y: 0},
+		"b": {X: 200, Y: 0},
+	}
+	svg := SVG(g, positions)
+	if !strings.Contains(svg, "<svg") || !strings.Contains(svg, "</svg>") {
+		t.Fatalf("expected a well-formed svg document, got %q", svg)
+	}
+	if !strings.Contains(svg, ">a<") || !strings.Contains(svg, ">b<") {
+		t.Fatalf("expected node labels a and b, got %q", svg)
+	}
+	if !strings.Contains(svg, "<line") {
+		t.Fatalf("expected an edge line, got %q", svg)
+	}
+}
+
+func TestPNGProducesValidImage(t *testing.T) {
+	g := testGraph(t)
+	positions := Positions{"a": {X: 0, Y: 0}, "b": {X: 200, Y: 0}}
+	data, err := PNG(g, positions)
+	if err != nil {
+		t.Fatalf("PNG: %v", err)
+	}
+	if len(data) < 8 || string(data[1:4]) != "PNG" {
+		t.Fatalf("expected a PNG file signature, got %d bytes", len(data))
+	}
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// StepDiff compares one step, matched by name, between two executions.
+type StepDiff struct {
+	StepName string `json:"step_name"`
+	// PresentInA/PresentInB is false if no step by this name ran in
+	// that execution (e.g. it was added, removed, or skipped by a
+	// fault between the two runs), in which case the rest of this
+	// StepDiff's fields for that side are zero.
+	PresentInA bool `json:"present_in_a"`
+	PresentInB bool `json:"present_in_b"`
+
+	StatusA model.ExecutionStatus `json:"status_a,omitempty"`
+	StatusB model.ExecutionStatus `json:"status_b,omitempty"`
+
+	DurationA time.Duration `json:"duration_a_ms"`
+	DurationB time.Duration `json:"duration_b_ms"`
+
+	// OutputHashA/OutputHashB are sha256 hashes (hex-encoded) of each
+	// side's output, so two multi-megabyte outputs can be compared for
+	// equality without embedding both in full; the full output is still
+	// available from GET /executions/:id/steps/:stepId.
+	OutputHashA string `json:"output_hash_a,omitempty"`
+	OutputHashB string `json:"output_hash_b,omitempty"`
+
+	// Diverged is true if A and B differ on presence, status, or
+	// output hash for this step. It deliberately ignores Duration, so
+	// two runs that agree on outcome but ran at different speeds don't
+	// register as diverging.
+	Diverged bool `json:"diverged"`
+}
+
+// Diff compares two WorkflowExecutions step by step, matched by name,
+// so a postmortem can see exactly where behavior diverged between a run
+// that succeeded and one that failed. There's no separate "environment"
+// concept recorded alongside an execution beyond the Params it was
+// started with, so that's what ParamsA/ParamsB compare; a step's own
+// environment variables (e.g. ShellTask's RunAsUser) are part of its
+// config and would show up as a StepDiff divergence if they changed the
+// step's output.
+type Diff struct {
+	ExecutionIDA uint `json:"execution_id_a"`
+	ExecutionIDB uint `json:"execution_id_b"`
+	TemplateIDA  uint `json:"template_id_a"`
+	TemplateIDB  uint `json:"template_id_b"`
+
+	ParamsA    string `json:"params_a,omitempty"`
+	ParamsB    string `json:"params_b,omitempty"`
+	SameParams bool   `json:"same_params"`
+
+	Steps []StepDiff `json:"steps"`
+	// FirstDivergence is the name of the first step, in A's own step
+	// order, whose StepDiff has Diverged set, or "" if none did.
+	FirstDivergence string `json:"first_divergence,omitempty"`
+}
+
+// Compare builds a Diff between a and b. Steps are matched by name,
+// ordered as they appear in a (with any step present only in b appended
+// after, in b's order), so FirstDivergence reflects a's own execution
+// order.
+func Compare(a, b *model.WorkflowExecution) *Diff {
+	diff := &Diff{
+		ExecutionIDA: a.ID,
+		ExecutionIDB: b.ID,
+		TemplateIDA:  a.TemplateID,
+		TemplateIDB:  b.TemplateID,
+		ParamsA:      a.Params,
+		ParamsB:      b.Params,
+		SameParams:   a.Params == b.Params,
+	}
+
+	stepsA := latestStepsByName(a.Steps)
+	stepsB := latestStepsByName(b.Steps)
+
+	var order []string
+	seen := make(map[string]bool)
+	for _, step := range a.Steps {
+		if !seen[step.StepName] {
+			seen[step.StepName] = true
+			order = append(order, step.StepName)
+		}
+	}
+	for _, step := range b.Steps {
+		if !seen[step.StepName] {
+			seen[step.StepName] = true
+			order = append(order, step.StepName)
+		}
+	}
+
+	for _, name := range order {
+		stepA, okA := stepsA[name]
+		stepB, okB := stepsB[name]
+		sd := stepDiff(name, stepA, okA, stepB, okB)
+		if sd.Diverged && diff.FirstDivergence == "" {
+			diff.FirstDivergence = name
+		}
+		diff.Steps = append(diff.Steps, sd)
+	}
+
+	return diff
+}
+
+// latestStepsByName indexes steps by name, keeping only each name's
+// highest Attempt, so a step that was rerun compares against its final
+// outcome rather than an earlier, superseded one.
+func latestStepsByName(steps []model.StepExecution) map[string]model.StepExecution {
+	byName := make(map[string]model.StepExecution, len(steps))
+	for _, step := range steps {
+		if existing, ok := byName[step.StepName]; !ok || step.Attempt > existing.Attempt {
+			byName[step.StepName] = step
+		}
+	}
+	return byName
+}
+
+func stepDiff(name string, a model.StepExecution, okA bool, b model.StepExecution, okB bool) StepDiff {
+	sd := StepDiff{
+		StepName:   name,
+		PresentInA: okA,
+		PresentInB: okB,
+	}
+	if okA {
+		sd.StatusA = a.Status
+		sd.DurationA = stepDuration(a)
+		sd.OutputHashA = hashOutput(a.Output)
+	}
+	if okB {
+		sd.StatusB = b.Status
+		sd.DurationB = stepDuration(b)
+		sd.OutputHashB = hashOutput(b.Output)
+	}
+	sd.Diverged = okA != okB || sd.StatusA != sd.StatusB || sd.OutputHashA != sd.OutputHashB
+	return sd
+}
+
+func stepDuration(step model.StepExecution) time.Duration {
+	if step.StartedAt == nil || step.FinishedAt == nil {
+		return 0
+	}
+	return step.FinishedAt.Sub(*step.StartedAt)
+}
+
+// hashOutput returns the sha256 of output, hex-encoded, or "" for an
+// empty output (so two steps that both produced nothing don't report a
+// spurious divergence from comparing two differently-cased "empty"
+// hashes).
+func hashOutput(output string) string {
+	if output == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestCompareFindsFirstDivergentStepByOutput(t *testing.T) {
+	start := time.Unix(0, 0)
+	finish := start.Add(time.Second)
+	a := &model.WorkflowExecution{
+		ID: 1, TemplateID: 9, Params: `{"env":"prod"}`,
+		Steps: []model.StepExecution{
+			{StepName: "fetch", Status: model.StatusSucceeded, Output: "same", StartedAt: &start, FinishedAt: &finish},
+			{StepName: "deploy", Status: model.StatusSucceeded, Output: "deployed v1", StartedAt: &start, FinishedAt: &finish},
+		},
+	}
+	b := &model.WorkflowExecution{
+		ID: 2, TemplateID: 9, Params: `{"env":"prod"}`,
+		Steps: []model.StepExecution{
+			{StepName: "fetch", Status: model.StatusSucceeded, Output: "same", StartedAt: &start, FinishedAt: &finish},
+			{StepName: "deploy", Status: model.StatusFailed, Output: "", StartedAt: &start, FinishedAt: &finish},
+		},
+	}
+
+	diff := Compare(a, b)
+	if !diff.SameParams {
+		t.Error("expected SameParams to be true")
+	}
+	if diff.FirstDivergence != "deploy" {
+		t.Errorf("FirstDivergence = %q, want %q", diff.FirstDivergence, "deploy")
+	}
+	if len(diff.Steps) != 2 || diff.Steps[0].Diverged {
+		t.Fatalf("expected only the second step to diverge, got %+v", diff.Steps)
+	}
+}
+
+func TestCompareTreatsAStepMissingFromOneSideAsDiverged(t *testing.T) {
+	a := &model.WorkflowExecution{ID: 1, Steps: []model.StepExecution{
+		{StepName: "only-in-a", Status: model.StatusSucceeded},
+	}}
+	b := &model.WorkflowExecution{ID: 2}
+
+	diff := Compare(a, b)
+	if len(diff.Steps) != 1 {
+		t.Fatalf("expected 1 step, got %d", len(diff.Steps))
+	}
+	step := diff.Steps[0]
+	if !step.PresentInA || step.PresentInB || !step.Diverged {
+		t.Errorf("got %+v, want present in A only and diverged", step)
+	}
+}
+
+func TestCompareUsesEachStepsLatestAttempt(t *testing.T) {
+	a := &model.WorkflowExecution{ID: 1, Steps: []model.StepExecution{
+		{StepName: "retry-me", Attempt: 1, Status: model.StatusFailed, Output: "first try"},
+		{StepName: "retry-me", Attempt: 2, Status: model.StatusSucceeded, Output: "second try"},
+	}}
+	b := &model.WorkflowExecution{ID: 2, Steps: []model.StepExecution{
+		{StepName: "retry-me", Attempt: 1, Status: model.StatusSucceeded, Output: "second try"},
+	}}
+
+	diff := Compare(a, b)
+	if len(diff.Steps) != 1 {
+		t.Fatalf("expected 1 step, got %d", len(diff.Steps))
+	}
+	if diff.Steps[0].Diverged {
+		t.Errorf("expected a's final attempt to match b, got %+v", diff.Steps[0])
+	}
+}
@@ -0,0 +1,217 @@
+// Package report renders a WorkflowExecution as a self-contained report
+// (JSON, CSV, or HTML), suitable for attaching to an incident postmortem
+// without needing to query the API again.
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// maxOutputLength truncates a step's output and error in the report, so
+// a step that dumped megabytes of logs doesn't balloon the report; the
+// full value is still available from GET /executions/:id.
+const maxOutputLength = 2000
+
+// Step is one StepExecution's entry in a Report.
+type Step struct {
+	Name       string                `json:"name"`
+	Status     model.ExecutionStatus `json:"status"`
+	Output     string                `json:"output,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	StartedAt  *time.Time            `json:"started_at,omitempty"`
+	FinishedAt *time.Time            `json:"finished_at,omitempty"`
+	Duration   time.Duration         `json:"duration_ms"`
+}
+
+// Note is one operator annotation's entry in a Report.
+type Note struct {
+	StepName  string    `json:"step_name,omitempty"`
+	Message   string    `json:"message"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Link is one external reference's entry in a Report.
+type Link struct {
+	Kind  model.ExecutionLinkKind `json:"kind"`
+	URL   string                  `json:"url"`
+	Label string                  `json:"label,omitempty"`
+}
+
+// Report summarizes one WorkflowExecution for human consumption.
+type Report struct {
+	ExecutionID  uint                  `json:"execution_id"`
+	TemplateID   uint                  `json:"template_id"`
+	TemplateName string                `json:"template_name"`
+	Status       model.ExecutionStatus `json:"status"`
+	Error        string                `json:"error,omitempty"`
+	StartedAt    *time.Time            `json:"started_at,omitempty"`
+	FinishedAt   *time.Time            `json:"finished_at,omitempty"`
+	Duration     time.Duration         `json:"duration_ms"`
+	// DAGImageURL points at the template's exported DAG image (see GET
+	// /templates/:id/export), rather than embedding the image itself.
+	DAGImageURL string `json:"dag_image_url,omitempty"`
+	Steps       []Step `json:"steps"`
+	Notes       []Note `json:"notes,omitempty"`
+	Links       []Link `json:"links,omitempty"`
+}
+
+// Build assembles a Report from execution, its steps, any operator
+// notes, and any external links, truncating each step's Output and Error
+// to maxOutputLength.
+func Build(execution *model.WorkflowExecution, templateName string, dagImageURL string, notes []model.ExecutionNote, links []model.ExecutionLink) *Report {
+	r := &Report{
+		ExecutionID:  execution.ID,
+		TemplateID:   execution.TemplateID,
+		TemplateName: templateName,
+		Status:       execution.Status,
+		Error:        execution.Error,
+		StartedAt:    execution.StartedAt,
+		FinishedAt:   execution.FinishedAt,
+		DAGImageURL:  dagImageURL,
+		Steps:        make([]Step, 0, len(execution.Steps)),
+		Notes:        make([]Note, 0, len(notes)),
+		Links:        make([]Link, 0, len(links)),
+	}
+	for _, n := range notes {
+		r.Notes = append(r.Notes, Note{StepName: n.StepName, Message: n.Message, CreatedBy: n.CreatedBy, CreatedAt: n.CreatedAt})
+	}
+	for _, l := range links {
+		r.Links = append(r.Links, Link{Kind: l.Kind, URL: l.URL, Label: l.Label})
+	}
+	if execution.StartedAt != nil && execution.FinishedAt != nil {
+		r.Duration = execution.FinishedAt.Sub(*execution.StartedAt)
+	}
+	for _, se := range execution.Steps {
+		step := Step{
+			Name:       se.StepName,
+			Status:     se.Status,
+			Output:     truncate(se.Output),
+			Error:      truncate(se.Error),
+			StartedAt:  se.StartedAt,
+			FinishedAt: se.FinishedAt,
+		}
+		if se.StartedAt != nil && se.FinishedAt != nil {
+			step.Duration = se.FinishedAt.Sub(*se.StartedAt)
+		}
+		r.Steps = append(r.Steps, step)
+	}
+	return r
+}
+
+func truncate(s string) string {
+	if len(s) <= maxOutputLength {
+		return s
+	}
+	return s[:maxOutputLength] + "... (truncated)"
+}
+
+// JSON renders r as indented JSON.
+func JSON(r *Report) ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// CSV renders r as a CSV with one row per step, preceded by a header
+// row naming the execution itself.
+func CSV(r *Report) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"execution_id", "template_name", "status", "duration_ms", "error"}); err != nil {
+		return "", err
+	}
+	if err := w.Write([]string{fmt.Sprint(r.ExecutionID), r.TemplateName, string(r.Status), fmt.Sprint(r.Duration.Milliseconds()), r.Error}); err != nil {
+		return "", err
+	}
+	if err := w.Write(nil); err != nil {
+		return "", err
+	}
+	if err := w.Write([]string{"step", "status", "duration_ms", "output", "error"}); err != nil {
+		return "", err
+	}
+	for _, step := range r.Steps {
+		row := []string{step.Name, string(step.Status), fmt.Sprint(step.Duration.Milliseconds()), step.Output, step.Error}
+		if err := w.Write(row); err != nil {
+			return "", err
+		}
+	}
+	if len(r.Notes) > 0 {
+		if err := w.Write(nil); err != nil {
+			return "", err
+		}
+		if err := w.Write([]string{"note_step", "note_created_by", "note_created_at", "note_message"}); err != nil {
+			return "", err
+		}
+		for _, note := range r.Notes {
+			row := []string{note.StepName, note.CreatedBy, note.CreatedAt.Format(time.RFC3339), note.Message}
+			if err := w.Write(row); err != nil {
+				return "", err
+			}
+		}
+	}
+	if len(r.Links) > 0 {
+		if err := w.Write(nil); err != nil {
+			return "", err
+		}
+		if err := w.Write([]string{"link_kind", "link_label", "link_url"}); err != nil {
+			return "", err
+		}
+		for _, link := range r.Links {
+			row := []string{string(link.Kind), link.Label, link.URL}
+			if err := w.Write(row); err != nil {
+				return "", err
+			}
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// HTML renders r as a standalone HTML document: a summary table, a link
+// to its DAG image, and a table of steps, all escaped for safe embedding
+// of arbitrary step output and error text.
+func HTML(r *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<html><head><title>Execution %d report</title></head><body>", r.ExecutionID)
+	fmt.Fprintf(&b, "<h1>Execution %d: %s</h1>", r.ExecutionID, html.EscapeString(r.TemplateName))
+	fmt.Fprintf(&b, "<p>Status: %s</p>", html.EscapeString(string(r.Status)))
+	if r.Error != "" {
+		fmt.Fprintf(&b, "<p>Error: %s</p>", html.EscapeString(r.Error))
+	}
+	fmt.Fprintf(&b, "<p>Duration: %dms</p>", r.Duration.Milliseconds())
+	if r.DAGImageURL != "" {
+		fmt.Fprintf(&b, `<p><a href="%s">DAG image</a></p>`, html.EscapeString(r.DAGImageURL))
+	}
+	b.WriteString("<table border=\"1\"><tr><th>Step</th><th>Status</th><th>Duration (ms)</th><th>Output</th><th>Error</th></tr>")
+	for _, step := range r.Steps {
+		fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%d</td><td><pre>%s</pre></td><td><pre>%s</pre></td></tr>",
+			html.EscapeString(step.Name), html.EscapeString(string(step.Status)), step.Duration.Milliseconds(),
+			html.EscapeString(step.Output), html.EscapeString(step.Error))
+	}
+	b.WriteString("</table>")
+	if len(r.Notes) > 0 {
+		b.WriteString("<h2>Notes</h2><table border=\"1\"><tr><th>Step</th><th>By</th><th>At</th><th>Note</th></tr>")
+		for _, note := range r.Notes {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>",
+				html.EscapeString(note.StepName), html.EscapeString(note.CreatedBy), note.CreatedAt.Format(time.RFC3339), html.EscapeString(note.Message))
+		}
+		b.WriteString("</table>")
+	}
+	if len(r.Links) > 0 {
+		b.WriteString("<h2>Links</h2><table border=\"1\"><tr><th>Kind</th><th>Label</th><th>URL</th></tr>")
+		for _, link := range r.Links {
+			fmt.Fprintf(&b, `<tr><td>%s</td><td>%s</td><td><a href="%s">%s</a></td></tr>`,
+				html.EscapeString(string(link.Kind)), html.EscapeString(link.Label), html.EscapeString(link.URL), html.EscapeString(link.URL))
+		}
+		b.WriteString("</table>")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
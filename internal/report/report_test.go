@@ -0,0 +1,136 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func testExecution() *model.WorkflowExecution {
+	start := time.Unix(0, 0)
+	finish := start.Add(5 * time.Second)
+	stepStart := start
+	stepFinish := start.Add(2 * time.Second)
+	return &model.WorkflowExecution{
+		ID:         1,
+		TemplateID: 2,
+		Status:     model.StatusSucceeded,
+		StartedAt:  &start,
+		FinishedAt: &finish,
+		Steps: []model.StepExecution{
+			{StepName: "a", Status: model.StatusSucceeded, Output: "ok", StartedAt: &stepStart, FinishedAt: &stepFinish},
+		},
+	}
+}
+
+func TestBuildComputesDurations(t *testing.T) {
+	r := Build(testExecution(), "my-template", "/api/v1/templates/2/export?format=svg", nil, nil)
+	if r.Duration != 5*time.Second {
+		t.Fatalf("expected a 5s duration, got %s", r.Duration)
+	}
+	if len(r.Steps) != 1 || r.Steps[0].Duration != 2*time.Second {
+		t.Fatalf("expected step a to have a 2s duration, got %+v", r.Steps)
+	}
+	if r.TemplateName != "my-template" {
+		t.Fatalf("expected template name to be set, got %q", r.TemplateName)
+	}
+}
+
+func TestBuildTruncatesLongOutput(t *testing.T) {
+	execution := testExecution()
+	execution.Steps[0].Output = strings.Repeat("x", maxOutputLength+100)
+	r := Build(execution, "t", "", nil, nil)
+	if len(r.Steps[0].Output) >= maxOutputLength+100 {
+		t.Fatalf("expected output to be truncated, got length %d", len(r.Steps[0].Output))
+	}
+	if !strings.HasSuffix(r.Steps[0].Output, "(truncated)") {
+		t.Fatalf("expected a truncation marker, got %q", r.Steps[0].Output)
+	}
+}
+
+func TestJSONRoundTripsTheReport(t *testing.T) {
+	r := Build(testExecution(), "t", "/x", nil, nil)
+	body, err := JSON(r)
+	if err != nil {
+		t.Fatalf("JSON: %v", err)
+	}
+	if !strings.Contains(string(body), `"execution_id": 1`) {
+		t.Fatalf("expected execution_id in JSON output, got %s", body)
+	}
+}
+
+func TestBuildIncludesNotes(t *testing.T) {
+	createdAt := time.Unix(100, 0)
+	notes := []model.ExecutionNote{
+		{StepName: "a", Message: "restarted manually", CreatedBy: "op", CreatedAt: createdAt},
+	}
+	r := Build(testExecution(), "my-template", "", notes, nil)
+	if len(r.Notes) != 1 || r.Notes[0].Message != "restarted manually" {
+		t.Fatalf("expected the note to carry through to the report, got %+v", r.Notes)
+	}
+
+	html := HTML(r)
+	if !strings.Contains(html, "restarted manually") {
+		t.Fatalf("expected HTML report to include the note, got %s", html)
+	}
+
+	csv, err := CSV(r)
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if !strings.Contains(csv, "restarted manually") {
+		t.Fatalf("expected CSV report to include the note, got %s", csv)
+	}
+}
+
+func TestBuildIncludesLinks(t *testing.T) {
+	links := []model.ExecutionLink{
+		{Kind: model.LinkKindJira, URL: "https://jira.example/INC-1", Label: "INC-1"},
+	}
+	r := Build(testExecution(), "my-template", "", nil, links)
+	if len(r.Links) != 1 || r.Links[0].Label != "INC-1" {
+		t.Fatalf("expected the link to carry through to the report, got %+v", r.Links)
+	}
+
+	html := HTML(r)
+	if !strings.Contains(html, "INC-1") {
+		t.Fatalf("expected HTML report to include the link, got %s", html)
+	}
+
+	csv, err := CSV(r)
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if !strings.Contains(csv, "INC-1") {
+		t.Fatalf("expected CSV report to include the link, got %s", csv)
+	}
+}
+
+func TestCSVIncludesSummaryAndStepRows(t *testing.T) {
+	r := Build(testExecution(), "my-template", "", nil, nil)
+	csv, err := CSV(r)
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if !strings.Contains(csv, "my-template") {
+		t.Fatalf("expected the template name in the CSV, got %q", csv)
+	}
+	if !strings.Contains(csv, "a,succeeded") {
+		t.Fatalf("expected step a's row in the CSV, got %q", csv)
+	}
+}
+
+func TestHTMLEscapesStepOutput(t *testing.T) {
+	execution := testExecution()
+	execution.Steps[0].Output = "<script>alert(1)</script>"
+	r := Build(execution, "t", "", nil, nil)
+	out := HTML(r)
+	if strings.Contains(out, "<script>alert(1)</script>") {
+		t.Fatal("expected step output to be HTML-escaped")
+	}
+	if !strings.Contains(out, "&lt;script&gt;") {
+		t.Fatalf("expected an escaped marker in the HTML, got %q", out)
+	}
+}
@@ -0,0 +1,76 @@
+// Package schema validates JSON values against JSON Schema documents, so
+// templates can declare contracts for the parameters they accept and the
+// outputs they promise, and callers get back every violation rather than
+// a single opaque error.
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ValidationError reports that a value didn't match a JSON Schema,
+// carrying every individual violation (rather than just the first) so a
+// caller validating another template's promised output can surface all
+// of them at once.
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("schema validation failed: %s", strings.Join(e.Errors, "; "))
+}
+
+// Compile checks that schemaJSON is a well-formed JSON Schema document,
+// without validating any data against it. An empty schemaJSON always
+// passes. Callers persisting a schema (e.g. TemplateService.Create) use
+// this to reject a malformed one up front, rather than waiting for the
+// first execution that tries to validate against it.
+func Compile(schemaJSON string) error {
+	if strings.TrimSpace(schemaJSON) == "" {
+		return nil
+	}
+	if _, err := jsonschema.CompileString("schema.json", schemaJSON); err != nil {
+		return fmt.Errorf("compile schema: %w", err)
+	}
+	return nil
+}
+
+// Validate checks data (a value already shaped like decoded JSON, e.g.
+// the result of json.Unmarshal into map[string]any) against schemaJSON,
+// a JSON Schema document. An empty schemaJSON always passes, since most
+// templates don't declare one. A malformed schemaJSON is reported
+// distinctly from a *ValidationError, so callers can tell "the contract
+// itself is broken" from "the value doesn't satisfy it".
+func Validate(schemaJSON string, data any) error {
+	if strings.TrimSpace(schemaJSON) == "" {
+		return nil
+	}
+	sch, err := jsonschema.CompileString("schema.json", schemaJSON)
+	if err != nil {
+		return fmt.Errorf("compile schema: %w", err)
+	}
+	if err := sch.Validate(data); err != nil {
+		if ve, ok := err.(*jsonschema.ValidationError); ok {
+			return &ValidationError{Errors: leafMessages(ve)}
+		}
+		return err
+	}
+	return nil
+}
+
+// leafMessages flattens a jsonschema.ValidationError's cause tree into
+// one human-readable message per leaf violation, since the top-level
+// error is usually just "doesn't validate against the root schema".
+func leafMessages(ve *jsonschema.ValidationError) []string {
+	if len(ve.Causes) == 0 {
+		return []string{fmt.Sprintf("%s: %s", ve.InstanceLocation, ve.Message)}
+	}
+	var messages []string
+	for _, cause := range ve.Causes {
+		messages = append(messages, leafMessages(cause)...)
+	}
+	return messages
+}
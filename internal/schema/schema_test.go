@@ -0,0 +1,73 @@
+package schema
+
+import "testing"
+
+func TestValidateEmptySchemaAlwaysPasses(t *testing.T) {
+	if err := Validate("", map[string]any{"anything": true}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidatePassesMatchingData(t *testing.T) {
+	s := `{"type": "object", "properties": {"count": {"type": "integer"}}, "required": ["count"]}`
+	if err := Validate(s, map[string]any{"count": 3}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateReturnsValidationErrorOnMismatch(t *testing.T) {
+	s := `{"type": "object", "properties": {"count": {"type": "integer"}}, "required": ["count"]}`
+	err := Validate(s, map[string]any{"count": "not a number"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	var ve *ValidationError
+	if !asValidationError(err, &ve) {
+		t.Fatalf("expected a *ValidationError, got %T: %v", err, err)
+	}
+	if len(ve.Errors) == 0 {
+		t.Fatal("expected at least one violation message")
+	}
+}
+
+func TestValidateReportsMissingRequiredField(t *testing.T) {
+	s := `{"type": "object", "properties": {"count": {"type": "integer"}}, "required": ["count"]}`
+	err := Validate(s, map[string]any{})
+	if err == nil {
+		t.Fatal("expected an error for a missing required field")
+	}
+}
+
+func TestValidateRejectsMalformedSchema(t *testing.T) {
+	if err := Validate("{not json", map[string]any{}); err == nil {
+		t.Fatal("expected an error compiling a malformed schema")
+	}
+}
+
+func TestCompileEmptySchemaAlwaysPasses(t *testing.T) {
+	if err := Compile(""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestCompileRejectsMalformedSchema(t *testing.T) {
+	if err := Compile("{not json"); err == nil {
+		t.Fatal("expected an error for a malformed schema")
+	}
+}
+
+func TestCompileAcceptsWellFormedSchemaRequiringFields(t *testing.T) {
+	s := `{"type": "object", "required": ["count"]}`
+	if err := Compile(s); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func asValidationError(err error, target **ValidationError) bool {
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		return false
+	}
+	*target = ve
+	return true
+}
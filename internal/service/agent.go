@@ -0,0 +1,38 @@
+package service
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// AgentService records agent heartbeats and reports the current fleet
+// inventory.
+type AgentService struct {
+	db *gorm.DB
+}
+
+// NewAgentService builds an AgentService backed by db.
+func NewAgentService(db *gorm.DB) *AgentService {
+	return &AgentService{db: db}
+}
+
+// Heartbeat upserts agent's inventory row by name and refreshes
+// LastHeartbeatAt to now, so a restarted agent reusing its name picks up
+// its existing row rather than accumulating duplicates.
+func (s *AgentService) Heartbeat(agent model.Agent) (model.Agent, error) {
+	agent.LastHeartbeatAt = time.Now()
+	var saved model.Agent
+	err := s.db.Where("name = ?", agent.Name).Assign(agent).FirstOrCreate(&saved).Error
+	return saved, err
+}
+
+// List returns every agent in the inventory, most recently heartbeated
+// first.
+func (s *AgentService) List() ([]model.Agent, error) {
+	var agents []model.Agent
+	err := s.db.Order("last_heartbeat_at desc").Find(&agents).Error
+	return agents, err
+}
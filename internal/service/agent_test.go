@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestAgentHeartbeatCreatesNewAgent(t *testing.T) {
+	gormDB := newTestDB(t)
+	agents := NewAgentService(gormDB)
+
+	saved, err := agents.Heartbeat(model.Agent{
+		Name:         "worker-1",
+		OS:           "linux",
+		Version:      "1.2.3",
+		Capabilities: "network-zone=prod,has-docker",
+	})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if saved.ID == 0 {
+		t.Fatal("expected the saved agent to have an ID")
+	}
+	if saved.LastHeartbeatAt.IsZero() {
+		t.Fatal("expected LastHeartbeatAt to be set")
+	}
+}
+
+func TestAgentHeartbeatUpdatesExistingAgentByName(t *testing.T) {
+	gormDB := newTestDB(t)
+	agents := NewAgentService(gormDB)
+
+	first, err := agents.Heartbeat(model.Agent{Name: "worker-1", Version: "1.2.3"})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+
+	second, err := agents.Heartbeat(model.Agent{Name: "worker-1", Version: "1.3.0"})
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+
+	if second.ID != first.ID {
+		t.Fatalf("expected the same agent row to be reused, got IDs %d and %d", first.ID, second.ID)
+	}
+	if second.Version != "1.3.0" {
+		t.Fatalf("expected Version to be updated, got %q", second.Version)
+	}
+	if !second.LastHeartbeatAt.After(first.LastHeartbeatAt) && second.LastHeartbeatAt != first.LastHeartbeatAt {
+		t.Fatalf("expected LastHeartbeatAt to advance, got %v then %v", first.LastHeartbeatAt, second.LastHeartbeatAt)
+	}
+
+	all, err := agents.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("expected exactly one agent row, got %d", len(all))
+	}
+}
+
+func TestAgentListReturnsEveryAgent(t *testing.T) {
+	gormDB := newTestDB(t)
+	agents := NewAgentService(gormDB)
+
+	if _, err := agents.Heartbeat(model.Agent{Name: "worker-1"}); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+	if _, err := agents.Heartbeat(model.Agent{Name: "worker-2"}); err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+
+	all, err := agents.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 agents, got %d", len(all))
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// AlertService receives Alertmanager webhook deliveries, routes each
+// alert to a template via its AlertRoutes, and starts (or annotates) the
+// execution that remediates it.
+type AlertService struct {
+	db         *gorm.DB
+	executions *ExecutionService
+	labels     *LabelService
+}
+
+// NewAlertService builds an AlertService backed by db, starting
+// executions through executions and annotating them through labels.
+func NewAlertService(db *gorm.DB, executions *ExecutionService, labels *LabelService) *AlertService {
+	return &AlertService{db: db, executions: executions, labels: labels}
+}
+
+// CreateRoute persists a new AlertRoute.
+func (s *AlertService) CreateRoute(route *model.AlertRoute) error {
+	return s.db.Create(route).Error
+}
+
+// Routes returns every AlertRoute in evaluation order (ascending
+// Priority, ties broken by ID).
+func (s *AlertService) Routes() ([]model.AlertRoute, error) {
+	var routes []model.AlertRoute
+	if err := s.db.Order("priority asc, id asc").Find(&routes).Error; err != nil {
+		return nil, err
+	}
+	return routes, nil
+}
+
+// AlertmanagerWebhook is the payload shape Alertmanager's webhook_config
+// POSTs to a receiver. Only the fields the orchestrator acts on are
+// modeled; see
+// https://prometheus.io/docs/alerting/latest/configuration/#webhook_config.
+type AlertmanagerWebhook struct {
+	Status string              `json:"status"`
+	Alerts []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert is one alert within an AlertmanagerWebhook delivery.
+type AlertmanagerAlert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	Fingerprint string            `json:"fingerprint"`
+}
+
+// AlertAction describes what HandleWebhook did with one alert.
+type AlertAction string
+
+const (
+	AlertActionStarted   AlertAction = "started"
+	AlertActionDeduped   AlertAction = "deduped"
+	AlertActionAnnotated AlertAction = "annotated"
+	AlertActionUnrouted  AlertAction = "unrouted"
+	AlertActionIgnored   AlertAction = "ignored"
+	AlertActionError     AlertAction = "error"
+)
+
+// AlertOutcome reports what HandleWebhook did for one alert in a
+// delivery, so the caller can see routing, dedup and annotation
+// decisions per alert instead of a single pass/fail for the whole batch.
+type AlertOutcome struct {
+	Fingerprint string      `json:"fingerprint"`
+	Action      AlertAction `json:"action"`
+	ExecutionID uint        `json:"execution_id,omitempty"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// HandleWebhook processes every alert in webhook independently: a firing
+// alert is routed to a template and started, unless an execution is
+// already tracked as firing for the same fingerprint (dedup, reported as
+// AlertActionDeduped so repeated Alertmanager notifications don't each
+// start a new remediation); a resolved alert annotates the execution
+// that alert most recently started, if any (AlertActionAnnotated), or is
+// ignored if the orchestrator never saw it fire.
+//
+// The orchestrator runs every execution synchronously to completion (see
+// ExecutionService.Start), so there is no in-flight execution left to
+// cancel by the time a "resolved" delivery arrives; annotating its
+// labels with the resolution is the only part of "cancel or annotate"
+// a synchronous run model can actually do.
+func (s *AlertService) HandleWebhook(ctx context.Context, webhook AlertmanagerWebhook) []AlertOutcome {
+	outcomes := make([]AlertOutcome, 0, len(webhook.Alerts))
+	for _, alert := range webhook.Alerts {
+		outcomes = append(outcomes, s.handleAlert(ctx, alert))
+	}
+	return outcomes
+}
+
+func (s *AlertService) handleAlert(ctx context.Context, alert AlertmanagerAlert) AlertOutcome {
+	outcome := AlertOutcome{Fingerprint: alert.Fingerprint}
+
+	var existing model.AlertExecution
+	err := s.db.Where("fingerprint = ?", alert.Fingerprint).First(&existing).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		outcome.Action = AlertActionError
+		outcome.Error = err.Error()
+		return outcome
+	}
+	tracked := err == nil
+
+	if alert.Status == string(model.AlertResolved) {
+		if !tracked {
+			outcome.Action = AlertActionIgnored
+			return outcome
+		}
+		if err := s.annotateResolved(&existing); err != nil {
+			outcome.Action = AlertActionError
+			outcome.Error = err.Error()
+			return outcome
+		}
+		outcome.Action = AlertActionAnnotated
+		outcome.ExecutionID = existing.ExecutionID
+		return outcome
+	}
+
+	if tracked && existing.Status == model.AlertFiring {
+		outcome.Action = AlertActionDeduped
+		outcome.ExecutionID = existing.ExecutionID
+		return outcome
+	}
+
+	route, err := s.routeFor(alert.Labels)
+	if err != nil {
+		outcome.Action = AlertActionError
+		outcome.Error = err.Error()
+		return outcome
+	}
+	if route == nil {
+		outcome.Action = AlertActionUnrouted
+		outcome.Error = "no alert route matches this alert's labels"
+		return outcome
+	}
+
+	params, err := json.Marshal(map[string]any{"labels": alert.Labels, "annotations": alert.Annotations})
+	if err != nil {
+		outcome.Action = AlertActionError
+		outcome.Error = err.Error()
+		return outcome
+	}
+	execution, startErr := s.executions.Start(ctx, route.TemplateID, 0, 0, string(params), false)
+	if execution == nil {
+		outcome.Action = AlertActionError
+		outcome.Error = startErr.Error()
+		return outcome
+	}
+
+	upsert := model.AlertExecution{Fingerprint: alert.Fingerprint}
+	if err := s.db.Where("fingerprint = ?", alert.Fingerprint).
+		Assign(model.AlertExecution{ExecutionID: execution.ID, Status: model.AlertFiring}).
+		FirstOrCreate(&upsert).Error; err != nil {
+		outcome.Action = AlertActionError
+		outcome.Error = err.Error()
+		return outcome
+	}
+
+	outcome.Action = AlertActionStarted
+	outcome.ExecutionID = execution.ID
+	if startErr != nil {
+		outcome.Error = startErr.Error()
+	}
+	return outcome
+}
+
+// routeFor returns the first AlertRoute (in evaluation order) whose
+// Matchers are satisfied by labels, or nil if none match.
+func (s *AlertService) routeFor(labels map[string]string) (*model.AlertRoute, error) {
+	routes, err := s.Routes()
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes {
+		if route.Matches(labels) {
+			return &route, nil
+		}
+	}
+	return nil, nil
+}
+
+// annotateResolved records that existing's alert resolved: it adds an
+// alert_status=resolved label to the execution it triggered and marks
+// existing itself resolved, so the same fingerprint firing again later
+// starts a fresh execution instead of deduplicating against this one.
+func (s *AlertService) annotateResolved(existing *model.AlertExecution) error {
+	labels, err := s.labels.Get(model.LabelOwnerExecution, existing.ExecutionID)
+	if err != nil {
+		return fmt.Errorf("annotate execution %d: %w", existing.ExecutionID, err)
+	}
+	labels["alert_status"] = string(model.AlertResolved)
+	if err := s.labels.Set(model.LabelOwnerExecution, existing.ExecutionID, labels); err != nil {
+		return fmt.Errorf("annotate execution %d: %w", existing.ExecutionID, err)
+	}
+	existing.Status = model.AlertResolved
+	if err := s.db.Save(existing).Error; err != nil {
+		return fmt.Errorf("annotate execution %d: %w", existing.ExecutionID, err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func newTestAlertService(t *testing.T) (*AlertService, *gorm.DB) {
+	t.Helper()
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	labels := NewLabelService(gormDB)
+	return NewAlertService(gormDB, executions, labels), gormDB
+}
+
+func createAlertTemplate(t *testing.T, gormDB *gorm.DB) model.WorkflowTemplate {
+	t.Helper()
+	tmpl := model.WorkflowTemplate{Name: "remediate"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "true"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	return tmpl
+}
+
+func TestHandleWebhookStartsAnExecutionForAMatchingFiringAlert(t *testing.T) {
+	alerts, gormDB := newTestAlertService(t)
+	tmpl := createAlertTemplate(t, gormDB)
+	if err := alerts.CreateRoute(&model.AlertRoute{Name: "payments", Matchers: "service=payments", TemplateID: tmpl.ID}); err != nil {
+		t.Fatalf("create route: %v", err)
+	}
+
+	webhook := AlertmanagerWebhook{
+		Status: "firing",
+		Alerts: []AlertmanagerAlert{
+			{Status: "firing", Fingerprint: "fp1", Labels: map[string]string{"service": "payments", "severity": "critical"}},
+		},
+	}
+	outcomes := alerts.HandleWebhook(context.Background(), webhook)
+	if len(outcomes) != 1 {
+		t.Fatalf("expected 1 outcome, got %d", len(outcomes))
+	}
+	if outcomes[0].Action != AlertActionStarted {
+		t.Fatalf("expected AlertActionStarted, got %q (%s)", outcomes[0].Action, outcomes[0].Error)
+	}
+	if outcomes[0].ExecutionID == 0 {
+		t.Fatal("expected a non-zero execution ID")
+	}
+
+	var execution model.WorkflowExecution
+	if err := gormDB.First(&execution, outcomes[0].ExecutionID).Error; err != nil {
+		t.Fatalf("load execution: %v", err)
+	}
+	if execution.TemplateID != tmpl.ID {
+		t.Fatalf("expected execution to use template %d, got %d", tmpl.ID, execution.TemplateID)
+	}
+}
+
+func TestHandleWebhookDedupsARepeatedFiringAlert(t *testing.T) {
+	alerts, gormDB := newTestAlertService(t)
+	tmpl := createAlertTemplate(t, gormDB)
+	if err := alerts.CreateRoute(&model.AlertRoute{Name: "catchall", TemplateID: tmpl.ID}); err != nil {
+		t.Fatalf("create route: %v", err)
+	}
+
+	webhook := AlertmanagerWebhook{Status: "firing", Alerts: []AlertmanagerAlert{{Status: "firing", Fingerprint: "fp1"}}}
+	first := alerts.HandleWebhook(context.Background(), webhook)
+	second := alerts.HandleWebhook(context.Background(), webhook)
+
+	if first[0].Action != AlertActionStarted {
+		t.Fatalf("expected the first delivery to start an execution, got %q", first[0].Action)
+	}
+	if second[0].Action != AlertActionDeduped {
+		t.Fatalf("expected the repeated delivery to be deduped, got %q", second[0].Action)
+	}
+	if second[0].ExecutionID != first[0].ExecutionID {
+		t.Fatalf("expected the deduped outcome to report the original execution %d, got %d", first[0].ExecutionID, second[0].ExecutionID)
+	}
+}
+
+func TestHandleWebhookAnnotatesTheExecutionOnResolve(t *testing.T) {
+	alerts, gormDB := newTestAlertService(t)
+	tmpl := createAlertTemplate(t, gormDB)
+	if err := alerts.CreateRoute(&model.AlertRoute{Name: "catchall", TemplateID: tmpl.ID}); err != nil {
+		t.Fatalf("create route: %v", err)
+	}
+
+	firing := alerts.HandleWebhook(context.Background(), AlertmanagerWebhook{
+		Status: "firing",
+		Alerts: []AlertmanagerAlert{{Status: "firing", Fingerprint: "fp1"}},
+	})
+	resolved := alerts.HandleWebhook(context.Background(), AlertmanagerWebhook{
+		Status: "resolved",
+		Alerts: []AlertmanagerAlert{{Status: "resolved", Fingerprint: "fp1"}},
+	})
+
+	if resolved[0].Action != AlertActionAnnotated {
+		t.Fatalf("expected AlertActionAnnotated, got %q (%s)", resolved[0].Action, resolved[0].Error)
+	}
+	if resolved[0].ExecutionID != firing[0].ExecutionID {
+		t.Fatalf("expected the annotated outcome to report execution %d, got %d", firing[0].ExecutionID, resolved[0].ExecutionID)
+	}
+
+	got, err := NewLabelService(gormDB).Get(model.LabelOwnerExecution, firing[0].ExecutionID)
+	if err != nil {
+		t.Fatalf("get labels: %v", err)
+	}
+	if got["alert_status"] != "resolved" {
+		t.Fatalf("expected alert_status=resolved label, got %+v", got)
+	}
+
+	// A fresh firing of the same fingerprint starts a new execution
+	// rather than deduplicating against the resolved one.
+	refired := alerts.HandleWebhook(context.Background(), AlertmanagerWebhook{
+		Status: "firing",
+		Alerts: []AlertmanagerAlert{{Status: "firing", Fingerprint: "fp1"}},
+	})
+	if refired[0].Action != AlertActionStarted {
+		t.Fatalf("expected a re-fire after resolution to start a new execution, got %q", refired[0].Action)
+	}
+}
+
+func TestHandleWebhookReportsUnroutedWhenNoRouteMatches(t *testing.T) {
+	alerts, _ := newTestAlertService(t)
+
+	outcomes := alerts.HandleWebhook(context.Background(), AlertmanagerWebhook{
+		Status: "firing",
+		Alerts: []AlertmanagerAlert{{Status: "firing", Fingerprint: "fp1", Labels: map[string]string{"service": "payments"}}},
+	})
+	if outcomes[0].Action != AlertActionUnrouted {
+		t.Fatalf("expected AlertActionUnrouted, got %q", outcomes[0].Action)
+	}
+}
+
+func TestHandleWebhookIgnoresAResolveForAnUntrackedAlert(t *testing.T) {
+	alerts, _ := newTestAlertService(t)
+
+	outcomes := alerts.HandleWebhook(context.Background(), AlertmanagerWebhook{
+		Status: "resolved",
+		Alerts: []AlertmanagerAlert{{Status: "resolved", Fingerprint: "never-seen"}},
+	})
+	if outcomes[0].Action != AlertActionIgnored {
+		t.Fatalf("expected AlertActionIgnored, got %q", outcomes[0].Action)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// ArtifactService serves artifacts a step produced during an execution,
+// backed by the same artifact.Store the TaskOrchestrator was configured
+// with.
+type ArtifactService struct {
+	db    *gorm.DB
+	store artifact.Store
+}
+
+// NewArtifactService builds an ArtifactService backed by db and store.
+func NewArtifactService(db *gorm.DB, store artifact.Store) *ArtifactService {
+	return &ArtifactService{db: db, store: store}
+}
+
+// ListByExecution returns every artifact recorded for executionID.
+func (s *ArtifactService) ListByExecution(executionID uint) ([]model.Artifact, error) {
+	var artifacts []model.Artifact
+	if err := s.db.Where("execution_id = ?", executionID).Order("created_at asc").Find(&artifacts).Error; err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// Open returns the named artifact of executionID along with a reader for
+// its bytes. Callers must Close the reader.
+func (s *ArtifactService) Open(ctx context.Context, executionID uint, name string) (*model.Artifact, io.ReadCloser, error) {
+	var art model.Artifact
+	if err := s.db.Where("execution_id = ? AND name = ?", executionID, name).First(&art).Error; err != nil {
+		return nil, nil, fmt.Errorf("artifact %q: %w", name, err)
+	}
+	body, err := s.store.Get(ctx, art.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &art, body, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/artifact"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestArtifactServiceListsAndOpensArtifacts(t *testing.T) {
+	gormDB := newTestDB(t)
+	store := artifact.NewLocalStore(t.TempDir())
+
+	art := model.Artifact{ExecutionID: 1, StepName: "step", Name: "report.txt", Size: 4, StorageKey: "1/step/report.txt"}
+	if err := gormDB.Create(&art).Error; err != nil {
+		t.Fatalf("create artifact: %v", err)
+	}
+	if _, err := store.Put(context.Background(), art.StorageKey, strings.NewReader("data")); err != nil {
+		t.Fatalf("seed artifact bytes: %v", err)
+	}
+
+	svc := NewArtifactService(gormDB, store)
+
+	artifacts, err := svc.ListByExecution(1)
+	if err != nil {
+		t.Fatalf("ListByExecution: %v", err)
+	}
+	if len(artifacts) != 1 {
+		t.Fatalf("expected one artifact, got %d", len(artifacts))
+	}
+
+	_, body, err := svc.Open(context.Background(), 1, "report.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer body.Close()
+	got, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(got) != "data" {
+		t.Fatalf("expected %q, got %q", "data", got)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// BlackoutError reports that an execution was rejected because it fell
+// inside a model.BlackoutWindow. The API layer maps it to a 429
+// response, the same as a *QuotaExceededError.
+type BlackoutError struct {
+	Window model.BlackoutWindow
+}
+
+func (e *BlackoutError) Error() string {
+	reason := e.Window.Reason
+	if reason == "" {
+		reason = "maintenance window"
+	}
+	return fmt.Sprintf("blackout window %d active until %s: %s", e.Window.ID, e.Window.EndsAt.Format(time.RFC3339), reason)
+}
+
+// BlackoutService manages model.BlackoutWindows and checks whether a
+// template's project is currently inside one, so ExecutionService can
+// queue or reject an automatic execution during maintenance.
+type BlackoutService struct {
+	db *gorm.DB
+}
+
+// NewBlackoutService builds a BlackoutService backed by db.
+func NewBlackoutService(db *gorm.DB) *BlackoutService {
+	return &BlackoutService{db: db}
+}
+
+// Create persists window.
+func (s *BlackoutService) Create(window *model.BlackoutWindow) error {
+	return s.db.Create(window).Error
+}
+
+// Delete removes the window with the given id.
+func (s *BlackoutService) Delete(id uint) error {
+	return s.db.Delete(&model.BlackoutWindow{}, id).Error
+}
+
+// List returns every BlackoutWindow that could apply to project
+// (project-specific or global), ordered by StartsAt.
+func (s *BlackoutService) List(project string) ([]model.BlackoutWindow, error) {
+	var windows []model.BlackoutWindow
+	if err := s.db.Where("project = ? OR project = ''", project).Order("starts_at asc").Find(&windows).Error; err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// Active returns the BlackoutWindow currently in effect for templateID
+// in project, or nil if none covers time.Now().
+func (s *BlackoutService) Active(project string, templateID uint) (*model.BlackoutWindow, error) {
+	windows, err := s.List(project)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, window := range windows {
+		if window.Covers(project, templateID, now) {
+			return &window, nil
+		}
+	}
+	return nil, nil
+}
+
+// Blocked implements executor.BlackoutChecker, so a WorkerPool can
+// re-check a queued execution against the active blackout right before
+// it runs rather than trusting the check ExecutionService.Start already
+// made when it was submitted. A List error is treated the same as no
+// active window: a WorkerPool dispatch isn't the place to surface a
+// transient DB error, and the ExecutionService.Start check a dispatched
+// execution already passed will have surfaced a real outage already.
+func (s *BlackoutService) Blocked(project string, templateID uint) (retryAfter time.Duration, blocked bool) {
+	window, err := s.Active(project, templateID)
+	if err != nil || window == nil {
+		return 0, false
+	}
+	retryAfter = time.Until(window.EndsAt)
+	if retryAfter < 0 {
+		retryAfter = 0
+	}
+	return retryAfter, true
+}
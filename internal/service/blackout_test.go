@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestBlackoutActiveFindsAWindowCoveringNow(t *testing.T) {
+	gormDB := newTestDB(t)
+	blackouts := NewBlackoutService(gormDB)
+	window := &model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+		Reason:   "quarterly freeze",
+	}
+	if err := blackouts.Create(window); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	active, err := blackouts.Active("payments", 1)
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+	if active == nil {
+		t.Fatal("expected an active window")
+	}
+	if active.Reason != "quarterly freeze" {
+		t.Fatalf("expected the stored window back, got %+v", active)
+	}
+}
+
+func TestBlackoutActiveIgnoresAWindowThatHasEnded(t *testing.T) {
+	gormDB := newTestDB(t)
+	blackouts := NewBlackoutService(gormDB)
+	window := &model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-2 * time.Hour),
+		EndsAt:   time.Now().Add(-time.Hour),
+	}
+	if err := blackouts.Create(window); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	active, err := blackouts.Active("payments", 1)
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+	if active != nil {
+		t.Fatalf("expected no active window, got %+v", active)
+	}
+}
+
+func TestBlackoutListReturnsGlobalAndProjectScopedWindows(t *testing.T) {
+	gormDB := newTestDB(t)
+	blackouts := NewBlackoutService(gormDB)
+	if err := blackouts.Create(&model.BlackoutWindow{Project: "payments", StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := blackouts.Create(&model.BlackoutWindow{StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := blackouts.Create(&model.BlackoutWindow{Project: "checkout", StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	windows, err := blackouts.List("payments")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected the payments-scoped window plus the global one, got %d", len(windows))
+	}
+}
+
+func TestBlackoutDeleteRemovesAWindow(t *testing.T) {
+	gormDB := newTestDB(t)
+	blackouts := NewBlackoutService(gormDB)
+	window := &model.BlackoutWindow{Project: "payments", StartsAt: time.Now(), EndsAt: time.Now().Add(time.Hour)}
+	if err := blackouts.Create(window); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := blackouts.Delete(window.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	active, err := blackouts.Active("payments", 1)
+	if err != nil {
+		t.Fatalf("Active: %v", err)
+	}
+	if active != nil {
+		t.Fatalf("expected no active window after deletion, got %+v", active)
+	}
+}
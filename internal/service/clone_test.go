@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestCloneDeepCopiesStepsWithNewIDs(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	src := &model.WorkflowTemplate{
+		Name:      "deploy",
+		Published: true,
+		Steps: []model.TemplateStep{
+			{Name: "build", Type: "shell", Config: "make build"},
+			{Name: "deploy", Type: "shell", Config: "make deploy", DependsOn: "build"},
+		},
+	}
+	if err := svc.Create(src, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	clone, err := svc.Clone(src.ID)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if clone.ID == src.ID {
+		t.Fatal("expected clone to have a different ID")
+	}
+	if clone.Published {
+		t.Fatal("expected clone to start unpublished")
+	}
+	if clone.ClonedFromID == nil || *clone.ClonedFromID != src.ID {
+		t.Fatalf("expected ClonedFromID to point at %d, got %+v", src.ID, clone.ClonedFromID)
+	}
+	if len(clone.Steps) != 2 {
+		t.Fatalf("expected 2 cloned steps, got %d", len(clone.Steps))
+	}
+	for _, step := range clone.Steps {
+		if step.TemplateID != clone.ID {
+			t.Fatalf("expected cloned step to belong to clone %d, got %d", clone.ID, step.TemplateID)
+		}
+	}
+}
+
+func TestCatalogOnlyReturnsPublished(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+	if err := svc.Create(&model.WorkflowTemplate{Name: "public", Published: true}, nil); err != nil {
+		t.Fatalf("Create public: %v", err)
+	}
+	if err := svc.Create(&model.WorkflowTemplate{Name: "draft", Published: false}, nil); err != nil {
+		t.Fatalf("Create draft: %v", err)
+	}
+
+	catalog, err := svc.Catalog()
+	if err != nil {
+		t.Fatalf("Catalog: %v", err)
+	}
+	if len(catalog) != 1 || catalog[0].Name != "public" {
+		t.Fatalf("expected only the published template, got %+v", catalog)
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestConcurrencyReportsRunningStepsAndBlockedLayers(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+	pool := executor.NewWorkerPool(orchestrator, 2)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "sleep 0.3"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "sleep 0.3"},
+		{TemplateID: tmpl.ID, Name: "c", Type: "shell", Config: "true", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, pool, nil)
+	go executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+
+	deadline := time.After(2 * time.Second)
+	for {
+		snapshot, err := executions.Concurrency()
+		if err != nil {
+			t.Fatalf("Concurrency: %v", err)
+		}
+		if len(snapshot.Executions) > 0 && len(snapshot.Executions[0].Running) == 2 {
+			if snapshot.Pool.Capacity != 2 || snapshot.Pool.ActiveWorkers != 1 {
+				t.Fatalf("expected pool capacity 2 and 1 active worker, got %+v", snapshot.Pool)
+			}
+			exec := snapshot.Executions[0]
+			if len(exec.Running) != 2 {
+				t.Fatalf("expected 2 steps running, got %+v", exec.Running)
+			}
+			if len(exec.Layers) != 2 {
+				t.Fatalf("expected 2 DAG layers, got %+v", exec.Layers)
+			}
+			if exec.Layers[0].Running != 2 || exec.Layers[0].Blocked != 0 {
+				t.Fatalf("expected layer 0 to show 2 running, 0 blocked, got %+v", exec.Layers[0])
+			}
+			if exec.Layers[1].Blocked != 1 {
+				t.Fatalf("expected layer 1's step to be blocked on its dependency, got %+v", exec.Layers[1])
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for a running execution to appear in the concurrency snapshot")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// StepCost is one step's contribution to an ExecutionCost.
+type StepCost struct {
+	StepName            string  `json:"step_name"`
+	AgentRuntimeSeconds float64 `json:"agent_runtime_seconds"`
+	CostCents           int64   `json:"cost_cents"`
+}
+
+// ExecutionCost reports one execution's resource usage: AgentRuntimeSeconds
+// is the sum of every step's actual Task.Run time (excluding time spent
+// queueing for a sandbox, bulkhead slot or ConcurrencyKey lock), and
+// CostCents is the sum of every step's RecordCost annotations.
+type ExecutionCost struct {
+	ExecutionID         uint       `json:"execution_id"`
+	AgentRuntimeSeconds float64    `json:"agent_runtime_seconds"`
+	CostCents           int64      `json:"cost_cents"`
+	Steps               []StepCost `json:"steps"`
+}
+
+// AggregateCost rolls up ExecutionCost across every execution matched by
+// a CostService.Template or CostService.Project query.
+type AggregateCost struct {
+	ExecutionCount      int     `json:"execution_count"`
+	AgentRuntimeSeconds float64 `json:"agent_runtime_seconds"`
+	CostCents           int64   `json:"cost_cents"`
+}
+
+// CostService reports AgentRuntimeSeconds and CostCents rolled up from
+// StepExecution rows, the same way DashboardService rolls up counts: each
+// query is a single aggregation against the existing schema, with no new
+// accounting table of its own.
+type CostService struct {
+	db *gorm.DB
+}
+
+// NewCostService builds a CostService backed by db.
+func NewCostService(db *gorm.DB) *CostService {
+	return &CostService{db: db}
+}
+
+// Execution reports executionID's cost, broken down per step.
+func (s *CostService) Execution(executionID uint) (*ExecutionCost, error) {
+	var steps []model.StepExecution
+	if err := s.db.Where("execution_id = ?", executionID).Find(&steps).Error; err != nil {
+		return nil, fmt.Errorf("load step executions: %w", err)
+	}
+
+	report := &ExecutionCost{ExecutionID: executionID, Steps: make([]StepCost, 0, len(steps))}
+	for _, se := range steps {
+		report.AgentRuntimeSeconds += se.AgentRuntimeSeconds
+		report.CostCents += se.CostCents
+		report.Steps = append(report.Steps, StepCost{
+			StepName:            se.StepName,
+			AgentRuntimeSeconds: se.AgentRuntimeSeconds,
+			CostCents:           se.CostCents,
+		})
+	}
+	return report, nil
+}
+
+// Template rolls up cost across every execution of templateID.
+func (s *CostService) Template(templateID uint) (*AggregateCost, error) {
+	return s.aggregate("workflow_executions.template_id = ?", templateID)
+}
+
+// Project rolls up cost across every execution whose template belongs to
+// project.
+func (s *CostService) Project(project string) (*AggregateCost, error) {
+	return s.aggregate("workflow_executions.project = ?", project)
+}
+
+// aggregate sums StepExecution cost across every execution matching
+// where/arg, joining back to workflow_executions so the caller can
+// filter by template or project without a second query.
+func (s *CostService) aggregate(where string, arg interface{}) (*AggregateCost, error) {
+	var row struct {
+		ExecutionCount      int64
+		AgentRuntimeSeconds float64
+		CostCents           int64
+	}
+	err := s.db.Model(&model.StepExecution{}).
+		Joins("JOIN workflow_executions ON workflow_executions.id = step_executions.execution_id").
+		Where(where, arg).
+		Select("COUNT(DISTINCT workflow_executions.id) AS execution_count, "+
+			"COALESCE(SUM(step_executions.agent_runtime_seconds), 0) AS agent_runtime_seconds, "+
+			"COALESCE(SUM(step_executions.cost_cents), 0) AS cost_cents").
+		Scan(&row).Error
+	if err != nil {
+		return nil, fmt.Errorf("aggregate cost: %w", err)
+	}
+	return &AggregateCost{
+		ExecutionCount:      int(row.ExecutionCount),
+		AgentRuntimeSeconds: row.AgentRuntimeSeconds,
+		CostCents:           row.CostCents,
+	}, nil
+}
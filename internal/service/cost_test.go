@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestCostExecutionSumsEachStepsRuntimeAndCost(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	exec := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusSucceeded}
+	if err := gormDB.Create(&exec).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	steps := []model.StepExecution{
+		{ExecutionID: exec.ID, StepName: "a", Status: model.StatusSucceeded, AgentRuntimeSeconds: 1.5, CostCents: 100},
+		{ExecutionID: exec.ID, StepName: "b", Status: model.StatusSucceeded, AgentRuntimeSeconds: 2.5, CostCents: 50},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step execution: %v", err)
+		}
+	}
+
+	report, err := NewCostService(gormDB).Execution(exec.ID)
+	if err != nil {
+		t.Fatalf("Execution: %v", err)
+	}
+	if report.AgentRuntimeSeconds != 4 {
+		t.Errorf("AgentRuntimeSeconds = %v, want 4", report.AgentRuntimeSeconds)
+	}
+	if report.CostCents != 150 {
+		t.Errorf("CostCents = %d, want 150", report.CostCents)
+	}
+	if len(report.Steps) != 2 {
+		t.Fatalf("expected 2 steps in the breakdown, got %d", len(report.Steps))
+	}
+}
+
+func TestCostTemplateAggregatesAcrossEveryExecution(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	other := model.WorkflowTemplate{Name: "other"}
+	if err := gormDB.Create(&other).Error; err != nil {
+		t.Fatalf("create other template: %v", err)
+	}
+
+	for _, row := range []struct {
+		tmplID uint
+		cents  int64
+	}{
+		{tmpl.ID, 100},
+		{tmpl.ID, 200},
+		{other.ID, 999},
+	} {
+		exec := model.WorkflowExecution{TemplateID: row.tmplID, Status: model.StatusSucceeded}
+		if err := gormDB.Create(&exec).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+		se := model.StepExecution{ExecutionID: exec.ID, StepName: "a", Status: model.StatusSucceeded, CostCents: row.cents}
+		if err := gormDB.Create(&se).Error; err != nil {
+			t.Fatalf("create step execution: %v", err)
+		}
+	}
+
+	agg, err := NewCostService(gormDB).Template(tmpl.ID)
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	if agg.ExecutionCount != 2 {
+		t.Errorf("ExecutionCount = %d, want 2", agg.ExecutionCount)
+	}
+	if agg.CostCents != 300 {
+		t.Errorf("CostCents = %d, want 300 (the other template's cost must not leak in)", agg.CostCents)
+	}
+}
+
+func TestCostProjectAggregatesByExecutionProject(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	for _, row := range []struct {
+		project string
+		cents   int64
+	}{
+		{"payments", 10},
+		{"payments", 20},
+		{"checkout", 999},
+	} {
+		exec := model.WorkflowExecution{TemplateID: tmpl.ID, Project: row.project, Status: model.StatusSucceeded}
+		if err := gormDB.Create(&exec).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+		se := model.StepExecution{ExecutionID: exec.ID, StepName: "a", Status: model.StatusSucceeded, CostCents: row.cents}
+		if err := gormDB.Create(&se).Error; err != nil {
+			t.Fatalf("create step execution: %v", err)
+		}
+	}
+
+	agg, err := NewCostService(gormDB).Project("payments")
+	if err != nil {
+		t.Fatalf("Project: %v", err)
+	}
+	if agg.ExecutionCount != 2 {
+		t.Errorf("ExecutionCount = %d, want 2", agg.ExecutionCount)
+	}
+	if agg.CostCents != 30 {
+		t.Errorf("CostCents = %d, want 30 (checkout's cost must not leak in)", agg.CostCents)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// RecentFailuresLimit bounds how many recent failed executions a
+// Dashboard reports, so a bad day with hundreds of failures doesn't
+// produce an unreadable response.
+const RecentFailuresLimit = 10
+
+// ErrorSnippetLength bounds how much of a failed execution's Error a
+// Dashboard's RecentFailures carries, so a long stack trace doesn't
+// dominate the response; the full error is still available from
+// GET /executions/:id.
+const ErrorSnippetLength = 200
+
+// RecentFailure is one failed execution's entry in a Dashboard's
+// RecentFailures.
+type RecentFailure struct {
+	ExecutionID  uint      `json:"execution_id"`
+	TemplateName string    `json:"template_name"`
+	FinishedAt   time.Time `json:"finished_at"`
+	ErrorSnippet string    `json:"error_snippet"`
+}
+
+// Dashboard aggregates the counters and recent activity an operations
+// home page needs into one call, instead of it assembling the same
+// picture from several list endpoints. "Today" means the trailing 24
+// hours, matching QuotaUsage.ExecutionsStartedToday rather than the
+// calendar day. There is no "upcoming schedules" section: this
+// orchestrator has no built-in scheduler (see DigestService.Send's doc
+// comment), so there's nothing to report here until a deployment's own
+// cron/trigger layer exists to ask.
+type Dashboard struct {
+	Running        int             `json:"running"`
+	FailedToday    int             `json:"failed_today"`
+	SucceededToday int             `json:"succeeded_today"`
+	RecentFailures []RecentFailure `json:"recent_failures"`
+	LockContention []LockMetrics   `json:"lock_contention"`
+}
+
+// DashboardService assembles a Dashboard from across the orchestrator's
+// other data, each already scoped to its own part of the schema.
+type DashboardService struct {
+	db    *gorm.DB
+	locks *LockService
+}
+
+// NewDashboardService builds a DashboardService backed by db, reporting
+// lock contention from locks.
+func NewDashboardService(db *gorm.DB, locks *LockService) *DashboardService {
+	return &DashboardService{db: db, locks: locks}
+}
+
+// Build assembles the current Dashboard.
+func (s *DashboardService) Build() (Dashboard, error) {
+	var dashboard Dashboard
+	since := time.Now().Add(-24 * time.Hour)
+
+	var running int64
+	if err := s.db.Model(&model.WorkflowExecution{}).Where("status = ?", model.StatusRunning).Count(&running).Error; err != nil {
+		return dashboard, fmt.Errorf("count running executions: %w", err)
+	}
+	dashboard.Running = int(running)
+
+	var failedToday int64
+	if err := s.db.Model(&model.WorkflowExecution{}).
+		Where("status = ? AND created_at >= ?", model.StatusFailed, since).
+		Count(&failedToday).Error; err != nil {
+		return dashboard, fmt.Errorf("count failed executions: %w", err)
+	}
+	dashboard.FailedToday = int(failedToday)
+
+	var succeededToday int64
+	if err := s.db.Model(&model.WorkflowExecution{}).
+		Where("status = ? AND created_at >= ?", model.StatusSucceeded, since).
+		Count(&succeededToday).Error; err != nil {
+		return dashboard, fmt.Errorf("count succeeded executions: %w", err)
+	}
+	dashboard.SucceededToday = int(succeededToday)
+
+	failures, err := s.recentFailures()
+	if err != nil {
+		return dashboard, err
+	}
+	dashboard.RecentFailures = failures
+
+	if s.locks != nil {
+		dashboard.LockContention = s.locks.Metrics()
+	}
+
+	return dashboard, nil
+}
+
+// recentFailures returns the RecentFailuresLimit most recently finished
+// failed executions, newest first.
+func (s *DashboardService) recentFailures() ([]RecentFailure, error) {
+	var rows []struct {
+		ExecutionID  uint
+		TemplateName string
+		FinishedAt   time.Time
+		Error        string
+	}
+	err := s.db.Model(&model.WorkflowExecution{}).
+		Joins("JOIN workflow_templates ON workflow_templates.id = workflow_executions.template_id").
+		Where("workflow_executions.status = ? AND workflow_executions.finished_at IS NOT NULL", model.StatusFailed).
+		Select("workflow_executions.id AS execution_id, workflow_templates.name AS template_name, "+
+			"workflow_executions.finished_at AS finished_at, workflow_executions.error AS error").
+		Order("workflow_executions.finished_at DESC").
+		Limit(RecentFailuresLimit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("query recent failures: %w", err)
+	}
+
+	failures := make([]RecentFailure, 0, len(rows))
+	for _, row := range rows {
+		failures = append(failures, RecentFailure{
+			ExecutionID:  row.ExecutionID,
+			TemplateName: row.TemplateName,
+			FinishedAt:   row.FinishedAt,
+			ErrorSnippet: truncate(row.Error, ErrorSnippetLength),
+		})
+	}
+	return failures, nil
+}
+
+// truncate shortens s to at most n runes, appending "..." if it was cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestDashboardBuildCountsExecutionsByStatus(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	for _, status := range []model.ExecutionStatus{model.StatusRunning, model.StatusRunning, model.StatusFailed, model.StatusSucceeded} {
+		exec := model.WorkflowExecution{TemplateID: tmpl.ID, Status: status}
+		if err := gormDB.Create(&exec).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+	}
+
+	dashboard, err := NewDashboardService(gormDB, nil).Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if dashboard.Running != 2 {
+		t.Errorf("Running = %d, want 2", dashboard.Running)
+	}
+	if dashboard.FailedToday != 1 {
+		t.Errorf("FailedToday = %d, want 1", dashboard.FailedToday)
+	}
+	if dashboard.SucceededToday != 1 {
+		t.Errorf("SucceededToday = %d, want 1", dashboard.SucceededToday)
+	}
+}
+
+func TestDashboardBuildReportsRecentFailuresWithTruncatedError(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "nightly-batch"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	finishedAt := time.Now()
+	exec := model.WorkflowExecution{
+		TemplateID: tmpl.ID,
+		Status:     model.StatusFailed,
+		Error:      strings.Repeat("x", ErrorSnippetLength+50),
+		FinishedAt: &finishedAt,
+	}
+	if err := gormDB.Create(&exec).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	dashboard, err := NewDashboardService(gormDB, nil).Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(dashboard.RecentFailures) != 1 {
+		t.Fatalf("expected 1 recent failure, got %d", len(dashboard.RecentFailures))
+	}
+	failure := dashboard.RecentFailures[0]
+	if failure.TemplateName != "nightly-batch" {
+		t.Errorf("TemplateName = %q, want %q", failure.TemplateName, "nightly-batch")
+	}
+	if !strings.HasSuffix(failure.ErrorSnippet, "...") || len(failure.ErrorSnippet) != ErrorSnippetLength+3 {
+		t.Errorf("ErrorSnippet = %q, want truncated to %d chars plus ellipsis", failure.ErrorSnippet, ErrorSnippetLength)
+	}
+}
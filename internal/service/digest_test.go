@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+type recordingNotifier struct {
+	messages []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, message string) error {
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func TestDigestBuildComputesFailureRateAndSLABreaches(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	for _, e := range []model.WorkflowExecution{
+		{TemplateID: tmpl.ID, Status: model.StatusSucceeded},
+		{TemplateID: tmpl.ID, Status: model.StatusFailed},
+		{TemplateID: tmpl.ID, Status: model.StatusFailed},
+		{TemplateID: tmpl.ID, Status: model.StatusSucceeded, Late: true},
+	} {
+		if err := gormDB.Create(&e).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+	}
+
+	digests := NewDigestService(gormDB, &recordingNotifier{})
+	digest, err := digests.Build("payments", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if digest.Executions != 4 {
+		t.Fatalf("expected 4 executions, got %d", digest.Executions)
+	}
+	if digest.Failed != 2 {
+		t.Fatalf("expected 2 failed, got %d", digest.Failed)
+	}
+	if digest.FailureRate != 0.5 {
+		t.Fatalf("expected a 0.5 failure rate, got %f", digest.FailureRate)
+	}
+	if digest.SLABreaches != 1 {
+		t.Fatalf("expected 1 SLA breach, got %d", digest.SLABreaches)
+	}
+}
+
+func TestDigestBuildExcludesOtherProjects(t *testing.T) {
+	gormDB := newTestDB(t)
+	ours := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	theirs := model.WorkflowTemplate{Name: "t", Project: "checkout"}
+	if err := gormDB.Create(&ours).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if err := gormDB.Create(&theirs).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if err := gormDB.Create(&model.WorkflowExecution{TemplateID: theirs.ID, Status: model.StatusFailed}).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	digests := NewDigestService(gormDB, &recordingNotifier{})
+	digest, err := digests.Build("payments", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if digest.Executions != 0 {
+		t.Fatalf("expected 0 executions for an unrelated project, got %d", digest.Executions)
+	}
+}
+
+func TestDigestBuildRanksSlowestSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusSucceeded}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	start := time.Now().Add(-time.Hour)
+	fast := start.Add(1 * time.Second)
+	slow := start.Add(10 * time.Second)
+	steps := []model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "fast", Status: model.StatusSucceeded, StartedAt: &start, FinishedAt: &fast},
+		{ExecutionID: execution.ID, StepName: "slow", Status: model.StatusSucceeded, StartedAt: &start, FinishedAt: &slow},
+	}
+	for _, se := range steps {
+		if err := gormDB.Create(&se).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	digests := NewDigestService(gormDB, &recordingNotifier{})
+	digest, err := digests.Build("payments", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(digest.SlowestSteps) != 2 || digest.SlowestSteps[0].StepName != "slow" {
+		t.Fatalf("expected slow to rank first, got %+v", digest.SlowestSteps)
+	}
+}
+
+func TestDigestSendDeliversFormattedMessage(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if err := gormDB.Create(&model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusFailed}).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	notifier := &recordingNotifier{}
+	digests := NewDigestService(gormDB, notifier)
+	if _, err := digests.Send(context.Background(), "payments", time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifier.messages))
+	}
+	if !strings.Contains(notifier.messages[0], "payments") {
+		t.Fatalf("expected the project name in the digest message, got %q", notifier.messages[0])
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// StepDurationEstimate is one step's contribution to a
+// DurationEstimate: the range of wall-clock time it's expected to take,
+// and what that range was derived from.
+type StepDurationEstimate struct {
+	Name string        `json:"name"`
+	Min  time.Duration `json:"min_ms"`
+	Max  time.Duration `json:"max_ms"`
+	// Samples is how many past StepExecutions of this step Min/Max was
+	// computed from. Zero means no history exists yet, so Max falls back
+	// to the step's effective hard timeout (or 0 if it has none either,
+	// meaning this step's duration is simply unknown).
+	Samples int `json:"samples"`
+}
+
+// LayerDurationEstimate is one DAG layer's contribution: since the
+// orchestrator doesn't start a later layer until every step in the
+// current one finishes, a layer's own min/max is the slowest of its
+// steps', not their sum.
+type LayerDurationEstimate struct {
+	Steps []StepDurationEstimate `json:"steps"`
+	Min   time.Duration          `json:"min_ms"`
+	Max   time.Duration          `json:"max_ms"`
+}
+
+// DurationEstimate is a template's theoretical best-case and worst-case
+// wall-clock duration, for authors sizing an SLA or choosing a
+// MaxParallel before they have enough real runs to go on.
+type DurationEstimate struct {
+	TemplateID uint                    `json:"template_id"`
+	Layers     []LayerDurationEstimate `json:"layers"`
+	Min        time.Duration           `json:"min_ms"`
+	Max        time.Duration           `json:"max_ms"`
+}
+
+// SimulateDuration estimates templateID's best-case and worst-case
+// wall-clock duration by laying its DAG out in topological layers (the
+// same layering the orchestrator actually runs) and, for each step,
+// combining its historical StepExecution durations with its effective
+// hard timeout: a step that has run before is bounded by the fastest
+// and slowest it's actually taken, further capped at its hard timeout
+// if that's tighter than the slowest observed run (the orchestrator
+// would have killed it there); a step with no history yet is assumed to
+// take its full hard timeout in the worst case, since nothing bounds it
+// otherwise, and 0 in the best case.
+func (s *TemplateService) SimulateDuration(templateID uint) (*DurationEstimate, error) {
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, templateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+
+	graph, err := dag.Build(tmpl.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("build dag: %w", err)
+	}
+	layers, err := graph.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("layer dag: %w", err)
+	}
+
+	history, err := s.stepDurationHistory(templateID)
+	if err != nil {
+		return nil, fmt.Errorf("load step duration history: %w", err)
+	}
+
+	estimate := &DurationEstimate{TemplateID: templateID}
+	for _, layer := range layers {
+		layerEstimate := LayerDurationEstimate{}
+		for _, node := range layer {
+			stepEstimate := estimateStepDuration(&tmpl, node, history[node.Name])
+			layerEstimate.Steps = append(layerEstimate.Steps, stepEstimate)
+			if stepEstimate.Min > layerEstimate.Min {
+				layerEstimate.Min = stepEstimate.Min
+			}
+			if stepEstimate.Max > layerEstimate.Max {
+				layerEstimate.Max = stepEstimate.Max
+			}
+		}
+		estimate.Layers = append(estimate.Layers, layerEstimate)
+		estimate.Min += layerEstimate.Min
+		estimate.Max += layerEstimate.Max
+	}
+	return estimate, nil
+}
+
+// durationSample is one step name's observed fastest and slowest run
+// across every past execution of its template.
+type durationSample struct {
+	min     time.Duration
+	max     time.Duration
+	samples int
+}
+
+// stepDurationHistory returns, for every step name that has at least
+// one finished StepExecution under templateID, the fastest and slowest
+// it's taken.
+func (s *TemplateService) stepDurationHistory(templateID uint) (map[string]durationSample, error) {
+	var rows []struct {
+		StepName string
+		MinSecs  int64
+		MaxSecs  int64
+		Samples  int
+	}
+	err := s.db.Table("step_executions").
+		Select("step_executions.step_name AS step_name, "+
+			"min(strftime('%s', step_executions.finished_at) - strftime('%s', step_executions.started_at)) AS min_secs, "+
+			"max(strftime('%s', step_executions.finished_at) - strftime('%s', step_executions.started_at)) AS max_secs, "+
+			"count(*) AS samples").
+		Joins("JOIN workflow_executions ON workflow_executions.id = step_executions.execution_id").
+		Where("workflow_executions.template_id = ? AND step_executions.started_at IS NOT NULL AND step_executions.finished_at IS NOT NULL", templateID).
+		Group("step_executions.step_name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[string]durationSample, len(rows))
+	for _, row := range rows {
+		history[row.StepName] = durationSample{
+			min:     time.Duration(row.MinSecs) * time.Second,
+			max:     time.Duration(row.MaxSecs) * time.Second,
+			samples: row.Samples,
+		}
+	}
+	return history, nil
+}
+
+// estimateStepDuration combines node's historical duration sample, if
+// any, with its effective hard timeout (its own override, else tmpl's
+// default): history sets Min, and Max is the slower of the two tiers
+// unless the hard timeout is both set and tighter, since the
+// orchestrator would have cut the step off there.
+func estimateStepDuration(tmpl *model.WorkflowTemplate, node *dag.Node, sample durationSample) StepDurationEstimate {
+	hardTimeout := tmpl.HardTimeoutSeconds
+	if node.Step.HardTimeoutSeconds != 0 {
+		hardTimeout = node.Step.HardTimeoutSeconds
+	}
+	hard := time.Duration(hardTimeout) * time.Second
+
+	estimate := StepDurationEstimate{Name: node.Name, Samples: sample.samples}
+	if sample.samples > 0 {
+		estimate.Min = sample.min
+		estimate.Max = sample.max
+	}
+	if hard > 0 && (sample.samples == 0 || hard < estimate.Max) {
+		estimate.Max = hard
+	}
+	return estimate
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestSimulateDurationSumsLayersByTheirSlowestStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", HardTimeoutSeconds: 60}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell"},
+		{TemplateID: tmpl.ID, Name: "c", Type: "shell", DependsOn: "a,b"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusSucceeded}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	start := time.Now().Add(-time.Hour)
+	aFinish := start.Add(5 * time.Second)
+	bFinish := start.Add(10 * time.Second)
+	cFinish := start.Add(3 * time.Second)
+	history := []model.StepExecution{
+		{ExecutionID: execution.ID, StepName: "a", Status: model.StatusSucceeded, StartedAt: &start, FinishedAt: &aFinish},
+		{ExecutionID: execution.ID, StepName: "b", Status: model.StatusSucceeded, StartedAt: &start, FinishedAt: &bFinish},
+		{ExecutionID: execution.ID, StepName: "c", Status: model.StatusSucceeded, StartedAt: &start, FinishedAt: &cFinish},
+	}
+	for _, se := range history {
+		if err := gormDB.Create(&se).Error; err != nil {
+			t.Fatalf("create step execution: %v", err)
+		}
+	}
+
+	templates := NewTemplateService(gormDB, nil, nil, nil)
+	estimate, err := templates.SimulateDuration(tmpl.ID)
+	if err != nil {
+		t.Fatalf("SimulateDuration: %v", err)
+	}
+	if len(estimate.Layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(estimate.Layers))
+	}
+	// Layer 0 (a, b) is bounded by b, the slower of the two.
+	if estimate.Layers[0].Max != 10*time.Second {
+		t.Fatalf("expected layer 0 max to be bounded by its slowest step, got %s", estimate.Layers[0].Max)
+	}
+	// Total is the sum of each layer's slowest step: 10s + 3s.
+	if estimate.Max != 13*time.Second {
+		t.Fatalf("expected total max of 13s, got %s", estimate.Max)
+	}
+}
+
+func TestSimulateDurationFallsBackToHardTimeoutWithoutHistory(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", HardTimeoutSeconds: 30}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	templates := NewTemplateService(gormDB, nil, nil, nil)
+	estimate, err := templates.SimulateDuration(tmpl.ID)
+	if err != nil {
+		t.Fatalf("SimulateDuration: %v", err)
+	}
+	if estimate.Min != 0 {
+		t.Fatalf("expected a min of 0 with no history, got %s", estimate.Min)
+	}
+	if estimate.Max != 30*time.Second {
+		t.Fatalf("expected max to fall back to the step's hard timeout, got %s", estimate.Max)
+	}
+	if estimate.Layers[0].Steps[0].Samples != 0 {
+		t.Fatalf("expected 0 samples, got %d", estimate.Layers[0].Steps[0].Samples)
+	}
+}
@@ -0,0 +1,725 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/report"
+	"github.com/fengmingli/orchestrator/internal/schema"
+)
+
+// ExecutionService starts and queries WorkflowExecutions.
+type ExecutionService struct {
+	db           *gorm.DB
+	orchestrator *executor.TaskOrchestrator
+	quotas       *QuotaService
+	pool         *executor.WorkerPool
+	blackouts    *BlackoutService
+}
+
+// DAGChangedError reports that a template's DAG no longer matches the
+// structure an execution started with, so resuming it would run against
+// steps the execution's history doesn't describe.
+type DAGChangedError struct {
+	ExecutionID uint
+	TemplateID  uint
+}
+
+func (e *DAGChangedError) Error() string {
+	return fmt.Sprintf("execution %d: template %d's DAG changed since this execution started, refusing to resume", e.ExecutionID, e.TemplateID)
+}
+
+// NewExecutionService builds an ExecutionService backed by db and
+// orchestrator, enforcing quotas via quotas before starting an execution.
+// If pool is non-nil, Start dispatches through it instead of running
+// directly, so priority and fair-share scheduling apply whenever the
+// pool is saturated; pass nil to run every execution immediately.
+// blackouts, if non-nil, is checked before a non-overriding Start or
+// StartWithChaos creates an execution.
+func NewExecutionService(db *gorm.DB, orchestrator *executor.TaskOrchestrator, quotas *QuotaService, pool *executor.WorkerPool, blackouts *BlackoutService) *ExecutionService {
+	return &ExecutionService{db: db, orchestrator: orchestrator, quotas: quotas, pool: pool, blackouts: blackouts}
+}
+
+// OutputSchemaViolationError reports that an execution's completed
+// outputs didn't satisfy its template's OutputSchema. The execution
+// itself is left Succeeded, since the run genuinely completed; this
+// just tells the caller (often another template composing this one as
+// a sub-workflow) that the contract it was promised was broken.
+type OutputSchemaViolationError struct {
+	ExecutionID uint
+	TemplateID  uint
+	Err         error
+}
+
+func (e *OutputSchemaViolationError) Error() string {
+	return fmt.Sprintf("execution %d: template %d's output contract violated: %s", e.ExecutionID, e.TemplateID, e.Err)
+}
+
+func (e *OutputSchemaViolationError) Unwrap() error {
+	return e.Err
+}
+
+// Start creates a WorkflowExecution for templateID at priority and runs
+// it to completion, returning once it finishes. Higher priority lets it
+// jump ahead of lower-priority executions still waiting for a free
+// worker when the service was built with a WorkerPool; it has no effect
+// otherwise. maxParallel overrides the template's own MaxParallel for
+// this execution if greater than zero. params is the JSON object the
+// execution is started with; it's rejected (without creating an
+// execution) if it doesn't satisfy the template's ParamsSchema, and
+// empty params are treated as {}. It returns a *QuotaExceededError
+// without creating an execution if the template's project is over
+// quota (concurrent calls for the same project are serialized around
+// this check, see QuotaService.Reserve), a *BlackoutError without
+// creating an execution if the template's project (or the template
+// itself) is inside a model.BlackoutWindow and override is false, or an
+// *OutputSchemaViolationError if the run completes but its outputs
+// don't satisfy the template's OutputSchema. override also exempts the
+// execution from the WorkerPool's own blackout recheck at dispatch time
+// (see WorkerPool.Submit), so an admin-authorized override during a
+// window isn't held back again once it's queued.
+func (s *ExecutionService) Start(ctx context.Context, templateID uint, priority int, maxParallel int, params string, override bool) (*model.WorkflowExecution, error) {
+	tmpl, release, err := s.loadTemplateWithinQuota(templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if err := s.checkBlackout(tmpl, override); err != nil {
+		return nil, err
+	}
+	if maxParallel <= 0 {
+		maxParallel = tmpl.MaxParallel
+	}
+	if err := validateAgainstSchema(tmpl.ParamsSchema, params); err != nil {
+		return nil, fmt.Errorf("template %d: params: %w", tmpl.ID, err)
+	}
+
+	execution := &model.WorkflowExecution{
+		TemplateID:         tmpl.ID,
+		Status:             model.StatusPending,
+		Priority:           priority,
+		MaxParallel:        maxParallel,
+		SoftTimeoutSeconds: tmpl.SoftTimeoutSeconds,
+		HardTimeoutSeconds: tmpl.HardTimeoutSeconds,
+		Project:            tmpl.Project,
+		OnCallRoutingKey:   tmpl.OnCallRoutingKey,
+		Params:             params,
+	}
+	if err := s.db.Create(execution).Error; err != nil {
+		return nil, err
+	}
+	release()
+
+	if s.pool != nil {
+		if err := model.ValidateTransition(execution.Status, model.StatusQueued); err != nil {
+			return execution, err
+		}
+		execution.Status = model.StatusQueued
+		// Select("*") is required: plain Save falls back to an upsert
+		// when its update affects no rows, which would silently defeat
+		// the optimistic lock on Version.
+		result := s.db.Select("*").Save(execution)
+		if result.Error != nil {
+			return execution, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return execution, model.ErrConcurrentUpdate
+		}
+		execution.BumpVersion()
+		runErr := <-s.pool.Submit(ctx, execution, tmpl.Steps, tmpl.Project, priority, tmpl.SLASeconds, override)
+		return execution, s.checkOutputSchema(tmpl, execution, runErr)
+	}
+	runErr := s.orchestrator.RunWithSLA(ctx, execution, tmpl.Steps, tmpl.SLASeconds)
+	return execution, s.checkOutputSchema(tmpl, execution, runErr)
+}
+
+// checkOutputSchema validates execution's step outputs against tmpl's
+// OutputSchema once it's finished running, but only if runErr is nil:
+// a failed run was never going to produce the outputs it promised, so
+// there's nothing useful to check. It returns runErr unchanged unless
+// the schema is violated.
+func (s *ExecutionService) checkOutputSchema(tmpl *model.WorkflowTemplate, execution *model.WorkflowExecution, runErr error) error {
+	if runErr != nil || tmpl.OutputSchema == "" {
+		return runErr
+	}
+	var steps []model.StepExecution
+	if err := s.db.Where("execution_id = ?", execution.ID).Find(&steps).Error; err != nil {
+		return err
+	}
+	outputs := make(map[string]any, len(steps))
+	for _, step := range steps {
+		outputs[step.StepName] = decodeOutput(step.Output)
+	}
+	if err := schema.Validate(tmpl.OutputSchema, outputs); err != nil {
+		return &OutputSchemaViolationError{ExecutionID: execution.ID, TemplateID: tmpl.ID, Err: err}
+	}
+	return nil
+}
+
+// decodeOutput parses output as JSON if it is some, so a step whose
+// output is itself a JSON object or number validates against a schema
+// expecting that shape rather than always being treated as a string.
+func decodeOutput(output string) any {
+	var v any
+	if err := json.Unmarshal([]byte(output), &v); err != nil {
+		return output
+	}
+	return v
+}
+
+// validateAgainstSchema parses params as JSON (treating an empty string
+// as {}) and validates it against paramsSchema.
+func validateAgainstSchema(paramsSchema, params string) error {
+	if paramsSchema == "" {
+		return nil
+	}
+	if params == "" {
+		params = "{}"
+	}
+	var v any
+	if err := json.Unmarshal([]byte(params), &v); err != nil {
+		return fmt.Errorf("invalid params JSON: %w", err)
+	}
+	return schema.Validate(paramsSchema, v)
+}
+
+// StartWithChaos behaves like Start, except every step is additionally
+// subject to chaos's randomized fault injection (random delays, injected
+// failures, simulated lock loss and database write errors), so operators
+// can verify that failure policies, retries and recovery actually work
+// end-to-end against unpredictable failures rather than only the fixed
+// scenarios StartSimulated's faults describe. chaos is recorded on the
+// execution as JSON, so a run affected by it can be told apart from a
+// genuine failure after the fact. Like Start, it returns a
+// *BlackoutError without creating an execution if the template's
+// project or the template itself is inside a model.BlackoutWindow and
+// override is false: chaos testing during a maintenance window is not
+// an exception, it's an extra reason to keep production load down.
+func (s *ExecutionService) StartWithChaos(ctx context.Context, templateID uint, priority int, maxParallel int, params string, chaos executor.ChaosConfig, override bool) (*model.WorkflowExecution, error) {
+	tmpl, release, err := s.loadTemplateWithinQuota(templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if err := s.checkBlackout(tmpl, override); err != nil {
+		return nil, err
+	}
+	if maxParallel <= 0 {
+		maxParallel = tmpl.MaxParallel
+	}
+	if err := validateAgainstSchema(tmpl.ParamsSchema, params); err != nil {
+		return nil, fmt.Errorf("template %d: params: %w", tmpl.ID, err)
+	}
+	encodedChaos, err := json.Marshal(chaos)
+	if err != nil {
+		return nil, fmt.Errorf("encode chaos config: %w", err)
+	}
+
+	execution := &model.WorkflowExecution{
+		TemplateID:         tmpl.ID,
+		Status:             model.StatusPending,
+		Priority:           priority,
+		MaxParallel:        maxParallel,
+		SoftTimeoutSeconds: tmpl.SoftTimeoutSeconds,
+		HardTimeoutSeconds: tmpl.HardTimeoutSeconds,
+		Project:            tmpl.Project,
+		OnCallRoutingKey:   tmpl.OnCallRoutingKey,
+		Params:             params,
+		ChaosConfig:        string(encodedChaos),
+	}
+	if err := s.db.Create(execution).Error; err != nil {
+		return nil, err
+	}
+	release()
+
+	runErr := s.orchestrator.RunWithChaos(ctx, execution, tmpl.Steps, tmpl.SLASeconds, chaos)
+	return execution, s.checkOutputSchema(tmpl, execution, runErr)
+}
+
+// StartSimulated runs templateID in simulation mode: every step named in
+// faults has its outcome forced rather than being executed for real, so
+// template authors can validate failure policies and compensation paths.
+// It is subject to the same quota check as Start, but always runs
+// directly rather than through a WorkerPool, since simulations are a
+// design-time tool rather than production load to schedule fairly.
+func (s *ExecutionService) StartSimulated(ctx context.Context, templateID uint, faults map[string]executor.Fault) (*model.WorkflowExecution, error) {
+	tmpl, release, err := s.loadTemplateWithinQuota(templateID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	execution := &model.WorkflowExecution{
+		TemplateID:         tmpl.ID,
+		Status:             model.StatusPending,
+		Simulated:          true,
+		MaxParallel:        tmpl.MaxParallel,
+		SoftTimeoutSeconds: tmpl.SoftTimeoutSeconds,
+		HardTimeoutSeconds: tmpl.HardTimeoutSeconds,
+		Project:            tmpl.Project,
+		OnCallRoutingKey:   tmpl.OnCallRoutingKey,
+	}
+	if err := s.db.Create(execution).Error; err != nil {
+		return nil, err
+	}
+	release()
+
+	runErr := s.orchestrator.RunSimulated(ctx, execution, tmpl.Steps, faults)
+	return execution, runErr
+}
+
+// Resume re-runs a failed execution against its template's current DAG,
+// refusing with a *DAGChangedError if the template's structure has
+// changed since the execution originally started, since replaying its
+// steps against a different DAG could run steps the original execution
+// never recorded or skip ones it did. Resuming currently re-runs every
+// step rather than only the ones that didn't finish, since the
+// TaskOrchestrator has no notion of partial progress within a single
+// run; that's a reasonable next step once this detection is in place.
+func (s *ExecutionService) Resume(ctx context.Context, executionID uint) (*model.WorkflowExecution, error) {
+	var execution model.WorkflowExecution
+	if err := s.db.First(&execution, executionID).Error; err != nil {
+		return nil, fmt.Errorf("load execution: %w", err)
+	}
+	if execution.Status != model.StatusFailed {
+		return nil, fmt.Errorf("execution %d: only failed executions can be resumed, status is %q", execution.ID, execution.Status)
+	}
+
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+
+	graph, err := dag.Build(tmpl.Steps)
+	if err != nil {
+		return nil, err
+	}
+	if execution.DAGHash != "" && graph.StructuralHash() != execution.DAGHash {
+		return nil, &DAGChangedError{ExecutionID: execution.ID, TemplateID: tmpl.ID}
+	}
+	if execution.MaxParallel == 0 {
+		execution.MaxParallel = tmpl.MaxParallel
+	}
+	if execution.SoftTimeoutSeconds == 0 {
+		execution.SoftTimeoutSeconds = tmpl.SoftTimeoutSeconds
+	}
+	if execution.HardTimeoutSeconds == 0 {
+		execution.HardTimeoutSeconds = tmpl.HardTimeoutSeconds
+	}
+	if execution.Project == "" {
+		execution.Project = tmpl.Project
+	}
+
+	return &execution, s.orchestrator.RunWithSLA(ctx, &execution, tmpl.Steps, tmpl.SLASeconds)
+}
+
+// loadTemplateWithinQuota loads templateID and reserves its project's
+// quota, returning a *QuotaExceededError if starting another execution
+// would exceed it. The returned release func must be called once the
+// caller has either recorded the execution this reservation was for or
+// decided not to start it; until then, any other call reserving the
+// same project is held at its own quota check, so the two can't
+// collectively exceed it the way two unsynchronized checks could.
+func (s *ExecutionService) loadTemplateWithinQuota(templateID uint) (*model.WorkflowTemplate, func(), error) {
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, templateID).Error; err != nil {
+		return nil, func() {}, fmt.Errorf("load template: %w", err)
+	}
+	if s.quotas == nil {
+		return &tmpl, func() {}, nil
+	}
+	release, err := s.quotas.Reserve(tmpl.Project)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	return &tmpl, release, nil
+}
+
+// checkBlackout returns a *BlackoutError if tmpl's project or template
+// is currently inside a model.BlackoutWindow, unless override is true
+// or no BlackoutService was configured. This is a point-in-time check
+// made when Start/StartWithChaos is called, not a queue: an execution
+// that clears it here and is then queued behind a saturated WorkerPool
+// is re-checked against the active blackout by the pool itself at
+// dispatch time (see executor.WorkerPool's BlackoutChecker), which
+// waits out the window instead of running early; called directly
+// without a pool, there's no scheduler to queue behind, so this check
+// is the only one and a rejection here is final.
+func (s *ExecutionService) checkBlackout(tmpl *model.WorkflowTemplate, override bool) error {
+	if s.blackouts == nil || override {
+		return nil
+	}
+	window, err := s.blackouts.Active(tmpl.Project, tmpl.ID)
+	if err != nil {
+		return err
+	}
+	if window != nil {
+		return &BlackoutError{Window: *window}
+	}
+	return nil
+}
+
+// omitStepBlobs drops a step's Output and Error from a preloaded query,
+// for views that only need status/timing, so listing or summarizing an
+// execution with many steps (or steps that logged megabytes of output)
+// doesn't pull all of that text into memory just to show a status dot.
+// Callers needing a step's actual output/error should fetch it on
+// demand with Step.
+func omitStepBlobs(db *gorm.DB) *gorm.DB {
+	return db.Omit("Output", "Error")
+}
+
+// Get loads an execution by ID, including its step executions with
+// their full output and error text. Prefer ListPage/List for rendering
+// many executions at once; Get is for a single execution's detail view.
+func (s *ExecutionService) Get(id uint) (*model.WorkflowExecution, error) {
+	var execution model.WorkflowExecution
+	if err := s.db.Preload("Steps").First(&execution, id).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// LiveDAGNode is one step's DAG position and its current status, for
+// rendering a live-updating graph view.
+type LiveDAGNode struct {
+	Name      string                `json:"name"`
+	Type      string                `json:"type"`
+	DependsOn []string              `json:"depends_on,omitempty"`
+	Status    model.ExecutionStatus `json:"status"`
+}
+
+// LiveDAG is a template's DAG structure, laid out in topological layers,
+// with each node annotated with its current StepExecution status.
+type LiveDAG struct {
+	ExecutionID uint            `json:"execution_id"`
+	Layers      [][]LiveDAGNode `json:"layers"`
+}
+
+// LiveDAG combines executionID's template DAG structure with its
+// StepExecution rows' current status, so a UI can render real-time node
+// colors without separately fetching and correlating the template and
+// the execution. There is no in-memory running-scheduler snapshot to
+// read from: RunSimulated persists each step's status to the database as
+// it goes (see StatusWriter), so the database rows already are the live
+// state; a step the scheduler hasn't reached yet simply has no
+// StepExecution row and is reported as StatusPending.
+func (s *ExecutionService) LiveDAG(executionID uint) (*LiveDAG, error) {
+	var execution model.WorkflowExecution
+	if err := omitStepBlobs(s.db).Preload("Steps").First(&execution, executionID).Error; err != nil {
+		return nil, fmt.Errorf("load execution: %w", err)
+	}
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+	graph, err := dag.Build(tmpl.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("build dag: %w", err)
+	}
+	layers, err := graph.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("layers: %w", err)
+	}
+
+	statusByName := make(map[string]model.ExecutionStatus, len(execution.Steps))
+	for _, step := range execution.Steps {
+		statusByName[step.StepName] = step.Status
+	}
+
+	live := &LiveDAG{ExecutionID: executionID, Layers: make([][]LiveDAGNode, len(layers))}
+	for i, layer := range layers {
+		nodes := make([]LiveDAGNode, len(layer))
+		for j, node := range layer {
+			status, ok := statusByName[node.Name]
+			if !ok {
+				status = model.StatusPending
+			}
+			nodes[j] = LiveDAGNode{
+				Name:      node.Name,
+				Type:      node.Step.Type,
+				DependsOn: node.DependsOn,
+				Status:    status,
+			}
+		}
+		live.Layers[i] = nodes
+	}
+	return live, nil
+}
+
+// LayerConcurrency summarizes one DAG layer's node statuses for
+// ConcurrencySnapshot: how many are actually running versus still
+// blocked behind earlier layers or unresolved dependencies.
+type LayerConcurrency struct {
+	Running int `json:"running"`
+	Blocked int `json:"blocked"`
+	Total   int `json:"total"`
+}
+
+// ExecutionConcurrency is one execution's slice of a ConcurrencySnapshot:
+// exactly which steps are running right now (from the orchestrator's
+// in-memory bookkeeping, not subject to StatusWriter's batching) plus a
+// per-layer breakdown of running versus blocked-on-dependencies nodes.
+type ExecutionConcurrency struct {
+	ExecutionID uint                  `json:"execution_id"`
+	Running     []executor.ActiveStep `json:"running"`
+	Layers      []LayerConcurrency    `json:"layers"`
+}
+
+// ConcurrencySnapshot is a point-in-time view of how busy the
+// orchestrator is, for tuning WorkerPool and execution MaxParallel
+// settings: the pool's own utilization plus, for every execution with a
+// step running right now, which nodes are running and how many are
+// still blocked on dependencies.
+type ConcurrencySnapshot struct {
+	Pool       executor.PoolStats     `json:"pool"`
+	Executions []ExecutionConcurrency `json:"executions"`
+}
+
+// Concurrency builds a ConcurrencySnapshot. Pool is the zero PoolStats
+// if this ExecutionService was built without a WorkerPool (see
+// NewExecutionService).
+func (s *ExecutionService) Concurrency() (*ConcurrencySnapshot, error) {
+	snapshot := &ConcurrencySnapshot{}
+	if s.pool != nil {
+		snapshot.Pool = s.pool.Stats()
+	}
+
+	byExecution := make(map[uint][]executor.ActiveStep)
+	for _, step := range s.orchestrator.ActiveSteps() {
+		byExecution[step.ExecutionID] = append(byExecution[step.ExecutionID], step)
+	}
+
+	for executionID, running := range byExecution {
+		live, err := s.LiveDAG(executionID)
+		if err != nil {
+			return nil, fmt.Errorf("live dag for execution %d: %w", executionID, err)
+		}
+		runningNames := make(map[string]bool, len(running))
+		for _, step := range running {
+			runningNames[step.StepName] = true
+		}
+		layers := make([]LayerConcurrency, len(live.Layers))
+		for i, layer := range live.Layers {
+			lc := LayerConcurrency{Total: len(layer)}
+			for _, node := range layer {
+				switch {
+				// A node's own in-memory ActiveStep entry is
+				// authoritative over its LiveDAG status, which lags
+				// until StatusWriter's next batched flush.
+				case runningNames[node.Name]:
+					lc.Running++
+				case node.Status == model.StatusPending:
+					lc.Blocked++
+				}
+			}
+			layers[i] = lc
+		}
+		snapshot.Executions = append(snapshot.Executions, ExecutionConcurrency{
+			ExecutionID: executionID, Running: running, Layers: layers,
+		})
+	}
+	return snapshot, nil
+}
+
+// Step loads one step execution by ID, including its full output and
+// error text, for callers that fetched a summary (via List/ListPage,
+// which omit that text) and now need one step's heavy columns.
+func (s *ExecutionService) Step(stepID uint) (*model.StepExecution, error) {
+	var step model.StepExecution
+	if err := s.db.First(&step, stepID).Error; err != nil {
+		return nil, err
+	}
+	return &step, nil
+}
+
+// StepAttempts returns every StepExecution run for stepName under
+// executionID, ordered oldest attempt first, including the full output
+// and error text of each (like Step; List/ListPage's embedded steps
+// omit it). Useful for seeing what went wrong on earlier attempts of a
+// step that was eventually resumed or rerun into success, since those
+// earlier rows are otherwise only reachable one at a time by guessing
+// their StepExecution ID.
+func (s *ExecutionService) StepAttempts(executionID uint, stepName string) ([]model.StepExecution, error) {
+	var steps []model.StepExecution
+	if err := s.db.Where("execution_id = ? AND step_name = ?", executionID, stepName).Order("attempt asc").Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// List returns executions ordered by most recently created first, with
+// their step executions eager-loaded in one extra batch query rather
+// than one query per execution. Each step's Output and Error are
+// omitted; fetch them on demand with Step.
+func (s *ExecutionService) List() ([]model.WorkflowExecution, error) {
+	var executions []model.WorkflowExecution
+	if err := s.db.Preload("Steps", omitStepBlobs).Order("created_at desc").Find(&executions).Error; err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
+
+// ListPage returns one cursor-paginated page of executions matching q,
+// plus the cursor for the next page (empty once there are no more
+// rows). Each step's Output and Error are omitted; fetch them on demand
+// with Step.
+func (s *ExecutionService) ListPage(q ListQuery) ([]model.WorkflowExecution, string, error) {
+	if err := q.normalize(); err != nil {
+		return nil, "", err
+	}
+	query, err := applyListQuery(s.db.Model(&model.WorkflowExecution{}).Preload("Steps", omitStepBlobs), q, model.LabelOwnerExecution)
+	if err != nil {
+		return nil, "", err
+	}
+	var executions []model.WorkflowExecution
+	if err := query.Find(&executions).Error; err != nil {
+		return nil, "", err
+	}
+	if len(executions) == 0 {
+		return executions, "", nil
+	}
+	last := executions[len(executions)-1]
+	next := ""
+	if len(executions) == q.Limit {
+		next = encodeCursor(sortValueOf(q.Sort, last.ID, last.CreatedAt))
+	}
+	return executions, next, nil
+}
+
+// CompleteExternalStep delivers result to the "external" step stepID of
+// executionID, if it's currently pending-external and token matches the
+// one it was given when it started waiting. See
+// executor.TaskOrchestrator.CompleteExternalStep.
+func (s *ExecutionService) CompleteExternalStep(executionID, stepID uint, token string, result executor.ExternalResult) error {
+	var step model.StepExecution
+	if err := s.db.Where("id = ? AND execution_id = ?", stepID, executionID).First(&step).Error; err != nil {
+		return fmt.Errorf("load step execution: %w", err)
+	}
+	return s.orchestrator.CompleteExternalStep(step.ID, token, result)
+}
+
+// Report builds a report.Report summarizing executionID: its status,
+// timing, every step's output/error, any operator notes, and any
+// attached external links, plus a reference to its template's DAG
+// image, suitable for attaching to an incident postmortem.
+func (s *ExecutionService) Report(executionID uint) (*report.Report, error) {
+	execution, err := s.Get(executionID)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl model.WorkflowTemplate
+	if err := s.db.First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+	notes, err := s.Notes(executionID)
+	if err != nil {
+		return nil, fmt.Errorf("load notes: %w", err)
+	}
+	links, err := s.Links(executionID)
+	if err != nil {
+		return nil, fmt.Errorf("load links: %w", err)
+	}
+	dagImageURL := fmt.Sprintf("/api/v1/templates/%d/export?format=svg", tmpl.ID)
+	return report.Build(execution, tmpl.Name, dagImageURL, notes, links), nil
+}
+
+// Compare builds a report.Diff between executions idA and idB, matching
+// steps by name, for a postmortem asking why one run succeeded and
+// another failed.
+func (s *ExecutionService) Compare(idA, idB uint) (*report.Diff, error) {
+	a, err := s.Get(idA)
+	if err != nil {
+		return nil, fmt.Errorf("load execution %d: %w", idA, err)
+	}
+	b, err := s.Get(idB)
+	if err != nil {
+		return nil, fmt.Errorf("load execution %d: %w", idB, err)
+	}
+	return report.Compare(a, b), nil
+}
+
+// Events returns the full, ordered event stream for an execution, which
+// reconstructs its timeline exactly: every node start/finish/fail/retry/
+// skip and lock acquisition/refresh, in the order they occurred.
+func (s *ExecutionService) Events(executionID uint) ([]model.ExecutionEvent, error) {
+	var events []model.ExecutionEvent
+	if err := s.db.Where("execution_id = ?", executionID).Order("sequence asc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ExternalInputs returns every external input recorded for an execution
+// (e.g. the HTTP responses its steps consumed via
+// executor.RecordExternalInput), in recording order, so a failed
+// execution's steps can be replayed against the exact same external data
+// instead of whatever a live endpoint returns on retry.
+func (s *ExecutionService) ExternalInputs(executionID uint) ([]model.ExternalInput, error) {
+	var inputs []model.ExternalInput
+	if err := s.db.Where("execution_id = ?", executionID).Order("id asc").Find(&inputs).Error; err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
+
+// AddNote attaches a timestamped operator note to executionID, optionally
+// scoped to one of its steps via stepName, attributed to createdBy.
+func (s *ExecutionService) AddNote(executionID uint, stepName, message, createdBy string) (*model.ExecutionNote, error) {
+	note := model.ExecutionNote{
+		ExecutionID: executionID,
+		StepName:    stepName,
+		Message:     message,
+		CreatedBy:   createdBy,
+	}
+	if err := s.db.Create(&note).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// Notes returns every note attached to executionID, in the order they
+// were added.
+func (s *ExecutionService) Notes(executionID uint) ([]model.ExecutionNote, error) {
+	var notes []model.ExecutionNote
+	if err := s.db.Where("execution_id = ?", executionID).Order("id asc").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// AddLink attaches an external reference (a ticket, an incident, a
+// dashboard) to executionID. Unlike ExecutionNote, a link is typed (see
+// ExecutionLinkKind) so a caller building its own notification message
+// (e.g. a webhook payload assembled from GET /executions/:id/links) can
+// pick an icon or label per kind instead of treating every URL the same;
+// the orchestrator's own Notifier still sends only a plain-text summary
+// and doesn't read links itself.
+func (s *ExecutionService) AddLink(executionID uint, kind model.ExecutionLinkKind, url, label string) (*model.ExecutionLink, error) {
+	link := model.ExecutionLink{
+		ExecutionID: executionID,
+		Kind:        kind,
+		URL:         url,
+		Label:       label,
+	}
+	if err := s.db.Create(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// Links returns every external reference attached to executionID, in the
+// order they were added.
+func (s *ExecutionService) Links(executionID uint) ([]model.ExecutionLink, error) {
+	var links []model.ExecutionLink
+	if err := s.db.Where("execution_id = ?", executionID).Order("id asc").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
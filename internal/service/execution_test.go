@@ -0,0 +1,540 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestResumeRerunsAFailedExecutionAgainstTheSameDAG(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	// The step fails the first time it runs and succeeds afterward,
+	// without its Config (and so its structural hash) ever changing, to
+	// isolate Resume's re-run behavior from its DAG-change detection.
+	sentinel := filepath.Join(t.TempDir(), "ran")
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{
+		TemplateID: tmpl.ID,
+		Name:       "a",
+		Type:       "shell",
+		Config:     fmt.Sprintf("test -f %s && exit 0 || (touch %s && exit 1)", sentinel, sentinel),
+	}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	if execution.Status != model.StatusFailed {
+		t.Fatalf("expected a failed execution, got %q", execution.Status)
+	}
+
+	resumed, err := executions.Resume(context.Background(), execution.ID)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if resumed.Status != model.StatusSucceeded {
+		t.Fatalf("expected the resumed execution to succeed, got %q", resumed.Status)
+	}
+}
+
+func TestStepAttemptsReturnsEveryAttemptOldestFirst(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	sentinel := filepath.Join(t.TempDir(), "ran")
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{
+		TemplateID: tmpl.ID,
+		Name:       "a",
+		Type:       "shell",
+		Config:     fmt.Sprintf("test -f %s && exit 0 || (touch %s && exit 1)", sentinel, sentinel),
+	}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the first run to fail")
+	}
+	if _, err := executions.Resume(context.Background(), execution.ID); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+
+	attempts, err := executions.StepAttempts(execution.ID, "a")
+	if err != nil {
+		t.Fatalf("StepAttempts: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(attempts))
+	}
+	if attempts[0].Attempt != 1 || attempts[0].Status != model.StatusFailed {
+		t.Fatalf("expected attempt 1 to be the failed run, got %+v", attempts[0])
+	}
+	if attempts[1].Attempt != 2 || attempts[1].Status != model.StatusSucceeded {
+		t.Fatalf("expected attempt 2 to be the succeeded run, got %+v", attempts[1])
+	}
+}
+
+func TestResumeRefusesWhenDAGChanged(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the step's exit 1 to fail the execution")
+	}
+
+	if err := gormDB.Model(&step).Update("config", "exit 0").Error; err != nil {
+		t.Fatalf("update step config: %v", err)
+	}
+
+	_, err = executions.Resume(context.Background(), execution.ID)
+	var dagErr *DAGChangedError
+	if !errors.As(err, &dagErr) {
+		t.Fatalf("expected a *DAGChangedError, got %v", err)
+	}
+}
+
+func TestResumeRejectsNonFailedExecution(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if _, err := executions.Resume(context.Background(), execution.ID); err == nil {
+		t.Fatal("expected resuming a succeeded execution to be rejected")
+	}
+}
+
+func TestStartRejectsParamsViolatingParamsSchema(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", ParamsSchema: `{"type": "object", "required": ["env"]}`}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	if _, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false); err == nil {
+		t.Fatal("expected params missing a required field to be rejected")
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.WorkflowExecution{}).Count(&count).Error; err != nil {
+		t.Fatalf("count executions: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no execution to be created, found %d", count)
+	}
+}
+
+func TestStartAcceptsParamsSatisfyingParamsSchema(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", ParamsSchema: `{"type": "object", "required": ["env"]}`}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, `{"env": "staging"}`, false)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if execution.Params != `{"env": "staging"}` {
+		t.Fatalf("expected the execution to record its params, got %q", execution.Params)
+	}
+}
+
+func TestStartRejectsDuringAnActiveBlackoutWindow(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	blackouts := NewBlackoutService(gormDB)
+	if err := blackouts.Create(&model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create blackout window: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, blackouts)
+	_, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	var blackoutErr *BlackoutError
+	if !errors.As(err, &blackoutErr) {
+		t.Fatalf("expected a BlackoutError, got %v", err)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.WorkflowExecution{}).Count(&count).Error; err != nil {
+		t.Fatalf("count executions: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no execution to be created, found %d", count)
+	}
+}
+
+func TestStartWithOverrideRunsDuringAnActiveBlackoutWindow(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	blackouts := NewBlackoutService(gormDB)
+	if err := blackouts.Create(&model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create blackout window: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, blackouts)
+	if _, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", true); err != nil {
+		t.Fatalf("expected an override to run despite the blackout window, got %v", err)
+	}
+}
+
+func TestStartWithChaosRejectsAnExecutionInsideAnActiveBlackoutWindow(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	blackouts := NewBlackoutService(gormDB)
+	if err := blackouts.Create(&model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   time.Now().Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("create blackout window: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, blackouts)
+	_, err := executions.StartWithChaos(context.Background(), tmpl.ID, 0, 0, "", executor.ChaosConfig{}, false)
+	var blackoutErr *BlackoutError
+	if !errors.As(err, &blackoutErr) {
+		t.Fatalf("expected a BlackoutError, got %v", err)
+	}
+
+	var count int64
+	if err := gormDB.Model(&model.WorkflowExecution{}).Count(&count).Error; err != nil {
+		t.Fatalf("count executions: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no execution to be created, found %d", count)
+	}
+}
+
+func TestBlackoutServiceBlockedReportsTheActiveWindowsRemainingTime(t *testing.T) {
+	gormDB := newTestDB(t)
+	blackouts := NewBlackoutService(gormDB)
+
+	if _, blocked := blackouts.Blocked("payments", 0); blocked {
+		t.Fatal("expected no window to block an unaffected project")
+	}
+
+	endsAt := time.Now().Add(time.Hour)
+	if err := blackouts.Create(&model.BlackoutWindow{
+		Project:  "payments",
+		StartsAt: time.Now().Add(-time.Hour),
+		EndsAt:   endsAt,
+	}); err != nil {
+		t.Fatalf("create blackout window: %v", err)
+	}
+
+	retryAfter, blocked := blackouts.Blocked("payments", 0)
+	if !blocked {
+		t.Fatal("expected the active window to block this project")
+	}
+	if retryAfter <= 0 || retryAfter > time.Hour {
+		t.Fatalf("expected retryAfter to be close to the window's remaining hour, got %s", retryAfter)
+	}
+}
+
+func TestStartReportsOutputSchemaViolationWithoutFailingTheExecution(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", OutputSchema: `{"type": "object", "properties": {"a": {"type": "integer"}}, "required": ["a"]}`}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "echo -n not-an-integer"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	var violation *OutputSchemaViolationError
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected an *OutputSchemaViolationError, got %v", err)
+	}
+	if execution.Status != model.StatusSucceeded {
+		t.Fatalf("expected the execution to remain succeeded, got %q", execution.Status)
+	}
+}
+
+func TestStartPassesOutputSchemaWhenStepOutputsSatisfyIt(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t", OutputSchema: `{"type": "object", "properties": {"a": {"type": "integer"}}, "required": ["a"]}`}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "echo -n 42"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	if _, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+}
+
+func TestStartWithChaosForcesStepFailuresAndRecordsChaosConfig(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	chaos := executor.ChaosConfig{StepFailureProbability: 1, Seed: 1}
+	execution, err := executions.StartWithChaos(context.Background(), tmpl.ID, 0, 0, "", chaos, false)
+	if err == nil {
+		t.Fatal("expected StartWithChaos to report the chaos-forced step failure")
+	}
+	if execution == nil {
+		t.Fatal("expected a non-nil execution even though its step failed")
+	}
+	if execution.ChaosConfig == "" {
+		t.Fatal("expected the execution to record its chaos config")
+	}
+}
+
+func TestReportIncludesStepsAndTemplateName(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "incident-response"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "echo -n done"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	rep, err := executions.Report(execution.ID)
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if rep.TemplateName != "incident-response" {
+		t.Fatalf("expected the template name, got %q", rep.TemplateName)
+	}
+	if len(rep.Steps) != 1 || rep.Steps[0].Output != "done" {
+		t.Fatalf("expected step a's output, got %+v", rep.Steps)
+	}
+}
+
+func TestExecutionServiceCompareDiffsStepsBetweenTwoExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	a := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusSucceeded}
+	if err := gormDB.Create(&a).Error; err != nil {
+		t.Fatalf("create execution a: %v", err)
+	}
+	if err := gormDB.Create(&model.StepExecution{ExecutionID: a.ID, StepName: "deploy", Status: model.StatusSucceeded, Output: "v1"}).Error; err != nil {
+		t.Fatalf("create step a: %v", err)
+	}
+
+	b := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusFailed}
+	if err := gormDB.Create(&b).Error; err != nil {
+		t.Fatalf("create execution b: %v", err)
+	}
+	if err := gormDB.Create(&model.StepExecution{ExecutionID: b.ID, StepName: "deploy", Status: model.StatusFailed, Output: ""}).Error; err != nil {
+		t.Fatalf("create step b: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, nil, nil, nil, nil)
+	diff, err := executions.Compare(a.ID, b.ID)
+	if err != nil {
+		t.Fatalf("Compare: %v", err)
+	}
+	if diff.FirstDivergence != "deploy" {
+		t.Errorf("FirstDivergence = %q, want %q", diff.FirstDivergence, "deploy")
+	}
+}
+
+func TestAddNoteAndNotesReturnsThemInOrder(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+
+	if _, err := executions.AddNote(1, "", "investigating", "op1"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	if _, err := executions.AddNote(1, "deploy", "manually restarted DB at 14:32", "op2"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+
+	notes, err := executions.Notes(1)
+	if err != nil {
+		t.Fatalf("Notes: %v", err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 notes, got %d", len(notes))
+	}
+	if notes[0].CreatedBy != "op1" || notes[1].StepName != "deploy" {
+		t.Fatalf("unexpected notes: %+v", notes)
+	}
+}
+
+func TestAddLinkAndLinksReturnsThemInOrder(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+
+	if _, err := executions.AddLink(1, model.LinkKindJira, "https://jira.example/INC-1", "INC-1"); err != nil {
+		t.Fatalf("AddLink: %v", err)
+	}
+	if _, err := executions.AddLink(1, model.LinkKindPagerDuty, "https://pagerduty.example/incidents/1", ""); err != nil {
+		t.Fatalf("AddLink: %v", err)
+	}
+
+	links, err := executions.Links(1)
+	if err != nil {
+		t.Fatalf("Links: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %d", len(links))
+	}
+	if links[0].Label != "INC-1" || links[1].Kind != model.LinkKindPagerDuty {
+		t.Fatalf("unexpected links: %+v", links)
+	}
+}
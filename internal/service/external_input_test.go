@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestExternalInputsReturnsRecordedInputsInOrder(t *testing.T) {
+	gormDB := newTestDB(t)
+	executions := NewExecutionService(gormDB, nil, nil, nil, nil)
+
+	execution := model.WorkflowExecution{Status: model.StatusFailed}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	other := model.WorkflowExecution{Status: model.StatusFailed}
+	if err := gormDB.Create(&other).Error; err != nil {
+		t.Fatalf("create other execution: %v", err)
+	}
+	rows := []model.ExternalInput{
+		{ExecutionID: execution.ID, StepName: "ping", Name: "response_body", Value: "first"},
+		{ExecutionID: execution.ID, StepName: "ping", Name: "response_body", Value: "second"},
+		{ExecutionID: other.ID, StepName: "ping", Name: "response_body", Value: "unrelated"},
+	}
+	for i := range rows {
+		if err := gormDB.Create(&rows[i]).Error; err != nil {
+			t.Fatalf("create external input: %v", err)
+		}
+	}
+
+	inputs, err := executions.ExternalInputs(execution.ID)
+	if err != nil {
+		t.Fatalf("ExternalInputs: %v", err)
+	}
+	if len(inputs) != 2 {
+		t.Fatalf("expected 2 external inputs, got %d", len(inputs))
+	}
+	if inputs[0].Value != "first" || inputs[1].Value != "second" {
+		t.Fatalf("unexpected order: %+v", inputs)
+	}
+}
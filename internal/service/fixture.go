@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/report"
+)
+
+// FixtureService manages TemplateFixtures: golden simulated executions
+// recorded for a WorkflowTemplate, and the regression test runs that
+// replay them against the template's current DAG.
+type FixtureService struct {
+	db         *gorm.DB
+	executions *ExecutionService
+}
+
+// NewFixtureService builds a FixtureService backed by db, running every
+// fixture's simulated executions through executions.
+func NewFixtureService(db *gorm.DB, executions *ExecutionService) *FixtureService {
+	return &FixtureService{db: db, executions: executions}
+}
+
+// FixtureTestResult is the outcome of replaying a TemplateFixture: the new
+// execution it produced, the Diff against its golden execution, and
+// whether the two agree.
+type FixtureTestResult struct {
+	Execution *model.WorkflowExecution `json:"execution"`
+	Diff      *report.Diff             `json:"diff"`
+	// Passed is true if Diff has no divergent step, i.e. the template
+	// still behaves the way the golden execution recorded.
+	Passed bool `json:"passed"`
+}
+
+// CreateFixture runs templateID in simulation mode with faults and records
+// the result as a new TemplateFixture's golden execution, so future calls
+// to TestRun have something to diff against.
+func (s *FixtureService) CreateFixture(ctx context.Context, templateID uint, name string, faults map[string]executor.Fault) (*model.TemplateFixture, error) {
+	encodedFaults, err := json.Marshal(faults)
+	if err != nil {
+		return nil, fmt.Errorf("encode faults: %w", err)
+	}
+	// StartSimulated returns a non-nil execution and a non-nil error
+	// whenever a step fails or is skipped, same as Run: that's expected
+	// (and often the whole point) for a fixture exercising a failure
+	// path, so only a nil execution here means something actually
+	// prevented the run (e.g. the template doesn't exist or is over
+	// quota).
+	golden, err := s.executions.StartSimulated(ctx, templateID, faults)
+	if golden == nil {
+		return nil, fmt.Errorf("run golden execution: %w", err)
+	}
+
+	fixture := &model.TemplateFixture{
+		TemplateID:        templateID,
+		Name:              name,
+		Faults:            string(encodedFaults),
+		GoldenExecutionID: golden.ID,
+	}
+	if err := s.db.Create(fixture).Error; err != nil {
+		return nil, err
+	}
+	return fixture, nil
+}
+
+// List returns every fixture recorded for templateID, oldest first.
+func (s *FixtureService) List(templateID uint) ([]model.TemplateFixture, error) {
+	var fixtures []model.TemplateFixture
+	if err := s.db.Where("template_id = ?", templateID).Order("id asc").Find(&fixtures).Error; err != nil {
+		return nil, err
+	}
+	return fixtures, nil
+}
+
+// TestRun replays fixtureID's Faults against its template's current DAG
+// in simulation mode and diffs the fresh execution against the fixture's
+// golden one, so a template edit that changes behavior is caught instead
+// of only discovered on the next real run.
+func (s *FixtureService) TestRun(ctx context.Context, fixtureID uint) (*FixtureTestResult, error) {
+	var fixture model.TemplateFixture
+	if err := s.db.First(&fixture, fixtureID).Error; err != nil {
+		return nil, fmt.Errorf("load fixture: %w", err)
+	}
+
+	var faults map[string]executor.Fault
+	if fixture.Faults != "" {
+		if err := json.Unmarshal([]byte(fixture.Faults), &faults); err != nil {
+			return nil, fmt.Errorf("decode faults: %w", err)
+		}
+	}
+
+	// As in CreateFixture, a non-nil execution with a non-nil error just
+	// means a step failed or was skipped, which the Diff below is meant
+	// to detect; only bail out if the run never happened at all.
+	fresh, err := s.executions.StartSimulated(ctx, fixture.TemplateID, faults)
+	if fresh == nil {
+		return nil, fmt.Errorf("run test execution: %w", err)
+	}
+
+	diff, err := s.executions.Compare(fixture.GoldenExecutionID, fresh.ID)
+	if err != nil {
+		return nil, fmt.Errorf("compare against golden execution: %w", err)
+	}
+
+	return &FixtureTestResult{Execution: fresh, Diff: diff, Passed: diff.FirstDivergence == ""}, nil
+}
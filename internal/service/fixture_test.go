@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestFixtureTestRunPassesWhenBehaviorMatchesTheGoldenExecution(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "true"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	fixtures := NewFixtureService(gormDB, executions)
+
+	faults := map[string]executor.Fault{"a": {Mode: executor.FaultForceFail}}
+	fixture, err := fixtures.CreateFixture(context.Background(), tmpl.ID, "force-fail", faults)
+	if err != nil {
+		t.Fatalf("CreateFixture: %v", err)
+	}
+	if fixture.GoldenExecutionID == 0 {
+		t.Fatal("expected CreateFixture to record a golden execution")
+	}
+
+	result, err := fixtures.TestRun(context.Background(), fixture.ID)
+	if err != nil {
+		t.Fatalf("TestRun: %v", err)
+	}
+	if !result.Passed {
+		t.Fatalf("expected TestRun to pass when the template hasn't changed, got diff %+v", result.Diff)
+	}
+}
+
+func TestFixtureTestRunFailsWhenTheTemplateStepChanges(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "true"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	fixtures := NewFixtureService(gormDB, executions)
+
+	fixture, err := fixtures.CreateFixture(context.Background(), tmpl.ID, "happy-path", nil)
+	if err != nil {
+		t.Fatalf("CreateFixture: %v", err)
+	}
+
+	if err := gormDB.Model(&step).Update("config", "false").Error; err != nil {
+		t.Fatalf("update step: %v", err)
+	}
+
+	result, err := fixtures.TestRun(context.Background(), fixture.ID)
+	if err != nil {
+		t.Fatalf("TestRun: %v", err)
+	}
+	if result.Passed {
+		t.Fatal("expected TestRun to fail once the step's behavior diverged from the golden execution")
+	}
+	if result.Diff.FirstDivergence != "a" {
+		t.Fatalf("expected the divergence to be reported on step a, got %q", result.Diff.FirstDivergence)
+	}
+}
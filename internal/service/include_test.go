@@ -0,0 +1,134 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestCreateWithIncludeImportsStepsUnderPrefix(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	source := &model.WorkflowTemplate{
+		Name: "provision-db",
+		Steps: []model.TemplateStep{
+			{Name: "create", Type: "shell", Config: "make create"},
+			{Name: "migrate", Type: "shell", Config: "make migrate", DependsOn: "create"},
+		},
+	}
+	if err := svc.Create(source, nil); err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+
+	tmpl := &model.WorkflowTemplate{
+		Name: "deploy",
+		Steps: []model.TemplateStep{
+			{Name: "build", Type: "shell", Config: "make build"},
+		},
+	}
+	includes := []TemplateInclude{{SourceTemplateID: source.ID, Prefix: "db"}}
+	if err := svc.Create(tmpl, includes); err != nil {
+		t.Fatalf("create with include: %v", err)
+	}
+
+	loaded, err := svc.Get(tmpl.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(loaded.Steps) != 3 {
+		t.Fatalf("expected 3 steps (1 own + 2 included), got %d", len(loaded.Steps))
+	}
+
+	var migrate *model.TemplateStep
+	for i := range loaded.Steps {
+		if loaded.Steps[i].Name == "db_migrate" {
+			migrate = &loaded.Steps[i]
+		}
+	}
+	if migrate == nil {
+		t.Fatalf("expected an included step named db_migrate, got %+v", loaded.Steps)
+	}
+	if migrate.DependsOn != "db_create" {
+		t.Fatalf("expected db_migrate's DependsOn to be remapped to db_create, got %q", migrate.DependsOn)
+	}
+	if migrate.IncludedFromTemplateID == nil || *migrate.IncludedFromTemplateID != source.ID {
+		t.Fatalf("expected db_migrate to record its source template, got %+v", migrate.IncludedFromTemplateID)
+	}
+}
+
+func TestCreateWithIncludeRejectsEmptyPrefix(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	source := &model.WorkflowTemplate{Name: "provision-db", Steps: []model.TemplateStep{{Name: "create", Type: "shell"}}}
+	if err := svc.Create(source, nil); err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+
+	tmpl := &model.WorkflowTemplate{Name: "deploy"}
+	err := svc.Create(tmpl, []TemplateInclude{{SourceTemplateID: source.ID, Prefix: ""}})
+	if err == nil {
+		t.Fatal("expected an error for an empty include prefix")
+	}
+}
+
+func TestCreateWithIncludeRejectsDuplicateSource(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	source := &model.WorkflowTemplate{Name: "provision-db", Steps: []model.TemplateStep{{Name: "create", Type: "shell"}}}
+	if err := svc.Create(source, nil); err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+
+	tmpl := &model.WorkflowTemplate{Name: "deploy"}
+	includes := []TemplateInclude{
+		{SourceTemplateID: source.ID, Prefix: "a"},
+		{SourceTemplateID: source.ID, Prefix: "b"},
+	}
+	err := svc.Create(tmpl, includes)
+	if err == nil || !strings.Contains(err.Error(), "included more than once") {
+		t.Fatalf("expected a duplicate-include error, got %v", err)
+	}
+}
+
+func TestCreateWithIncludeRejectsNameCollisionWithOwnSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	source := &model.WorkflowTemplate{
+		Name:  "provision-db",
+		Steps: []model.TemplateStep{{Name: "create", Type: "shell"}},
+	}
+	if err := svc.Create(source, nil); err != nil {
+		t.Fatalf("create source: %v", err)
+	}
+
+	tmpl := &model.WorkflowTemplate{
+		Name: "deploy",
+		Steps: []model.TemplateStep{
+			// Collides with the included step's renamed "db_create",
+			// which the combined-DAG validation should catch even
+			// though neither tmpl's own steps nor the source's steps
+			// are invalid on their own.
+			{Name: "db_create", Type: "shell"},
+		},
+	}
+	err := svc.Create(tmpl, []TemplateInclude{{SourceTemplateID: source.ID, Prefix: "db"}})
+	if err == nil {
+		t.Fatal("expected a name collision between an include and tmpl's own steps to be rejected")
+	}
+}
+
+func TestCreateWithIncludeOnMissingSourceFails(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	tmpl := &model.WorkflowTemplate{Name: "deploy"}
+	err := svc.Create(tmpl, []TemplateInclude{{SourceTemplateID: 999, Prefix: "db"}})
+	if err == nil {
+		t.Fatal("expected an error including a nonexistent template")
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// LabelService manages key/value labels attached to templates and
+// executions.
+type LabelService struct {
+	db *gorm.DB
+}
+
+// NewLabelService builds a LabelService backed by db.
+func NewLabelService(db *gorm.DB) *LabelService {
+	return &LabelService{db: db}
+}
+
+// Set replaces all labels on (ownerType, ownerID) with labels.
+func (s *LabelService) Set(ownerType model.LabelOwnerType, ownerID uint, labels map[string]string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Delete(&model.Label{}).Error; err != nil {
+			return err
+		}
+		if len(labels) == 0 {
+			return nil
+		}
+		rows := make([]*model.Label, 0, len(labels))
+		for k, v := range labels {
+			rows = append(rows, &model.Label{OwnerType: ownerType, OwnerID: ownerID, Key: k, Value: v})
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+}
+
+// Get returns the labels attached to (ownerType, ownerID) as a map.
+func (s *LabelService) Get(ownerType model.LabelOwnerType, ownerID uint) (map[string]string, error) {
+	var rows []model.Label
+	if err := s.db.Where("owner_type = ? AND owner_id = ?", ownerType, ownerID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(rows))
+	for _, r := range rows {
+		out[r.Key] = r.Value
+	}
+	return out, nil
+}
+
+// BulkSet applies labels to every ID in ownerIDs, in a single
+// transaction.
+func (s *LabelService) BulkSet(ownerType model.LabelOwnerType, ownerIDs []uint, labels map[string]string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, id := range ownerIDs {
+			if err := (&LabelService{db: tx}).Set(ownerType, id, labels); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// matchingOwnerIDs returns the owner IDs of ownerType that have every
+// key/value pair in selector, by intersecting one query per selector key.
+func matchingOwnerIDs(db *gorm.DB, ownerType model.LabelOwnerType, selector map[string]string) ([]uint, error) {
+	var ids []uint
+	first := true
+	for k, v := range selector {
+		var matched []uint
+		if err := db.Model(&model.Label{}).
+			Where("owner_type = ? AND key = ? AND value = ?", ownerType, k, v).
+			Pluck("owner_id", &matched).Error; err != nil {
+			return nil, err
+		}
+		if first {
+			ids = matched
+			first = false
+			continue
+		}
+		ids = intersect(ids, matched)
+	}
+	return ids, nil
+}
+
+func intersect(a, b []uint) []uint {
+	set := make(map[uint]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	var out []uint
+	for _, v := range b {
+		if set[v] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestLabelSetAndFilter(t *testing.T) {
+	gormDB := newTestDB(t)
+	prod := model.WorkflowExecution{Status: model.StatusSucceeded}
+	staging := model.WorkflowExecution{Status: model.StatusSucceeded}
+	if err := gormDB.Create(&prod).Error; err != nil {
+		t.Fatalf("create prod: %v", err)
+	}
+	if err := gormDB.Create(&staging).Error; err != nil {
+		t.Fatalf("create staging: %v", err)
+	}
+
+	labels := NewLabelService(gormDB)
+	if err := labels.Set(model.LabelOwnerExecution, prod.ID, map[string]string{"env": "prod", "service": "payments"}); err != nil {
+		t.Fatalf("Set prod: %v", err)
+	}
+	if err := labels.Set(model.LabelOwnerExecution, staging.ID, map[string]string{"env": "staging", "service": "payments"}); err != nil {
+		t.Fatalf("Set staging: %v", err)
+	}
+
+	got, err := labels.Get(model.LabelOwnerExecution, prod.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got["env"] != "prod" {
+		t.Fatalf("expected env=prod, got %+v", got)
+	}
+
+	execs := NewExecutionService(gormDB, nil, nil, nil, nil)
+	page, _, err := execs.ListPage(ListQuery{Labels: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != prod.ID {
+		t.Fatalf("expected only the prod execution, got %+v", page)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/render"
+)
+
+// layerSpacing and nodeSpacing control the spread of the default
+// layered (Sugiyama-style) auto-layout, in editor canvas units.
+const (
+	layerSpacing = 200.0
+	nodeSpacing  = 120.0
+)
+
+// LayoutService persists and computes node positions and edge routing
+// for a template's visual DAG editor.
+type LayoutService struct {
+	db *gorm.DB
+}
+
+// NewLayoutService builds a LayoutService backed by db.
+func NewLayoutService(db *gorm.DB) *LayoutService {
+	return &LayoutService{db: db}
+}
+
+// NodePosition is one step's canvas coordinates.
+type NodePosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// SavePositions persists the given step-name -> position mapping for
+// templateID.
+func (s *LayoutService) SavePositions(templateID uint, positions map[string]NodePosition) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for name, pos := range positions {
+			if err := tx.Model(&model.TemplateStep{}).
+				Where("template_id = ? AND name = ?", templateID, name).
+				Updates(map[string]any{"position_x": pos.X, "position_y": pos.Y}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SaveEdgeLayout replaces all persisted edge routing metadata for
+// templateID with edges.
+func (s *LayoutService) SaveEdgeLayout(templateID uint, edges []model.EdgeLayout) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", templateID).Delete(&model.EdgeLayout{}).Error; err != nil {
+			return err
+		}
+		if len(edges) == 0 {
+			return nil
+		}
+		rows := make([]*model.EdgeLayout, len(edges))
+		for i := range edges {
+			edges[i].TemplateID = templateID
+			rows[i] = &edges[i]
+		}
+		return tx.CreateInBatches(rows, 100).Error
+	})
+}
+
+// AutoLayout computes default layered coordinates for every step of
+// templateID (nodes with no remaining dependencies on the left, their
+// dependents to the right) and persists them, returning the computed
+// positions.
+func (s *LayoutService) AutoLayout(templateID uint) (map[string]NodePosition, error) {
+	var steps []model.TemplateStep
+	if err := s.db.Where("template_id = ?", templateID).Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	graph, err := dag.Build(steps)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := graph.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]NodePosition)
+	for layerIdx, layer := range layers {
+		for nodeIdx, node := range layer {
+			positions[node.Name] = NodePosition{
+				X: float64(layerIdx) * layerSpacing,
+				Y: float64(nodeIdx) * nodeSpacing,
+			}
+		}
+	}
+	if err := s.SavePositions(templateID, positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// graphAndPositions loads templateID's steps and builds both its DAG and
+// a Positions map, auto-computing layered coordinates for any step that
+// has never been explicitly positioned.
+func (s *LayoutService) graphAndPositions(templateID uint) (*dag.Graph, render.Positions, error) {
+	var steps []model.TemplateStep
+	if err := s.db.Where("template_id = ?", templateID).Find(&steps).Error; err != nil {
+		return nil, nil, err
+	}
+	graph, err := dag.Build(steps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions := make(render.Positions, len(steps))
+	needsAutoLayout := false
+	for _, step := range steps {
+		if step.PositionX == 0 && step.PositionY == 0 {
+			needsAutoLayout = true
+		}
+		positions[step.Name] = struct{ X, Y float64 }{step.PositionX, step.PositionY}
+	}
+	if needsAutoLayout {
+		layers, err := graph.Layers()
+		if err != nil {
+			return nil, nil, err
+		}
+		for layerIdx, layer := range layers {
+			for nodeIdx, node := range layer {
+				positions[node.Name] = struct{ X, Y float64 }{
+					X: float64(layerIdx) * layerSpacing,
+					Y: float64(nodeIdx) * nodeSpacing,
+				}
+			}
+		}
+	}
+	return graph, positions, nil
+}
+
+// ExportSVG renders templateID's DAG as an SVG document.
+func (s *LayoutService) ExportSVG(templateID uint) (string, error) {
+	graph, positions, err := s.graphAndPositions(templateID)
+	if err != nil {
+		return "", err
+	}
+	return render.SVG(graph, positions), nil
+}
+
+// ExportPNG renders templateID's DAG as a PNG image.
+func (s *LayoutService) ExportPNG(templateID uint) ([]byte, error) {
+	graph, positions, err := s.graphAndPositions(templateID)
+	if err != nil {
+		return nil, err
+	}
+	return render.PNG(graph, positions)
+}
+
+// ExportGraphviz renders templateID's DAG as a DOT document.
+func (s *LayoutService) ExportGraphviz(templateID uint) (string, error) {
+	var steps []model.TemplateStep
+	if err := s.db.Where("template_id = ?", templateID).Find(&steps).Error; err != nil {
+		return "", err
+	}
+	graph, err := dag.Build(steps)
+	if err != nil {
+		return "", err
+	}
+	return graph.ToGraphviz(), nil
+}
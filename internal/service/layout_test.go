@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestAutoLayoutPlacesDependentsInLaterLayers(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := &model.WorkflowTemplate{
+		Name: "t",
+		Steps: []model.TemplateStep{
+			{Name: "a", Type: "shell"},
+			{Name: "b", Type: "shell", DependsOn: "a"},
+		},
+	}
+	if err := NewTemplateService(gormDB, nil, nil, nil).Create(tmpl, nil); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	layout := NewLayoutService(gormDB)
+	positions, err := layout.AutoLayout(tmpl.ID)
+	if err != nil {
+		t.Fatalf("AutoLayout: %v", err)
+	}
+	if positions["a"].X >= positions["b"].X {
+		t.Fatalf("expected a to be left of b, got a=%v b=%v", positions["a"], positions["b"])
+	}
+
+	var persisted model.TemplateStep
+	if err := gormDB.Where("template_id = ? AND name = ?", tmpl.ID, "b").First(&persisted).Error; err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if persisted.PositionX != positions["b"].X {
+		t.Fatalf("expected position to be persisted, got %v", persisted.PositionX)
+	}
+}
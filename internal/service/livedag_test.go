@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestLiveDAGReflectsStepStatusesAfterARun(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "true"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "false", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the run to fail")
+	}
+
+	live, err := executions.LiveDAG(execution.ID)
+	if err != nil {
+		t.Fatalf("LiveDAG: %v", err)
+	}
+	if live.ExecutionID != execution.ID {
+		t.Fatalf("expected ExecutionID %d, got %d", execution.ID, live.ExecutionID)
+	}
+	if len(live.Layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(live.Layers))
+	}
+	if live.Layers[0][0].Name != "a" || live.Layers[0][0].Status != model.StatusSucceeded {
+		t.Fatalf("expected layer 0 = [a:succeeded], got %+v", live.Layers[0])
+	}
+	if live.Layers[1][0].Name != "b" || live.Layers[1][0].Status != model.StatusFailed {
+		t.Fatalf("expected layer 1 = [b:failed], got %+v", live.Layers[1])
+	}
+}
+
+func TestLiveDAGReportsPendingForStepsNotYetReached(t *testing.T) {
+	gormDB := newTestDB(t)
+	executions := NewExecutionService(gormDB, nil, nil, nil, nil)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	// Simulate an execution whose first layer has started running but
+	// whose second layer hasn't been reached yet, so "b" has no
+	// StepExecution row at all.
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	if err := gormDB.Create(&model.StepExecution{ExecutionID: execution.ID, StepName: "a", Status: model.StatusRunning}).Error; err != nil {
+		t.Fatalf("create step execution: %v", err)
+	}
+
+	live, err := executions.LiveDAG(execution.ID)
+	if err != nil {
+		t.Fatalf("LiveDAG: %v", err)
+	}
+	if live.Layers[0][0].Status != model.StatusRunning {
+		t.Fatalf("expected step a to report StatusRunning, got %q", live.Layers[0][0].Status)
+	}
+	if live.Layers[1][0].Status != model.StatusPending {
+		t.Fatalf("expected the unreached step to report StatusPending, got %q", live.Layers[1][0].Status)
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// LockEntry describes a held lock together with which provider it was
+// acquired against, for a caller aggregating across several providers.
+type LockEntry struct {
+	lock.Lock
+	Provider string `json:"provider"`
+}
+
+// LockMetrics reports a provider's cumulative contention stats by name.
+type LockMetrics struct {
+	Provider  string `json:"provider"`
+	Contended int64  `json:"contended"`
+	// WaitTimeSeconds sums how long LockWithWait callers spent blocked
+	// against this provider.
+	WaitTimeSeconds float64 `json:"wait_time_seconds"`
+}
+
+// LockService provides introspection and administration over one or
+// more named lock.Providers, recording an audit trail whenever a lock
+// is force-released.
+type LockService struct {
+	db        *gorm.DB
+	providers map[string]lock.Provider
+}
+
+// NewLockService builds a LockService backed by db, reporting on the
+// given named providers (e.g. "memory").
+func NewLockService(db *gorm.DB, providers map[string]lock.Provider) *LockService {
+	return &LockService{db: db, providers: providers}
+}
+
+// List returns every lock currently held across all registered providers.
+func (s *LockService) List(ctx context.Context) ([]LockEntry, error) {
+	var entries []LockEntry
+	for name, provider := range s.providers {
+		locks, err := provider.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list locks for provider %q: %w", name, err)
+		}
+		for _, l := range locks {
+			entries = append(entries, LockEntry{Lock: l, Provider: name})
+		}
+	}
+	return entries, nil
+}
+
+// Metrics returns cumulative contention stats for every registered
+// provider.
+func (s *LockService) Metrics() []LockMetrics {
+	metrics := make([]LockMetrics, 0, len(s.providers))
+	for name, provider := range s.providers {
+		m := provider.Metrics()
+		metrics = append(metrics, LockMetrics{Provider: name, Contended: m.Contended, WaitTimeSeconds: m.WaitTime.Seconds()})
+	}
+	return metrics
+}
+
+// ForceRelease releases key on the named provider regardless of its
+// current owner, and records a LockAuditEvent describing who asked for
+// it and why, so a stuck lock left behind by a crashed process can be
+// cleared without losing an audit trail of the intervention.
+func (s *LockService) ForceRelease(ctx context.Context, providerName, key, requestedBy, reason string) error {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return fmt.Errorf("unknown lock provider %q", providerName)
+	}
+	priorOwner, err := provider.ForceUnlock(ctx, key)
+	if err != nil {
+		return err
+	}
+	return s.db.Create(&model.LockAuditEvent{
+		Provider:    providerName,
+		Key:         key,
+		PriorOwner:  priorOwner,
+		RequestedBy: requestedBy,
+		Reason:      reason,
+	}).Error
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/lock"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestLockServiceListsLocksAcrossProviders(t *testing.T) {
+	gormDB := newTestDB(t)
+	memory := lock.NewMemoryLockProvider()
+	svc := NewLockService(gormDB, map[string]lock.Provider{"memory": memory})
+
+	if _, err := memory.TryLock(context.Background(), "k", "owner-1", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	entries, err := svc.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one held lock, got %d", len(entries))
+	}
+	if entries[0].Provider != "memory" || entries[0].Key != "k" || entries[0].Owner != "owner-1" {
+		t.Fatalf("unexpected entry: %+v", entries[0])
+	}
+}
+
+func TestLockServiceForceReleaseRecordsAuditEvent(t *testing.T) {
+	gormDB := newTestDB(t)
+	memory := lock.NewMemoryLockProvider()
+	svc := NewLockService(gormDB, map[string]lock.Provider{"memory": memory})
+
+	if _, err := memory.TryLock(context.Background(), "k", "owner-1", time.Minute); err != nil {
+		t.Fatalf("TryLock: %v", err)
+	}
+
+	if err := svc.ForceRelease(context.Background(), "memory", "k", "alice", "stuck after crash"); err != nil {
+		t.Fatalf("ForceRelease: %v", err)
+	}
+
+	// The lock is gone, and a new owner can now acquire it.
+	ok, err := memory.TryLock(context.Background(), "k", "owner-2", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("expected TryLock to succeed after force release, got ok=%v err=%v", ok, err)
+	}
+
+	var events []model.LockAuditEvent
+	if err := gormDB.Find(&events).Error; err != nil {
+		t.Fatalf("load audit events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one audit event, got %d", len(events))
+	}
+	if events[0].PriorOwner != "owner-1" || events[0].RequestedBy != "alice" || events[0].Reason != "stuck after crash" {
+		t.Fatalf("unexpected audit event: %+v", events[0])
+	}
+}
+
+func TestLockServiceForceReleaseRejectsUnknownProvider(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewLockService(gormDB, map[string]lock.Provider{})
+	if err := svc.ForceRelease(context.Background(), "mysql", "k", "alice", "reason"); err == nil {
+		t.Fatal("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// allowedSortFields whitelists the columns list endpoints may sort by, to
+// avoid building queries out of arbitrary client-supplied column names.
+var allowedSortFields = map[string]bool{
+	"created_at": true,
+	"id":         true,
+}
+
+// ListQuery describes a single page of a cursor-paginated list request.
+type ListQuery struct {
+	Limit  int    // page size; defaults to 20, capped at 200
+	Cursor string // opaque cursor returned by a previous page, empty for the first page
+	Sort   string // column to sort by, one of allowedSortFields; defaults to "created_at"
+	Desc   bool   // sort descending (most recent first) when true
+
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	// Labels, when non-empty, restricts results to rows whose labels
+	// contain every key/value pair given here.
+	Labels map[string]string
+
+	// Maintainer, when non-empty, restricts results to rows whose
+	// Maintainers column contains this value as an exact comma-separated
+	// entry. Only TemplateService.ListPage honors this; it's not a
+	// generic concept applyListQuery enforces, since WorkflowTemplate is
+	// the only model with a Maintainers column.
+	Maintainer string
+}
+
+// cursor identifies the last row of a page by its sort value and ID, so
+// the next page can resume with a WHERE clause instead of an OFFSET.
+type cursor struct {
+	SortValue string
+	ID        uint
+}
+
+func encodeCursor(c cursor) string {
+	raw := fmt.Sprintf("%s:%d", c.SortValue, c.ID)
+	return base64.URLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (cursor, error) {
+	raw, err := base64.URLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return cursor{}, fmt.Errorf("invalid cursor")
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return cursor{SortValue: parts[0], ID: uint(id)}, nil
+}
+
+// normalize fills in defaults and validates the sort field.
+func (q *ListQuery) normalize() error {
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+	if q.Limit > 200 {
+		q.Limit = 200
+	}
+	if q.Sort == "" {
+		q.Sort = "created_at"
+	}
+	if !allowedSortFields[q.Sort] {
+		return fmt.Errorf("unsupported sort field %q", q.Sort)
+	}
+	return nil
+}
+
+// applyListQuery adds the keyset WHERE clause, range filters, ordering and
+// limit for q to db, and returns the resulting query. Rows are always
+// ordered by (Sort, id) so ties on the sort column still yield a stable,
+// resumable order.
+func applyListQuery(db *gorm.DB, q ListQuery, ownerType model.LabelOwnerType) (*gorm.DB, error) {
+	if err := q.normalize(); err != nil {
+		return nil, err
+	}
+
+	if len(q.Labels) > 0 {
+		ids, err := matchingOwnerIDs(db.Session(&gorm.Session{NewDB: true}), ownerType, q.Labels)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			ids = []uint{0} // no matches: force an empty result set
+		}
+		db = db.Where("id IN ?", ids)
+	}
+
+	dir := "asc"
+	cmp := ">"
+	if q.Desc {
+		dir = "desc"
+		cmp = "<"
+	}
+	db = db.Order(fmt.Sprintf("%s %s, id %s", q.Sort, dir, dir))
+
+	if q.CreatedAfter != nil {
+		db = db.Where("created_at >= ?", *q.CreatedAfter)
+	}
+	if q.CreatedBefore != nil {
+		db = db.Where("created_at <= ?", *q.CreatedBefore)
+	}
+
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(
+			fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", q.Sort, cmp, q.Sort, cmp),
+			c.SortValue, c.SortValue, c.ID,
+		)
+	}
+
+	return db.Limit(q.Limit), nil
+}
+
+// sortValueOf extracts the string form of row's sort column value, for
+// building the cursor to the next page.
+func sortValueOf(sort string, id uint, createdAt time.Time) cursor {
+	switch sort {
+	case "id":
+		return cursor{SortValue: strconv.FormatUint(uint64(id), 10), ID: id}
+	default:
+		return cursor{SortValue: createdAt.UTC().Format(time.RFC3339Nano), ID: id}
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestTemplateListPagePaginatesByCursor(t *testing.T) {
+	gormDB := newTestDB(t)
+	for i := 0; i < 25; i++ {
+		if err := gormDB.Create(&model.WorkflowTemplate{Name: "t"}).Error; err != nil {
+			t.Fatalf("create template: %v", err)
+		}
+	}
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	seen := map[uint]bool{}
+	cursorVal := ""
+	for {
+		page, next, err := svc.ListPage(ListQuery{Limit: 10, Cursor: cursorVal, Sort: "id"})
+		if err != nil {
+			t.Fatalf("ListPage: %v", err)
+		}
+		for _, tmpl := range page {
+			if seen[tmpl.ID] {
+				t.Fatalf("template %d returned twice across pages", tmpl.ID)
+			}
+			seen[tmpl.ID] = true
+		}
+		if next == "" {
+			break
+		}
+		cursorVal = next
+	}
+	if len(seen) != 25 {
+		t.Fatalf("expected to see all 25 templates across pages, saw %d", len(seen))
+	}
+}
+
+func TestTemplateListPageRejectsUnknownSortField(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+	if _, _, err := svc.ListPage(ListQuery{Sort: "name"}); err == nil {
+		t.Fatal("expected error for unsupported sort field")
+	}
+}
+
+func TestTemplateListPageFiltersByMaintainer(t *testing.T) {
+	gormDB := newTestDB(t)
+	if err := gormDB.Create(&model.WorkflowTemplate{Name: "owned", Maintainers: "alice,bob"}).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	if err := gormDB.Create(&model.WorkflowTemplate{Name: "other", Maintainers: "carol"}).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	page, _, err := svc.ListPage(ListQuery{Maintainer: "bob"})
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	if len(page) != 1 || page[0].Name != "owned" {
+		t.Fatalf("expected only the template maintained by bob, got %+v", page)
+	}
+}
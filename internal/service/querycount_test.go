@@ -0,0 +1,26 @@
+package service
+
+import (
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// countingDB wraps db with a GORM callback that counts every query
+// issued, so tests can assert list endpoints don't regress into N+1
+// query patterns as page size grows.
+type queryCounter struct {
+	n atomic.Int64
+}
+
+func attachQueryCounter(db *gorm.DB) *queryCounter {
+	qc := &queryCounter{}
+	db.Callback().Query().After("gorm:query").Register("query_counter", func(*gorm.DB) {
+		qc.n.Add(1)
+	})
+	return qc
+}
+
+func (qc *queryCounter) count() int64 {
+	return qc.n.Load()
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// QuotaExceededError reports which quota dimension a project has
+// exhausted. The API layer maps it to a 429 response.
+type QuotaExceededError struct {
+	Project string
+	Reason  string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("quota exceeded for project %q: %s", e.Project, e.Reason)
+}
+
+// QuotaUsage reports a project's current consumption against its Quota.
+type QuotaUsage struct {
+	Project                 string  `json:"project"`
+	ConcurrentExecutions    int     `json:"concurrent_executions"`
+	ExecutionsStartedToday  int     `json:"executions_started_today"`
+	StepRuntimeSecondsToday float64 `json:"step_runtime_seconds_today"`
+}
+
+// QuotaService enforces and reports per-project execution quotas.
+type QuotaService struct {
+	db *gorm.DB
+
+	mu       sync.Mutex
+	reserved map[string]*sync.Mutex
+}
+
+// NewQuotaService builds a QuotaService backed by db.
+func NewQuotaService(db *gorm.DB) *QuotaService {
+	return &QuotaService{db: db, reserved: make(map[string]*sync.Mutex)}
+}
+
+// projectLock returns the mutex Reserve serializes project's
+// check-then-act quota window on, creating one on first use.
+func (s *QuotaService) projectLock(project string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.reserved[project]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.reserved[project] = lock
+	}
+	return lock
+}
+
+// Set upserts the quota limits for project.
+func (s *QuotaService) Set(project string, quota model.Quota) error {
+	quota.Project = project
+	return s.db.Where("project = ?", project).Assign(quota).FirstOrCreate(&model.Quota{}).Error
+}
+
+// Get returns the configured quota for project, or nil if none is set.
+func (s *QuotaService) Get(project string) (*model.Quota, error) {
+	var quota model.Quota
+	err := s.db.Where("project = ?", project).First(&quota).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// Usage reports project's current consumption against each quota
+// dimension, independent of whether a Quota is configured for it.
+func (s *QuotaService) Usage(project string) (QuotaUsage, error) {
+	usage := QuotaUsage{Project: project}
+
+	var concurrent int64
+	if err := s.executionsForProject(project).
+		Where("status = ?", model.StatusRunning).
+		Count(&concurrent).Error; err != nil {
+		return usage, err
+	}
+	usage.ConcurrentExecutions = int(concurrent)
+
+	since := time.Now().Add(-24 * time.Hour)
+	var startedToday int64
+	if err := s.executionsForProject(project).
+		Where("workflow_executions.created_at >= ?", since).
+		Count(&startedToday).Error; err != nil {
+		return usage, err
+	}
+	usage.ExecutionsStartedToday = int(startedToday)
+
+	var runtimeSeconds float64
+	row := s.db.Model(&model.StepExecution{}).
+		Joins("JOIN workflow_executions ON workflow_executions.id = step_executions.execution_id").
+		Joins("JOIN workflow_templates ON workflow_templates.id = workflow_executions.template_id").
+		Where("workflow_templates.project = ? AND step_executions.started_at >= ? AND step_executions.finished_at IS NOT NULL", project, since).
+		Select("COALESCE(SUM(strftime('%s', step_executions.finished_at) - strftime('%s', step_executions.started_at)), 0)").
+		Row()
+	if err := row.Scan(&runtimeSeconds); err != nil {
+		return usage, err
+	}
+	usage.StepRuntimeSecondsToday = runtimeSeconds
+
+	return usage, nil
+}
+
+// Check returns a *QuotaExceededError if starting one more execution for
+// project would violate its configured quota. A project with no Quota
+// configured is unlimited.
+func (s *QuotaService) Check(project string) error {
+	if project == "" {
+		return nil
+	}
+	quota, err := s.Get(project)
+	if err != nil {
+		return err
+	}
+	if quota == nil {
+		return nil
+	}
+
+	usage, err := s.Usage(project)
+	if err != nil {
+		return err
+	}
+
+	if quota.MaxConcurrentExecutions > 0 && usage.ConcurrentExecutions >= quota.MaxConcurrentExecutions {
+		return &QuotaExceededError{Project: project, Reason: "max concurrent executions reached"}
+	}
+	if quota.MaxExecutionsPerDay > 0 && usage.ExecutionsStartedToday >= quota.MaxExecutionsPerDay {
+		return &QuotaExceededError{Project: project, Reason: "max executions per day reached"}
+	}
+	if quota.MaxStepRuntimeSecondsPerDay > 0 && usage.StepRuntimeSecondsToday >= float64(quota.MaxStepRuntimeSecondsPerDay) {
+		return &QuotaExceededError{Project: project, Reason: "max step runtime per day reached"}
+	}
+	return nil
+}
+
+// Reserve blocks until it's the only caller checking project's quota,
+// runs Check against it, and, if project is within quota, returns a
+// release func the caller must call once it has either recorded the
+// execution the check was for or decided not to start it (safe to call
+// more than once). Holding the lock across both steps closes the race
+// where two concurrent callers both observe project under its
+// MaxConcurrentExecutions/MaxExecutionsPerDay limit before either has
+// recorded its own execution, letting them collectively exceed it; a
+// plain Check call racing the same way would not.
+func (s *QuotaService) Reserve(project string) (release func(), err error) {
+	lock := s.projectLock(project)
+	lock.Lock()
+	if err := s.Check(project); err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	var once sync.Once
+	return func() { once.Do(lock.Unlock) }, nil
+}
+
+func (s *QuotaService) executionsForProject(project string) *gorm.DB {
+	return s.db.Model(&model.WorkflowExecution{}).
+		Joins("JOIN workflow_templates ON workflow_templates.id = workflow_executions.template_id").
+		Where("workflow_templates.project = ?", project)
+}
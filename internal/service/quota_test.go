@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestQuotaCheckAllowsUnconfiguredProject(t *testing.T) {
+	gormDB := newTestDB(t)
+	quotas := NewQuotaService(gormDB)
+	if err := quotas.Check("unlimited"); err != nil {
+		t.Fatalf("expected no quota to mean unlimited, got %v", err)
+	}
+}
+
+func TestQuotaCheckEnforcesMaxConcurrentExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	running := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}
+	if err := gormDB.Create(&running).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	quotas := NewQuotaService(gormDB)
+	if err := quotas.Set("payments", model.Quota{MaxConcurrentExecutions: 1}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	err := quotas.Check("payments")
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expected a QuotaExceededError, got %v", err)
+	}
+}
+
+func TestQuotaUsageReportsConcurrentExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	for _, status := range []model.ExecutionStatus{model.StatusRunning, model.StatusSucceeded} {
+		execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: status}
+		if err := gormDB.Create(&execution).Error; err != nil {
+			t.Fatalf("create execution: %v", err)
+		}
+	}
+
+	quotas := NewQuotaService(gormDB)
+	usage, err := quotas.Usage("payments")
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.ConcurrentExecutions != 1 {
+		t.Fatalf("expected 1 concurrent execution, got %d", usage.ConcurrentExecutions)
+	}
+	if usage.ExecutionsStartedToday != 2 {
+		t.Fatalf("expected 2 executions started today, got %d", usage.ExecutionsStartedToday)
+	}
+}
+
+// TestQuotaReserveSerializesConcurrentChecksForTheSameProject reproduces
+// the race a plain Check call is exposed to: two callers racing to start
+// an execution for a project at MaxConcurrentExecutions-1 would both
+// observe it under the limit if they checked independently, then both
+// create an execution, together exceeding it. Reserve holds each
+// caller's check-then-act window exclusively per project, so the second
+// caller's Check only runs (and fails) after the first has recorded its
+// execution.
+func TestQuotaReserveSerializesConcurrentChecksForTheSameProject(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Project: "payments"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+
+	quotas := NewQuotaService(gormDB)
+	if err := quotas.Set("payments", model.Quota{MaxConcurrentExecutions: 1}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	start := func() error {
+		release, err := quotas.Reserve("payments")
+		if err != nil {
+			return err
+		}
+		defer release()
+		// Hold the reservation open briefly so a racing caller not
+		// serialized behind it would reach its own Check while this
+		// one's execution hasn't been recorded yet.
+		time.Sleep(20 * time.Millisecond)
+		return gormDB.Create(&model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}).Error
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = start()
+		}(i)
+	}
+	wg.Wait()
+
+	var quotaErrs, successes int
+	for _, err := range errs {
+		var quotaErr *QuotaExceededError
+		switch {
+		case errors.As(err, &quotaErr):
+			quotaErrs++
+		case err == nil:
+			successes++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if successes != 1 || quotaErrs != 1 {
+		t.Fatalf("expected exactly one success and one QuotaExceededError, got %d successes and %d quota errors", successes, quotaErrs)
+	}
+}
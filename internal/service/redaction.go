@@ -0,0 +1,66 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/logging"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/redact"
+)
+
+var redactionLog = logging.New("service")
+
+// RedactionService manages per-project model.RedactionRules and
+// implements executor.Redactor, so a TaskOrchestrator can scrub secrets
+// from step output, event messages and notifications as they're
+// produced, using whichever rules apply to an execution's project.
+type RedactionService struct {
+	db *gorm.DB
+}
+
+// NewRedactionService builds a RedactionService backed by db.
+func NewRedactionService(db *gorm.DB) *RedactionService {
+	return &RedactionService{db: db}
+}
+
+// CreateRule persists rule, rejecting it outright if its pattern doesn't
+// compile, so a typo is caught at authoring time rather than silently
+// failing to redact every execution that hits it afterward.
+func (s *RedactionService) CreateRule(rule *model.RedactionRule) error {
+	if _, err := redact.Build([]model.RedactionRule{*rule}); err != nil {
+		return err
+	}
+	return s.db.Create(rule).Error
+}
+
+// Rules returns every RedactionRule scoped to project plus every
+// global rule (empty Project), ordered by ID so rules apply in the
+// order they were created.
+func (s *RedactionService) Rules(project string) ([]model.RedactionRule, error) {
+	var rules []model.RedactionRule
+	if err := s.db.Where("project = ? OR project = ''", project).Order("id asc").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Redact scrubs text using project's rules, implementing
+// executor.Redactor. A rule that fails to compile (e.g. one edited
+// directly in the database since CreateRule checked it) is logged and
+// skipped rather than failing the step it's redacting.
+func (s *RedactionService) Redact(project, text string) string {
+	rules, err := s.Rules(project)
+	if err != nil {
+		redactionLog.Errorf("load rules for project %q: %v", project, err)
+		return text
+	}
+	if len(rules) == 0 {
+		return text
+	}
+	ruleset, err := redact.Build(rules)
+	if err != nil {
+		redactionLog.Errorf("project %q: %v", project, err)
+		return text
+	}
+	return ruleset.Scrub(text)
+}
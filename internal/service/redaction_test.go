@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestCreateRuleRejectsAnInvalidPattern(t *testing.T) {
+	gormDB := newTestDB(t)
+	redactions := NewRedactionService(gormDB)
+
+	if err := redactions.CreateRule(&model.RedactionRule{Project: "payments", Pattern: "(unterminated"}); err == nil {
+		t.Fatal("expected CreateRule to reject an invalid regex")
+	}
+}
+
+func TestRulesCombinesProjectAndGlobalRules(t *testing.T) {
+	gormDB := newTestDB(t)
+	redactions := NewRedactionService(gormDB)
+
+	if err := redactions.CreateRule(&model.RedactionRule{Pattern: `TOKEN=\S+`}); err != nil {
+		t.Fatalf("create global rule: %v", err)
+	}
+	if err := redactions.CreateRule(&model.RedactionRule{Project: "payments", Pattern: `card_\d+`}); err != nil {
+		t.Fatalf("create project rule: %v", err)
+	}
+	if err := redactions.CreateRule(&model.RedactionRule{Project: "other", Pattern: `unrelated`}); err != nil {
+		t.Fatalf("create other project's rule: %v", err)
+	}
+
+	rules, err := redactions.Rules("payments")
+	if err != nil {
+		t.Fatalf("Rules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules (1 global + 1 payments), got %d", len(rules))
+	}
+}
+
+func TestRedactScrubsTextUsingTheProjectsRules(t *testing.T) {
+	gormDB := newTestDB(t)
+	redactions := NewRedactionService(gormDB)
+	if err := redactions.CreateRule(&model.RedactionRule{Project: "payments", Pattern: `TOKEN=\S+`}); err != nil {
+		t.Fatalf("create rule: %v", err)
+	}
+
+	got := redactions.Redact("payments", "export TOKEN=abc123")
+	if got != "export [redacted]" {
+		t.Fatalf("unexpected redaction result: %q", got)
+	}
+
+	got = redactions.Redact("other-project", "export TOKEN=abc123")
+	if got != "export TOKEN=abc123" {
+		t.Fatalf("expected a project with no matching rules to pass text through unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,27 @@
+package service
+
+import "github.com/fengmingli/orchestrator/internal/executor"
+
+// RegistryService reports which step types the orchestrator's Registry
+// knows how to run, for an admin API that wants to show operators what
+// a deployment can execute without reading cmd/server/main.go's
+// registration calls. Registry.Disable is deployment configuration (see
+// ORCHESTRATOR_DISABLED_STEP_TYPES), not something this service exposes
+// a write path for, so a running deployment can't be talked into
+// re-enabling a step type (e.g. shell) that was deliberately turned off
+// for it.
+type RegistryService struct {
+	registry *executor.Registry
+}
+
+// NewRegistryService builds a RegistryService reporting on registry.
+func NewRegistryService(registry *executor.Registry) *RegistryService {
+	return &RegistryService{registry: registry}
+}
+
+// Types lists every step type the Registry knows how to run, in name
+// order, together with whether it's currently enabled and its config
+// schema, if it has one.
+func (s *RegistryService) Types() []executor.RegisteredType {
+	return s.registry.Types()
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+)
+
+func TestRegistryServiceTypesReflectsRegistryState(t *testing.T) {
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	registry.Disable("shell")
+
+	stepTypes := NewRegistryService(registry)
+	types := stepTypes.Types()
+	if len(types) != 1 || types[0].Name != "shell" || types[0].Enabled {
+		t.Fatalf("got %+v, want shell reported as disabled", types)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// SearchResultType identifies what kind of record a SearchResult points
+// at, so API clients can render or link to it appropriately.
+type SearchResultType string
+
+const (
+	SearchResultTemplate  SearchResultType = "template"
+	SearchResultStep      SearchResultType = "step"
+	SearchResultExecution SearchResultType = "execution"
+)
+
+// SearchResult is one match returned by SearchService.Search.
+type SearchResult struct {
+	Type    SearchResultType `json:"type"`
+	ID      uint             `json:"id"`
+	Title   string           `json:"title"`
+	Snippet string           `json:"snippet"`
+}
+
+// SearchService performs a simple substring search across templates,
+// steps and execution errors. It's backed by plain LIKE queries against
+// indexed text columns rather than a dedicated FTS engine, which is
+// enough for the catalog sizes this runs against; an in-memory index can
+// be layered on top later if that stops being true.
+type SearchService struct {
+	db *gorm.DB
+}
+
+// NewSearchService builds a SearchService backed by db.
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Search returns up to limit matches of q across template names/
+// descriptions, step names/scripts/URLs (stored in TemplateStep.Config),
+// and execution error messages.
+func (s *SearchService) Search(q string, limit int) ([]SearchResult, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	like := "%" + q + "%"
+	var results []SearchResult
+
+	var tmpls []model.WorkflowTemplate
+	if err := s.db.Where("name LIKE ? OR description LIKE ?", like, like).Limit(limit).Find(&tmpls).Error; err != nil {
+		return nil, err
+	}
+	for _, t := range tmpls {
+		results = append(results, SearchResult{Type: SearchResultTemplate, ID: t.ID, Title: t.Name, Snippet: t.Description})
+	}
+
+	var steps []model.TemplateStep
+	if err := s.db.Where("name LIKE ? OR config LIKE ?", like, like).Limit(limit).Find(&steps).Error; err != nil {
+		return nil, err
+	}
+	for _, st := range steps {
+		results = append(results, SearchResult{Type: SearchResultStep, ID: st.ID, Title: st.Name, Snippet: st.Config})
+	}
+
+	var executions []model.WorkflowExecution
+	if err := s.db.Where("error LIKE ?", like).Limit(limit).Find(&executions).Error; err != nil {
+		return nil, err
+	}
+	for _, e := range executions {
+		results = append(results, SearchResult{Type: SearchResultExecution, ID: e.ID, Title: string(e.Status), Snippet: e.Error})
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
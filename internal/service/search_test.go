@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestSearchMatchesAcrossTemplatesStepsAndExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "deploy-payments", Description: "deploys the payments service"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "run-payments-migration", Type: "shell", Config: "echo payments"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusFailed, Error: "payments gateway timeout"}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	svc := NewSearchService(gormDB)
+	results, err := svc.Search("payments", 0)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	var sawTemplate, sawStep, sawExecution bool
+	for _, r := range results {
+		switch r.Type {
+		case SearchResultTemplate:
+			sawTemplate = true
+		case SearchResultStep:
+			sawStep = true
+		case SearchResultExecution:
+			sawExecution = true
+		}
+	}
+	if !sawTemplate || !sawStep || !sawExecution {
+		t.Fatalf("expected matches across all three types, got %+v", results)
+	}
+}
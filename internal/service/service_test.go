@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func newTestDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := gormDB.AutoMigrate(
+		&model.WorkflowTemplate{},
+		&model.TemplateStep{},
+		&model.WorkflowExecution{},
+		&model.StepExecution{},
+		&model.Label{},
+		&model.Quota{},
+		&model.Artifact{},
+		&model.LockAuditEvent{},
+		&model.Agent{},
+		&model.ExternalInput{},
+		&model.AlertRoute{},
+		&model.AlertExecution{},
+		&model.RedactionRule{},
+		&model.TemplateOwnershipEvent{},
+		&model.ExecutionNote{},
+		&model.ExecutionLink{},
+		&model.TemplateFixture{},
+		&model.ExecutionEvent{},
+		&model.BlackoutWindow{},
+	); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return gormDB
+}
+
+func TestTemplateListUsesConstantQueryCount(t *testing.T) {
+	gormDB := newTestDB(t)
+	for i := 0; i < 100; i++ {
+		tmpl := model.WorkflowTemplate{Name: "t"}
+		if err := gormDB.Create(&tmpl).Error; err != nil {
+			t.Fatalf("create template: %v", err)
+		}
+		step := model.TemplateStep{TemplateID: tmpl.ID, Name: "s", Type: "shell"}
+		if err := gormDB.Create(&step).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	qc := attachQueryCounter(gormDB)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+	tmpls, err := svc.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(tmpls) != 100 {
+		t.Fatalf("expected 100 templates, got %d", len(tmpls))
+	}
+	for _, tmpl := range tmpls {
+		if len(tmpl.Steps) != 1 {
+			t.Fatalf("expected template %d to have 1 step eager-loaded, got %d", tmpl.ID, len(tmpl.Steps))
+		}
+	}
+	if got := qc.count(); got > 2 {
+		t.Fatalf("expected at most 2 queries to list 100 templates with steps, got %d", got)
+	}
+}
+
+func BenchmarkTemplateListWith100Items(b *testing.B) {
+	gormDB := newTestDB(b)
+	for i := 0; i < 100; i++ {
+		tmpl := model.WorkflowTemplate{Name: "t"}
+		gormDB.Create(&tmpl)
+		gormDB.Create(&model.TemplateStep{TemplateID: tmpl.ID, Name: "s", Type: "shell"})
+	}
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := svc.List(); err != nil {
+			b.Fatalf("List: %v", err)
+		}
+	}
+}
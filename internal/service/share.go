@@ -0,0 +1,89 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// ErrShareTokenInvalid is returned by ExecutionIDForToken for a token
+// that's malformed or fails signature verification.
+var ErrShareTokenInvalid = errors.New("share token invalid")
+
+// ErrShareTokenExpired is returned by ExecutionIDForToken for a
+// well-formed, correctly signed token whose expiry has passed.
+var ErrShareTokenExpired = errors.New("share token expired")
+
+// ShareService issues and verifies signed, expiring tokens that scope
+// the bearer to read-only access to a single execution, so an on-call
+// engineer can share a live view with a stakeholder who has no account
+// on the orchestrator. Tokens are stateless (HMAC-signed, nothing
+// persisted), so there's no way to revoke one before it expires, and
+// rotating Secret invalidates every outstanding link at once.
+type ShareService struct {
+	db     *gorm.DB
+	secret []byte
+}
+
+// NewShareService builds a ShareService backed by db, signing tokens
+// with secret. secret should be stable across restarts (see
+// cmd/server/main.go), or every link minted before a restart stops
+// verifying.
+func NewShareService(db *gorm.DB, secret []byte) *ShareService {
+	return &ShareService{db: db, secret: secret}
+}
+
+// CreateLink issues a token scoping the bearer to read-only access to
+// executionID until ttl from now. It fails if executionID doesn't
+// exist, so a link can't be minted for an execution that will 404
+// anyway.
+func (s *ShareService) CreateLink(executionID uint, ttl time.Duration) (token string, expiresAt time.Time, err error) {
+	if err := s.db.Select("id").First(&model.WorkflowExecution{}, executionID).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("load execution %d: %w", executionID, err)
+	}
+	expiresAt = time.Now().Add(ttl)
+	payload := fmt.Sprintf("%d.%d", executionID, expiresAt.Unix())
+	return payload + "." + s.sign(payload), expiresAt, nil
+}
+
+// ExecutionIDForToken verifies token's signature and expiry and
+// returns the execution ID it scopes access to.
+func (s *ShareService) ExecutionIDForToken(token string) (uint, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrShareTokenInvalid
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(payload))) {
+		return 0, ErrShareTokenInvalid
+	}
+
+	executionID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, ErrShareTokenInvalid
+	}
+	expiresAtUnix, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, ErrShareTokenInvalid
+	}
+	if time.Now().Unix() > expiresAtUnix {
+		return 0, ErrShareTokenExpired
+	}
+
+	return uint(executionID), nil
+}
+
+func (s *ShareService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
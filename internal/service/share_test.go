@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestShareCreateLinkAndResolve(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	shares := NewShareService(gormDB, []byte("secret"))
+	token, expiresAt, err := shares.CreateLink(execution.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	if expiresAt.Before(time.Now()) {
+		t.Fatalf("expected expiresAt in the future, got %s", expiresAt)
+	}
+
+	resolved, err := shares.ExecutionIDForToken(token)
+	if err != nil {
+		t.Fatalf("ExecutionIDForToken: %v", err)
+	}
+	if resolved != execution.ID {
+		t.Fatalf("expected token to resolve to execution %d, got %d", execution.ID, resolved)
+	}
+}
+
+func TestShareCreateLinkRejectsMissingExecution(t *testing.T) {
+	gormDB := newTestDB(t)
+	shares := NewShareService(gormDB, []byte("secret"))
+	if _, _, err := shares.CreateLink(999, time.Hour); err == nil {
+		t.Fatal("expected an error minting a link for a nonexistent execution")
+	}
+}
+
+func TestShareExecutionIDForTokenRejectsTamperedToken(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	shares := NewShareService(gormDB, []byte("secret"))
+	token, _, err := shares.CreateLink(execution.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+
+	otherShares := NewShareService(gormDB, []byte("a different secret"))
+	if _, err := otherShares.ExecutionIDForToken(token); !errors.Is(err, ErrShareTokenInvalid) {
+		t.Fatalf("expected ErrShareTokenInvalid for a token signed with a different secret, got %v", err)
+	}
+}
+
+func TestShareExecutionIDForTokenRejectsExpiredToken(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusRunning}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+
+	shares := NewShareService(gormDB, []byte("secret"))
+	token, _, err := shares.CreateLink(execution.ID, -time.Hour)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+
+	if _, err := shares.ExecutionIDForToken(token); !errors.Is(err, ErrShareTokenExpired) {
+		t.Fatalf("expected ErrShareTokenExpired, got %v", err)
+	}
+}
+
+func TestShareExecutionIDForTokenRejectsMalformedToken(t *testing.T) {
+	gormDB := newTestDB(t)
+	shares := NewShareService(gormDB, []byte("secret"))
+	if _, err := shares.ExecutionIDForToken("not-a-token"); !errors.Is(err, ErrShareTokenInvalid) {
+		t.Fatalf("expected ErrShareTokenInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/shellpolicy"
+)
+
+func TestCreateRejectsAShellStepThatViolatesThePolicy(t *testing.T) {
+	gormDB := newTestDB(t)
+	policy, err := shellpolicy.Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	svc := NewTemplateService(gormDB, nil, policy, nil)
+
+	tmpl := &model.WorkflowTemplate{
+		Name:  "cleanup",
+		Steps: []model.TemplateStep{{Name: "wipe", Type: "shell", Config: "rm -rf /"}},
+	}
+	if err := svc.Create(tmpl, nil); err == nil {
+		t.Fatal("expected Create to reject a step violating the shell policy")
+	}
+}
+
+func TestCreateAllowsAShellStepThatSatisfiesThePolicy(t *testing.T) {
+	gormDB := newTestDB(t)
+	policy, err := shellpolicy.Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	svc := NewTemplateService(gormDB, nil, policy, nil)
+
+	tmpl := &model.WorkflowTemplate{
+		Name:  "deploy",
+		Steps: []model.TemplateStep{{Name: "build", Type: "shell", Config: "make build"}},
+	}
+	if err := svc.Create(tmpl, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+}
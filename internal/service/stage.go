@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// StageSummary is the roll-up status of every step in one template
+// stage, for organizing the DAG view and execution summary by stage
+// (e.g. prepare -> remediate -> verify) instead of by individual step.
+type StageSummary struct {
+	Stage     string                `json:"stage"`
+	Status    model.ExecutionStatus `json:"status"`
+	StepNames []string              `json:"step_names"`
+}
+
+// StageSummaries groups executionID's steps by their template's Stage
+// and rolls each group's StepExecution statuses up into one status via
+// model.RollupStatus. Steps are returned in the template's own order,
+// and a step with no StepExecution yet (e.g. a later layer that hasn't
+// started) counts as StatusPending. Steps with no Stage set are
+// grouped under the empty string.
+func (s *ExecutionService) StageSummaries(ctx context.Context, executionID uint) ([]StageSummary, error) {
+	var execution model.WorkflowExecution
+	if err := s.db.First(&execution, executionID).Error; err != nil {
+		return nil, fmt.Errorf("load execution: %w", err)
+	}
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+	latest, err := s.latestStepExecutionsByName(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	byStage := make(map[string][]model.TemplateStep)
+	for _, step := range tmpl.Steps {
+		if _, ok := byStage[step.Stage]; !ok {
+			order = append(order, step.Stage)
+		}
+		byStage[step.Stage] = append(byStage[step.Stage], step)
+	}
+
+	summaries := make([]StageSummary, 0, len(order))
+	for _, stage := range order {
+		steps := byStage[stage]
+		statuses := make([]model.ExecutionStatus, 0, len(steps))
+		names := make([]string, 0, len(steps))
+		for _, step := range steps {
+			names = append(names, step.Name)
+			if se, ok := latest[step.Name]; ok {
+				statuses = append(statuses, se.Status)
+			} else {
+				statuses = append(statuses, model.StatusPending)
+			}
+		}
+		summaries = append(summaries, StageSummary{
+			Stage:     stage,
+			Status:    model.RollupStatus(statuses),
+			StepNames: names,
+		})
+	}
+	return summaries, nil
+}
+
+// latestStepExecutionsByName returns executionID's StepExecutions keyed
+// by step name, keeping only the most recent attempt for a step that
+// was run more than once (e.g. across a Resume). It delegates to the
+// orchestrator's StateStore rather than querying s.db directly, so this
+// package doesn't re-implement the engine's own "keep the latest attempt
+// per step" logic.
+func (s *ExecutionService) latestStepExecutionsByName(ctx context.Context, executionID uint) (map[string]*model.StepExecution, error) {
+	snapshot, err := s.orchestrator.StateStore().LoadSnapshot(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]*model.StepExecution, len(snapshot.Steps))
+	for i := range snapshot.Steps {
+		latest[snapshot.Steps[i].StepName] = &snapshot.Steps[i]
+	}
+	return latest, nil
+}
+
+// stageSteps returns tmpl's steps whose Stage matches stage, in the
+// template's own order, or an error if stage has no steps at all.
+func stageSteps(tmpl *model.WorkflowTemplate, stage string) ([]model.TemplateStep, error) {
+	var steps []model.TemplateStep
+	for _, step := range tmpl.Steps {
+		if step.Stage == stage {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("template %d has no steps in stage %q", tmpl.ID, stage)
+	}
+	return steps, nil
+}
+
+// SkipStage force-marks every step in stage Skipped, overriding
+// whatever status it last finished in (e.g. a genuine Failed), so the
+// execution summary reflects that the stage's outcome is being
+// deliberately disregarded rather than left to look like a real
+// failure or left blocking a later RerunStage of a dependent stage.
+// This is an explicit administrative override rather than a normal
+// lifecycle transition, the same way ForceUnlock overrides normal lock
+// ownership, so it bypasses model.ValidateTransition. It refuses while
+// the execution is still actively running, since the orchestrator
+// itself owns step status updates during a run.
+func (s *ExecutionService) SkipStage(ctx context.Context, executionID uint, stage string) error {
+	var execution model.WorkflowExecution
+	if err := s.db.First(&execution, executionID).Error; err != nil {
+		return fmt.Errorf("load execution: %w", err)
+	}
+	if execution.Status == model.StatusRunning || execution.Status == model.StatusQueued {
+		return fmt.Errorf("execution %d: cannot skip a stage while the execution is %q", execution.ID, execution.Status)
+	}
+
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return fmt.Errorf("load template: %w", err)
+	}
+	steps, err := stageSteps(&tmpl, stage)
+	if err != nil {
+		return err
+	}
+
+	latest, err := s.latestStepExecutionsByName(ctx, executionID)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		se, ok := latest[step.Name]
+		if !ok || se.Status == model.StatusSkipped {
+			continue
+		}
+		se.Status = model.StatusSkipped
+		if err := s.orchestrator.StateStore().SaveNodeState(ctx, se); err != nil {
+			return err
+		}
+		se.BumpVersion()
+	}
+	return nil
+}
+
+// RerunStage re-runs just the steps in stage against a failed
+// execution, the same restriction Resume applies, since Running is
+// only a legal transition out of Failed (or Paused/Pending, neither of
+// which makes sense to rerun a stage of). It requires that none of the
+// stage's steps depend on a step outside the stage: re-running a stage
+// in isolation can't satisfy a cross-stage dependency, since the
+// TaskOrchestrator has no notion of reusing another stage's already
+// recorded output within a fresh dag.Build.
+func (s *ExecutionService) RerunStage(ctx context.Context, executionID uint, stage string) (*model.WorkflowExecution, error) {
+	var execution model.WorkflowExecution
+	if err := s.db.First(&execution, executionID).Error; err != nil {
+		return nil, fmt.Errorf("load execution: %w", err)
+	}
+	if execution.Status != model.StatusFailed {
+		return nil, fmt.Errorf("execution %d: only a failed execution's stage can be rerun, status is %q", execution.ID, execution.Status)
+	}
+
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+	steps, err := stageSteps(&tmpl, stage)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dag.Build(steps); err != nil {
+		return nil, fmt.Errorf("stage %q can't be rerun in isolation: %w", stage, err)
+	}
+
+	runErr := s.orchestrator.Run(ctx, &execution, steps)
+	return &execution, runErr
+}
+
+// RerunStep re-executes a single step of a failed execution, identified
+// by its StepExecution ID, and optionally its downstream subtree, for
+// cases where an operator fixed an external issue by hand and just wants
+// that one step (and whatever depended on it) to run again rather than
+// resuming the whole execution from its original failure point.
+//
+// It applies the same restriction as RerunStage, for the same reason:
+// none of the rerun steps may depend on a step outside the rerun set,
+// since the TaskOrchestrator starts a fresh Run with no memory of
+// outputs recorded outside the steps it's given. A prior attempt's
+// StepExecution rows are left alone; the fresh attempt gets its own
+// rows, so latestStepExecutionsByName (and anything built on it) already
+// reports the most recent one.
+func (s *ExecutionService) RerunStep(ctx context.Context, executionID, stepExecutionID uint, includeDownstream bool) (*model.WorkflowExecution, error) {
+	var execution model.WorkflowExecution
+	if err := s.db.First(&execution, executionID).Error; err != nil {
+		return nil, fmt.Errorf("load execution: %w", err)
+	}
+	if execution.Status != model.StatusFailed {
+		return nil, fmt.Errorf("execution %d: only a failed execution's step can be rerun, status is %q", execution.ID, execution.Status)
+	}
+
+	var target model.StepExecution
+	if err := s.db.First(&target, stepExecutionID).Error; err != nil {
+		return nil, fmt.Errorf("load step execution: %w", err)
+	}
+	if target.ExecutionID != executionID {
+		return nil, fmt.Errorf("step execution %d does not belong to execution %d", stepExecutionID, executionID)
+	}
+
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, execution.TemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load template: %w", err)
+	}
+	graph, err := dag.Build(tmpl.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("build dag: %w", err)
+	}
+	if _, ok := graph.Nodes[target.StepName]; !ok {
+		return nil, fmt.Errorf("template %d has no step named %q", tmpl.ID, target.StepName)
+	}
+
+	names := map[string]bool{target.StepName: true}
+	if includeDownstream {
+		for name := range downstreamOf(graph, target.StepName) {
+			names[name] = true
+		}
+	}
+
+	var steps []model.TemplateStep
+	for _, step := range tmpl.Steps {
+		if !names[step.Name] {
+			continue
+		}
+		for _, dep := range graph.Nodes[step.Name].DependsOn {
+			if !names[dep] {
+				return nil, fmt.Errorf("step %q depends on %q outside the rerun set; rerun with includeDownstream or rerun the whole stage/execution instead", step.Name, dep)
+			}
+		}
+		steps = append(steps, step)
+	}
+	if _, err := dag.Build(steps); err != nil {
+		return nil, fmt.Errorf("step %q can't be rerun in isolation: %w", target.StepName, err)
+	}
+
+	runErr := s.orchestrator.Run(ctx, &execution, steps)
+	return &execution, runErr
+}
+
+// downstreamOf returns the names of every step that transitively depends
+// on stepName, according to graph's DependsOn edges.
+func downstreamOf(graph *dag.Graph, stepName string) map[string]bool {
+	dependents := make(map[string][]string, len(graph.Nodes))
+	for name, node := range graph.Nodes {
+		for _, dep := range node.DependsOn {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	downstream := make(map[string]bool)
+	queue := []string{stepName}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[next] {
+			if !downstream[dependent] {
+				downstream[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+	return downstream
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestStageSummariesRollsUpStatusPerStage(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0", Stage: "prepare"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "exit 1", Stage: "remediate", DependsOn: "a"},
+		{TemplateID: tmpl.ID, Name: "c", Type: "shell", Config: "exit 0", Stage: "verify", DependsOn: "b"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail at stage remediate")
+	}
+
+	summaries, err := executions.StageSummaries(context.Background(), execution.ID)
+	if err != nil {
+		t.Fatalf("StageSummaries: %v", err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("expected 3 stages, got %d", len(summaries))
+	}
+	byStage := make(map[string]StageSummary, len(summaries))
+	for _, s := range summaries {
+		byStage[s.Stage] = s
+	}
+	if got := byStage["prepare"].Status; got != model.StatusSucceeded {
+		t.Fatalf("expected prepare to be succeeded, got %q", got)
+	}
+	if got := byStage["remediate"].Status; got != model.StatusFailed {
+		t.Fatalf("expected remediate to be failed, got %q", got)
+	}
+	// verify was skipped because its dependency (remediate's step)
+	// failed, so the stage rolls up to skipped rather than pending.
+	if got := byStage["verify"].Status; got != model.StatusSkipped {
+		t.Fatalf("expected verify to be skipped, got %q", got)
+	}
+}
+
+func TestSkipStageOverridesFailedStepsToSkipped(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1", Stage: "prepare"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "exit 0", Stage: "verify", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail at stage prepare")
+	}
+
+	if err := executions.SkipStage(context.Background(), execution.ID, "prepare"); err != nil {
+		t.Fatalf("SkipStage: %v", err)
+	}
+
+	summaries, err := executions.StageSummaries(context.Background(), execution.ID)
+	if err != nil {
+		t.Fatalf("StageSummaries: %v", err)
+	}
+	for _, s := range summaries {
+		if s.Stage == "prepare" && s.Status != model.StatusSkipped {
+			t.Fatalf("expected prepare to be skipped after SkipStage, got %q", s.Status)
+		}
+	}
+}
+
+func TestSkipStageRejectsUnknownStage(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1", Stage: "prepare"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, _ := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+
+	if err := executions.SkipStage(context.Background(), execution.ID, "nonexistent"); err == nil {
+		t.Fatal("expected an error for a stage with no steps")
+	}
+}
+
+func TestRerunStageReRunsOnlyThatStagesSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1", Stage: "remediate"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail")
+	}
+
+	if err := gormDB.Model(&step).Update("config", "exit 0").Error; err != nil {
+		t.Fatalf("update step config: %v", err)
+	}
+
+	rerun, err := executions.RerunStage(context.Background(), execution.ID, "remediate")
+	if err != nil {
+		t.Fatalf("RerunStage: %v", err)
+	}
+	if rerun.Status != model.StatusSucceeded {
+		t.Fatalf("expected the execution to succeed after rerunning remediate, got %q", rerun.Status)
+	}
+}
+
+func TestRerunStageRejectsCrossStageDependencies(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0", Stage: "prepare"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "exit 1", Stage: "remediate", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail")
+	}
+
+	if _, err := executions.RerunStage(context.Background(), execution.ID, "remediate"); err == nil {
+		t.Fatal("expected rerunning a stage that depends on a step outside it to be rejected")
+	}
+}
+
+func TestRerunStageRejectsNonFailedExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0", Stage: "verify"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	if _, err := executions.RerunStage(context.Background(), execution.ID, "verify"); err == nil {
+		t.Fatal("expected rerunning a stage of a succeeded execution to be rejected")
+	}
+}
+
+func TestRerunStepReRunsJustThatStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail")
+	}
+
+	var failedStep model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&failedStep).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+
+	if err := gormDB.Model(&step).Update("config", "exit 0").Error; err != nil {
+		t.Fatalf("update step config: %v", err)
+	}
+
+	rerun, err := executions.RerunStep(context.Background(), execution.ID, failedStep.ID, false)
+	if err != nil {
+		t.Fatalf("RerunStep: %v", err)
+	}
+	if rerun.Status != model.StatusSucceeded {
+		t.Fatalf("expected the execution to succeed after rerunning a, got %q", rerun.Status)
+	}
+
+	var attempts int64
+	if err := gormDB.Model(&model.StepExecution{}).Where("execution_id = ? AND step_name = ?", execution.ID, "a").Count(&attempts).Error; err != nil {
+		t.Fatalf("count attempts: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 recorded attempts for step a, got %d", attempts)
+	}
+}
+
+func TestRerunStepIncludesDownstreamSubtreeWhenRequested(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 1"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "exit 0", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail")
+	}
+
+	var failedStep model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&failedStep).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+	if err := gormDB.Model(&steps[0]).Update("config", "exit 0").Error; err != nil {
+		t.Fatalf("update step config: %v", err)
+	}
+
+	rerun, err := executions.RerunStep(context.Background(), execution.ID, failedStep.ID, true)
+	if err != nil {
+		t.Fatalf("RerunStep: %v", err)
+	}
+	if rerun.Status != model.StatusSucceeded {
+		t.Fatalf("expected the execution to succeed, got %q", rerun.Status)
+	}
+
+	var bAttempts int64
+	if err := gormDB.Model(&model.StepExecution{}).Where("execution_id = ? AND step_name = ?", execution.ID, "b").Count(&bAttempts).Error; err != nil {
+		t.Fatalf("count attempts: %v", err)
+	}
+	if bAttempts != 2 {
+		t.Fatalf("expected b to have been rerun as part of a's downstream subtree, got %d attempts", bAttempts)
+	}
+}
+
+func TestRerunStepRejectsStepDependingOutsideTheRerunSet(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"},
+		{TemplateID: tmpl.ID, Name: "b", Type: "shell", Config: "exit 1", DependsOn: "a"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err == nil {
+		t.Fatal("expected the execution to fail")
+	}
+
+	var failedStep model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "b").First(&failedStep).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+
+	if _, err := executions.RerunStep(context.Background(), execution.ID, failedStep.ID, false); err == nil {
+		t.Fatal("expected rerunning a step that depends on a step outside the rerun set to be rejected")
+	}
+}
+
+func TestRerunStepRejectsNonFailedExecutions(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	registry.Register("shell", executor.ShellTask{})
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	step := model.TemplateStep{TemplateID: tmpl.ID, Name: "a", Type: "shell", Config: "exit 0"}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+	execution, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("execution_id = ? AND step_name = ?", execution.ID, "a").First(&se).Error; err != nil {
+		t.Fatalf("load step execution: %v", err)
+	}
+
+	if _, err := executions.RerunStep(context.Background(), execution.ID, se.ID, false); err == nil {
+		t.Fatal("expected rerunning a step of a succeeded execution to be rejected")
+	}
+}
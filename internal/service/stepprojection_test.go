@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func newExecutionWithOutput(t *testing.T, gormDB *gorm.DB) *model.WorkflowExecution {
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	execution := model.WorkflowExecution{TemplateID: tmpl.ID, Status: model.StatusSucceeded}
+	if err := gormDB.Create(&execution).Error; err != nil {
+		t.Fatalf("create execution: %v", err)
+	}
+	step := model.StepExecution{
+		ExecutionID: execution.ID,
+		StepName:    "a",
+		Status:      model.StatusSucceeded,
+		Output:      "a very large blob of output",
+		Error:       "",
+	}
+	if err := gormDB.Create(&step).Error; err != nil {
+		t.Fatalf("create step: %v", err)
+	}
+	return &execution
+}
+
+func TestListPageOmitsStepOutputAndError(t *testing.T) {
+	gormDB := newTestDB(t)
+	newExecutionWithOutput(t, gormDB)
+
+	executions := NewExecutionService(gormDB, nil, NewQuotaService(gormDB), nil, nil)
+	page, _, err := executions.ListPage(ListQuery{Limit: 10, Sort: "id"})
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	if len(page) != 1 || len(page[0].Steps) != 1 {
+		t.Fatalf("expected 1 execution with 1 step, got %+v", page)
+	}
+	if page[0].Steps[0].Output != "" {
+		t.Fatalf("expected ListPage to omit step output, got %q", page[0].Steps[0].Output)
+	}
+}
+
+func TestStepLoadsFullOutputOnDemand(t *testing.T) {
+	gormDB := newTestDB(t)
+	execution := newExecutionWithOutput(t, gormDB)
+
+	executions := NewExecutionService(gormDB, nil, NewQuotaService(gormDB), nil, nil)
+	page, _, err := executions.ListPage(ListQuery{Limit: 10, Sort: "id"})
+	if err != nil {
+		t.Fatalf("ListPage: %v", err)
+	}
+	stepID := page[0].Steps[0].ID
+
+	step, err := executions.Step(stepID)
+	if err != nil {
+		t.Fatalf("Step: %v", err)
+	}
+	if step.Output != "a very large blob of output" {
+		t.Fatalf("expected Step to load the full output, got %q", step.Output)
+	}
+	if step.ExecutionID != execution.ID {
+		t.Fatalf("expected the step to belong to the execution it was created under, got %d", step.ExecutionID)
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/stepsign"
+)
+
+func testStepSigner(t *testing.T) *stepsign.Signer {
+	t.Helper()
+	signer, err := stepsign.NewSigner([]byte("a-test-signing-key"))
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer
+}
+
+func TestCreateSignsEveryStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	signer := testStepSigner(t)
+	svc := NewTemplateService(gormDB, nil, nil, signer)
+
+	tmpl := &model.WorkflowTemplate{
+		Name:  "deploy",
+		Steps: []model.TemplateStep{{Name: "build", Type: "shell", Config: "make build"}},
+	}
+	if err := svc.Create(tmpl, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if tmpl.Steps[0].ConfigSignature == "" {
+		t.Fatal("expected Create to set a ConfigSignature")
+	}
+	if err := signer.Verify(tmpl.Steps[0]); err != nil {
+		t.Fatalf("expected the stored signature to verify, got %v", err)
+	}
+}
+
+func TestCloneReSignsCopiedSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	signer := testStepSigner(t)
+	svc := NewTemplateService(gormDB, nil, nil, signer)
+
+	src := &model.WorkflowTemplate{
+		Name:  "deploy",
+		Steps: []model.TemplateStep{{Name: "build", Type: "shell", Config: "make build"}},
+	}
+	if err := svc.Create(src, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	clone, err := svc.Clone(src.ID)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+	if err := signer.Verify(clone.Steps[0]); err != nil {
+		t.Fatalf("expected the clone's step signature to verify, got %v", err)
+	}
+}
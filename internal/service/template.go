@@ -0,0 +1,408 @@
+// Package service implements the orchestrator's business logic on top of
+// the model and executor packages, independent of any transport.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/fengmingli/orchestrator/internal/dag"
+	"github.com/fengmingli/orchestrator/internal/fieldcrypt"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/schema"
+	"github.com/fengmingli/orchestrator/internal/shellpolicy"
+	"github.com/fengmingli/orchestrator/internal/stepsign"
+)
+
+// RedactedConfig replaces a step's Config and DiagnosticConfig in list
+// responses, which show many steps at once and are the likeliest thing
+// to be logged, screen-shared or cached, where a single Get (the
+// template editor) needs the real value to let an author edit it.
+const RedactedConfig = "[redacted]"
+
+// TemplateService manages WorkflowTemplates and their steps.
+type TemplateService struct {
+	db     *gorm.DB
+	crypt  *fieldcrypt.KeyRing
+	policy *shellpolicy.Policy
+	signer *stepsign.Signer
+}
+
+// NewTemplateService builds a TemplateService backed by db. If crypt is
+// non-nil, every step's Config and DiagnosticConfig is encrypted before
+// it's written and decrypted transparently when a single template is
+// fetched with Get; pass nil to store and serve them as plaintext. If
+// policy is non-nil, every shell step's Config is validated against it
+// in Create, rejecting the template outright if any step violates it;
+// pass nil to skip this check. If signer is non-nil, every step's
+// ConfigSignature is (re)computed before it's written, so the executor
+// can later detect a step definition tampered with directly in the
+// database; pass nil to leave steps unsigned.
+func NewTemplateService(db *gorm.DB, crypt *fieldcrypt.KeyRing, policy *shellpolicy.Policy, signer *stepsign.Signer) *TemplateService {
+	return &TemplateService{db: db, crypt: crypt, policy: policy, signer: signer}
+}
+
+// TemplateInclude instructs Create to import another template's steps
+// under Prefix, so a template can compose an existing runbook as a
+// reusable unit instead of duplicating its steps or nesting a separate
+// execution inside a step.
+type TemplateInclude struct {
+	SourceTemplateID uint
+	Prefix           string
+}
+
+// Create persists a new template along with its steps, rejecting it
+// outright if ParamsSchema or OutputSchema is set but malformed, so a
+// broken contract is caught at authoring time rather than the first
+// execution that tries to validate against it. Each include's source
+// template's steps are renamed under its prefix and appended to tmpl's
+// own steps before the combined DAG is validated, so a cycle introduced
+// by an include (or a collision between an include and tmpl's own
+// steps) is caught here rather than at the first execution.
+func (s *TemplateService) Create(tmpl *model.WorkflowTemplate, includes []TemplateInclude) error {
+	if err := schema.Compile(tmpl.ParamsSchema); err != nil {
+		return fmt.Errorf("params_schema: %w", err)
+	}
+	if err := schema.Compile(tmpl.OutputSchema); err != nil {
+		return fmt.Errorf("output_schema: %w", err)
+	}
+	if err := s.checkShellPolicy(tmpl.Steps); err != nil {
+		return err
+	}
+
+	// Encrypt tmpl's own steps before any include's (already-encrypted)
+	// steps are appended below, so they aren't encrypted a second time.
+	if err := s.encryptSteps(tmpl.Steps); err != nil {
+		return fmt.Errorf("encrypt steps: %w", err)
+	}
+
+	seen := make(map[uint]bool, len(includes))
+	for _, include := range includes {
+		if seen[include.SourceTemplateID] {
+			return fmt.Errorf("template %d is included more than once", include.SourceTemplateID)
+		}
+		seen[include.SourceTemplateID] = true
+
+		steps, err := s.resolveInclude(include)
+		if err != nil {
+			return err
+		}
+		tmpl.Steps = append(tmpl.Steps, steps...)
+	}
+
+	if len(includes) > 0 {
+		graph, err := dag.Build(tmpl.Steps)
+		if err != nil {
+			return fmt.Errorf("resolve includes: %w", err)
+		}
+		if _, err := graph.Layers(); err != nil {
+			return fmt.Errorf("resolve includes: %w", err)
+		}
+	}
+
+	// Sign every step last, once the full set (including any renamed
+	// include steps) is final, so the signature always covers the exact
+	// definition that's about to be persisted.
+	s.signSteps(tmpl.Steps)
+
+	return s.db.Create(tmpl).Error
+}
+
+// ValidateDAG checks whether steps form a valid DAG without persisting
+// anything, so the template editor can flag a cycle (or an unknown
+// dependency) as the author builds it rather than waiting for Create or
+// the first execution attempt. If steps contain a cycle, the returned
+// path names the offending steps in dependency order so the UI can
+// highlight exactly those nodes.
+func (s *TemplateService) ValidateDAG(steps []model.TemplateStep) (cyclePath []string, err error) {
+	graph, err := dag.Build(steps)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := graph.Layers(); err != nil {
+		var cycleErr *dag.CycleError
+		if errors.As(err, &cycleErr) {
+			return cycleErr.Path, err
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// resolveInclude loads include's source template and returns its steps
+// renamed under include.Prefix, with every DependsOn edge remapped to
+// the renamed names, so the imported steps only ever depend on each
+// other or get depended on by name from the including template.
+func (s *TemplateService) resolveInclude(include TemplateInclude) ([]model.TemplateStep, error) {
+	if strings.TrimSpace(include.Prefix) == "" {
+		return nil, fmt.Errorf("include of template %d needs a non-empty prefix", include.SourceTemplateID)
+	}
+
+	var source model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&source, include.SourceTemplateID).Error; err != nil {
+		return nil, fmt.Errorf("load include source %d: %w", include.SourceTemplateID, err)
+	}
+
+	rename := func(name string) string { return include.Prefix + "_" + name }
+
+	sourceID := include.SourceTemplateID
+	steps := make([]model.TemplateStep, 0, len(source.Steps))
+	for _, step := range source.Steps {
+		var deps []string
+		for _, dep := range strings.Split(step.DependsOn, ",") {
+			if dep = strings.TrimSpace(dep); dep != "" {
+				deps = append(deps, rename(dep))
+			}
+		}
+		steps = append(steps, model.TemplateStep{
+			Name:                   rename(step.Name),
+			Type:                   step.Type,
+			Config:                 step.Config,
+			DependsOn:              strings.Join(deps, ","),
+			Stage:                  step.Stage,
+			SoftTimeoutSeconds:     step.SoftTimeoutSeconds,
+			HardTimeoutSeconds:     step.HardTimeoutSeconds,
+			DiagnosticType:         step.DiagnosticType,
+			DiagnosticConfig:       step.DiagnosticConfig,
+			IncludedFromTemplateID: &sourceID,
+		})
+	}
+	return steps, nil
+}
+
+// Get loads a template by ID, including its steps, with each step's
+// Config and DiagnosticConfig transparently decrypted so an editor can
+// show and resubmit their real values.
+func (s *TemplateService) Get(id uint) (*model.WorkflowTemplate, error) {
+	var tmpl model.WorkflowTemplate
+	if err := s.db.Preload("Steps").First(&tmpl, id).Error; err != nil {
+		return nil, err
+	}
+	if err := s.decryptSteps(tmpl.Steps); err != nil {
+		return nil, fmt.Errorf("decrypt steps: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// List returns templates ordered by most recently created first, with
+// their steps eager-loaded. Steps for the whole page are fetched in one
+// extra query (WHERE template_id IN (...)) rather than one query per
+// template, so the total cost is two queries regardless of page size.
+// Each step's Config and DiagnosticConfig is redacted rather than
+// decrypted, since a list response is the likeliest place for a
+// credential embedded in one of them to end up logged or screen-shared.
+func (s *TemplateService) List() ([]model.WorkflowTemplate, error) {
+	var tmpls []model.WorkflowTemplate
+	if err := s.db.Preload("Steps").Order("created_at desc").Find(&tmpls).Error; err != nil {
+		return nil, err
+	}
+	redactTemplateSteps(tmpls)
+	return tmpls, nil
+}
+
+// Clone deep-copies the template identified by id, including its steps,
+// as a new unpublished template with its own IDs, and records its
+// provenance via ClonedFromID.
+func (s *TemplateService) Clone(id uint) (*model.WorkflowTemplate, error) {
+	src, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	clone := &model.WorkflowTemplate{
+		Name:         src.Name + " (copy)",
+		Description:  src.Description,
+		Version:      1,
+		Published:    false,
+		ClonedFromID: &src.ID,
+	}
+	for _, step := range src.Steps {
+		clone.Steps = append(clone.Steps, model.TemplateStep{
+			Name:      step.Name,
+			Type:      step.Type,
+			Config:    step.Config,
+			DependsOn: step.DependsOn,
+		})
+	}
+	if err := s.encryptSteps(clone.Steps); err != nil {
+		return nil, fmt.Errorf("encrypt steps: %w", err)
+	}
+	s.signSteps(clone.Steps)
+	if err := s.db.Create(clone).Error; err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// Catalog returns all published templates, most recently created first,
+// so teams can discover and reuse existing runbooks instead of
+// re-creating them.
+func (s *TemplateService) Catalog() ([]model.WorkflowTemplate, error) {
+	var tmpls []model.WorkflowTemplate
+	if err := s.db.Preload("Steps").Where("published = ?", true).Order("created_at desc").Find(&tmpls).Error; err != nil {
+		return nil, err
+	}
+	redactTemplateSteps(tmpls)
+	return tmpls, nil
+}
+
+// ListPage returns one cursor-paginated page of templates matching q,
+// plus the cursor to pass as q.Cursor to fetch the next page (empty once
+// there are no more rows). Unlike offset pagination, the cost of fetching
+// a page does not grow with how deep into the history it is.
+func (s *TemplateService) ListPage(q ListQuery) ([]model.WorkflowTemplate, string, error) {
+	if err := q.normalize(); err != nil {
+		return nil, "", err
+	}
+	base := s.db.Model(&model.WorkflowTemplate{}).Preload("Steps")
+	if q.Maintainer != "" {
+		base = base.Where("(',' || maintainers || ',') LIKE ?", "%,"+q.Maintainer+",%")
+	}
+	query, err := applyListQuery(base, q, model.LabelOwnerTemplate)
+	if err != nil {
+		return nil, "", err
+	}
+	var tmpls []model.WorkflowTemplate
+	if err := query.Find(&tmpls).Error; err != nil {
+		return nil, "", err
+	}
+	redactTemplateSteps(tmpls)
+	if len(tmpls) == 0 {
+		return tmpls, "", nil
+	}
+	last := tmpls[len(tmpls)-1]
+	next := ""
+	if len(tmpls) == q.Limit {
+		next = encodeCursor(sortValueOf(q.Sort, last.ID, last.CreatedAt))
+	}
+	return tmpls, next, nil
+}
+
+// TransferOwnership replaces the template's Maintainers with newMaintainers,
+// attributing the change to requestedBy (and, optionally, reason), and
+// records a TemplateOwnershipEvent capturing the prior value so the
+// transfer leaves an audit trail, the same way ForceRelease does for a
+// lock. Neither requestedBy nor reason is validated against any identity
+// system, since this codebase has none; callers self-report both.
+func (s *TemplateService) TransferOwnership(id uint, newMaintainers, requestedBy, reason string) (*model.WorkflowTemplate, error) {
+	var tmpl model.WorkflowTemplate
+	if err := s.db.First(&tmpl, id).Error; err != nil {
+		return nil, err
+	}
+	prior := tmpl.Maintainers
+	tmpl.Maintainers = newMaintainers
+	if err := s.db.Save(&tmpl).Error; err != nil {
+		return nil, err
+	}
+	event := model.TemplateOwnershipEvent{
+		TemplateID:       tmpl.ID,
+		PriorMaintainers: prior,
+		NewMaintainers:   newMaintainers,
+		RequestedBy:      requestedBy,
+		Reason:           reason,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// OwnershipHistory returns every recorded ownership transfer for the
+// template identified by id, most recent first.
+func (s *TemplateService) OwnershipHistory(id uint) ([]model.TemplateOwnershipEvent, error) {
+	var events []model.TemplateOwnershipEvent
+	if err := s.db.Where("template_id = ?", id).Order("created_at desc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// checkShellPolicy validates every shell step's Config against
+// s.policy, a no-op if s.policy is nil, returning the first violation
+// found so an author sees a clear reason their template was rejected.
+func (s *TemplateService) checkShellPolicy(steps []model.TemplateStep) error {
+	if s.policy == nil {
+		return nil
+	}
+	for _, step := range steps {
+		if step.Type != "shell" {
+			continue
+		}
+		if err := s.policy.Validate(step.Config); err != nil {
+			return fmt.Errorf("step %q: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// signSteps (re)computes each step's ConfigSignature under s.signer, a
+// no-op if s.signer is nil, leaving steps unsigned.
+func (s *TemplateService) signSteps(steps []model.TemplateStep) {
+	if s.signer == nil {
+		return
+	}
+	for i := range steps {
+		steps[i].ConfigSignature = s.signer.Sign(steps[i])
+	}
+}
+
+// encryptSteps encrypts each step's Config and DiagnosticConfig in
+// place under s.crypt, a no-op if s.crypt is nil.
+func (s *TemplateService) encryptSteps(steps []model.TemplateStep) error {
+	if s.crypt == nil {
+		return nil
+	}
+	for i := range steps {
+		config, err := s.crypt.Encrypt(steps[i].Config)
+		if err != nil {
+			return err
+		}
+		diagnosticConfig, err := s.crypt.Encrypt(steps[i].DiagnosticConfig)
+		if err != nil {
+			return err
+		}
+		steps[i].Config = config
+		steps[i].DiagnosticConfig = diagnosticConfig
+	}
+	return nil
+}
+
+// decryptSteps reverses encryptSteps, a no-op if s.crypt is nil.
+func (s *TemplateService) decryptSteps(steps []model.TemplateStep) error {
+	if s.crypt == nil {
+		return nil
+	}
+	for i := range steps {
+		config, err := s.crypt.Decrypt(steps[i].Config)
+		if err != nil {
+			return err
+		}
+		diagnosticConfig, err := s.crypt.Decrypt(steps[i].DiagnosticConfig)
+		if err != nil {
+			return err
+		}
+		steps[i].Config = config
+		steps[i].DiagnosticConfig = diagnosticConfig
+	}
+	return nil
+}
+
+// redactTemplateSteps overwrites every step's Config and
+// DiagnosticConfig across tmpls with RedactedConfig, regardless of
+// whether field encryption is configured: a list response shouldn't
+// carry either a plaintext secret or live ciphertext an attacker could
+// replay elsewhere.
+func redactTemplateSteps(tmpls []model.WorkflowTemplate) {
+	for i := range tmpls {
+		for j := range tmpls[i].Steps {
+			if tmpls[i].Steps[j].Config != "" {
+				tmpls[i].Steps[j].Config = RedactedConfig
+			}
+			if tmpls[i].Steps[j].DiagnosticConfig != "" {
+				tmpls[i].Steps[j].DiagnosticConfig = RedactedConfig
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/fieldcrypt"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func testKeyRing(t *testing.T) *fieldcrypt.KeyRing {
+	t.Helper()
+	ring, err := fieldcrypt.NewKeyRing("v1", map[string][]byte{"v1": make([]byte, 32)})
+	if err != nil {
+		t.Fatalf("NewKeyRing: %v", err)
+	}
+	return ring
+}
+
+func TestCreateEncryptsStepConfigAtRestAndGetDecryptsIt(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, testKeyRing(t), nil, nil)
+
+	tmpl := &model.WorkflowTemplate{
+		Name: "deploy",
+		Steps: []model.TemplateStep{
+			{Name: "call", Type: "http", Config: `{"headers":{"Authorization":"Bearer secret"}}`},
+		},
+	}
+	if err := svc.Create(tmpl, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var stored model.TemplateStep
+	if err := gormDB.First(&stored, "template_id = ?", tmpl.ID).Error; err != nil {
+		t.Fatalf("load stored step: %v", err)
+	}
+	if stored.Config == `{"headers":{"Authorization":"Bearer secret"}}` {
+		t.Fatal("expected the stored Config to be encrypted, not plaintext")
+	}
+
+	got, err := svc.Get(tmpl.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Steps[0].Config != `{"headers":{"Authorization":"Bearer secret"}}` {
+		t.Fatalf("expected Get to decrypt Config, got %q", got.Steps[0].Config)
+	}
+}
+
+func TestListRedactsStepConfigEvenWithoutEncryptionConfigured(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	tmpl := &model.WorkflowTemplate{
+		Name:      "deploy",
+		Published: true,
+		Steps:     []model.TemplateStep{{Name: "call", Type: "shell", Config: "export TOKEN=secret"}},
+	}
+	if err := svc.Create(tmpl, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	list, err := svc.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	found := false
+	for _, got := range list {
+		if got.ID != tmpl.ID {
+			continue
+		}
+		found = true
+		if got.Steps[0].Config != RedactedConfig {
+			t.Fatalf("expected List to redact Config, got %q", got.Steps[0].Config)
+		}
+	}
+	if !found {
+		t.Fatal("expected the created template in List's results")
+	}
+
+	catalog, err := svc.Catalog()
+	if err != nil {
+		t.Fatalf("Catalog: %v", err)
+	}
+	if catalog[0].Steps[0].Config != RedactedConfig {
+		t.Fatalf("expected Catalog to redact Config, got %q", catalog[0].Steps[0].Config)
+	}
+}
+
+func TestCloneReencryptsCopiedSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, testKeyRing(t), nil, nil)
+
+	src := &model.WorkflowTemplate{
+		Name:  "deploy",
+		Steps: []model.TemplateStep{{Name: "call", Type: "shell", Config: "export TOKEN=secret"}},
+	}
+	if err := svc.Create(src, nil); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	clone, err := svc.Clone(src.ID)
+	if err != nil {
+		t.Fatalf("Clone: %v", err)
+	}
+
+	var stored model.TemplateStep
+	if err := gormDB.First(&stored, "template_id = ?", clone.ID).Error; err != nil {
+		t.Fatalf("load stored clone step: %v", err)
+	}
+	if stored.Config == "export TOKEN=secret" {
+		t.Fatal("expected the cloned step's stored Config to be encrypted, not plaintext")
+	}
+
+	got, err := svc.Get(clone.ID)
+	if err != nil {
+		t.Fatalf("Get clone: %v", err)
+	}
+	if got.Steps[0].Config != "export TOKEN=secret" {
+		t.Fatalf("expected the clone to decrypt back to the original Config, got %q", got.Steps[0].Config)
+	}
+}
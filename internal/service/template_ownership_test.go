@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestTemplateTransferOwnershipUpdatesMaintainersAndRecordsEvent(t *testing.T) {
+	gormDB := newTestDB(t)
+	tmpl := model.WorkflowTemplate{Name: "t", Maintainers: "alice"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+
+	updated, err := svc.TransferOwnership(tmpl.ID, "bob,carol", "alice", "going on leave")
+	if err != nil {
+		t.Fatalf("TransferOwnership: %v", err)
+	}
+	if updated.Maintainers != "bob,carol" {
+		t.Fatalf("expected maintainers to be updated, got %q", updated.Maintainers)
+	}
+
+	history, err := svc.OwnershipHistory(tmpl.ID)
+	if err != nil {
+		t.Fatalf("OwnershipHistory: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected one ownership event, got %d", len(history))
+	}
+	event := history[0]
+	if event.PriorMaintainers != "alice" || event.NewMaintainers != "bob,carol" || event.RequestedBy != "alice" {
+		t.Fatalf("unexpected ownership event: %+v", event)
+	}
+}
+
+func TestTemplateTransferOwnershipRejectsUnknownTemplate(t *testing.T) {
+	gormDB := newTestDB(t)
+	svc := NewTemplateService(gormDB, nil, nil, nil)
+	if _, err := svc.TransferOwnership(999, "bob", "alice", ""); err == nil {
+		t.Fatal("expected an error for an unknown template")
+	}
+}
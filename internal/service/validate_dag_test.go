@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func TestValidateDAGAcceptsAcyclicSteps(t *testing.T) {
+	gormDB := newTestDB(t)
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	}
+	cyclePath, err := NewTemplateService(gormDB, nil, nil, nil).ValidateDAG(steps)
+	if err != nil {
+		t.Fatalf("ValidateDAG: %v", err)
+	}
+	if cyclePath != nil {
+		t.Fatalf("expected no cycle path for a valid DAG, got %v", cyclePath)
+	}
+}
+
+func TestValidateDAGReturnsCyclePath(t *testing.T) {
+	gormDB := newTestDB(t)
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", DependsOn: "b"},
+		{Name: "b", Type: "shell", DependsOn: "a"},
+	}
+	cyclePath, err := NewTemplateService(gormDB, nil, nil, nil).ValidateDAG(steps)
+	if err == nil {
+		t.Fatal("expected an error for a cyclic DAG")
+	}
+	if len(cyclePath) != 3 || cyclePath[0] != cyclePath[2] {
+		t.Fatalf("expected a closed 2-node cycle path, got %v", cyclePath)
+	}
+}
+
+func TestValidateDAGRejectsUnknownDependency(t *testing.T) {
+	gormDB := newTestDB(t)
+	steps := []model.TemplateStep{
+		{Name: "a", Type: "shell", DependsOn: "missing"},
+	}
+	if _, err := NewTemplateService(gormDB, nil, nil, nil).ValidateDAG(steps); err == nil {
+		t.Fatal("expected an error for an unknown dependency")
+	}
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/logging"
+	"github.com/fengmingli/orchestrator/internal/model"
+	"github.com/fengmingli/orchestrator/internal/notify"
+)
+
+var watchdogLog = logging.New("service")
+
+// DefaultWatchdogInterval is how often a Watchdog scans for stuck steps
+// when NewWatchdog is given a zero interval.
+const DefaultWatchdogInterval = 30 * time.Second
+
+// Watchdog periodically scans every execution this orchestrator is
+// driving for a step that's still running after Threshold. A step still
+// present in TaskOrchestrator.ActiveSteps past that point has, by
+// definition, made no state transition since it started: the
+// orchestrator only tracks a running step's start time in memory, so
+// "stuck" here just means "hasn't finished, failed, or otherwise left
+// ActiveSteps in time."
+//
+// Each stuck step is logged together with the DAG layers it's blocking
+// (every later-layer node still StatusPending, since RunSimulated
+// schedules layer by layer) and reported through Notifier, the same
+// extension point TaskOrchestrator uses for SLA breaches. If ForceFail
+// is set, the step is also cancelled via ForceFailStep, failing it the
+// same way a hard per-step timeout would rather than leaving the
+// execution hung indefinitely.
+type Watchdog struct {
+	executions *ExecutionService
+	clock      clock.Clock
+	notifier   notify.Notifier
+	interval   time.Duration
+
+	// Threshold is how long a step may run before it's considered stuck.
+	Threshold time.Duration
+	// ForceFail, when true, cancels a stuck step's run context once
+	// it's been reported. When false, the watchdog only logs and
+	// notifies, leaving the step running.
+	ForceFail bool
+
+	mu      sync.Mutex
+	flagged map[uint]map[string]bool // executionID -> step name -> already reported this episode
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WatchdogOption configures a Watchdog built by NewWatchdog.
+type WatchdogOption func(*Watchdog)
+
+// WithWatchdogInterval overrides how often the Watchdog scans for stuck
+// steps, which defaults to DefaultWatchdogInterval.
+func WithWatchdogInterval(d time.Duration) WatchdogOption {
+	return func(w *Watchdog) { w.interval = d }
+}
+
+// WithWatchdogClock overrides the Watchdog's Clock, which defaults to
+// clock.Real{}. Tests typically pass a *clock.Fake.
+func WithWatchdogClock(c clock.Clock) WatchdogOption {
+	return func(w *Watchdog) { w.clock = c }
+}
+
+// WithWatchdogNotifier overrides the Watchdog's Notifier, which defaults
+// to notify.LogNotifier{}.
+func WithWatchdogNotifier(n notify.Notifier) WatchdogOption {
+	return func(w *Watchdog) { w.notifier = n }
+}
+
+// NewWatchdog builds a Watchdog over executions that flags any step
+// still running after threshold, force-failing it if forceFail is set,
+// and starts its scan loop immediately. Call Close to shut it down.
+func NewWatchdog(executions *ExecutionService, threshold time.Duration, forceFail bool, opts ...WatchdogOption) *Watchdog {
+	w := &Watchdog{
+		executions: executions,
+		clock:      clock.Real{},
+		notifier:   notify.LogNotifier{},
+		interval:   DefaultWatchdogInterval,
+		Threshold:  threshold,
+		ForceFail:  forceFail,
+		flagged:    make(map[uint]map[string]bool),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	go w.loop()
+	return w
+}
+
+// Close stops the Watchdog's background scan loop.
+func (w *Watchdog) Close() error {
+	close(w.stop)
+	<-w.done
+	return nil
+}
+
+func (w *Watchdog) loop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Scan(context.Background())
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Scan runs one pass over every currently active step, reporting (and,
+// if ForceFail is set, failing) any that have been running longer than
+// Threshold. It's exported so tests can drive it directly rather than
+// waiting on the scan loop's ticker.
+func (w *Watchdog) Scan(ctx context.Context) {
+	for _, step := range w.executions.orchestrator.ActiveSteps() {
+		elapsed := w.clock.Now().Sub(step.StartedAt)
+		if elapsed < w.Threshold {
+			w.clear(step.ExecutionID, step.StepName)
+			continue
+		}
+		if w.alreadyFlagged(step.ExecutionID, step.StepName) {
+			continue
+		}
+		w.flag(step.ExecutionID, step.StepName)
+		w.report(ctx, step.ExecutionID, step.StepName, elapsed)
+	}
+}
+
+// report logs and notifies that executionID's step name has been stuck
+// for elapsed, including the names of every downstream step it's
+// blocking, then force-fails it if ForceFail is set.
+func (w *Watchdog) report(ctx context.Context, executionID uint, stepName string, elapsed time.Duration) {
+	log := watchdogLog.With("execution_id", executionID).With("step_id", stepName)
+
+	blocked, err := w.blockedChain(executionID, stepName)
+	if err != nil {
+		log.Warnf("blocked chain: %v", err)
+	}
+
+	message := fmt.Sprintf("execution %d: step %q has made no progress for %s (threshold %s)", executionID, stepName, elapsed.Round(time.Second), w.Threshold)
+	if len(blocked) > 0 {
+		message += fmt.Sprintf("; blocking %d downstream step(s): %s", len(blocked), strings.Join(blocked, ", "))
+	}
+	log.Warnf("%s", message)
+	if notifyErr := w.notifier.Notify(ctx, message); notifyErr != nil {
+		log.Warnf("notify failed: %v", notifyErr)
+	}
+
+	if !w.ForceFail {
+		return
+	}
+	if w.executions.orchestrator.ForceFailStep(executionID, stepName) {
+		log.Infof("force-failed step")
+	}
+}
+
+// blockedChain returns the names of every StatusPending step in a later
+// DAG layer than stepName's, i.e. the steps that can't start until
+// stepName's layer finishes, since RunSimulated schedules one layer at
+// a time.
+func (w *Watchdog) blockedChain(executionID uint, stepName string) ([]string, error) {
+	live, err := w.executions.LiveDAG(executionID)
+	if err != nil {
+		return nil, err
+	}
+	stuckLayer := -1
+	for i, layer := range live.Layers {
+		for _, node := range layer {
+			if node.Name == stepName {
+				stuckLayer = i
+			}
+		}
+	}
+	if stuckLayer < 0 {
+		return nil, nil
+	}
+	var blocked []string
+	for _, layer := range live.Layers[stuckLayer+1:] {
+		for _, node := range layer {
+			if node.Status == model.StatusPending {
+				blocked = append(blocked, node.Name)
+			}
+		}
+	}
+	return blocked, nil
+}
+
+func (w *Watchdog) alreadyFlagged(executionID uint, stepName string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flagged[executionID][stepName]
+}
+
+func (w *Watchdog) flag(executionID uint, stepName string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.flagged[executionID] == nil {
+		w.flagged[executionID] = make(map[string]bool)
+	}
+	w.flagged[executionID][stepName] = true
+}
+
+func (w *Watchdog) clear(executionID uint, stepName string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.flagged[executionID], stepName)
+	if len(w.flagged[executionID]) == 0 {
+		delete(w.flagged, executionID)
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fengmingli/orchestrator/internal/clock"
+	"github.com/fengmingli/orchestrator/internal/executor"
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// cancelAwareTask blocks until its run context is cancelled, reporting
+// ctx.Err() as its failure, so tests can observe ForceFailStep actually
+// tearing down a stuck step instead of leaving it running forever.
+type cancelAwareTask struct {
+	started chan struct{}
+}
+
+func (t cancelAwareTask) Run(ctx context.Context, config string) (string, error) {
+	close(t.started)
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+type collectingNotifier struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (n *collectingNotifier) Notify(ctx context.Context, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, message)
+	return nil
+}
+
+func (n *collectingNotifier) snapshot() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.messages...)
+}
+
+func TestWatchdogReportsAndForceFailsAStuckStep(t *testing.T) {
+	gormDB := newTestDB(t)
+	registry := executor.NewRegistry()
+	block := cancelAwareTask{started: make(chan struct{})}
+	registry.Register("block", block)
+	registry.Register("shell", executor.ShellTask{})
+
+	fakeClock := clock.NewFake(time.Unix(0, 0))
+	orchestrator := executor.NewTaskOrchestrator(gormDB, registry, executor.WithClock(fakeClock))
+	executions := NewExecutionService(gormDB, orchestrator, nil, nil, nil)
+
+	tmpl := model.WorkflowTemplate{Name: "t"}
+	if err := gormDB.Create(&tmpl).Error; err != nil {
+		t.Fatalf("create template: %v", err)
+	}
+	steps := []model.TemplateStep{
+		{TemplateID: tmpl.ID, Name: "stuck", Type: "block"},
+		{TemplateID: tmpl.ID, Name: "after", Type: "shell", Config: "true", DependsOn: "stuck"},
+	}
+	for i := range steps {
+		if err := gormDB.Create(&steps[i]).Error; err != nil {
+			t.Fatalf("create step: %v", err)
+		}
+	}
+
+	runDone := make(chan error, 1)
+	go func() {
+		_, err := executions.Start(context.Background(), tmpl.ID, 0, 0, "", false)
+		runDone <- err
+	}()
+
+	select {
+	case <-block.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the stuck step to start")
+	}
+
+	notifier := &collectingNotifier{}
+	watchdog := NewWatchdog(executions, 5*time.Second, true, WithWatchdogClock(fakeClock), WithWatchdogNotifier(notifier))
+	defer watchdog.Close()
+
+	fakeClock.Advance(4 * time.Second)
+	watchdog.Scan(context.Background())
+	if len(notifier.snapshot()) != 0 {
+		t.Fatalf("expected no report before the threshold elapsed, got %v", notifier.snapshot())
+	}
+
+	fakeClock.Advance(2 * time.Second)
+	watchdog.Scan(context.Background())
+
+	select {
+	case err := <-runDone:
+		if err == nil {
+			t.Fatal("expected the execution to fail once its stuck step was force-failed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the force-failed execution to finish")
+	}
+
+	messages := notifier.snapshot()
+	if len(messages) != 1 {
+		t.Fatalf("expected exactly one watchdog report, got %v", messages)
+	}
+	if want := "stuck"; !strings.Contains(messages[0], want) {
+		t.Fatalf("expected the report to name the stuck step, got %q", messages[0])
+	}
+	if want := "after"; !strings.Contains(messages[0], want) {
+		t.Fatalf("expected the report to name the step it's blocking, got %q", messages[0])
+	}
+
+	var se model.StepExecution
+	if err := gormDB.Where("step_name = ?", "stuck").First(&se).Error; err != nil {
+		t.Fatalf("load stuck step execution: %v", err)
+	}
+	if se.Status != model.StatusFailed {
+		t.Fatalf("expected the stuck step to be force-failed, got status %q", se.Status)
+	}
+}
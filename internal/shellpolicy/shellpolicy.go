@@ -0,0 +1,92 @@
+// Package shellpolicy validates shell step configs against a denylist
+// of known-dangerous command patterns and an optional sudo allowlist,
+// so a destructive or exfiltration-prone script can be caught before
+// it's saved in a template or run by the executor, rather than only
+// discovered from its damage.
+package shellpolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultDenylist blocks a short list of well-known destructive or
+// exfiltration-prone command patterns. It's a starting point, not an
+// exhaustive list; operators extend it with their own patterns via
+// New.
+var DefaultDenylist = []string{
+	`rm\s+-[a-zA-Z]*r[a-zA-Z]*f`,
+	`rm\s+-[a-zA-Z]*f[a-zA-Z]*r`,
+	`curl[^|]*\|\s*(sudo\s+)?(sh|bash)\b`,
+	`wget[^|]*\|\s*(sudo\s+)?(sh|bash)\b`,
+	`:\(\)\s*\{\s*:\|:&\s*\};:`,
+	`mkfs\.`,
+	`dd\s+.*of=/dev/`,
+}
+
+// Policy is a compiled set of rules a shell step's Config must satisfy.
+// The zero value rejects nothing; use New or Default to build one with
+// rules.
+type Policy struct {
+	denylist  []*regexp.Regexp
+	sudoAllow []*regexp.Regexp
+}
+
+// New compiles denylist (regexes a script must not match anywhere) and
+// sudoAllow (regexes a sudo invocation's command must match at least
+// one of; a sudo invocation matching none of them is rejected). An
+// empty sudoAllow leaves sudo unrestricted.
+func New(denylist, sudoAllow []string) (*Policy, error) {
+	p := &Policy{}
+	for _, pattern := range denylist {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("shell policy: compile denylist pattern %q: %w", pattern, err)
+		}
+		p.denylist = append(p.denylist, re)
+	}
+	for _, pattern := range sudoAllow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("shell policy: compile sudo allowlist pattern %q: %w", pattern, err)
+		}
+		p.sudoAllow = append(p.sudoAllow, re)
+	}
+	return p, nil
+}
+
+// Default builds a Policy from DefaultDenylist with sudo left
+// unrestricted.
+func Default() (*Policy, error) {
+	return New(DefaultDenylist, nil)
+}
+
+var sudoInvocation = regexp.MustCompile(`\bsudo\s+(\S.*?)(?:[;&|\n]|$)`)
+
+// Validate returns an error describing the first violation found in
+// script, or nil if script satisfies every rule.
+func (p *Policy) Validate(script string) error {
+	for _, re := range p.denylist {
+		if re.MatchString(script) {
+			return fmt.Errorf("shell policy: command matches forbidden pattern %q", re.String())
+		}
+	}
+	if len(p.sudoAllow) == 0 {
+		return nil
+	}
+	for _, match := range sudoInvocation.FindAllStringSubmatch(script, -1) {
+		command := strings.TrimSpace(match[1])
+		allowed := false
+		for _, re := range p.sudoAllow {
+			if re.MatchString(command) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("shell policy: sudo command %q is not in the sudo allowlist", command)
+		}
+	}
+	return nil
+}
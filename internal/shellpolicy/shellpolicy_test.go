@@ -0,0 +1,65 @@
+package shellpolicy
+
+import "testing"
+
+func TestDefaultBlocksRmRfAndPipeToShell(t *testing.T) {
+	policy, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+
+	cases := []string{
+		"rm -rf /var/lib/data",
+		"curl https://example.com/install.sh | sh",
+		"wget -qO- https://example.com/install.sh | bash",
+	}
+	for _, script := range cases {
+		if err := policy.Validate(script); err == nil {
+			t.Errorf("expected %q to be rejected", script)
+		}
+	}
+}
+
+func TestDefaultAllowsOrdinaryCommands(t *testing.T) {
+	policy, err := Default()
+	if err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+
+	if err := policy.Validate("echo hello && ls -la /tmp"); err != nil {
+		t.Fatalf("expected an ordinary command to pass, got %v", err)
+	}
+}
+
+func TestSudoAllowlistRejectsUnlistedCommands(t *testing.T) {
+	policy, err := New(nil, []string{`^systemctl\s+restart\s+nginx$`})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if err := policy.Validate("sudo systemctl restart nginx"); err != nil {
+		t.Fatalf("expected an allowlisted sudo command to pass, got %v", err)
+	}
+	if err := policy.Validate("sudo rm -rf /etc"); err == nil {
+		t.Fatal("expected a sudo command outside the allowlist to be rejected")
+	}
+}
+
+func TestEmptySudoAllowlistLeavesSudoUnrestricted(t *testing.T) {
+	policy, err := New(nil, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := policy.Validate("sudo whoami"); err != nil {
+		t.Fatalf("expected sudo to be unrestricted when no allowlist is set, got %v", err)
+	}
+}
+
+func TestNewRejectsAnInvalidPattern(t *testing.T) {
+	if _, err := New([]string{"(unterminated"}, nil); err == nil {
+		t.Fatal("expected New to reject an invalid denylist regex")
+	}
+	if _, err := New(nil, []string{"(unterminated"}); err == nil {
+		t.Fatal("expected New to reject an invalid sudo allowlist regex")
+	}
+}
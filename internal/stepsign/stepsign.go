@@ -0,0 +1,89 @@
+// Package stepsign signs a TemplateStep's definition with HMAC-SHA256
+// so the engine can detect a step whose stored row was tampered with
+// directly in the database (e.g. a remediation script's Config
+// rewritten to something other than what was authored and signed).
+package stepsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+// ErrSignatureMismatch is returned by Verify when a step's computed
+// signature doesn't match the one it's expected to carry.
+var ErrSignatureMismatch = errors.New("stepsign: signature does not match the step's definition")
+
+// Signer signs and verifies TemplateStep definitions with a single
+// HMAC-SHA256 key.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner builds a Signer from key, rejecting an empty one since an
+// empty key would make every signature trivial to forge.
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) == 0 {
+		return nil, fmt.Errorf("stepsign: key must not be empty")
+	}
+	return &Signer{key: key}, nil
+}
+
+// SignerFromEnvVar builds a Signer from the base64-encoded key in the
+// named environment variable, or returns nil if it's unset, since
+// signing step definitions is opt-in.
+func SignerFromEnvVar(name string) (*Signer, error) {
+	raw := os.Getenv(name)
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("stepsign: decode %s: %w", name, err)
+	}
+	return NewSigner(key)
+}
+
+// canonicalize renders the fields of a step's definition that matter
+// for integrity into a single string, each field length-prefixed so no
+// combination of field values can be confused with another.
+func canonicalize(step model.TemplateStep) string {
+	fields := []string{step.Name, step.Type, step.Config, step.DependsOn, step.DiagnosticType, step.DiagnosticConfig}
+	var b strings.Builder
+	for _, f := range fields {
+		fmt.Fprintf(&b, "%d:%s|", len(f), f)
+	}
+	return b.String()
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of step's definition under
+// s's key.
+func (s *Signer) Sign(step model.TemplateStep) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(canonicalize(step)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns nil if step.ConfigSignature matches the signature
+// Sign would compute for step's current definition, and
+// ErrSignatureMismatch otherwise (including when ConfigSignature is
+// empty).
+func (s *Signer) Verify(step model.TemplateStep) error {
+	want, err := hex.DecodeString(step.ConfigSignature)
+	if err != nil {
+		return ErrSignatureMismatch
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(canonicalize(step)))
+	if !hmac.Equal(want, mac.Sum(nil)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
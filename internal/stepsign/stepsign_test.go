@@ -0,0 +1,81 @@
+package stepsign
+
+import (
+	"encoding/base64"
+	"os"
+	"testing"
+
+	"github.com/fengmingli/orchestrator/internal/model"
+)
+
+func testSigner(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := NewSigner([]byte("a-test-signing-key"))
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer
+}
+
+func TestSignThenVerifySucceeds(t *testing.T) {
+	signer := testSigner(t)
+	step := model.TemplateStep{Name: "deploy", Type: "shell", Config: "make deploy"}
+	step.ConfigSignature = signer.Sign(step)
+
+	if err := signer.Verify(step); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestVerifyRejectsATamperedConfig(t *testing.T) {
+	signer := testSigner(t)
+	step := model.TemplateStep{Name: "deploy", Type: "shell", Config: "make deploy"}
+	step.ConfigSignature = signer.Sign(step)
+
+	step.Config = "rm -rf /"
+	if err := signer.Verify(step); err != ErrSignatureMismatch {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+}
+
+func TestVerifyRejectsAMissingSignature(t *testing.T) {
+	signer := testSigner(t)
+	step := model.TemplateStep{Name: "deploy", Type: "shell", Config: "make deploy"}
+
+	if err := signer.Verify(step); err != ErrSignatureMismatch {
+		t.Fatalf("expected ErrSignatureMismatch, got %v", err)
+	}
+}
+
+func TestNewSignerRejectsAnEmptyKey(t *testing.T) {
+	if _, err := NewSigner(nil); err == nil {
+		t.Fatal("expected NewSigner to reject an empty key")
+	}
+}
+
+func TestSignerFromEnvVarReturnsNilWhenUnset(t *testing.T) {
+	os.Unsetenv("STEPSIGN_TEST_KEY")
+	signer, err := SignerFromEnvVar("STEPSIGN_TEST_KEY")
+	if err != nil {
+		t.Fatalf("SignerFromEnvVar: %v", err)
+	}
+	if signer != nil {
+		t.Fatal("expected a nil Signer when the env var is unset")
+	}
+}
+
+func TestSignerFromEnvVarParsesABase64Key(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("a-test-signing-key"))
+	os.Setenv("STEPSIGN_TEST_KEY", key)
+	defer os.Unsetenv("STEPSIGN_TEST_KEY")
+
+	signer, err := SignerFromEnvVar("STEPSIGN_TEST_KEY")
+	if err != nil {
+		t.Fatalf("SignerFromEnvVar: %v", err)
+	}
+	step := model.TemplateStep{Name: "a", Type: "shell", Config: "echo hi"}
+	step.ConfigSignature = signer.Sign(step)
+	if err := signer.Verify(step); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}